@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apiserver/pkg/quota/v1"
 	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 )
@@ -52,6 +53,7 @@ const (
 	AnnotationAllocated             = QuotaKoordinatorPrefix + "/allocated"
 	AnnotationNonPreemptibleRequest = QuotaKoordinatorPrefix + "/non-preemptible-request"
 	AnnotationNonPreemptibleUsed    = QuotaKoordinatorPrefix + "/non-preemptible-used"
+	AnnotationUsageAccounting       = QuotaKoordinatorPrefix + "/usage-accounting"
 )
 
 func GetParentQuotaName(quota *v1alpha1.ElasticQuota) string {
@@ -195,3 +197,27 @@ func GetChildRequest(quota *v1alpha1.ElasticQuota) (corev1.ResourceList, error)
 	}
 	return request, nil
 }
+
+// QuotaUsageAccounting is the cumulative resource-time usage of the quota since StartTime.
+// The amounts are in resource-hours: core-hours for cpu, GiB-hours for the memory and storage resources,
+// and unit-hours for the others (e.g. gpu).
+type QuotaUsageAccounting struct {
+	StartTime      metav1.Time `json:"startTime"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// Used is the integral of the used resources over time.
+	Used map[corev1.ResourceName]float64 `json:"used,omitempty"`
+	// Borrowed is the integral of the used resources above min over time.
+	Borrowed map[corev1.ResourceName]float64 `json:"borrowed,omitempty"`
+}
+
+func GetUsageAccounting(quota *v1alpha1.ElasticQuota) (*QuotaUsageAccounting, error) {
+	data, ok := quota.Annotations[AnnotationUsageAccounting]
+	if !ok || data == "" {
+		return nil, nil
+	}
+	accounting := &QuotaUsageAccounting{}
+	if err := json.Unmarshal([]byte(data), accounting); err != nil {
+		return nil, err
+	}
+	return accounting, nil
+}
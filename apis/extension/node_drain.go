@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationNodeDrain requests the descheduler to drain the node when it is set to "true".
+	// The node is cordoned and all the Pods on it are migrated by PodMigrationJobs in ReservationFirst mode.
+	// Removing the annotation cancels the drain and uncordons the node if it was cordoned by the drain.
+	AnnotationNodeDrain = SchedulingDomainPrefix + "/drain"
+	// AnnotationNodeDrainStatus reports the progress of the drain, see NodeDrainStatus.
+	AnnotationNodeDrainStatus = SchedulingDomainPrefix + "/drain-status"
+	// LabelNodeDrainName is set on the PodMigrationJobs created by the drain, whose value is the name of the node.
+	LabelNodeDrainName = SchedulingDomainPrefix + "/drain-node"
+)
+
+type NodeDrainPhase string
+
+const (
+	NodeDrainPhaseDraining  NodeDrainPhase = "Draining"
+	NodeDrainPhaseSucceeded NodeDrainPhase = "Succeeded"
+	NodeDrainPhaseFailed    NodeDrainPhase = "Failed"
+	NodeDrainPhaseCancelled NodeDrainPhase = "Cancelled"
+)
+
+type NodeDrainStatus struct {
+	Phase NodeDrainPhase `json:"phase,omitempty"`
+	// Cordoned indicates the node was cordoned by the drain, and it will be uncordoned if the drain is cancelled.
+	Cordoned  bool         `json:"cordoned,omitempty"`
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Total is the number of Pods to be migrated.
+	Total     int `json:"total,omitempty"`
+	Migrating int `json:"migrating,omitempty"`
+	Succeeded int `json:"succeeded,omitempty"`
+	Failed    int `json:"failed,omitempty"`
+	// Failures are the Pods failed to migrate.
+	Failures []NodeDrainFailure `json:"failures,omitempty"`
+}
+
+type NodeDrainFailure struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Job       string `json:"job,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+func IsNodeDrainRequested(annotations map[string]string) bool {
+	return annotations[AnnotationNodeDrain] == "true"
+}
+
+func GetNodeDrainStatus(annotations map[string]string) (*NodeDrainStatus, error) {
+	data, ok := annotations[AnnotationNodeDrainStatus]
+	if !ok {
+		return nil, nil
+	}
+	status := &NodeDrainStatus{}
+	if err := json.Unmarshal([]byte(data), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func SetNodeDrainStatus(obj metav1.Object, status *NodeDrainStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationNodeDrainStatus] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
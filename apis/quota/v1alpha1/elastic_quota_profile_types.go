@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -35,6 +36,27 @@ type ElasticQuotaProfileSpec struct {
 	// NodeSelector defines a node selector to select nodes.
 	// +required
 	NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+	// ChildQuotas defines the child quotas under the quota of the profile. The min of the quota is partitioned
+	// into the child quotas by percentage or weight, and they are updated as the total resource changes.
+	ChildQuotas []ElasticQuotaProfileChildQuota `json:"childQuotas,omitempty"`
+}
+
+type ElasticQuotaProfileChildQuota struct {
+	// Name defines the name of the child quota.
+	// +required
+	Name string `json:"name"`
+	// Labels defines the labels of the child quota.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Percentage is the percentage of the min of the quota taken by the child quota, ranging from 0 to 100.
+	// It takes precedence over the Weight.
+	Percentage *int32 `json:"percentage,omitempty"`
+	// Weight is the weight of the child quota to share the min of the quota left by the child quotas with percentage.
+	Weight *int32 `json:"weight,omitempty"`
+	// Min is the floor of the min of the child quota.
+	Min corev1.ResourceList `json:"min,omitempty"`
+	// Max is the cap of the min of the child quota and is also the max of the child quota.
+	// The max of the child quota is the max of the quota if it is not specified.
+	Max corev1.ResourceList `json:"max,omitempty"`
 }
 
 type ElasticQuotaProfileStatus struct {
@@ -81,6 +81,8 @@ type VirtualFunction struct {
 }
 
 type DeviceStatus struct {
+	// Allocations are the devices allocated out of the koord-scheduler, e.g. by the kubelet device plugins.
+	// The scheduler does not allocate them to the other pods.
 	Allocations []DeviceAllocation `json:"allocations,omitempty"`
 }
 
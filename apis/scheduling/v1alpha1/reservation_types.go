@@ -185,6 +185,7 @@ const (
 	ReasonReservationAvailable = "Available"
 	ReasonReservationSucceeded = "Succeeded"
 	ReasonReservationExpired   = "Expired"
+	ReasonReservationPreempted = "Preempted"
 )
 
 type ReservationCondition struct {
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -45,6 +46,10 @@ type NodeMetricInfo struct {
 	// AggregatedSystemUsages will report only if there are enough samples
 	// Deleted pods will be excluded during aggregation
 	AggregatedSystemUsages []AggregatedUsage `json:"aggregatedSystemUsages,omitempty"`
+	// NetworkUsage is the traffic rate summed over the physical NICs of node
+	NetworkUsage *NetworkUsage `json:"networkUsage,omitempty"`
+	// BlockIOUsages is the block I/O rates of each disk of node
+	BlockIOUsages []BlockIOUsage `json:"blockIOUsages,omitempty"`
 }
 
 type AggregatedUsage struct {
@@ -62,6 +67,70 @@ type PodMetricInfo struct {
 	QoS apiext.QoSClass `json:"qos,omitempty"`
 	// Third party extensions for PodMetric
 	Extensions *ExtensionsMap `json:"extensions,omitempty"`
+	// NetworkUsage is the traffic rate of the pod network namespace, not reported for host-network pods
+	NetworkUsage *NetworkUsage `json:"networkUsage,omitempty"`
+	// BlockIOUsages is the block I/O rates of the pod on each device
+	BlockIOUsages []BlockIOUsage `json:"blockIOUsages,omitempty"`
+	// Interference is the interference indicators of the pod, reported if the CPI or PSI collector is enabled
+	Interference *PodInterference `json:"interference,omitempty"`
+}
+
+// NetworkUsage describes the average network traffic rates in the aggregate duration.
+type NetworkUsage struct {
+	// RxBytes is the received bytes per second
+	RxBytes resource.Quantity `json:"rxBytes,omitempty"`
+	// TxBytes is the transmitted bytes per second
+	TxBytes resource.Quantity `json:"txBytes,omitempty"`
+	// RxPackets is the received packets per second
+	RxPackets resource.Quantity `json:"rxPackets,omitempty"`
+	// TxPackets is the transmitted packets per second
+	TxPackets resource.Quantity `json:"txPackets,omitempty"`
+	// RxDrops is the dropped received packets per second
+	RxDrops resource.Quantity `json:"rxDrops,omitempty"`
+	// TxDrops is the dropped transmitted packets per second
+	TxDrops resource.Quantity `json:"txDrops,omitempty"`
+}
+
+// BlockIOUsage describes the average block I/O rates of a device in the aggregate duration.
+type BlockIOUsage struct {
+	// Device is the name of the block device, e.g. sda, or the device number `major:minor` if the name is unknown
+	Device string `json:"device"`
+	// ReadBytes is the read bytes per second
+	ReadBytes resource.Quantity `json:"readBytes,omitempty"`
+	// WriteBytes is the written bytes per second
+	WriteBytes resource.Quantity `json:"writeBytes,omitempty"`
+	// ReadIOPS is the completed read I/Os per second
+	ReadIOPS resource.Quantity `json:"readIOPS,omitempty"`
+	// WriteIOPS is the completed write I/Os per second
+	WriteIOPS resource.Quantity `json:"writeIOPS,omitempty"`
+	// ReadLatency is the average milliseconds spent by each read I/O, only reported for the node
+	ReadLatency *resource.Quantity `json:"readLatency,omitempty"`
+	// WriteLatency is the average milliseconds spent by each write I/O, only reported for the node
+	WriteLatency *resource.Quantity `json:"writeLatency,omitempty"`
+}
+
+// PodInterference describes the interference indicators of a pod in the aggregate duration.
+type PodInterference struct {
+	// CPI is the average cycles per instruction of the pod
+	CPI *resource.Quantity `json:"cpi,omitempty"`
+	// BaselineCPI is the long-term moving average of the CPI of the pod before the aggregate duration,
+	// which is regarded as the CPI of the pod without interference
+	BaselineCPI *resource.Quantity `json:"baselineCPI,omitempty"`
+	// CPIDeviation is the relative deviation of the CPI from the baseline, i.e. (CPI - BaselineCPI) / BaselineCPI
+	CPIDeviation *resource.Quantity `json:"cpiDeviation,omitempty"`
+	// PSI is the average pressure stall information of the pod
+	PSI *PressureStall `json:"psi,omitempty"`
+}
+
+// PressureStall describes the percentages of time in the last 10 seconds in which some or all tasks of the pod
+// were stalled on the resources.
+type PressureStall struct {
+	CPUSome    resource.Quantity `json:"cpuSome,omitempty"`
+	CPUFull    resource.Quantity `json:"cpuFull,omitempty"`
+	MemorySome resource.Quantity `json:"memorySome,omitempty"`
+	MemoryFull resource.Quantity `json:"memoryFull,omitempty"`
+	IOSome     resource.Quantity `json:"ioSome,omitempty"`
+	IOFull     resource.Quantity `json:"ioFull,omitempty"`
 }
 
 type HostApplicationMetricInfo struct {
@@ -217,12 +217,14 @@ type NetworkQOS struct {
 	// unit: bps(bytes per second), two expressions are supported，int and string,
 	// int: percentage based on total bandwidth，valid in 0-100
 	// string: a specific network bandwidth value, eg: 50M.
+	// It is not enforced by koordlet yet.
 	// +kubebuilder:default=0
 	IngressRequest *intstr.IntOrString `json:"ingressRequest,omitempty"`
 	// IngressLimit describes the maximum network bandwidth can be used in the ingress direction,
 	// unit: bps(bytes per second), two expressions are supported，int and string,
 	// int: percentage based on total bandwidth，valid in 0-100
 	// string: a specific network bandwidth value, eg: 50M.
+	// It is not enforced by koordlet yet.
 	// +kubebuilder:default=100
 	IngressLimit *intstr.IntOrString `json:"ingressLimit,omitempty"`
 
@@ -314,6 +316,41 @@ type ResourceThresholdStrategy struct {
 	// CPUEvictPolicy defines the policy for the BECPUEvict feature.
 	// Default: `evictByRealLimit`.
 	CPUEvictPolicy CPUEvictPolicy `json:"cpuEvictPolicy,omitempty"`
+
+	// MemoryPressureThrottle throttles the memory of BE pods according to the memory pressure of LS pods.
+	MemoryPressureThrottle *MemoryPressureThrottleStrategy `json:"memoryPressureThrottle,omitempty"`
+}
+
+// MemoryPressureThrottleStrategy lowers the memory.high of the besteffort cgroup step by step when the memory PSI of
+// LS pods rises above the upper thresholds, and raises it step by step when the PSI falls below the lower thresholds,
+// so that the memory of BE pods is reclaimed before the memory eviction.
+type MemoryPressureThrottleStrategy struct {
+	// whether the strategy is enabled, default = false
+	Enable *bool `json:"enable,omitempty"`
+	// throttle BE pods if the memory PSI some avg10 of any LS pod reaches the percentage, default = 20
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	PSISomeUpperPercent *int64 `json:"psiSomeUpperPercent,omitempty" validate:"omitempty,min=0,max=100,gtfield=PSISomeLowerPercent"`
+	// relax BE pods if the memory PSI some avg10 of all LS pods are below the percentage, default = 5
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	PSISomeLowerPercent *int64 `json:"psiSomeLowerPercent,omitempty" validate:"omitempty,min=0,max=100,ltfield=PSISomeUpperPercent"`
+	// throttle BE pods if the memory PSI full avg10 of any LS pod reaches the percentage, default = 5
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	PSIFullUpperPercent *int64 `json:"psiFullUpperPercent,omitempty" validate:"omitempty,min=0,max=100,gtfield=PSIFullLowerPercent"`
+	// relax BE pods if the memory PSI full avg10 of all LS pods are below the percentage, default = 1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	PSIFullLowerPercent *int64 `json:"psiFullLowerPercent,omitempty" validate:"omitempty,min=0,max=100,ltfield=PSIFullUpperPercent"`
+	// the percentage of memory.high to lower or raise in each round, default = 10
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=1
+	StepPercent *int64 `json:"stepPercent,omitempty" validate:"omitempty,min=1,max=100"`
+	// the lower bound of the memory.high of the besteffort cgroup, percentage of the node memory capacity, default = 10
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	MinMemoryHighPercent *int64 `json:"minMemoryHighPercent,omitempty" validate:"omitempty,min=0,max=100"`
 }
 
 // ResctrlQOSCfg stores node-level config of resctrl qos
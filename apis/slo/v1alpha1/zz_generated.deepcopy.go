@@ -136,6 +136,35 @@ func (in *BlockCfg) DeepCopy() *BlockCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockIOUsage) DeepCopyInto(out *BlockIOUsage) {
+	*out = *in
+	out.ReadBytes = in.ReadBytes.DeepCopy()
+	out.WriteBytes = in.WriteBytes.DeepCopy()
+	out.ReadIOPS = in.ReadIOPS.DeepCopy()
+	out.WriteIOPS = in.WriteIOPS.DeepCopy()
+	if in.ReadLatency != nil {
+		in, out := &in.ReadLatency, &out.ReadLatency
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.WriteLatency != nil {
+		in, out := &in.WriteLatency, &out.WriteLatency
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockIOUsage.
+func (in *BlockIOUsage) DeepCopy() *BlockIOUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockIOUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CPUBurstConfig) DeepCopyInto(out *CPUBurstConfig) {
 	*out = *in
@@ -359,6 +388,56 @@ func (in *IOCfg) DeepCopy() *IOCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryPressureThrottleStrategy) DeepCopyInto(out *MemoryPressureThrottleStrategy) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PSISomeUpperPercent != nil {
+		in, out := &in.PSISomeUpperPercent, &out.PSISomeUpperPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PSISomeLowerPercent != nil {
+		in, out := &in.PSISomeLowerPercent, &out.PSISomeLowerPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PSIFullUpperPercent != nil {
+		in, out := &in.PSIFullUpperPercent, &out.PSIFullUpperPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PSIFullLowerPercent != nil {
+		in, out := &in.PSIFullLowerPercent, &out.PSIFullLowerPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.StepPercent != nil {
+		in, out := &in.StepPercent, &out.StepPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinMemoryHighPercent != nil {
+		in, out := &in.MinMemoryHighPercent, &out.MinMemoryHighPercent
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryPressureThrottleStrategy.
+func (in *MemoryPressureThrottleStrategy) DeepCopy() *MemoryPressureThrottleStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryPressureThrottleStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemoryQOS) DeepCopyInto(out *MemoryQOS) {
 	*out = *in
@@ -496,6 +575,27 @@ func (in *NetworkQOSCfg) DeepCopy() *NetworkQOSCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkUsage) DeepCopyInto(out *NetworkUsage) {
+	*out = *in
+	out.RxBytes = in.RxBytes.DeepCopy()
+	out.TxBytes = in.TxBytes.DeepCopy()
+	out.RxPackets = in.RxPackets.DeepCopy()
+	out.TxPackets = in.TxPackets.DeepCopy()
+	out.RxDrops = in.RxDrops.DeepCopy()
+	out.TxDrops = in.TxDrops.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkUsage.
+func (in *NetworkUsage) DeepCopy() *NetworkUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeMetric) DeepCopyInto(out *NodeMetric) {
 	*out = *in
@@ -577,6 +677,18 @@ func (in *NodeMetricInfo) DeepCopyInto(out *NodeMetricInfo) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NetworkUsage != nil {
+		in, out := &in.NetworkUsage, &out.NetworkUsage
+		*out = new(NetworkUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockIOUsages != nil {
+		in, out := &in.BlockIOUsages, &out.BlockIOUsages
+		*out = make([]BlockIOUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetricInfo.
@@ -850,6 +962,41 @@ func (in *PodMemoryQOSConfig) DeepCopy() *PodMemoryQOSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodInterference) DeepCopyInto(out *PodInterference) {
+	*out = *in
+	if in.CPI != nil {
+		in, out := &in.CPI, &out.CPI
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BaselineCPI != nil {
+		in, out := &in.BaselineCPI, &out.BaselineCPI
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPIDeviation != nil {
+		in, out := &in.CPIDeviation, &out.CPIDeviation
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.PSI != nil {
+		in, out := &in.PSI, &out.PSI
+		*out = new(PressureStall)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodInterference.
+func (in *PodInterference) DeepCopy() *PodInterference {
+	if in == nil {
+		return nil
+	}
+	out := new(PodInterference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodMetricInfo) DeepCopyInto(out *PodMetricInfo) {
 	*out = *in
@@ -858,6 +1005,23 @@ func (in *PodMetricInfo) DeepCopyInto(out *PodMetricInfo) {
 		in, out := &in.Extensions, &out.Extensions
 		*out = (*in).DeepCopy()
 	}
+	if in.NetworkUsage != nil {
+		in, out := &in.NetworkUsage, &out.NetworkUsage
+		*out = new(NetworkUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockIOUsages != nil {
+		in, out := &in.BlockIOUsages, &out.BlockIOUsages
+		*out = make([]BlockIOUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Interference != nil {
+		in, out := &in.Interference, &out.Interference
+		*out = new(PodInterference)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMetricInfo.
@@ -870,6 +1034,27 @@ func (in *PodMetricInfo) DeepCopy() *PodMetricInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PressureStall) DeepCopyInto(out *PressureStall) {
+	*out = *in
+	out.CPUSome = in.CPUSome.DeepCopy()
+	out.CPUFull = in.CPUFull.DeepCopy()
+	out.MemorySome = in.MemorySome.DeepCopy()
+	out.MemoryFull = in.MemoryFull.DeepCopy()
+	out.IOSome = in.IOSome.DeepCopy()
+	out.IOFull = in.IOFull.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PressureStall.
+func (in *PressureStall) DeepCopy() *PressureStall {
+	if in == nil {
+		return nil
+	}
+	out := new(PressureStall)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReclaimableMetric) DeepCopyInto(out *ReclaimableMetric) {
 	*out = *in
@@ -1114,6 +1299,11 @@ func (in *ResourceThresholdStrategy) DeepCopyInto(out *ResourceThresholdStrategy
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MemoryPressureThrottle != nil {
+		in, out := &in.MemoryPressureThrottle, &out.MemoryPressureThrottle
+		*out = new(MemoryPressureThrottleStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceThresholdStrategy.
@@ -25,15 +25,18 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/nodemetric"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/noderesource"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/nodeslo"
+	yarncontroller "github.com/koordinator-sh/koordinator/pkg/yarn/controller"
 )
 
 var controllerInitFlags = map[string]func(*flag.FlagSet){
-	noderesource.Name: noderesource.InitFlags,
+	noderesource.Name:   noderesource.InitFlags,
+	yarncontroller.Name: yarncontroller.InitFlags,
 }
 
 var controllerAddFuncs = map[string]func(manager.Manager) error{
-	nodemetric.Name:   nodemetric.Add,
-	noderesource.Name: noderesource.Add,
-	nodeslo.Name:      nodeslo.Add,
-	profile.Name:      profile.Add,
+	nodemetric.Name:     nodemetric.Add,
+	noderesource.Name:   noderesource.Add,
+	nodeslo.Name:        nodeslo.Add,
+	profile.Name:        profile.Add,
+	yarncontroller.Name: yarncontroller.Add,
 }
@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/yarn/copilot"
+)
+
+func main() {
+	cfg := copilot.NewDefaultConfig()
+	cfg.InitFlags(flag.CommandLine)
+	system.Conf.InitFlags(flag.CommandLine)
+	logs.AddGoFlags(flag.CommandLine)
+	flag.Parse()
+
+	go wait.Forever(klog.Flush, 5*time.Second)
+	defer klog.Flush()
+
+	stopCtx := signals.SetupSignalHandler()
+	if err := copilot.NewYarnCopilot(cfg).Run(stopCtx.Done()); err != nil {
+		klog.Fatal("Unable to run the yarn copilot: ", err)
+	}
+}
@@ -20,10 +20,12 @@ import (
 	"flag"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/component-base/logs"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
@@ -61,11 +63,6 @@ func main() {
 
 	stopCtx := signals.SetupSignalHandler()
 
-	// setup the default auditor
-	if features.DefaultKoordletFeatureGate.Enabled(features.AuditEvents) {
-		audit.SetupDefaultAuditor(cfg.AuditConf, stopCtx.Done())
-	}
-
 	// Get a config to talk to the apiserver
 	klog.Info("Setting up kubeconfig for koordlet")
 	err := cfg.InitKubeConfigForKoordlet(*options.KubeAPIQPS, *options.KubeAPIBurst)
@@ -73,6 +70,16 @@ func main() {
 		klog.Fatalf("Unable to setup kubeconfig: %v", err)
 	}
 
+	// setup the default auditor
+	if features.DefaultKoordletFeatureGate.Enabled(features.AuditEvents) {
+		var sinks []audit.EventWriter
+		if features.DefaultKoordletFeatureGate.Enabled(features.AuditEventsKubeEvent) {
+			kubeClient := clientset.NewForConfigOrDie(cfg.KubeRestConf)
+			sinks = append(sinks, audit.NewKubeEventWriter(cfg.AuditConf, kubeClient, os.Getenv("NODE_NAME")))
+		}
+		audit.SetupDefaultAuditor(cfg.AuditConf, stopCtx.Done(), sinks...)
+	}
+
 	d, err := agent.NewDaemon(cfg)
 	if err != nil {
 		klog.Fatalf("Unable to setup koordlet daemon: %v", err)
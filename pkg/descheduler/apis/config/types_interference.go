@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// InterferenceArgs holds arguments used to configure the Interference plugin.
+type InterferenceArgs struct {
+	metav1.TypeMeta
+
+	// Paused indicates whether the Interference should to work or not.
+	// Default is false
+	Paused bool
+
+	// DryRun means only execute the entire deschedule logic but don't migrate Pod
+	// Default is false
+	DryRun bool
+
+	// NodeSelector selects the nodes that matched labelSelector
+	NodeSelector *metav1.LabelSelector
+
+	// EvictableNamespaces carries a list of included/excluded namespaces of the pods to be migrated
+	EvictableNamespaces *Namespaces
+
+	// NodeMetricExpirationSeconds indicates the NodeMetric expiration in seconds.
+	// When NodeMetrics expired, the node is skipped.
+	// Default is 180 seconds.
+	NodeMetricExpirationSeconds *int64
+
+	// CPIDeviationThresholdPercent indicates the latency-sensitive pod is interfered
+	// when its CPI exceeds its own baseline by the percentage. Zero means the CPI is not considered.
+	// Default is 50
+	CPIDeviationThresholdPercent int64
+
+	// PSISomeThresholdPercent indicates the latency-sensitive pod is interfered when the percentage of time
+	// in which some of its tasks are stalled on CPU, memory or IO exceeds it. Zero means the PSI some is not considered.
+	// Default is 40
+	PSISomeThresholdPercent int64
+
+	// PSIFullThresholdPercent indicates the latency-sensitive pod is interfered when the percentage of time
+	// in which all of its tasks are stalled on CPU, memory or IO exceeds it. Zero means the PSI full is not considered.
+	// Default is 20
+	PSIFullThresholdPercent int64
+
+	// AnomalyCondition indicates how many consecutive times the pod is interfered before it is regarded as a victim,
+	// the default is 3 consecutive times.
+	AnomalyCondition *LoadAnomalyCondition
+
+	// MigrationTarget indicates which pod is migrated to resolve the interference, the aggressor or the victim.
+	// Default is Aggressor
+	MigrationTarget InterferenceMigrationTarget
+
+	// CooldownSeconds indicates the minimum interval in seconds between two migrations on the same node,
+	// so that the effect of the last migration can be observed.
+	// Default is 600 seconds.
+	CooldownSeconds int64
+}
+
+type InterferenceMigrationTarget string
+
+const (
+	// InterferenceMigrateAggressor migrates the most likely aggressor on the node of the victim.
+	InterferenceMigrateAggressor InterferenceMigrationTarget = "Aggressor"
+	// InterferenceMigrateVictim migrates the victim to another node.
+	InterferenceMigrateVictim InterferenceMigrationTarget = "Victim"
+)
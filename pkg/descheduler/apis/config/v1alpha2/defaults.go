@@ -45,6 +45,15 @@ const (
 	defaultMigrationEvictBurst         = 1
 	defaultSchedulerSupportReservation = "koord-scheduler"
 	defaultArbitrationInterval         = 500 * time.Millisecond
+
+	defaultDefragmentationMaxMigratingPerNode = 2
+	defaultMinFragmentedPCPUs                 = 2
+
+	defaultCPIDeviationThresholdPercent         int64 = 50
+	defaultPSISomeThresholdPercent              int64 = 40
+	defaultPSIFullThresholdPercent              int64 = 20
+	defaultInterferenceConsecutiveAbnormalities       = 3
+	defaultInterferenceCooldownSeconds          int64 = 600
 )
 
 var (
@@ -288,3 +297,47 @@ func SetDefaults_LowNodeLoadArgs(obj *LowNodeLoadArgs) {
 		}
 	}
 }
+
+func SetDefaults_CPUDefragmentationArgs(obj *CPUDefragmentationArgs) {
+	if obj.MaxMigratingPerNode == nil {
+		obj.MaxMigratingPerNode = pointer.Int32(defaultDefragmentationMaxMigratingPerNode)
+	}
+	if obj.NUMANodeDefragmentation == nil {
+		obj.NUMANodeDefragmentation = pointer.Bool(true)
+	}
+	if obj.FullPCPUsDefragmentation == nil {
+		obj.FullPCPUsDefragmentation = pointer.Bool(true)
+	}
+	if obj.MinFragmentedPCPUs == nil {
+		obj.MinFragmentedPCPUs = pointer.Int32(defaultMinFragmentedPCPUs)
+	}
+}
+
+func SetDefaults_InterferenceArgs(obj *InterferenceArgs) {
+	if obj.NodeMetricExpirationSeconds == nil {
+		obj.NodeMetricExpirationSeconds = pointer.Int64(defaultNodeMetricExpirationSeconds)
+	}
+	if obj.CPIDeviationThresholdPercent == nil {
+		obj.CPIDeviationThresholdPercent = pointer.Int64(defaultCPIDeviationThresholdPercent)
+	}
+	if obj.PSISomeThresholdPercent == nil {
+		obj.PSISomeThresholdPercent = pointer.Int64(defaultPSISomeThresholdPercent)
+	}
+	if obj.PSIFullThresholdPercent == nil {
+		obj.PSIFullThresholdPercent = pointer.Int64(defaultPSIFullThresholdPercent)
+	}
+	if obj.AnomalyCondition == nil {
+		obj.AnomalyCondition = &LoadAnomalyCondition{
+			Timeout:                  &metav1.Duration{Duration: 5 * time.Minute},
+			ConsecutiveAbnormalities: defaultInterferenceConsecutiveAbnormalities,
+		}
+	} else if obj.AnomalyCondition.ConsecutiveAbnormalities == 0 {
+		obj.AnomalyCondition.ConsecutiveAbnormalities = defaultInterferenceConsecutiveAbnormalities
+	}
+	if obj.MigrationTarget == "" {
+		obj.MigrationTarget = InterferenceMigrateAggressor
+	}
+	if obj.CooldownSeconds == nil {
+		obj.CooldownSeconds = pointer.Int64(defaultInterferenceCooldownSeconds)
+	}
+}
@@ -107,3 +107,116 @@ func TestSetDefaults_LowNodeLoadArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDefaults_CPUDefragmentationArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     *CPUDefragmentationArgs
+		expected *CPUDefragmentationArgs
+	}{
+		{
+			name: "set default",
+			args: &CPUDefragmentationArgs{},
+			expected: &CPUDefragmentationArgs{
+				MaxMigratingPerNode:      pointer.Int32(2),
+				NUMANodeDefragmentation:  pointer.Bool(true),
+				FullPCPUsDefragmentation: pointer.Bool(true),
+				MinFragmentedPCPUs:       pointer.Int32(2),
+			},
+		},
+		{
+			name: "keep the specified values",
+			args: &CPUDefragmentationArgs{
+				MaxMigratingPerNode:      pointer.Int32(4),
+				NUMANodeDefragmentation:  pointer.Bool(false),
+				FullPCPUsDefragmentation: pointer.Bool(false),
+				MinFragmentedPCPUs:       pointer.Int32(8),
+			},
+			expected: &CPUDefragmentationArgs{
+				MaxMigratingPerNode:      pointer.Int32(4),
+				NUMANodeDefragmentation:  pointer.Bool(false),
+				FullPCPUsDefragmentation: pointer.Bool(false),
+				MinFragmentedPCPUs:       pointer.Int32(8),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDefaults_CPUDefragmentationArgs(tt.args)
+			assert.Equal(t, tt.expected, tt.args)
+		})
+	}
+}
+
+func TestSetDefaults_InterferenceArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     *InterferenceArgs
+		expected *InterferenceArgs
+	}{
+		{
+			name: "set default",
+			args: &InterferenceArgs{},
+			expected: &InterferenceArgs{
+				NodeMetricExpirationSeconds:  pointer.Int64(defaultNodeMetricExpirationSeconds),
+				CPIDeviationThresholdPercent: pointer.Int64(50),
+				PSISomeThresholdPercent:      pointer.Int64(40),
+				PSIFullThresholdPercent:      pointer.Int64(20),
+				AnomalyCondition: &LoadAnomalyCondition{
+					Timeout:                  &metav1.Duration{Duration: 5 * time.Minute},
+					ConsecutiveAbnormalities: 3,
+				},
+				MigrationTarget: InterferenceMigrateAggressor,
+				CooldownSeconds: pointer.Int64(600),
+			},
+		},
+		{
+			name: "keep the specified values",
+			args: &InterferenceArgs{
+				NodeMetricExpirationSeconds:  pointer.Int64(60),
+				CPIDeviationThresholdPercent: pointer.Int64(0),
+				PSISomeThresholdPercent:      pointer.Int64(30),
+				PSIFullThresholdPercent:      pointer.Int64(0),
+				AnomalyCondition: &LoadAnomalyCondition{
+					ConsecutiveAbnormalities: 5,
+				},
+				MigrationTarget: InterferenceMigrateVictim,
+				CooldownSeconds: pointer.Int64(0),
+			},
+			expected: &InterferenceArgs{
+				NodeMetricExpirationSeconds:  pointer.Int64(60),
+				CPIDeviationThresholdPercent: pointer.Int64(0),
+				PSISomeThresholdPercent:      pointer.Int64(30),
+				PSIFullThresholdPercent:      pointer.Int64(0),
+				AnomalyCondition: &LoadAnomalyCondition{
+					ConsecutiveAbnormalities: 5,
+				},
+				MigrationTarget: InterferenceMigrateVictim,
+				CooldownSeconds: pointer.Int64(0),
+			},
+		},
+		{
+			name: "set default consecutiveAbnormalities",
+			args: &InterferenceArgs{
+				AnomalyCondition: &LoadAnomalyCondition{},
+			},
+			expected: &InterferenceArgs{
+				NodeMetricExpirationSeconds:  pointer.Int64(defaultNodeMetricExpirationSeconds),
+				CPIDeviationThresholdPercent: pointer.Int64(50),
+				PSISomeThresholdPercent:      pointer.Int64(40),
+				PSIFullThresholdPercent:      pointer.Int64(20),
+				AnomalyCondition: &LoadAnomalyCondition{
+					ConsecutiveAbnormalities: 3,
+				},
+				MigrationTarget: InterferenceMigrateAggressor,
+				CooldownSeconds: pointer.Int64(600),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDefaults_InterferenceArgs(tt.args)
+			assert.Equal(t, tt.expected, tt.args)
+		})
+	}
+}
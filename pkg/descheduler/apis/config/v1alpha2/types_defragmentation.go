@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CPUDefragmentationArgs holds arguments used to configure the CPUDefragmentation plugin.
+type CPUDefragmentationArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Paused indicates whether the CPUDefragmentation should to work or not.
+	// Default is false
+	Paused *bool `json:"paused,omitempty"`
+
+	// DryRun means only execute the entire deschedule logic but don't migrate Pod
+	// Default is false
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// NodeSelector selects the nodes that matched labelSelector
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// EvictableNamespaces carries a list of included/excluded namespaces of the pods to be migrated
+	EvictableNamespaces *Namespaces `json:"evictableNamespaces,omitempty"`
+
+	// MaxMigratingPerNode represents the maximum number of pods migrated from one node in one descheduling cycle.
+	// A compaction plan which needs to migrate more pods is skipped.
+	// Default is 2
+	MaxMigratingPerNode *int32 `json:"maxMigratingPerNode,omitempty"`
+
+	// NUMANodeDefragmentation indicates whether to migrate pods to free a whole NUMA Node
+	// when no NUMA Node of the node is free but the free CPUs are enough to hold one.
+	// Default is true
+	NUMANodeDefragmentation *bool `json:"numaNodeDefragmentation,omitempty"`
+
+	// FullPCPUsDefragmentation indicates whether to migrate pods to free the physical cores
+	// which are partially allocated.
+	// Default is true
+	FullPCPUsDefragmentation *bool `json:"fullPCPUsDefragmentation,omitempty"`
+
+	// MinFragmentedPCPUs indicates the minimum number of the partially allocated physical cores on a node
+	// to trigger the defragmentation of physical cores.
+	// Default is 2
+	MinFragmentedPCPUs *int32 `json:"minFragmentedPCPUs,omitempty"`
+}
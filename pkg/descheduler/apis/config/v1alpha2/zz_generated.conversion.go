@@ -50,6 +50,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CPUDefragmentationArgs)(nil), (*config.CPUDefragmentationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CPUDefragmentationArgs_To_config_CPUDefragmentationArgs(a.(*CPUDefragmentationArgs), b.(*config.CPUDefragmentationArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CPUDefragmentationArgs)(nil), (*CPUDefragmentationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CPUDefragmentationArgs_To_v1alpha2_CPUDefragmentationArgs(a.(*config.CPUDefragmentationArgs), b.(*CPUDefragmentationArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DeschedulerProfile)(nil), (*config.DeschedulerProfile)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_DeschedulerProfile_To_config_DeschedulerProfile(a.(*DeschedulerProfile), b.(*config.DeschedulerProfile), scope)
 	}); err != nil {
@@ -60,6 +70,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*InterferenceArgs)(nil), (*config.InterferenceArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_InterferenceArgs_To_config_InterferenceArgs(a.(*InterferenceArgs), b.(*config.InterferenceArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.InterferenceArgs)(nil), (*InterferenceArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_InterferenceArgs_To_v1alpha2_InterferenceArgs(a.(*config.InterferenceArgs), b.(*InterferenceArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*LoadAnomalyCondition)(nil), (*config.LoadAnomalyCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(a.(*LoadAnomalyCondition), b.(*config.LoadAnomalyCondition), scope)
 	}); err != nil {
@@ -215,6 +235,64 @@ func Convert_config_ArbitrationArgs_To_v1alpha2_ArbitrationArgs(in *config.Arbit
 	return autoConvert_config_ArbitrationArgs_To_v1alpha2_ArbitrationArgs(in, out, s)
 }
 
+func autoConvert_v1alpha2_CPUDefragmentationArgs_To_config_CPUDefragmentationArgs(in *CPUDefragmentationArgs, out *config.CPUDefragmentationArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_bool_To_bool(&in.Paused, &out.Paused, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	out.EvictableNamespaces = (*config.Namespaces)(unsafe.Pointer(in.EvictableNamespaces))
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxMigratingPerNode, &out.MaxMigratingPerNode, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.NUMANodeDefragmentation, &out.NUMANodeDefragmentation, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.FullPCPUsDefragmentation, &out.FullPCPUsDefragmentation, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MinFragmentedPCPUs, &out.MinFragmentedPCPUs, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_CPUDefragmentationArgs_To_config_CPUDefragmentationArgs is an autogenerated conversion function.
+func Convert_v1alpha2_CPUDefragmentationArgs_To_config_CPUDefragmentationArgs(in *CPUDefragmentationArgs, out *config.CPUDefragmentationArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CPUDefragmentationArgs_To_config_CPUDefragmentationArgs(in, out, s)
+}
+
+func autoConvert_config_CPUDefragmentationArgs_To_v1alpha2_CPUDefragmentationArgs(in *config.CPUDefragmentationArgs, out *CPUDefragmentationArgs, s conversion.Scope) error {
+	if err := v1.Convert_bool_To_Pointer_bool(&in.Paused, &out.Paused, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	out.EvictableNamespaces = (*Namespaces)(unsafe.Pointer(in.EvictableNamespaces))
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxMigratingPerNode, &out.MaxMigratingPerNode, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.NUMANodeDefragmentation, &out.NUMANodeDefragmentation, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.FullPCPUsDefragmentation, &out.FullPCPUsDefragmentation, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MinFragmentedPCPUs, &out.MinFragmentedPCPUs, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_CPUDefragmentationArgs_To_v1alpha2_CPUDefragmentationArgs is an autogenerated conversion function.
+func Convert_config_CPUDefragmentationArgs_To_v1alpha2_CPUDefragmentationArgs(in *config.CPUDefragmentationArgs, out *CPUDefragmentationArgs, s conversion.Scope) error {
+	return autoConvert_config_CPUDefragmentationArgs_To_v1alpha2_CPUDefragmentationArgs(in, out, s)
+}
+
 func autoConvert_v1alpha2_DeschedulerConfiguration_To_config_DeschedulerConfiguration(in *DeschedulerConfiguration, out *config.DeschedulerConfiguration, s conversion.Scope) error {
 	if err := v1alpha1.Convert_v1alpha1_LeaderElectionConfiguration_To_config_LeaderElectionConfiguration(&in.LeaderElection, &out.LeaderElection, s); err != nil {
 		return err
@@ -329,6 +407,86 @@ func Convert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in *config
 	return autoConvert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in, out, s)
 }
 
+func autoConvert_v1alpha2_InterferenceArgs_To_config_InterferenceArgs(in *InterferenceArgs, out *config.InterferenceArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_bool_To_bool(&in.Paused, &out.Paused, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	out.EvictableNamespaces = (*config.Namespaces)(unsafe.Pointer(in.EvictableNamespaces))
+	out.NodeMetricExpirationSeconds = (*int64)(unsafe.Pointer(in.NodeMetricExpirationSeconds))
+	if err := v1.Convert_Pointer_int64_To_int64(&in.CPIDeviationThresholdPercent, &out.CPIDeviationThresholdPercent, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.PSISomeThresholdPercent, &out.PSISomeThresholdPercent, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.PSIFullThresholdPercent, &out.PSIFullThresholdPercent, s); err != nil {
+		return err
+	}
+	if in.AnomalyCondition != nil {
+		in, out := &in.AnomalyCondition, &out.AnomalyCondition
+		*out = new(config.LoadAnomalyCondition)
+		if err := Convert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AnomalyCondition = nil
+	}
+	out.MigrationTarget = config.InterferenceMigrationTarget(in.MigrationTarget)
+	if err := v1.Convert_Pointer_int64_To_int64(&in.CooldownSeconds, &out.CooldownSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_InterferenceArgs_To_config_InterferenceArgs is an autogenerated conversion function.
+func Convert_v1alpha2_InterferenceArgs_To_config_InterferenceArgs(in *InterferenceArgs, out *config.InterferenceArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_InterferenceArgs_To_config_InterferenceArgs(in, out, s)
+}
+
+func autoConvert_config_InterferenceArgs_To_v1alpha2_InterferenceArgs(in *config.InterferenceArgs, out *InterferenceArgs, s conversion.Scope) error {
+	if err := v1.Convert_bool_To_Pointer_bool(&in.Paused, &out.Paused, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	out.EvictableNamespaces = (*Namespaces)(unsafe.Pointer(in.EvictableNamespaces))
+	out.NodeMetricExpirationSeconds = (*int64)(unsafe.Pointer(in.NodeMetricExpirationSeconds))
+	if err := v1.Convert_int64_To_Pointer_int64(&in.CPIDeviationThresholdPercent, &out.CPIDeviationThresholdPercent, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.PSISomeThresholdPercent, &out.PSISomeThresholdPercent, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.PSIFullThresholdPercent, &out.PSIFullThresholdPercent, s); err != nil {
+		return err
+	}
+	if in.AnomalyCondition != nil {
+		in, out := &in.AnomalyCondition, &out.AnomalyCondition
+		*out = new(LoadAnomalyCondition)
+		if err := Convert_config_LoadAnomalyCondition_To_v1alpha2_LoadAnomalyCondition(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.AnomalyCondition = nil
+	}
+	out.MigrationTarget = InterferenceMigrationTarget(in.MigrationTarget)
+	if err := v1.Convert_int64_To_Pointer_int64(&in.CooldownSeconds, &out.CooldownSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_InterferenceArgs_To_v1alpha2_InterferenceArgs is an autogenerated conversion function.
+func Convert_config_InterferenceArgs_To_v1alpha2_InterferenceArgs(in *config.InterferenceArgs, out *InterferenceArgs, s conversion.Scope) error {
+	return autoConvert_config_InterferenceArgs_To_v1alpha2_InterferenceArgs(in, out, s)
+}
+
 func autoConvert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(in *LoadAnomalyCondition, out *config.LoadAnomalyCondition, s conversion.Scope) error {
 	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.Timeout, &out.Timeout, s); err != nil {
 		return err
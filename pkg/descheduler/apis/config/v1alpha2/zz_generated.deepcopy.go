@@ -50,6 +50,71 @@ func (in *ArbitrationArgs) DeepCopy() *ArbitrationArgs {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUDefragmentationArgs) DeepCopyInto(out *CPUDefragmentationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictableNamespaces != nil {
+		in, out := &in.EvictableNamespaces, &out.EvictableNamespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxMigratingPerNode != nil {
+		in, out := &in.MaxMigratingPerNode, &out.MaxMigratingPerNode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NUMANodeDefragmentation != nil {
+		in, out := &in.NUMANodeDefragmentation, &out.NUMANodeDefragmentation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FullPCPUsDefragmentation != nil {
+		in, out := &in.FullPCPUsDefragmentation, &out.FullPCPUsDefragmentation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinFragmentedPCPUs != nil {
+		in, out := &in.MinFragmentedPCPUs, &out.MinFragmentedPCPUs
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUDefragmentationArgs.
+func (in *CPUDefragmentationArgs) DeepCopy() *CPUDefragmentationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUDefragmentationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CPUDefragmentationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeschedulerConfiguration) DeepCopyInto(out *DeschedulerConfiguration) {
 	*out = *in
@@ -139,6 +204,81 @@ func (in *DeschedulerProfile) DeepCopy() *DeschedulerProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterferenceArgs) DeepCopyInto(out *InterferenceArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictableNamespaces != nil {
+		in, out := &in.EvictableNamespaces, &out.EvictableNamespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeMetricExpirationSeconds != nil {
+		in, out := &in.NodeMetricExpirationSeconds, &out.NodeMetricExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CPIDeviationThresholdPercent != nil {
+		in, out := &in.CPIDeviationThresholdPercent, &out.CPIDeviationThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PSISomeThresholdPercent != nil {
+		in, out := &in.PSISomeThresholdPercent, &out.PSISomeThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PSIFullThresholdPercent != nil {
+		in, out := &in.PSIFullThresholdPercent, &out.PSIFullThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AnomalyCondition != nil {
+		in, out := &in.AnomalyCondition, &out.AnomalyCondition
+		*out = new(LoadAnomalyCondition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CooldownSeconds != nil {
+		in, out := &in.CooldownSeconds, &out.CooldownSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterferenceArgs.
+func (in *InterferenceArgs) DeepCopy() *InterferenceArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(InterferenceArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InterferenceArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadAnomalyCondition) DeepCopyInto(out *LoadAnomalyCondition) {
 	*out = *in
@@ -29,16 +29,26 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&CPUDefragmentationArgs{}, func(obj interface{}) { SetObjectDefaults_CPUDefragmentationArgs(obj.(*CPUDefragmentationArgs)) })
 	scheme.AddTypeDefaultingFunc(&DeschedulerConfiguration{}, func(obj interface{}) { SetObjectDefaults_DeschedulerConfiguration(obj.(*DeschedulerConfiguration)) })
+	scheme.AddTypeDefaultingFunc(&InterferenceArgs{}, func(obj interface{}) { SetObjectDefaults_InterferenceArgs(obj.(*InterferenceArgs)) })
 	scheme.AddTypeDefaultingFunc(&LowNodeLoadArgs{}, func(obj interface{}) { SetObjectDefaults_LowNodeLoadArgs(obj.(*LowNodeLoadArgs)) })
 	scheme.AddTypeDefaultingFunc(&MigrationControllerArgs{}, func(obj interface{}) { SetObjectDefaults_MigrationControllerArgs(obj.(*MigrationControllerArgs)) })
 	return nil
 }
 
+func SetObjectDefaults_CPUDefragmentationArgs(in *CPUDefragmentationArgs) {
+	SetDefaults_CPUDefragmentationArgs(in)
+}
+
 func SetObjectDefaults_DeschedulerConfiguration(in *DeschedulerConfiguration) {
 	SetDefaults_DeschedulerConfiguration(in)
 }
 
+func SetObjectDefaults_InterferenceArgs(in *InterferenceArgs) {
+	SetDefaults_InterferenceArgs(in)
+}
+
 func SetObjectDefaults_LowNodeLoadArgs(in *LowNodeLoadArgs) {
 	SetDefaults_LowNodeLoadArgs(in)
 }
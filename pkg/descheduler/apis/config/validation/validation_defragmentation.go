@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+func ValidateCPUDefragmentationArgs(path *field.Path, args *deschedulerconfig.CPUDefragmentationArgs) error {
+	var allErrs field.ErrorList
+
+	if args.MaxMigratingPerNode <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxMigratingPerNode"), args.MaxMigratingPerNode, "must be greater than 0"))
+	}
+
+	if args.MinFragmentedPCPUs <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("minFragmentedPCPUs"), args.MinFragmentedPCPUs, "must be greater than 0"))
+	}
+
+	if args.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(args.NodeSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("nodeSelector"), args.NodeSelector, err.Error()))
+		}
+	}
+
+	if args.EvictableNamespaces != nil && len(args.EvictableNamespaces.Include) > 0 && len(args.EvictableNamespaces.Exclude) > 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("evictableNamespaces"), args.EvictableNamespaces, "only one of Include/Exclude namespaces can be set"))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+func TestValidateCPUDefragmentationArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *deschedulerconfig.CPUDefragmentationArgs
+		wantErr bool
+	}{
+		{
+			name: "valid args",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode: 2,
+				MinFragmentedPCPUs:  2,
+			},
+		},
+		{
+			name: "invalid maxMigratingPerNode",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode: 0,
+				MinFragmentedPCPUs:  2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid minFragmentedPCPUs",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode: 2,
+				MinFragmentedPCPUs:  -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid nodeSelector",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode: 2,
+				MinFragmentedPCPUs:  2,
+				NodeSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "test", Operator: "invalid"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "both include and exclude namespaces",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode: 2,
+				MinFragmentedPCPUs:  2,
+				EvictableNamespaces: &deschedulerconfig.Namespaces{
+					Include: []string{"a"},
+					Exclude: []string{"b"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCPUDefragmentationArgs(nil, tt.args)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+func ValidateInterferenceArgs(path *field.Path, args *deschedulerconfig.InterferenceArgs) error {
+	var allErrs field.ErrorList
+
+	if args.NodeMetricExpirationSeconds != nil && *args.NodeMetricExpirationSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("nodeMetricExpirationSeconds"), *args.NodeMetricExpirationSeconds, "must be greater than 0"))
+	}
+
+	if args.CPIDeviationThresholdPercent < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("cpiDeviationThresholdPercent"), args.CPIDeviationThresholdPercent, "must be greater than or equal to 0"))
+	}
+	if args.PSISomeThresholdPercent < 0 || args.PSISomeThresholdPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(path.Child("psiSomeThresholdPercent"), args.PSISomeThresholdPercent, "must be in the range [0, 100]"))
+	}
+	if args.PSIFullThresholdPercent < 0 || args.PSIFullThresholdPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(path.Child("psiFullThresholdPercent"), args.PSIFullThresholdPercent, "must be in the range [0, 100]"))
+	}
+	if args.CPIDeviationThresholdPercent == 0 && args.PSISomeThresholdPercent == 0 && args.PSIFullThresholdPercent == 0 {
+		allErrs = append(allErrs, field.Required(path, "at least one of cpiDeviationThresholdPercent, psiSomeThresholdPercent and psiFullThresholdPercent must be set"))
+	}
+
+	if args.AnomalyCondition != nil && args.AnomalyCondition.ConsecutiveAbnormalities <= 0 {
+		fieldElem := path.Child("anomalyCondition", "consecutiveAbnormalities")
+		allErrs = append(allErrs, field.Invalid(fieldElem, args.AnomalyCondition.ConsecutiveAbnormalities, "must be greater than 0"))
+	}
+
+	switch args.MigrationTarget {
+	case deschedulerconfig.InterferenceMigrateAggressor, deschedulerconfig.InterferenceMigrateVictim:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("migrationTarget"), args.MigrationTarget,
+			[]string{string(deschedulerconfig.InterferenceMigrateAggressor), string(deschedulerconfig.InterferenceMigrateVictim)}))
+	}
+
+	if args.CooldownSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("cooldownSeconds"), args.CooldownSeconds, "must be greater than or equal to 0"))
+	}
+
+	if args.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(args.NodeSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("nodeSelector"), args.NodeSelector, err.Error()))
+		}
+	}
+
+	if args.EvictableNamespaces != nil && len(args.EvictableNamespaces.Include) > 0 && len(args.EvictableNamespaces.Exclude) > 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("evictableNamespaces"), args.EvictableNamespaces, "only one of Include/Exclude namespaces can be set"))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
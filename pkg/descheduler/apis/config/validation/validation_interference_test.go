@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+func TestValidateInterferenceArgs(t *testing.T) {
+	validArgs := func() *deschedulerconfig.InterferenceArgs {
+		return &deschedulerconfig.InterferenceArgs{
+			NodeMetricExpirationSeconds:  pointer.Int64(180),
+			CPIDeviationThresholdPercent: 50,
+			PSISomeThresholdPercent:      40,
+			PSIFullThresholdPercent:      20,
+			AnomalyCondition: &deschedulerconfig.LoadAnomalyCondition{
+				ConsecutiveAbnormalities: 3,
+			},
+			MigrationTarget: deschedulerconfig.InterferenceMigrateAggressor,
+			CooldownSeconds: 600,
+		}
+	}
+	tests := []struct {
+		name    string
+		modify  func(args *deschedulerconfig.InterferenceArgs)
+		wantErr bool
+	}{
+		{
+			name: "valid args",
+		},
+		{
+			name: "migrate victim",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.MigrationTarget = deschedulerconfig.InterferenceMigrateVictim
+			},
+		},
+		{
+			name: "invalid nodeMetricExpirationSeconds",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.NodeMetricExpirationSeconds = pointer.Int64(0)
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cpiDeviationThresholdPercent",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.CPIDeviationThresholdPercent = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid psiSomeThresholdPercent",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.PSISomeThresholdPercent = 101
+			},
+			wantErr: true,
+		},
+		{
+			name: "all thresholds are disabled",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.CPIDeviationThresholdPercent = 0
+				args.PSISomeThresholdPercent = 0
+				args.PSIFullThresholdPercent = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid consecutiveAbnormalities",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.AnomalyCondition.ConsecutiveAbnormalities = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported migrationTarget",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.MigrationTarget = "Both"
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cooldownSeconds",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.CooldownSeconds = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid nodeSelector",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.NodeSelector = &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "test", Operator: "invalid"},
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "both include and exclude namespaces",
+			modify: func(args *deschedulerconfig.InterferenceArgs) {
+				args.EvictableNamespaces = &deschedulerconfig.Namespaces{
+					Include: []string{"a"},
+					Exclude: []string{"b"},
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := validArgs()
+			if tt.modify != nil {
+				tt.modify(args)
+			}
+			err := ValidateInterferenceArgs(nil, args)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -34,6 +34,8 @@ type JobContext struct {
 	Annotations map[string]string
 	Timeout     *time.Duration
 	Mode        sev1alpha1.PodMigrationJobMode
+	// Paused creates the PodMigrationJob paused, so that the caller can resume a batch of jobs together.
+	Paused bool
 }
 
 func WithContext(ctx context.Context, jobCtx *JobContext) context.Context {
@@ -73,5 +75,8 @@ func (c *JobContext) ApplyTo(job *sev1alpha1.PodMigrationJob) error {
 	if c.Mode != "" {
 		job.Spec.Mode = c.Mode
 	}
+	if c.Paused {
+		job.Spec.Paused = true
+	}
 	return nil
 }
@@ -38,6 +38,7 @@ func TestJobContext(t *testing.T) {
 		},
 		Mode:    sev1alpha1.PodMigrationJobModeEvictionDirectly,
 		Timeout: &timeout,
+		Paused:  true,
 	}
 
 	ctx := WithContext(context.TODO(), expectJobCtx)
@@ -56,8 +57,9 @@ func TestJobContext(t *testing.T) {
 			},
 		},
 		Spec: sev1alpha1.PodMigrationJobSpec{
-			Mode: sev1alpha1.PodMigrationJobModeEvictionDirectly,
-			TTL:  &metav1.Duration{Duration: timeout},
+			Mode:   sev1alpha1.PodMigrationJobModeEvictionDirectly,
+			TTL:    &metav1.Duration{Duration: timeout},
+			Paused: true,
 		},
 	}
 	assert.Equal(t, expectJob, job)
@@ -125,6 +125,9 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	if err = c.Watch(&source.Kind{Type: r.reservationInterpreter.GetReservationType()}, &handler.Funcs{}); err != nil {
 		return nil, err
 	}
+	if err = newNodeDrainController(r); err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
@@ -0,0 +1,338 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/options"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/fieldindex"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/utils"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	utilclient "github.com/koordinator-sh/koordinator/pkg/util/client"
+)
+
+const (
+	NodeDrainControllerName = "NodeDrainController"
+	NodeDrainTrigger        = "NodeDrain"
+
+	nodeDrainResyncPeriod = 30 * time.Second
+)
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+
+// nodeDrainReconciler drains the nodes annotated with extension.AnnotationNodeDrain.
+// It cordons the node and creates a ReservationFirst PodMigrationJob for every evictable Pod on the node,
+// the jobs are still arbitrated by the migration controller so that MaxMigratingPerNode and
+// MaxUnavailablePerWorkload are honoured. The progress is reported in extension.AnnotationNodeDrainStatus.
+type nodeDrainReconciler struct {
+	client.Client
+	args          *deschedulerconfig.MigrationControllerArgs
+	eventRecorder events.EventRecorder
+	clock         clock.Clock
+}
+
+func newNodeDrainController(r *Reconciler) error {
+	d := &nodeDrainReconciler{
+		Client:        r.Client,
+		args:          r.args,
+		eventRecorder: r.eventRecorder,
+		clock:         r.clock,
+	}
+	c, err := controller.New(NodeDrainControllerName, options.Manager, controller.Options{Reconciler: d})
+	if err != nil {
+		return err
+	}
+	if err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return isNodeDrainRelated(obj.GetAnnotations())
+	}), predicate.Or(predicate.AnnotationChangedPredicate{}, predicate.GenerationChangedPredicate{})); err != nil {
+		return err
+	}
+	return c.Watch(&source.Kind{Type: &sev1alpha1.PodMigrationJob{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		nodeName := obj.GetLabels()[extension.LabelNodeDrainName]
+		if nodeName == "" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: nodeName}}}
+	}), &predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldJob, newJob := e.ObjectOld.(*sev1alpha1.PodMigrationJob), e.ObjectNew.(*sev1alpha1.PodMigrationJob)
+			return oldJob.Status.Phase != newJob.Status.Phase
+		},
+	})
+}
+
+func isNodeDrainRelated(annotations map[string]string) bool {
+	if extension.IsNodeDrainRequested(annotations) {
+		return true
+	}
+	_, ok := annotations[extension.AnnotationNodeDrainStatus]
+	return ok
+}
+
+func (d *nodeDrainReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	node := &corev1.Node{}
+	if err := d.Client.Get(ctx, request.NamespacedName, node); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status, err := extension.GetNodeDrainStatus(node.Annotations)
+	if err != nil {
+		klog.Errorf("Failed to parse drain status of Node %s, reset it, err: %v", node.Name, err)
+		status = nil
+	}
+
+	if !extension.IsNodeDrainRequested(node.Annotations) {
+		if status == nil || status.Phase == extension.NodeDrainPhaseCancelled {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, d.cancel(ctx, node, status)
+	}
+
+	if status == nil || status.Phase == extension.NodeDrainPhaseCancelled {
+		status = &extension.NodeDrainStatus{
+			Phase:     extension.NodeDrainPhaseDraining,
+			StartTime: &metav1.Time{Time: d.clock.Now().Truncate(time.Second)},
+		}
+	} else if status.Phase != extension.NodeDrainPhaseDraining {
+		// the drain is completed, re-request it by removing and adding the annotation again
+		return reconcile.Result{}, nil
+	}
+
+	if err = d.drain(ctx, node, status); err != nil {
+		return reconcile.Result{}, err
+	}
+	if status.Phase == extension.NodeDrainPhaseDraining {
+		return reconcile.Result{RequeueAfter: nodeDrainResyncPeriod}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+func (d *nodeDrainReconciler) drain(ctx context.Context, node *corev1.Node, status *extension.NodeDrainStatus) error {
+	original := node.DeepCopy()
+	if !node.Spec.Unschedulable {
+		node.Spec.Unschedulable = true
+		status.Cordoned = true
+	}
+
+	pods, err := d.listPodsOnNode(ctx, node.Name)
+	if err != nil {
+		return err
+	}
+	jobs, err := d.listDrainJobs(ctx, node.Name, status.StartTime)
+	if err != nil {
+		return err
+	}
+
+	status.Migrating, status.Succeeded, status.Failed, status.Failures = 0, 0, 0, nil
+	for _, job := range jobs {
+		if job.Status.Phase == sev1alpha1.PodMigrationJobSucceeded {
+			status.Succeeded++
+		}
+	}
+	for _, pod := range pods {
+		if !isPodDrainable(pod) {
+			continue
+		}
+		job := jobs[pod.UID]
+		if job == nil {
+			if d.hasActiveMigrationJob(ctx, pod) {
+				status.Migrating++
+				continue
+			}
+			if err = d.createDrainJob(ctx, node, pod); err != nil {
+				return err
+			}
+			status.Migrating++
+			continue
+		}
+		switch job.Status.Phase {
+		case sev1alpha1.PodMigrationJobSucceeded:
+		case sev1alpha1.PodMigrationJobFailed, sev1alpha1.PodMigrationJobAborted:
+			status.Failed++
+			status.Failures = append(status.Failures, extension.NodeDrainFailure{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Job:       job.Name,
+				Reason:    job.Status.Reason,
+				Message:   job.Status.Message,
+			})
+		default:
+			status.Migrating++
+		}
+	}
+	status.Total = status.Migrating + status.Succeeded + status.Failed
+	if status.Migrating == 0 {
+		if status.Failed > 0 {
+			status.Phase = extension.NodeDrainPhaseFailed
+		} else {
+			status.Phase = extension.NodeDrainPhaseSucceeded
+		}
+		d.eventRecorder.Eventf(node, nil, corev1.EventTypeNormal, "NodeDrain"+string(status.Phase), "Drain",
+			"Drain node %s %s, succeeded: %d, failed: %d", node.Name, status.Phase, status.Succeeded, status.Failed)
+	}
+	return d.updateNode(ctx, original, node, status)
+}
+
+func (d *nodeDrainReconciler) cancel(ctx context.Context, node *corev1.Node, status *extension.NodeDrainStatus) error {
+	jobs, err := d.listDrainJobs(ctx, node.Name, status.StartTime)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		// the running jobs may have evicted the Pod, let them go on to avoid leaking the reservations
+		if job.Status.Phase != "" && job.Status.Phase != sev1alpha1.PodMigrationJobPending {
+			continue
+		}
+		job = job.DeepCopy()
+		job.Status.Phase = sev1alpha1.PodMigrationJobAborted
+		job.Status.Reason = "NodeDrainCancelled"
+		job.Status.Message = fmt.Sprintf("Abort job caused by the drain of node %s is cancelled", node.Name)
+		if err = d.Client.Status().Update(ctx, job); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("Failed to abort PodMigrationJob %s of draining Node %s, err: %v", job.Name, node.Name, err)
+			return err
+		}
+	}
+
+	original := node.DeepCopy()
+	if status.Cordoned {
+		node.Spec.Unschedulable = false
+		status.Cordoned = false
+	}
+	status.Phase = extension.NodeDrainPhaseCancelled
+	d.eventRecorder.Eventf(node, nil, corev1.EventTypeNormal, "NodeDrainCancelled", "Drain", "Drain node %s is cancelled", node.Name)
+	return d.updateNode(ctx, original, node, status)
+}
+
+func (d *nodeDrainReconciler) updateNode(ctx context.Context, original, node *corev1.Node, status *extension.NodeDrainStatus) error {
+	if err := extension.SetNodeDrainStatus(node, status); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(original.Spec, node.Spec) && reflect.DeepEqual(original.Annotations, node.Annotations) {
+		return nil
+	}
+	err := d.Client.Update(ctx, node)
+	if err != nil {
+		klog.Errorf("Failed to update drain status of Node %s, err: %v", node.Name, err)
+	}
+	return err
+}
+
+func (d *nodeDrainReconciler) listPodsOnNode(ctx context.Context, nodeName string) ([]*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	listOpts := &client.ListOptions{FieldSelector: fields.OneTermEqualSelector(fieldindex.IndexPodByNodeName, nodeName)}
+	if err := d.Client.List(ctx, podList, listOpts, utilclient.DisableDeepCopy); err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		if pod := &podList.Items[i]; pod.Spec.NodeName == nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// listDrainJobs returns the latest PodMigrationJobs created by the drain since startTime, keyed by the Pod UID.
+func (d *nodeDrainReconciler) listDrainJobs(ctx context.Context, nodeName string, startTime *metav1.Time) (map[types.UID]*sev1alpha1.PodMigrationJob, error) {
+	jobList := &sev1alpha1.PodMigrationJobList{}
+	if err := d.Client.List(ctx, jobList, client.MatchingLabels{extension.LabelNodeDrainName: nodeName}, utilclient.DisableDeepCopy); err != nil {
+		return nil, err
+	}
+	jobs := map[types.UID]*sev1alpha1.PodMigrationJob{}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.Spec.PodRef == nil || (startTime != nil && !job.CreationTimestamp.IsZero() && job.CreationTimestamp.Before(startTime)) {
+			continue
+		}
+		if existing := jobs[job.Spec.PodRef.UID]; existing == nil || existing.CreationTimestamp.Before(&job.CreationTimestamp) {
+			jobs[job.Spec.PodRef.UID] = job
+		}
+	}
+	return jobs, nil
+}
+
+func (d *nodeDrainReconciler) hasActiveMigrationJob(ctx context.Context, pod *corev1.Pod) bool {
+	jobList := &sev1alpha1.PodMigrationJobList{}
+	listOpts := &client.ListOptions{FieldSelector: fields.OneTermEqualSelector(fieldindex.IndexJobByPodUID, string(pod.UID))}
+	if err := d.Client.List(ctx, jobList, listOpts, utilclient.DisableDeepCopy); err != nil {
+		klog.Errorf("Failed to list PodMigrationJobs of Pod %s, err: %v", klog.KObj(pod), err)
+		return false
+	}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.Spec.PodRef == nil || job.Spec.PodRef.UID != pod.UID {
+			continue
+		}
+		if job.Status.Phase == "" || job.Status.Phase == sev1alpha1.PodMigrationJobPending || job.Status.Phase == sev1alpha1.PodMigrationJobRunning {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *nodeDrainReconciler) createDrainJob(ctx context.Context, node *corev1.Node, pod *corev1.Pod) error {
+	ctx = WithContext(ctx, &JobContext{
+		Labels: map[string]string{
+			extension.LabelNodeDrainName: node.Name,
+		},
+		Mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+	})
+	evictOptions := framework.EvictOptions{
+		PluginName: NodeDrainTrigger,
+		Reason:     fmt.Sprintf("drain node %s", node.Name),
+	}
+	if err := CreatePodMigrationJob(ctx, pod, evictOptions, d.Client, d.args); err != nil {
+		return err
+	}
+	klog.V(4).Infof("Create PodMigrationJob for Pod %s to drain Node %s", klog.KObj(pod), node.Name)
+	return nil
+}
+
+func isPodDrainable(pod *corev1.Pod) bool {
+	return !util.IsPodTerminated(pod) &&
+		!podutil.IsPodTerminating(pod) &&
+		!podutil.IsMirrorPod(pod) &&
+		!podutil.IsStaticPod(pod) &&
+		!podutil.IsDaemonsetPod(pod.OwnerReferences)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newTestNodeDrainReconciler() *nodeDrainReconciler {
+	r := newTestReconciler()
+	return &nodeDrainReconciler{
+		Client:        r.Client,
+		args:          r.args,
+		eventRecorder: r.eventRecorder,
+		clock:         r.clock,
+	}
+}
+
+func newTestDrainPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+func reconcileNodeDrain(t *testing.T, d *nodeDrainReconciler, nodeName string) (*corev1.Node, *extension.NodeDrainStatus) {
+	_, err := d.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: nodeName}})
+	assert.NoError(t, err)
+	node := &corev1.Node{}
+	assert.NoError(t, d.Client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node))
+	status, err := extension.GetNodeDrainStatus(node.Annotations)
+	assert.NoError(t, err)
+	return node, status
+}
+
+func listDrainJobsByPod(t *testing.T, d *nodeDrainReconciler, nodeName string) map[string]*sev1alpha1.PodMigrationJob {
+	jobList := &sev1alpha1.PodMigrationJobList{}
+	assert.NoError(t, d.Client.List(context.TODO(), jobList, client.MatchingLabels{extension.LabelNodeDrainName: nodeName}))
+	jobs := map[string]*sev1alpha1.PodMigrationJob{}
+	for i := range jobList.Items {
+		jobs[jobList.Items[i].Spec.PodRef.Name] = &jobList.Items[i]
+	}
+	return jobs
+}
+
+func TestNodeDrain(t *testing.T) {
+	d := newTestNodeDrainReconciler()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				extension.AnnotationNodeDrain: "true",
+			},
+		},
+	}
+	assert.NoError(t, d.Client.Create(context.TODO(), node))
+
+	daemonSetPod := newTestDrainPod("daemonset-pod", "test-node")
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "ds", UID: "ds"}}
+	mirrorPod := newTestDrainPod("mirror-pod", "test-node")
+	mirrorPod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "mirror"}
+	pods := []*corev1.Pod{
+		newTestDrainPod("pod-a", "test-node"),
+		newTestDrainPod("pod-b", "test-node"),
+		newTestDrainPod("pod-c", "other-node"),
+		daemonSetPod,
+		mirrorPod,
+	}
+	for _, pod := range pods {
+		assert.NoError(t, d.Client.Create(context.TODO(), pod))
+	}
+
+	node, status := reconcileNodeDrain(t, d, "test-node")
+	assert.True(t, node.Spec.Unschedulable)
+	assert.NotNil(t, status)
+	assert.Equal(t, extension.NodeDrainPhaseDraining, status.Phase)
+	assert.True(t, status.Cordoned)
+	assert.Equal(t, 2, status.Total)
+	assert.Equal(t, 2, status.Migrating)
+
+	jobs := listDrainJobsByPod(t, d, "test-node")
+	assert.Len(t, jobs, 2)
+	for _, name := range []string{"pod-a", "pod-b"} {
+		job := jobs[name]
+		assert.NotNil(t, job, name)
+		assert.Equal(t, sev1alpha1.PodMigrationJobModeReservationFirst, job.Spec.Mode)
+		assert.Equal(t, sev1alpha1.PodMigrationJobPending, job.Status.Phase)
+	}
+
+	// reconcile again should not create duplicated jobs
+	_, status = reconcileNodeDrain(t, d, "test-node")
+	assert.Equal(t, 2, status.Migrating)
+	assert.Len(t, listDrainJobsByPod(t, d, "test-node"), 2)
+
+	jobA := jobs["pod-a"]
+	jobA.Status.Phase = sev1alpha1.PodMigrationJobSucceeded
+	assert.NoError(t, d.Client.Status().Update(context.TODO(), jobA))
+	assert.NoError(t, d.Client.Delete(context.TODO(), pods[0]))
+	jobB := jobs["pod-b"]
+	jobB.Status.Phase = sev1alpha1.PodMigrationJobFailed
+	jobB.Status.Reason = sev1alpha1.PodMigrationJobReasonForbiddenMigratePod
+	jobB.Status.Message = "forbidden"
+	assert.NoError(t, d.Client.Status().Update(context.TODO(), jobB))
+
+	node, status = reconcileNodeDrain(t, d, "test-node")
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Equal(t, extension.NodeDrainPhaseFailed, status.Phase)
+	assert.Equal(t, 2, status.Total)
+	assert.Equal(t, 0, status.Migrating)
+	assert.Equal(t, 1, status.Succeeded)
+	assert.Equal(t, 1, status.Failed)
+	expectedFailures := []extension.NodeDrainFailure{
+		{
+			Namespace: "default",
+			Name:      "pod-b",
+			Job:       jobB.Name,
+			Reason:    sev1alpha1.PodMigrationJobReasonForbiddenMigratePod,
+			Message:   "forbidden",
+		},
+	}
+	assert.Equal(t, expectedFailures, status.Failures)
+}
+
+func TestNodeDrainSucceeded(t *testing.T) {
+	d := newTestNodeDrainReconciler()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				extension.AnnotationNodeDrain: "true",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+		},
+	}
+	assert.NoError(t, d.Client.Create(context.TODO(), node))
+
+	node, status := reconcileNodeDrain(t, d, "test-node")
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Equal(t, extension.NodeDrainPhaseSucceeded, status.Phase)
+	assert.False(t, status.Cordoned, "the node is cordoned by others")
+	assert.Equal(t, 0, status.Total)
+}
+
+func TestNodeDrainWithActiveMigrationJob(t *testing.T) {
+	d := newTestNodeDrainReconciler()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				extension.AnnotationNodeDrain: "true",
+			},
+		},
+	}
+	assert.NoError(t, d.Client.Create(context.TODO(), node))
+	pod := newTestDrainPod("pod-a", "test-node")
+	assert.NoError(t, d.Client.Create(context.TODO(), pod))
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "other-job",
+		},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+		},
+		Status: sev1alpha1.PodMigrationJobStatus{
+			Phase: sev1alpha1.PodMigrationJobRunning,
+		},
+	}
+	assert.NoError(t, d.Client.Create(context.TODO(), job))
+
+	_, status := reconcileNodeDrain(t, d, "test-node")
+	assert.Equal(t, extension.NodeDrainPhaseDraining, status.Phase)
+	assert.Equal(t, 1, status.Migrating)
+	assert.Len(t, listDrainJobsByPod(t, d, "test-node"), 0)
+}
+
+func TestNodeDrainCancel(t *testing.T) {
+	d := newTestNodeDrainReconciler()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				extension.AnnotationNodeDrain: "true",
+			},
+		},
+	}
+	assert.NoError(t, d.Client.Create(context.TODO(), node))
+	for _, name := range []string{"pod-a", "pod-b"} {
+		assert.NoError(t, d.Client.Create(context.TODO(), newTestDrainPod(name, "test-node")))
+	}
+
+	node, status := reconcileNodeDrain(t, d, "test-node")
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Equal(t, 2, status.Migrating)
+	jobs := listDrainJobsByPod(t, d, "test-node")
+	runningJob := jobs["pod-b"]
+	runningJob.Status.Phase = sev1alpha1.PodMigrationJobRunning
+	assert.NoError(t, d.Client.Status().Update(context.TODO(), runningJob))
+
+	delete(node.Annotations, extension.AnnotationNodeDrain)
+	assert.NoError(t, d.Client.Update(context.TODO(), node))
+
+	node, status = reconcileNodeDrain(t, d, "test-node")
+	assert.False(t, node.Spec.Unschedulable)
+	assert.Equal(t, extension.NodeDrainPhaseCancelled, status.Phase)
+	assert.False(t, status.Cordoned)
+
+	jobs = listDrainJobsByPod(t, d, "test-node")
+	assert.Equal(t, sev1alpha1.PodMigrationJobAborted, jobs["pod-a"].Status.Phase)
+	assert.Equal(t, sev1alpha1.PodMigrationJobRunning, jobs["pod-b"].Status.Phase)
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defragmentation
+
+import (
+	"context"
+	"fmt"
+
+	nrtclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned"
+	nrtinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	topologylister "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/listers/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	nodeutil "github.com/koordinator-sh/koordinator/pkg/descheduler/node"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+)
+
+const (
+	CPUDefragmentationName = "CPUDefragmentation"
+
+	// LabelDefragmentationPlan is the label of the PodMigrationJobs created for the same defragmentation plan.
+	LabelDefragmentationPlan = "descheduler.koordinator.sh/defragmentation-plan"
+)
+
+var _ framework.BalancePlugin = &CPUDefragmentation{}
+
+// CPUDefragmentation migrates the pods bound to cpusets or NUMA Nodes to reduce the CPU fragmentation of nodes,
+// so that the nodes have free whole NUMA Nodes or physical cores for the large LSE/LSR pods.
+// The pods are migrated by PodMigrationJob in ReservationFirst mode, a pod is evicted only after
+// the Reservation of it is scheduled successfully, and the Reservation never lands on the original node.
+// The PodMigrationJobs of a plan are created paused and resumed together, or deleted if any of them fails.
+// The pods allocated from Reservations, including the ones placed by the former migrations, are not migrated
+// to avoid moving the pods back and forth between nodes.
+type CPUDefragmentation struct {
+	handle         framework.Handle
+	args           *deschedulerconfig.CPUDefragmentationArgs
+	podFilter      framework.FilterFunc
+	nodeSelector   labels.Selector
+	nrtLister      topologylister.NodeResourceTopologyLister
+	koordClientSet koordclientset.Interface
+}
+
+// NewCPUDefragmentation builds plugin from its arguments while passing a handle
+func NewCPUDefragmentation(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	defragmentationArgs, ok := args.(*deschedulerconfig.CPUDefragmentationArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type CPUDefragmentationArgs, got %T", args)
+	}
+	if err := validation.ValidateCPUDefragmentationArgs(nil, defragmentationArgs); err != nil {
+		return nil, err
+	}
+
+	nodeSelector := labels.Everything()
+	if defragmentationArgs.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(defragmentationArgs.NodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		nodeSelector = selector
+	}
+
+	var excludedNamespaces sets.String
+	var includedNamespaces sets.String
+	if defragmentationArgs.EvictableNamespaces != nil {
+		excludedNamespaces = sets.NewString(defragmentationArgs.EvictableNamespaces.Exclude...)
+		includedNamespaces = sets.NewString(defragmentationArgs.EvictableNamespaces.Include...)
+	}
+	podFilter, err := podutil.NewOptions().
+		WithFilter(podutil.WrapFilterFuncs(handle.Evictor().Filter, isNotReservationAllocated)).
+		WithoutNamespaces(excludedNamespaces).
+		WithNamespaces(includedNamespaces).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	nrtClient, ok := handle.(nrtclientset.Interface)
+	if !ok {
+		kubeConfig := *handle.KubeConfig()
+		kubeConfig.ContentType = runtime.ContentTypeJSON
+		kubeConfig.AcceptContentTypes = runtime.ContentTypeJSON
+		nrtClient, err = nrtclientset.NewForConfig(&kubeConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	nrtInformerFactory := nrtinformers.NewSharedInformerFactory(nrtClient, 0)
+	nrtInformer := nrtInformerFactory.Topology().V1alpha1().NodeResourceTopologies()
+	nrtInformer.Informer()
+	nrtInformerFactory.Start(context.TODO().Done())
+	nrtInformerFactory.WaitForCacheSync(context.TODO().Done())
+
+	koordClientSet, ok := handle.(koordclientset.Interface)
+	if !ok {
+		kubeConfig := *handle.KubeConfig()
+		kubeConfig.ContentType = runtime.ContentTypeJSON
+		kubeConfig.AcceptContentTypes = runtime.ContentTypeJSON
+		koordClientSet, err = koordclientset.NewForConfig(&kubeConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &CPUDefragmentation{
+		handle:         handle,
+		args:           defragmentationArgs,
+		podFilter:      podFilter,
+		nodeSelector:   nodeSelector,
+		nrtLister:      nrtInformer.Lister(),
+		koordClientSet: koordClientSet,
+	}, nil
+}
+
+// isNotReservationAllocated filters out the pods allocated from Reservations. A pod migrated in ReservationFirst
+// mode is allocated from the Reservation of its PodMigrationJob, so it is never migrated again by the plugin.
+func isNotReservationAllocated(pod *corev1.Pod) bool {
+	allocated, err := apiext.GetReservationAllocated(pod)
+	return err == nil && allocated == nil
+}
+
+// Name retrieves the plugin name
+func (pl *CPUDefragmentation) Name() string {
+	return CPUDefragmentationName
+}
+
+// Balance extension point implementation for the plugin
+func (pl *CPUDefragmentation) Balance(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	if pl.args.Paused {
+		klog.Infof("CPUDefragmentation is paused and will do nothing.")
+		return nil
+	}
+
+	for _, node := range nodes {
+		if !pl.nodeSelector.Matches(labels.Set(node.Labels)) || nodeutil.IsNodeUnschedulable(node) {
+			continue
+		}
+		plan, err := pl.planNode(node)
+		if err != nil {
+			klog.ErrorS(err, "Failed to plan CPU defragmentation", "node", node.Name)
+			continue
+		}
+		if plan == nil {
+			klog.V(5).InfoS("Node is not fragmented or can not be defragmented, nothing to do here", "node", node.Name)
+			continue
+		}
+		pl.migratePods(ctx, node, plan)
+	}
+	return nil
+}
+
+func (pl *CPUDefragmentation) planNode(node *corev1.Node) (*defragmentationPlan, error) {
+	nrt, err := pl.nrtLister.Get(node.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	pods, err := pl.handle.GetPodsAssignedToNodeFunc()(node.Name, func(pod *corev1.Pod) bool {
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	state, err := buildNodeCPUState(nrt, pods, pl.podFilter)
+	if err != nil || state == nil {
+		return nil, err
+	}
+
+	maxMigrating := int(pl.args.MaxMigratingPerNode)
+	if pl.args.NUMANodeDefragmentation {
+		if plan := planNUMANodeDefragmentation(state, maxMigrating); plan != nil {
+			return plan, nil
+		}
+	}
+	if pl.args.FullPCPUsDefragmentation {
+		if plan := planFullPCPUsDefragmentation(state, maxMigrating, int(pl.args.MinFragmentedPCPUs)); plan != nil {
+			return plan, nil
+		}
+	}
+	return nil, nil
+}
+
+func (pl *CPUDefragmentation) migratePods(ctx context.Context, node *corev1.Node, plan *defragmentationPlan) {
+	podEvictor := pl.handle.Evictor()
+	var pods []*corev1.Pod
+	for _, p := range plan.pods {
+		if !podEvictor.PreEvictionFilter(p.pod) {
+			if plan.atomic {
+				klog.V(4).InfoS("Skip the defragmentation plan since pod can not be migrated", "pod", klog.KObj(p.pod), "node", node.Name)
+				return
+			}
+			continue
+		}
+		pods = append(pods, p.pod)
+	}
+	if len(pods) == 0 {
+		return
+	}
+
+	if pl.args.DryRun {
+		for _, pod := range pods {
+			klog.InfoS("Migrate pod in dry run mode", "pod", klog.KObj(pod), "node", node.Name, "reason", plan.reason)
+		}
+		return
+	}
+
+	planID := string(uuid.NewUUID())
+	ctx = migration.WithContext(ctx, &migration.JobContext{
+		Labels: map[string]string{
+			LabelDefragmentationPlan: planID,
+		},
+		Mode:   sev1alpha1.PodMigrationJobModeReservationFirst,
+		Paused: true,
+	})
+	var failed int
+	for _, pod := range pods {
+		evictionOptions := framework.EvictOptions{
+			PluginName: CPUDefragmentationName,
+			Reason:     plan.reason,
+		}
+		if !podEvictor.Evict(ctx, pod, evictionOptions) {
+			klog.InfoS("Failed to migrate pod", "pod", klog.KObj(pod), "node", node.Name)
+			failed++
+			if plan.atomic {
+				break
+			}
+		}
+	}
+
+	if failed > 0 && plan.atomic {
+		klog.InfoS("Roll back the defragmentation plan since not all pods can be migrated", "node", node.Name, "plan", planID)
+		if err := pl.deletePlanJobs(ctx, planID); err != nil {
+			klog.ErrorS(err, "Failed to roll back the defragmentation plan", "node", node.Name, "plan", planID)
+		}
+		return
+	}
+	if err := pl.resumePlanJobs(ctx, planID); err != nil {
+		klog.ErrorS(err, "Failed to resume the defragmentation plan", "node", node.Name, "plan", planID)
+		if plan.atomic {
+			if err := pl.deletePlanJobs(ctx, planID); err != nil {
+				klog.ErrorS(err, "Failed to roll back the defragmentation plan", "node", node.Name, "plan", planID)
+			}
+		}
+		return
+	}
+	klog.InfoS("Migrated pods", "node", node.Name, "plan", planID, "count", len(pods)-failed, "reason", plan.reason)
+}
+
+func (pl *CPUDefragmentation) planJobSelector(planID string) string {
+	return labels.SelectorFromSet(labels.Set{LabelDefragmentationPlan: planID}).String()
+}
+
+// resumePlanJobs unpauses the PodMigrationJobs of the plan after all of them are created.
+func (pl *CPUDefragmentation) resumePlanJobs(ctx context.Context, planID string) error {
+	jobClient := pl.koordClientSet.SchedulingV1alpha1().PodMigrationJobs()
+	jobList, err := jobClient.List(ctx, metav1.ListOptions{LabelSelector: pl.planJobSelector(planID)})
+	if err != nil {
+		return err
+	}
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if !job.Spec.Paused {
+			continue
+		}
+		job.Spec.Paused = false
+		if _, err := jobClient.Update(ctx, job, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePlanJobs deletes the PodMigrationJobs of the plan before they are resumed, so no pod has been migrated.
+func (pl *CPUDefragmentation) deletePlanJobs(ctx context.Context, planID string) error {
+	jobClient := pl.koordClientSet.SchedulingV1alpha1().PodMigrationJobs()
+	jobList, err := jobClient.List(ctx, metav1.ListOptions{LabelSelector: pl.planJobSelector(planID)})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range jobList.Items {
+		if err := jobClient.Delete(ctx, jobList.Items[i].Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
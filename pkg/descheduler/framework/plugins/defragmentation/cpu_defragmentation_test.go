@@ -0,0 +1,301 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defragmentation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	nrtv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	faketopologyclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+// fakeEvictor creates the PodMigrationJobs named by the pods like the migration controller
+type fakeEvictor struct {
+	client     koordclientset.Interface
+	failedPods sets.String
+	evicted    map[string]sev1alpha1.PodMigrationJobMode
+}
+
+func (e *fakeEvictor) Filter(pod *corev1.Pod) bool {
+	return pod.Labels["evictable"] != "false"
+}
+
+func (e *fakeEvictor) PreEvictionFilter(pod *corev1.Pod) bool {
+	return true
+}
+
+func (e *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	if e.failedPods.Has(pod.Name) {
+		return false
+	}
+	job := &sev1alpha1.PodMigrationJob{ObjectMeta: metav1.ObjectMeta{Name: pod.Name}}
+	if err := migration.FromContext(ctx).ApplyTo(job); err != nil {
+		return false
+	}
+	if _, err := e.client.SchedulingV1alpha1().PodMigrationJobs().Create(ctx, job, metav1.CreateOptions{}); err != nil {
+		return false
+	}
+	e.evicted[pod.Name] = job.Spec.Mode
+	return true
+}
+
+type koordFakeClientset = koordfake.Clientset
+
+type fakeFrameworkHandle struct {
+	framework.Handle
+	*faketopologyclientset.Clientset
+	*koordFakeClientset
+	evictor *fakeEvictor
+	pods    []*corev1.Pod
+}
+
+func (h *fakeFrameworkHandle) Discovery() discovery.DiscoveryInterface {
+	return h.Clientset.Discovery()
+}
+
+func (h *fakeFrameworkHandle) Evictor() framework.Evictor {
+	return h.evictor
+}
+
+func (h *fakeFrameworkHandle) GetPodsAssignedToNodeFunc() framework.GetPodsAssignedToNodeFunc {
+	return func(nodeName string, filter framework.FilterFunc) ([]*corev1.Pod, error) {
+		var pods []*corev1.Pod
+		for _, pod := range h.pods {
+			if pod.Spec.NodeName == nodeName && filter(pod) {
+				pods = append(pods, pod)
+			}
+		}
+		return pods, nil
+	}
+}
+
+func newTestNodeResourceTopology(t *testing.T, nodeName string) *nrtv1alpha1.NodeResourceTopology {
+	topology := &extension.CPUTopology{}
+	for cpu := 0; cpu < 8; cpu++ {
+		topology.Detail = append(topology.Detail, extension.CPUInfo{
+			ID:   int32(cpu),
+			Core: int32(cpu / 2),
+			Node: int32(cpu / 4),
+		})
+	}
+	data, err := json.Marshal(topology)
+	assert.NoError(t, err)
+	return &nrtv1alpha1.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+			Annotations: map[string]string{
+				extension.AnnotationNodeCPUTopology: string(data),
+			},
+		},
+	}
+}
+
+func newTestPod(t *testing.T, name, nodeName, cpus string, evictable bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+	}
+	if !evictable {
+		pod.Labels["evictable"] = "false"
+	}
+	assert.NoError(t, extension.SetResourceStatus(pod, &extension.ResourceStatus{CPUSet: cpus}))
+	return pod
+}
+
+func newTestReservationAllocatedPod(t *testing.T, name, nodeName, cpus string) *corev1.Pod {
+	pod := newTestPod(t, name, nodeName, cpus, true)
+	extension.SetReservationAllocated(pod, &sev1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-reservation", UID: "xxx-yyy"},
+	})
+	return pod
+}
+
+func TestCPUDefragmentation(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        *deschedulerconfig.CPUDefragmentationArgs
+		pods        []*corev1.Pod
+		failedPods  []string
+		wantEvicted map[string]sev1alpha1.PodMigrationJobMode
+		wantJobs    []string
+	}{
+		{
+			name: "migrate pods to free a NUMA Node",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				NUMANodeDefragmentation:  true,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0-1", false),
+				newTestPod(t, "pod-b", "test-node", "4-5", true),
+			},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"pod-b": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+			wantJobs: []string{"pod-b"},
+		},
+		{
+			name: "roll back the plan to free a NUMA Node if any pod fails to migrate",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				NUMANodeDefragmentation:  true,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0-1", false),
+				newTestPod(t, "pod-b", "test-node", "4", true),
+				newTestPod(t, "pod-c", "test-node", "6", true),
+			},
+			failedPods: []string{"pod-c"},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"pod-b": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+		},
+		{
+			name: "skip the pods allocated from reservations",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				NUMANodeDefragmentation:  true,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0-1", false),
+				newTestReservationAllocatedPod(t, "pod-b", "test-node", "4-5"),
+			},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "migrate pods to free physical cores",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				NUMANodeDefragmentation:  true,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0", true),
+				newTestPod(t, "pod-b", "test-node", "2", true),
+			},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"pod-a": sev1alpha1.PodMigrationJobModeReservationFirst,
+				"pod-b": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+			wantJobs: []string{"pod-a", "pod-b"},
+		},
+		{
+			name: "NUMA Node defragmentation is disabled",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0-1", true),
+				newTestPod(t, "pod-b", "test-node", "4-5", true),
+			},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "dry run",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				DryRun:                   true,
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				NUMANodeDefragmentation:  true,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0-1", true),
+				newTestPod(t, "pod-b", "test-node", "4-5", true),
+			},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "paused",
+			args: &deschedulerconfig.CPUDefragmentationArgs{
+				Paused:                   true,
+				MaxMigratingPerNode:      2,
+				MinFragmentedPCPUs:       2,
+				NUMANodeDefragmentation:  true,
+				FullPCPUsDefragmentation: true,
+			},
+			pods: []*corev1.Pod{
+				newTestPod(t, "pod-a", "test-node", "0-1", true),
+				newTestPod(t, "pod-b", "test-node", "4-5", true),
+			},
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []*corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-without-topology"}},
+			}
+			koordClientSet := koordfake.NewSimpleClientset()
+			handle := &fakeFrameworkHandle{
+				Clientset:          faketopologyclientset.NewSimpleClientset(newTestNodeResourceTopology(t, "test-node")),
+				koordFakeClientset: koordClientSet,
+				evictor: &fakeEvictor{
+					client:     koordClientSet,
+					failedPods: sets.NewString(tt.failedPods...),
+					evicted:    map[string]sev1alpha1.PodMigrationJobMode{},
+				},
+				pods: tt.pods,
+			}
+			pl, err := NewCPUDefragmentation(tt.args, handle)
+			assert.NoError(t, err)
+			status := pl.(framework.BalancePlugin).Balance(context.TODO(), nodes)
+			assert.Nil(t, status)
+			assert.Equal(t, tt.wantEvicted, handle.evictor.evicted)
+
+			jobList, err := koordClientSet.SchedulingV1alpha1().PodMigrationJobs().List(context.TODO(), metav1.ListOptions{})
+			assert.NoError(t, err)
+			var jobs []string
+			for _, job := range jobList.Items {
+				assert.False(t, job.Spec.Paused, "job %s should be resumed", job.Name)
+				jobs = append(jobs, job.Name)
+			}
+			assert.ElementsMatch(t, tt.wantJobs, jobs)
+		})
+	}
+}
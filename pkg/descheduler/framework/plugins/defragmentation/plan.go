@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defragmentation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// defragmentationPlan describes the pods to migrate from a node to reduce the CPU fragmentation.
+type defragmentationPlan struct {
+	reason string
+	pods   []*podCPUAllocation
+	// atomic indicates that the plan makes sense only if all the pods are migrated.
+	atomic bool
+}
+
+// planNUMANodeDefragmentation tries to free a whole NUMA Node of the node when none of the NUMA Nodes is free
+// but the free CPUs are enough to hold one. The NUMA Node which can be freed by migrating the fewest CPUs is chosen.
+// It returns nil if the node is not fragmented or no NUMA Node can be freed within maxMigrating pods.
+func planNUMANodeDefragmentation(state *nodeCPUState, maxMigrating int) *defragmentationPlan {
+	if len(state.numaNodeCPUs) <= 1 {
+		return nil
+	}
+	allocated := state.allocatedCPUs()
+	freeCPUs := state.allCPUs.Difference(allocated).Size()
+
+	numaNodes := make([]int, 0, len(state.numaNodeCPUs))
+	minNUMANodeCPUs := -1
+	for numaNode, cpus := range state.numaNodeCPUs {
+		numaNodes = append(numaNodes, numaNode)
+		if minNUMANodeCPUs < 0 || cpus.Size() < minNUMANodeCPUs {
+			minNUMANodeCPUs = cpus.Size()
+		}
+	}
+	sort.Ints(numaNodes)
+
+	occupiedPods := map[int][]*podCPUAllocation{}
+	for _, numaNode := range numaNodes {
+		cpus := state.numaNodeCPUs[numaNode]
+		for _, p := range state.pods {
+			if p.occupyNUMANode(numaNode, cpus) {
+				occupiedPods[numaNode] = append(occupiedPods[numaNode], p)
+			}
+		}
+		if len(occupiedPods[numaNode]) == 0 && allocated.Intersection(cpus).IsEmpty() {
+			// there is already a free NUMA Node
+			return nil
+		}
+	}
+	if freeCPUs < minNUMANodeCPUs {
+		// the node is short of CPUs rather than fragmented
+		return nil
+	}
+
+	bestNUMANode, bestCost := -1, 0
+	for _, numaNode := range numaNodes {
+		if !state.reservedCPUs.Intersection(state.numaNodeCPUs[numaNode]).IsEmpty() {
+			continue
+		}
+		pods := occupiedPods[numaNode]
+		if len(pods) > maxMigrating {
+			continue
+		}
+		cost := 0
+		evictable := true
+		for _, p := range pods {
+			if !p.evictable {
+				evictable = false
+				break
+			}
+			cost += p.cpus.Size()
+		}
+		if !evictable {
+			continue
+		}
+		if bestNUMANode < 0 || cost < bestCost ||
+			(cost == bestCost && len(pods) < len(occupiedPods[bestNUMANode])) {
+			bestNUMANode, bestCost = numaNode, cost
+		}
+	}
+	if bestNUMANode < 0 {
+		return nil
+	}
+	return &defragmentationPlan{
+		reason: fmt.Sprintf("migrate pods to free NUMA Node %d since CPUs of the node are fragmented, free CPUs %d", bestNUMANode, freeCPUs),
+		pods:   occupiedPods[bestNUMANode],
+		atomic: true,
+	}
+}
+
+// planFullPCPUsDefragmentation tries to free the physical cores which are partially allocated when the number of them
+// reaches minFragmentedPCPUs. The pods which can free the most physical cores by themselves are chosen.
+func planFullPCPUsDefragmentation(state *nodeCPUState, maxMigrating, minFragmentedPCPUs int) *defragmentationPlan {
+	allocated := state.allocatedCPUs()
+	var fragmentedCores []int
+	for core, cpus := range state.coreCPUs {
+		allocatedCPUs := allocated.Intersection(cpus)
+		if !allocatedCPUs.IsEmpty() && allocatedCPUs.Size() < cpus.Size() {
+			fragmentedCores = append(fragmentedCores, core)
+		}
+	}
+	if len(fragmentedCores) < minFragmentedPCPUs {
+		return nil
+	}
+
+	type candidate struct {
+		pod        *podCPUAllocation
+		freedCores int
+	}
+	var candidates []candidate
+	for _, p := range state.pods {
+		if !p.evictable || p.cpus.IsEmpty() {
+			continue
+		}
+		freedCores := 0
+		for _, core := range fragmentedCores {
+			allocatedCPUs := allocated.Intersection(state.coreCPUs[core])
+			if allocatedCPUs.IsSubsetOf(p.cpus) {
+				freedCores++
+			}
+		}
+		if freedCores > 0 {
+			candidates = append(candidates, candidate{pod: p, freedCores: freedCores})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].freedCores != candidates[j].freedCores {
+			return candidates[i].freedCores > candidates[j].freedCores
+		}
+		if candidates[i].pod.cpus.Size() != candidates[j].pod.cpus.Size() {
+			return candidates[i].pod.cpus.Size() < candidates[j].pod.cpus.Size()
+		}
+		podI, podJ := candidates[i].pod.pod, candidates[j].pod.pod
+		if podI.Namespace != podJ.Namespace {
+			return podI.Namespace < podJ.Namespace
+		}
+		return podI.Name < podJ.Name
+	})
+	if len(candidates) > maxMigrating {
+		candidates = candidates[:maxMigrating]
+	}
+	plan := &defragmentationPlan{
+		reason: fmt.Sprintf("migrate pods to free physical cores since %d physical cores of the node are partially allocated", len(fragmentedCores)),
+	}
+	for _, c := range candidates {
+		plan.pods = append(plan.pods, c.pod)
+	}
+	return plan
+}
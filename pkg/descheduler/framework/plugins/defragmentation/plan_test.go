@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defragmentation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+// newTestNodeCPUState returns a node with 2 NUMA Nodes, each NUMA Node has 2 physical cores with 2 logical CPUs.
+// NUMA Node 0: core 0 (cpu 0, 1), core 1 (cpu 2, 3); NUMA Node 1: core 2 (cpu 4, 5), core 3 (cpu 6, 7).
+func newTestNodeCPUState(reservedCPUs string, pods ...*podCPUAllocation) *nodeCPUState {
+	return &nodeCPUState{
+		nodeName: "test-node",
+		allCPUs:  cpuset.MustParse("0-7"),
+		numaNodeCPUs: map[int]cpuset.CPUSet{
+			0: cpuset.MustParse("0-3"),
+			1: cpuset.MustParse("4-7"),
+		},
+		coreCPUs: map[int]cpuset.CPUSet{
+			0: cpuset.MustParse("0-1"),
+			1: cpuset.MustParse("2-3"),
+			2: cpuset.MustParse("4-5"),
+			3: cpuset.MustParse("6-7"),
+		},
+		reservedCPUs: cpuset.MustParse(reservedCPUs),
+		pods:         pods,
+	}
+}
+
+func newTestPodCPUAllocation(name string, cpus string, evictable bool, numaNodes ...int) *podCPUAllocation {
+	return &podCPUAllocation{
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+		},
+		cpus:      cpuset.MustParse(cpus),
+		numaNodes: sets.NewInt(numaNodes...),
+		evictable: evictable,
+	}
+}
+
+func getPlanPodNames(plan *defragmentationPlan) []string {
+	if plan == nil {
+		return nil
+	}
+	var names []string
+	for _, p := range plan.pods {
+		names = append(names, p.pod.Name)
+	}
+	return names
+}
+
+func TestPlanNUMANodeDefragmentation(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        *nodeCPUState
+		maxMigrating int
+		want         []string
+	}{
+		{
+			name: "free the NUMA Node with the lower id when the costs are equal",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0-1", true),
+				newTestPodCPUAllocation("pod-b", "4-5", true),
+			),
+			maxMigrating: 2,
+			want:         []string{"pod-a"},
+		},
+		{
+			name: "skip the NUMA Node occupied by unevictable pods",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0-1", false),
+				newTestPodCPUAllocation("pod-b", "4-5", true),
+			),
+			maxMigrating: 2,
+			want:         []string{"pod-b"},
+		},
+		{
+			name: "skip the NUMA Node occupied by reserved CPUs",
+			state: newTestNodeCPUState("0",
+				newTestPodCPUAllocation("pod-a", "1", true),
+				newTestPodCPUAllocation("pod-b", "4-5", true),
+			),
+			maxMigrating: 2,
+			want:         []string{"pod-b"},
+		},
+		{
+			name: "skip the NUMA Node which needs to migrate too many pods",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0", true),
+				newTestPodCPUAllocation("pod-c", "1", true),
+				newTestPodCPUAllocation("pod-b", "4-5", true),
+			),
+			maxMigrating: 1,
+			want:         []string{"pod-b"},
+		},
+		{
+			name: "NUMA Node bound by the resource status is occupied",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0-1", true),
+				newTestPodCPUAllocation("pod-b", "", true, 1),
+			),
+			maxMigrating: 2,
+			want:         []string{"pod-b"},
+		},
+		{
+			name: "there is already a free NUMA Node",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0-1", true),
+			),
+			maxMigrating: 2,
+		},
+		{
+			name: "node is short of CPUs",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0-2", true),
+				newTestPodCPUAllocation("pod-b", "4-6", true),
+			),
+			maxMigrating: 2,
+		},
+		{
+			name: "no NUMA Node can be freed",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0-1", false),
+				newTestPodCPUAllocation("pod-b", "4-5", false),
+			),
+			maxMigrating: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planNUMANodeDefragmentation(tt.state, tt.maxMigrating)
+			assert.Equal(t, tt.want, getPlanPodNames(plan))
+			if plan != nil {
+				assert.True(t, plan.atomic)
+			}
+		})
+	}
+}
+
+func TestPlanFullPCPUsDefragmentation(t *testing.T) {
+	tests := []struct {
+		name               string
+		state              *nodeCPUState
+		maxMigrating       int
+		minFragmentedPCPUs int
+		want               []string
+	}{
+		{
+			name: "free the partially allocated physical cores",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-b", "2", true),
+				newTestPodCPUAllocation("pod-a", "0", true),
+				newTestPodCPUAllocation("pod-c", "4-5", true),
+			),
+			maxMigrating:       2,
+			minFragmentedPCPUs: 2,
+			want:               []string{"pod-a", "pod-b"},
+		},
+		{
+			name: "fragmented physical cores are not enough",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-b", "2", true),
+				newTestPodCPUAllocation("pod-a", "0", true),
+			),
+			maxMigrating:       2,
+			minFragmentedPCPUs: 3,
+		},
+		{
+			name: "skip unevictable pods",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-b", "2", true),
+				newTestPodCPUAllocation("pod-a", "0", false),
+			),
+			maxMigrating:       2,
+			minFragmentedPCPUs: 2,
+			want:               []string{"pod-b"},
+		},
+		{
+			name: "prefer the pod freeing more physical cores within the limit",
+			state: newTestNodeCPUState("",
+				newTestPodCPUAllocation("pod-a", "0", true),
+				newTestPodCPUAllocation("pod-b", "1", true),
+				newTestPodCPUAllocation("pod-c", "2,4", true),
+				newTestPodCPUAllocation("pod-d", "6", true),
+			),
+			maxMigrating:       1,
+			minFragmentedPCPUs: 2,
+			want:               []string{"pod-c"},
+		},
+		{
+			name: "physical core partially allocated by reserved CPUs can not be freed",
+			state: newTestNodeCPUState("0",
+				newTestPodCPUAllocation("pod-a", "2", true),
+			),
+			maxMigrating:       2,
+			minFragmentedPCPUs: 2,
+			want:               []string{"pod-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planFullPCPUsDefragmentation(tt.state, tt.maxMigrating, tt.minFragmentedPCPUs)
+			assert.Equal(t, tt.want, getPlanPodNames(plan))
+		})
+	}
+}
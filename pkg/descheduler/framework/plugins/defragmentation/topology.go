@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defragmentation
+
+import (
+	"fmt"
+
+	nrtv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+// nodeCPUState describes the CPU topology and the CPU allocations of a node.
+type nodeCPUState struct {
+	nodeName     string
+	allCPUs      cpuset.CPUSet
+	numaNodeCPUs map[int]cpuset.CPUSet
+	coreCPUs     map[int]cpuset.CPUSet
+	// reservedCPUs are the allocated CPUs which can not be freed by migrating pods,
+	// e.g. the CPUs reserved by kubelet or node reservation, and the CPUs allocated to the pods managed by kubelet.
+	reservedCPUs cpuset.CPUSet
+	pods         []*podCPUAllocation
+}
+
+// podCPUAllocation describes the CPUs and NUMA Nodes bound to a pod by koord-scheduler.
+type podCPUAllocation struct {
+	pod       *corev1.Pod
+	cpus      cpuset.CPUSet
+	numaNodes sets.Int
+	evictable bool
+}
+
+func (s *nodeCPUState) allocatedCPUs() cpuset.CPUSet {
+	allocated := s.reservedCPUs
+	for _, p := range s.pods {
+		allocated = allocated.Union(p.cpus)
+	}
+	return allocated
+}
+
+func (p *podCPUAllocation) occupyNUMANode(numaNode int, numaNodeCPUs cpuset.CPUSet) bool {
+	return p.numaNodes.Has(numaNode) || !p.cpus.Intersection(numaNodeCPUs).IsEmpty()
+}
+
+// buildNodeCPUState builds the nodeCPUState from the NodeResourceTopology reported by koordlet and
+// the resource-status annotations of the pods. It returns nil if the CPU topology is not reported.
+func buildNodeCPUState(nrt *nrtv1alpha1.NodeResourceTopology, pods []*corev1.Pod, podFilter framework.FilterFunc) (*nodeCPUState, error) {
+	cpuTopology, err := extension.GetCPUTopology(nrt.Annotations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cpu topology, err: %w", err)
+	}
+	if len(cpuTopology.Detail) == 0 {
+		return nil, nil
+	}
+
+	state := &nodeCPUState{
+		nodeName:     nrt.Name,
+		numaNodeCPUs: map[int]cpuset.CPUSet{},
+		coreCPUs:     map[int]cpuset.CPUSet{},
+	}
+	allCPUs := cpuset.NewCPUSetBuilder()
+	numaNodeCPUs := map[int]*cpuset.CPUSetBuilder{}
+	coreCPUs := map[int]*cpuset.CPUSetBuilder{}
+	for _, info := range cpuTopology.Detail {
+		allCPUs.Add(int(info.ID))
+		if numaNodeCPUs[int(info.Node)] == nil {
+			numaNodeCPUs[int(info.Node)] = cpuset.NewCPUSetBuilder()
+		}
+		numaNodeCPUs[int(info.Node)].Add(int(info.ID))
+		if coreCPUs[int(info.Core)] == nil {
+			coreCPUs[int(info.Core)] = cpuset.NewCPUSetBuilder()
+		}
+		coreCPUs[int(info.Core)].Add(int(info.ID))
+	}
+	state.allCPUs = allCPUs.Result()
+	for node, builder := range numaNodeCPUs {
+		state.numaNodeCPUs[node] = builder.Result()
+	}
+	for core, builder := range coreCPUs {
+		state.coreCPUs[core] = builder.Result()
+	}
+	state.reservedCPUs = getReservedCPUs(nrt)
+
+	for _, pod := range pods {
+		if util.IsPodTerminated(pod) {
+			continue
+		}
+		resourceStatus, err := extension.GetResourceStatus(pod.Annotations)
+		if err != nil {
+			klog.V(4).InfoS("Failed to get resource status of pod", "pod", klog.KObj(pod), "err", err)
+			continue
+		}
+		cpus, err := cpuset.Parse(resourceStatus.CPUSet)
+		if err != nil {
+			klog.V(4).InfoS("Failed to parse cpuset of pod", "pod", klog.KObj(pod), "cpuset", resourceStatus.CPUSet, "err", err)
+			continue
+		}
+		numaNodes := sets.NewInt()
+		for _, numaNodeResource := range resourceStatus.NUMANodeResources {
+			numaNodes.Insert(int(numaNodeResource.Node))
+		}
+		if cpus.IsEmpty() && numaNodes.Len() == 0 {
+			continue
+		}
+		state.pods = append(state.pods, &podCPUAllocation{
+			pod:       pod,
+			cpus:      cpus,
+			numaNodes: numaNodes,
+			evictable: pod.DeletionTimestamp == nil && podFilter(pod),
+		})
+	}
+	return state, nil
+}
+
+// getReservedCPUs returns the CPUs which are not allocated by koord-scheduler, it is similar to how koord-scheduler
+// calculates the reserved CPUs of the node.
+func getReservedCPUs(nrt *nrtv1alpha1.NodeResourceTopology) cpuset.CPUSet {
+	builder := cpuset.NewCPUSetBuilder()
+	podCPUAllocs, err := extension.GetPodCPUAllocs(nrt.Annotations)
+	if err != nil {
+		klog.ErrorS(err, "Failed to GetPodCPUAllocs from NodeResourceTopology", "node", nrt.Name)
+	}
+	for _, v := range podCPUAllocs {
+		if !v.ManagedByKubelet || v.UID == "" || v.CPUSet == "" {
+			continue
+		}
+		cpus, err := cpuset.Parse(v.CPUSet)
+		if err != nil {
+			continue
+		}
+		builder.Add(cpus.ToSliceNoSort()...)
+	}
+
+	kubeletPolicy, err := extension.GetKubeletCPUManagerPolicy(nrt.Annotations)
+	if err != nil {
+		klog.ErrorS(err, "Failed to GetKubeletCPUManagerPolicy from NodeResourceTopology", "node", nrt.Name)
+	} else if cpus, err := cpuset.Parse(kubeletPolicy.ReservedCPUs); err == nil {
+		builder.Add(cpus.ToSliceNoSort()...)
+	}
+
+	reservedCPUs, _ := extension.GetReservedCPUs(nrt.Annotations)
+	if cpus, err := cpuset.Parse(reservedCPUs); err == nil {
+		builder.Add(cpus.ToSliceNoSort()...)
+	}
+
+	systemQOSResource, err := extension.GetSystemQOSResource(nrt.Annotations)
+	if err != nil {
+		klog.ErrorS(err, "Failed to GetSystemQOSResource from NodeResourceTopology", "node", nrt.Name)
+	} else if systemQOSResource != nil && systemQOSResource.IsCPUSetExclusive() {
+		if cpus, err := cpuset.Parse(systemQOSResource.CPUSet); err == nil {
+			builder.Add(cpus.ToSliceNoSort()...)
+		}
+	}
+	return builder.Result()
+}
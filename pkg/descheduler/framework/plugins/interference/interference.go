@@ -0,0 +1,391 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	koordslolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	nodeutil "github.com/koordinator-sh/koordinator/pkg/descheduler/node"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/utils/anomaly"
+)
+
+const (
+	InterferenceName = "Interference"
+)
+
+// contendedResource is the resource on which the victim is most likely interfered.
+type contendedResource string
+
+const (
+	contendedCPU    contendedResource = "cpu"
+	contendedMemory contendedResource = "memory"
+	contendedIO     contendedResource = "io"
+)
+
+var _ framework.BalancePlugin = &Interference{}
+
+// Interference migrates the pods to resolve the interference suffered by the latency-sensitive pods.
+// A LSE/LSR/LS pod is interfered when its CPI deviates from its own baseline or its PSI exceeds the thresholds,
+// which are reported by koordlet in the NodeMetric. After the pod is interfered for consecutive times, the pod
+// is regarded as a victim, and the most likely aggressor on the node, or the victim itself, is migrated by
+// PodMigrationJob in ReservationFirst mode. The node is not processed again until the cooldown expires.
+type Interference struct {
+	handle              framework.Handle
+	args                *deschedulerconfig.InterferenceArgs
+	podFilter           framework.FilterFunc
+	nodeSelector        labels.Selector
+	nodeMetricLister    koordslolisters.NodeMetricLister
+	podAnomalyDetectors *gocache.Cache
+	nodeCooldowns       *gocache.Cache
+}
+
+// NewInterference builds plugin from its arguments while passing a handle
+func NewInterference(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	interferenceArgs, ok := args.(*deschedulerconfig.InterferenceArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type InterferenceArgs, got %T", args)
+	}
+	if err := validation.ValidateInterferenceArgs(nil, interferenceArgs); err != nil {
+		return nil, err
+	}
+
+	nodeSelector := labels.Everything()
+	if interferenceArgs.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(interferenceArgs.NodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		nodeSelector = selector
+	}
+
+	var excludedNamespaces sets.String
+	var includedNamespaces sets.String
+	if interferenceArgs.EvictableNamespaces != nil {
+		excludedNamespaces = sets.NewString(interferenceArgs.EvictableNamespaces.Exclude...)
+		includedNamespaces = sets.NewString(interferenceArgs.EvictableNamespaces.Include...)
+	}
+	podFilter, err := podutil.NewOptions().
+		WithFilter(handle.Evictor().Filter).
+		WithoutNamespaces(excludedNamespaces).
+		WithNamespaces(includedNamespaces).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	koordClientSet, ok := handle.(koordclientset.Interface)
+	if !ok {
+		kubeConfig := *handle.KubeConfig()
+		kubeConfig.ContentType = runtime.ContentTypeJSON
+		kubeConfig.AcceptContentTypes = runtime.ContentTypeJSON
+		koordClientSet, err = koordclientset.NewForConfig(&kubeConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(koordClientSet, 0)
+	nodeMetricInformer := koordSharedInformerFactory.Slo().V1alpha1().NodeMetrics()
+	nodeMetricInformer.Informer()
+	koordSharedInformerFactory.Start(context.TODO().Done())
+	koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+
+	return &Interference{
+		handle:              handle,
+		args:                interferenceArgs,
+		podFilter:           podFilter,
+		nodeSelector:        nodeSelector,
+		nodeMetricLister:    nodeMetricInformer.Lister(),
+		podAnomalyDetectors: gocache.New(5*time.Minute, 5*time.Minute),
+		nodeCooldowns:       gocache.New(time.Duration(interferenceArgs.CooldownSeconds)*time.Second, time.Minute),
+	}, nil
+}
+
+// Name retrieves the plugin name
+func (pl *Interference) Name() string {
+	return InterferenceName
+}
+
+// Balance extension point implementation for the plugin
+func (pl *Interference) Balance(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	if pl.args.Paused {
+		klog.Infof("Interference is paused and will do nothing.")
+		return nil
+	}
+
+	for _, node := range nodes {
+		if !pl.nodeSelector.Matches(labels.Set(node.Labels)) || nodeutil.IsNodeUnschedulable(node) {
+			continue
+		}
+		if _, ok := pl.nodeCooldowns.Get(node.Name); ok {
+			klog.V(5).InfoS("Node is in the cooldown of the last migration, skip it", "node", node.Name)
+			continue
+		}
+		if err := pl.processNode(ctx, node); err != nil {
+			klog.ErrorS(err, "Failed to resolve the interference", "node", node.Name)
+		}
+	}
+	return nil
+}
+
+// interferedPod is a latency-sensitive pod interfered in the current round.
+type interferedPod struct {
+	pod *corev1.Pod
+	// severity is the maximum ratio of the interference indicators to their thresholds, not less than 1.
+	severity float64
+	resource contendedResource
+}
+
+func (pl *Interference) processNode(ctx context.Context, node *corev1.Node) error {
+	nodeMetric, err := pl.nodeMetricLister.Get(node.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if nodeMetric.Status.UpdateTime == nil || pl.args.NodeMetricExpirationSeconds != nil &&
+		time.Since(nodeMetric.Status.UpdateTime.Time) >= time.Duration(*pl.args.NodeMetricExpirationSeconds)*time.Second {
+		klog.V(4).InfoS("NodeMetric has expired, skip the node", "node", node.Name)
+		return nil
+	}
+	pods, err := pl.handle.GetPodsAssignedToNodeFunc()(node.Name, func(pod *corev1.Pod) bool {
+		return pod.DeletionTimestamp == nil
+	})
+	if err != nil {
+		return err
+	}
+	podMetrics := make(map[types.NamespacedName]*slov1alpha1.PodMetricInfo, len(nodeMetric.Status.PodsMetric))
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		podMetrics[types.NamespacedName{Namespace: podMetric.Namespace, Name: podMetric.Name}] = podMetric
+	}
+
+	victims := pl.filterRealVictims(pl.detectInterferedPods(pods, podMetrics))
+	if len(victims) == 0 {
+		klog.V(5).InfoS("No latency-sensitive pods are persistently interfered, nothing to do here", "node", node.Name)
+		return nil
+	}
+	sort.Slice(victims, func(i, j int) bool {
+		if victims[i].severity != victims[j].severity {
+			return victims[i].severity > victims[j].severity
+		}
+		return victims[i].pod.Name < victims[j].pod.Name
+	})
+	victim := victims[0]
+
+	var target *corev1.Pod
+	var reason string
+	if pl.args.MigrationTarget == deschedulerconfig.InterferenceMigrateVictim {
+		if pl.podFilter(victim.pod) {
+			target = victim.pod
+		}
+		reason = fmt.Sprintf("pod is interfered on %s by the neighbours", victim.resource)
+	} else {
+		excluded := sets.NewString()
+		for _, v := range victims {
+			excluded.Insert(string(v.pod.UID))
+		}
+		target = pl.selectAggressor(pods, podMetrics, victim.resource, excluded)
+		reason = fmt.Sprintf("pod interferes with pod %s on %s", klog.KObj(victim.pod), victim.resource)
+	}
+	if target == nil || !pl.handle.Evictor().PreEvictionFilter(target) {
+		klog.V(4).InfoS("No pods can be migrated to resolve the interference", "victim", klog.KObj(victim.pod),
+			"resource", victim.resource, "node", node.Name)
+		return nil
+	}
+
+	if pl.args.DryRun {
+		klog.InfoS("Migrate pod in dry run mode", "pod", klog.KObj(target), "node", node.Name, "reason", reason)
+	} else {
+		ctx = migration.WithContext(ctx, &migration.JobContext{
+			Mode: sev1alpha1.PodMigrationJobModeReservationFirst,
+		})
+		evictionOptions := framework.EvictOptions{
+			PluginName: InterferenceName,
+			Reason:     reason,
+		}
+		if !pl.handle.Evictor().Evict(ctx, target, evictionOptions) {
+			klog.InfoS("Failed to migrate pod", "pod", klog.KObj(target), "node", node.Name)
+			return nil
+		}
+		klog.InfoS("Migrated pod", "pod", klog.KObj(target), "node", node.Name, "reason", reason)
+	}
+	if pl.args.CooldownSeconds > 0 {
+		pl.nodeCooldowns.SetDefault(node.Name, struct{}{})
+	}
+	if obj, ok := pl.podAnomalyDetectors.Get(string(victim.pod.UID)); ok {
+		obj.(anomaly.Detector).Reset()
+	}
+	return nil
+}
+
+// detectInterferedPods returns the latency-sensitive pods whose interference indicators exceed the thresholds.
+func (pl *Interference) detectInterferedPods(pods []*corev1.Pod, podMetrics map[types.NamespacedName]*slov1alpha1.PodMetricInfo) []*interferedPod {
+	var interferedPods []*interferedPod
+	for _, pod := range pods {
+		switch extension.GetPodQoSClassWithDefault(pod) {
+		case extension.QoSLSE, extension.QoSLSR, extension.QoSLS:
+		default:
+			continue
+		}
+		podMetric := podMetrics[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+		if podMetric == nil || podMetric.Interference == nil {
+			continue
+		}
+		severity, contended := pl.getInterferenceSeverity(podMetric.Interference)
+		if severity < 1 {
+			// the pod is not interfered in this round, restart the detection
+			if obj, ok := pl.podAnomalyDetectors.Get(string(pod.UID)); ok {
+				obj.(anomaly.Detector).Reset()
+			}
+			continue
+		}
+		interferedPods = append(interferedPods, &interferedPod{pod: pod, severity: severity, resource: contended})
+	}
+	return interferedPods
+}
+
+// getInterferenceSeverity returns the maximum ratio of the interference indicators to their thresholds,
+// and the resource which the indicator belongs to.
+func (pl *Interference) getInterferenceSeverity(interference *slov1alpha1.PodInterference) (float64, contendedResource) {
+	var severity float64
+	var contended contendedResource
+	update := func(value float64, threshold int64, r contendedResource) {
+		if threshold <= 0 {
+			return
+		}
+		if s := value / float64(threshold); s > severity {
+			severity, contended = s, r
+		}
+	}
+	if interference.CPIDeviation != nil {
+		// the deviation is a ratio, convert it to a percentage
+		update(float64(interference.CPIDeviation.MilliValue())/10, pl.args.CPIDeviationThresholdPercent, contendedCPU)
+	}
+	if psi := interference.PSI; psi != nil {
+		update(quantityToFloat(psi.CPUSome), pl.args.PSISomeThresholdPercent, contendedCPU)
+		update(quantityToFloat(psi.CPUFull), pl.args.PSIFullThresholdPercent, contendedCPU)
+		update(quantityToFloat(psi.MemorySome), pl.args.PSISomeThresholdPercent, contendedMemory)
+		update(quantityToFloat(psi.MemoryFull), pl.args.PSIFullThresholdPercent, contendedMemory)
+		update(quantityToFloat(psi.IOSome), pl.args.PSISomeThresholdPercent, contendedIO)
+		update(quantityToFloat(psi.IOFull), pl.args.PSIFullThresholdPercent, contendedIO)
+	}
+	return severity, contended
+}
+
+// filterRealVictims returns the interfered pods which have been interfered for consecutive times.
+func (pl *Interference) filterRealVictims(interferedPods []*interferedPod) []*interferedPod {
+	anomalyCondition := pl.args.AnomalyCondition
+	if anomalyCondition == nil || anomalyCondition.ConsecutiveAbnormalities <= 1 {
+		return interferedPods
+	}
+	var victims []*interferedPod
+	for _, v := range interferedPods {
+		key := string(v.pod.UID)
+		obj, ok := pl.podAnomalyDetectors.Get(key)
+		if !ok {
+			opts := anomaly.Options{
+				Timeout: anomalyCondition.Timeout.Duration,
+				NormalConditionFn: func(counter anomaly.Counter) bool {
+					return counter.ConsecutiveNormalities > anomalyCondition.ConsecutiveNormalities
+				},
+				AnomalyConditionFn: func(counter anomaly.Counter) bool {
+					return counter.ConsecutiveAbnormalities >= anomalyCondition.ConsecutiveAbnormalities
+				},
+			}
+			obj = anomaly.NewBasicDetector(key, opts)
+		}
+		anomalyDetector := obj.(anomaly.Detector)
+		if state, _ := anomalyDetector.Mark(false); state == anomaly.StateAnomaly {
+			victims = append(victims, v)
+		}
+		pl.podAnomalyDetectors.Set(key, anomalyDetector, gocache.DefaultExpiration)
+	}
+	return victims
+}
+
+// selectAggressor returns the evictable pod which uses the most of the contended resource on the node.
+// The pods with lower priority are preferred if the usages are the same.
+func (pl *Interference) selectAggressor(pods []*corev1.Pod, podMetrics map[types.NamespacedName]*slov1alpha1.PodMetricInfo,
+	contended contendedResource, excluded sets.String) *corev1.Pod {
+	var aggressor *corev1.Pod
+	var maxUsage int64
+	for _, pod := range pods {
+		if excluded.Has(string(pod.UID)) || !pl.podFilter(pod) {
+			continue
+		}
+		podMetric := podMetrics[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+		if podMetric == nil {
+			continue
+		}
+		usage := getContendedResourceUsage(podMetric, contended)
+		if usage <= 0 {
+			continue
+		}
+		if aggressor == nil || usage > maxUsage ||
+			usage == maxUsage && corev1helpers.PodPriority(pod) < corev1helpers.PodPriority(aggressor) {
+			aggressor, maxUsage = pod, usage
+		}
+	}
+	return aggressor
+}
+
+func getContendedResourceUsage(podMetric *slov1alpha1.PodMetricInfo, contended contendedResource) int64 {
+	switch contended {
+	case contendedMemory:
+		q := podMetric.PodUsage.ResourceList[corev1.ResourceMemory]
+		return q.Value()
+	case contendedIO:
+		var bytes int64
+		for _, usage := range podMetric.BlockIOUsages {
+			bytes += usage.ReadBytes.Value() + usage.WriteBytes.Value()
+		}
+		return bytes
+	default:
+		q := podMetric.PodUsage.ResourceList[corev1.ResourceCPU]
+		return q.MilliValue()
+	}
+}
+
+func quantityToFloat(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000
+}
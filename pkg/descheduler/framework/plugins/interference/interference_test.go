@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interference
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+)
+
+type fakeEvictor struct {
+	evicted map[string]sev1alpha1.PodMigrationJobMode
+}
+
+func (e *fakeEvictor) Filter(pod *corev1.Pod) bool {
+	return pod.Labels["evictable"] != "false"
+}
+
+func (e *fakeEvictor) PreEvictionFilter(pod *corev1.Pod) bool {
+	return true
+}
+
+func (e *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	var mode sev1alpha1.PodMigrationJobMode
+	if jobCtx := migration.FromContext(ctx); jobCtx != nil {
+		mode = jobCtx.Mode
+	}
+	e.evicted[pod.Name] = mode
+	return true
+}
+
+type fakeFrameworkHandle struct {
+	framework.Handle
+	*koordfake.Clientset
+	evictor *fakeEvictor
+	pods    []*corev1.Pod
+}
+
+func (h *fakeFrameworkHandle) Evictor() framework.Evictor {
+	return h.evictor
+}
+
+func (h *fakeFrameworkHandle) GetPodsAssignedToNodeFunc() framework.GetPodsAssignedToNodeFunc {
+	return func(nodeName string, filter framework.FilterFunc) ([]*corev1.Pod, error) {
+		var pods []*corev1.Pod
+		for _, pod := range h.pods {
+			if pod.Spec.NodeName == nodeName && filter(pod) {
+				pods = append(pods, pod)
+			}
+		}
+		return pods, nil
+	}
+}
+
+type testPod struct {
+	name         string
+	qos          extension.QoSClass
+	evictable    bool
+	cpu          string
+	memory       string
+	ioBytes      string
+	interference *slov1alpha1.PodInterference
+}
+
+func newTestPodsAndNodeMetric(nodeName string, testPods []testPod) ([]*corev1.Pod, *slov1alpha1.NodeMetric) {
+	nodeMetric := &slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{Time: time.Now()},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{},
+		},
+	}
+	var pods []*corev1.Pod
+	for _, p := range testPods {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      p.name,
+				UID:       types.UID(p.name),
+				Labels: map[string]string{
+					extension.LabelPodQoS: string(p.qos),
+				},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+			},
+		}
+		if !p.evictable {
+			pod.Labels["evictable"] = "false"
+		}
+		pods = append(pods, pod)
+
+		podMetric := &slov1alpha1.PodMetricInfo{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			PodUsage: slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(p.cpu),
+					corev1.ResourceMemory: resource.MustParse(p.memory),
+				},
+			},
+			Interference: p.interference,
+		}
+		if p.ioBytes != "" {
+			podMetric.BlockIOUsages = []slov1alpha1.BlockIOUsage{
+				{Device: "sda", ReadBytes: resource.MustParse(p.ioBytes)},
+			}
+		}
+		nodeMetric.Status.PodsMetric = append(nodeMetric.Status.PodsMetric, podMetric)
+	}
+	return pods, nodeMetric
+}
+
+func cpiDeviation(percent int64) *slov1alpha1.PodInterference {
+	return &slov1alpha1.PodInterference{
+		CPIDeviation: resource.NewMilliQuantity(percent*10, resource.DecimalSI),
+	}
+}
+
+func ioPressure(some string) *slov1alpha1.PodInterference {
+	return &slov1alpha1.PodInterference{
+		PSI: &slov1alpha1.PressureStall{
+			IOSome: resource.MustParse(some),
+		},
+	}
+}
+
+func newTestArgs() *deschedulerconfig.InterferenceArgs {
+	return &deschedulerconfig.InterferenceArgs{
+		NodeMetricExpirationSeconds:  pointer.Int64(180),
+		CPIDeviationThresholdPercent: 50,
+		PSISomeThresholdPercent:      40,
+		PSIFullThresholdPercent:      20,
+		AnomalyCondition: &deschedulerconfig.LoadAnomalyCondition{
+			Timeout:                  metav1.Duration{Duration: 5 * time.Minute},
+			ConsecutiveAbnormalities: 2,
+		},
+		MigrationTarget: deschedulerconfig.InterferenceMigrateAggressor,
+		CooldownSeconds: 600,
+	}
+}
+
+func TestInterference(t *testing.T) {
+	tests := []struct {
+		name        string
+		modifyArgs  func(args *deschedulerconfig.InterferenceArgs)
+		pods        []testPod
+		rounds      int
+		wantEvicted map[string]sev1alpha1.PodMigrationJobMode
+	}{
+		{
+			name: "migrate the aggressor on cpu",
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-small", qos: extension.QoSBE, evictable: true, cpu: "1", memory: "8Gi"},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+				{name: "be-unevictable", qos: extension.QoSBE, cpu: "8", memory: "1Gi"},
+			},
+			rounds: 2,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"be-large": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+		},
+		{
+			name: "migrate the aggressor on io",
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLSR, cpu: "2", memory: "1Gi", interference: ioPressure("45")},
+				{name: "be-cpu", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi", ioBytes: "1Mi"},
+				{name: "be-io", qos: extension.QoSBE, evictable: true, cpu: "1", memory: "1Gi", ioBytes: "100Mi"},
+			},
+			rounds: 2,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"be-io": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+		},
+		{
+			name: "pod is not interfered for consecutive times",
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+			},
+			rounds:      1,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "interference is under the thresholds",
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, cpu: "2", memory: "1Gi", interference: cpiDeviation(30)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+			},
+			rounds:      3,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "BE pods are not regarded as victims",
+			pods: []testPod{
+				{name: "be-victim", qos: extension.QoSBE, evictable: true, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+			},
+			rounds:      3,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "migrate the victim",
+			modifyArgs: func(args *deschedulerconfig.InterferenceArgs) {
+				args.MigrationTarget = deschedulerconfig.InterferenceMigrateVictim
+			},
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, evictable: true, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+			},
+			rounds: 2,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"victim": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+		},
+		{
+			name: "migrate only once in the cooldown",
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+				{name: "be-small", qos: extension.QoSBE, evictable: true, cpu: "1", memory: "1Gi"},
+			},
+			rounds: 6,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{
+				"be-large": sev1alpha1.PodMigrationJobModeReservationFirst,
+			},
+		},
+		{
+			name: "dry run",
+			modifyArgs: func(args *deschedulerconfig.InterferenceArgs) {
+				args.DryRun = true
+			},
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+			},
+			rounds:      2,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+		{
+			name: "paused",
+			modifyArgs: func(args *deschedulerconfig.InterferenceArgs) {
+				args.Paused = true
+			},
+			pods: []testPod{
+				{name: "victim", qos: extension.QoSLS, cpu: "2", memory: "1Gi", interference: cpiDeviation(80)},
+				{name: "be-large", qos: extension.QoSBE, evictable: true, cpu: "4", memory: "1Gi"},
+			},
+			rounds:      2,
+			wantEvicted: map[string]sev1alpha1.PodMigrationJobMode{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := newTestArgs()
+			if tt.modifyArgs != nil {
+				tt.modifyArgs(args)
+			}
+			pods, nodeMetric := newTestPodsAndNodeMetric("test-node", tt.pods)
+			handle := &fakeFrameworkHandle{
+				Clientset: koordfake.NewSimpleClientset(nodeMetric),
+				evictor:   &fakeEvictor{evicted: map[string]sev1alpha1.PodMigrationJobMode{}},
+				pods:      pods,
+			}
+			pl, err := NewInterference(args, handle)
+			assert.NoError(t, err)
+			nodes := []*corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-without-metric"}},
+			}
+			for i := 0; i < tt.rounds; i++ {
+				status := pl.(framework.BalancePlugin).Balance(context.TODO(), nodes)
+				assert.Nil(t, status)
+			}
+			assert.Equal(t, tt.wantEvicted, handle.evictor.evicted)
+		})
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package plugins
 
 import (
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/defragmentation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/interference"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/kubernetes"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/loadaware"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
@@ -24,7 +26,9 @@ import (
 
 func NewInTreeRegistry() runtime.Registry {
 	registry := runtime.Registry{
-		loadaware.LowNodeLoadName: loadaware.NewLowNodeLoad,
+		loadaware.LowNodeLoadName:              loadaware.NewLowNodeLoad,
+		defragmentation.CPUDefragmentationName: defragmentation.NewCPUDefragmentation,
+		interference.InterferenceName:          interference.NewInterference,
 	}
 	kubernetes.SetupK8sDeschedulerPlugins(registry)
 	return registry
@@ -38,6 +38,13 @@ const (
 	// AuditEventsHTTPHandler is used to get recent events from koordlet port.
 	AuditEventsHTTPHandler featuregate.Feature = "AuditEventsHTTPHandler"
 
+	// owner: @zwzhang0107
+	// alpha: v1.4
+	//
+	// AuditEventsKubeEvent is used to send the audit events as kubernetes events, so that the audit events of all
+	// nodes can be queried from the apiserver.
+	AuditEventsKubeEvent featuregate.Feature = "AuditEventsKubeEvent"
+
 	// owner: @zwzhang0107 @saintube
 	// alpha: v0.1
 	// beta: v1.1
@@ -141,6 +148,45 @@ const (
 	// Backend applications can enable the hugepages based on the allocation results.
 	// For example, the CSI mounts the pre-allocated hugepages into the pod.
 	HugePageReport featuregate.Feature = "HugePageReport"
+
+	// owner: @zwzhang0107 @saintube
+	// alpha: v1.4
+	//
+	// NetQoSReconcile enforces the network bandwidth guarantees and limits of the qos classes.
+	NetQoSReconcile featuregate.Feature = "NetQoSReconcile"
+
+	// owner: @zwzhang0107 @saintube
+	// alpha: v1.4
+	//
+	// SeasonalPrediction trains the time-slotted models of the node usages and reports the prod reclaimable
+	// resources according to the predicted peak of the upcoming hours.
+	SeasonalPrediction featuregate.Feature = "SeasonalPrediction"
+
+	// owner: @jasonliu747 @eahydra
+	// alpha: v1.4
+	//
+	// RDMADevices discovers the RDMA NICs and their SR-IOV virtual functions and reports them into the Device CR.
+	RDMADevices featuregate.Feature = "RDMADevices"
+
+	// owner: @zwzhang0107 @saintube
+	// alpha: v1.4
+	//
+	// BEMemoryPressureThrottle lowers the memory.high of BE pods when LS pods suffer from the memory pressure and
+	// relaxes it when the pressure subsides.
+	BEMemoryPressureThrottle featuregate.Feature = "BEMemoryPressureThrottle"
+
+	// owner: @zwzhang0107 @saintube
+	// alpha: v1.4
+	//
+	// NetworkCollector collects the network traffic of pods and physical NICs and reports them into the NodeMetric.
+	NetworkCollector featuregate.Feature = "NetworkCollector"
+
+	// owner: @zwzhang0107 @saintube
+	// alpha: v1.4
+	//
+	// BlkIOCollector collects the block I/O throughput, IOPS and latency of pods, containers and disks,
+	// and reports them into the NodeMetric.
+	BlkIOCollector featuregate.Feature = "BlkIOCollector"
 )
 
 func init() {
@@ -152,24 +198,31 @@ var (
 	DefaultKoordletFeatureGate        featuregate.FeatureGate        = DefaultMutableKoordletFeatureGate
 
 	defaultKoordletFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-		AuditEvents:            {Default: false, PreRelease: featuregate.Alpha},
-		AuditEventsHTTPHandler: {Default: false, PreRelease: featuregate.Alpha},
-		BECPUSuppress:          {Default: true, PreRelease: featuregate.Beta},
-		BECPUManager:           {Default: false, PreRelease: featuregate.Alpha},
-		BECPUEvict:             {Default: false, PreRelease: featuregate.Alpha},
-		BEMemoryEvict:          {Default: false, PreRelease: featuregate.Alpha},
-		CPUBurst:               {Default: true, PreRelease: featuregate.Beta},
-		SystemConfig:           {Default: false, PreRelease: featuregate.Alpha},
-		RdtResctrl:             {Default: true, PreRelease: featuregate.Beta},
-		CgroupReconcile:        {Default: false, PreRelease: featuregate.Alpha},
-		NodeTopologyReport:     {Default: true, PreRelease: featuregate.Beta},
-		Accelerators:           {Default: false, PreRelease: featuregate.Alpha},
-		CPICollector:           {Default: false, PreRelease: featuregate.Alpha},
-		Libpfm4:                {Default: false, PreRelease: featuregate.Alpha},
-		PSICollector:           {Default: false, PreRelease: featuregate.Alpha},
-		BlkIOReconcile:         {Default: false, PreRelease: featuregate.Alpha},
-		ColdPageCollector:      {Default: false, PreRelease: featuregate.Alpha},
-		HugePageReport:         {Default: false, PreRelease: featuregate.Alpha},
+		AuditEvents:              {Default: false, PreRelease: featuregate.Alpha},
+		AuditEventsHTTPHandler:   {Default: false, PreRelease: featuregate.Alpha},
+		AuditEventsKubeEvent:     {Default: false, PreRelease: featuregate.Alpha},
+		BECPUSuppress:            {Default: true, PreRelease: featuregate.Beta},
+		BECPUManager:             {Default: false, PreRelease: featuregate.Alpha},
+		BECPUEvict:               {Default: false, PreRelease: featuregate.Alpha},
+		BEMemoryEvict:            {Default: false, PreRelease: featuregate.Alpha},
+		CPUBurst:                 {Default: true, PreRelease: featuregate.Beta},
+		SystemConfig:             {Default: false, PreRelease: featuregate.Alpha},
+		RdtResctrl:               {Default: true, PreRelease: featuregate.Beta},
+		CgroupReconcile:          {Default: false, PreRelease: featuregate.Alpha},
+		NodeTopologyReport:       {Default: true, PreRelease: featuregate.Beta},
+		Accelerators:             {Default: false, PreRelease: featuregate.Alpha},
+		CPICollector:             {Default: false, PreRelease: featuregate.Alpha},
+		Libpfm4:                  {Default: false, PreRelease: featuregate.Alpha},
+		PSICollector:             {Default: false, PreRelease: featuregate.Alpha},
+		BlkIOReconcile:           {Default: false, PreRelease: featuregate.Alpha},
+		ColdPageCollector:        {Default: false, PreRelease: featuregate.Alpha},
+		HugePageReport:           {Default: false, PreRelease: featuregate.Alpha},
+		NetQoSReconcile:          {Default: false, PreRelease: featuregate.Alpha},
+		SeasonalPrediction:       {Default: false, PreRelease: featuregate.Alpha},
+		RDMADevices:              {Default: false, PreRelease: featuregate.Alpha},
+		BEMemoryPressureThrottle: {Default: false, PreRelease: featuregate.Alpha},
+		NetworkCollector:         {Default: false, PreRelease: featuregate.Alpha},
+		BlkIOCollector:           {Default: false, PreRelease: featuregate.Alpha},
 	}
 )
 
@@ -186,6 +239,13 @@ func IsFeatureDisabled(nodeSLO *slov1alpha1.NodeSLO, feature featuregate.Feature
 			return true, fmt.Errorf("cannot parse feature config for invalid nodeSLO %v", nodeSLO)
 		}
 		return !(*spec.ResourceUsedThresholdWithBE.Enable), nil
+	case BEMemoryPressureThrottle:
+		if spec.ResourceUsedThresholdWithBE == nil || spec.ResourceUsedThresholdWithBE.Enable == nil {
+			return true, fmt.Errorf("cannot parse feature config for invalid nodeSLO %v", nodeSLO)
+		}
+		throttleStrategy := spec.ResourceUsedThresholdWithBE.MemoryPressureThrottle
+		return !(*spec.ResourceUsedThresholdWithBE.Enable) || throttleStrategy == nil ||
+			throttleStrategy.Enable == nil || !(*throttleStrategy.Enable), nil
 	default:
 		return true, fmt.Errorf("cannot parse feature config for unsupported feature %s", feature)
 	}
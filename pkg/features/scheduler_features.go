@@ -57,6 +57,13 @@ const (
 	//
 	// ResizePod is used to enable resize pod feature
 	ResizePod featuregate.Feature = "ResizePod"
+
+	// owner: @joseph
+	// alpha: v1.4
+	//
+	// ElasticQuotaUsageAccounting is used to accumulate the used and borrowed resource-hours of
+	// ElasticQuotas for chargeback, which are persisted in the quota annotations and exported as metrics.
+	ElasticQuotaUsageAccounting featuregate.Feature = "ElasticQuotaUsageAccounting"
 )
 
 var defaultSchedulerFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -69,6 +76,7 @@ var defaultSchedulerFeatureGates = map[featuregate.Feature]featuregate.FeatureSp
 	ElasticQuotaIgnorePodOverhead:      {Default: false, PreRelease: featuregate.Alpha},
 	ElasticQuotaGuaranteeUsage:         {Default: false, PreRelease: featuregate.Alpha},
 	DisableDefaultQuota:                {Default: false, PreRelease: featuregate.Alpha},
+	ElasticQuotaUsageAccounting:        {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
@@ -35,8 +35,10 @@ var (
 	Default = NewEmptyAuditor()
 )
 
-func NewAuditor(c *Config) Auditor {
-	logWriter := NewFluentEventLogger(c.LogDir, c.MaxDiskSpaceMB, c.Verbose)
+// NewAuditor creates an Auditor which writes the events to the local log files, and also the extra sinks if any.
+func NewAuditor(c *Config, sinks ...EventWriter) Auditor {
+	writers := append([]EventWriter{NewEventLogger(c.LogDir, c.MaxDiskSpaceMB, c.Verbose)}, sinks...)
+	logWriter := &eventFluentWriter{writer: newMultiEventWriter(writers...)}
 	logReader := NewEventReader(c.LogDir)
 	return &auditor{
 		config:        c,
@@ -291,8 +293,8 @@ func (e *emptyEventWriter) Close() error {
 }
 
 // SetupDefaultAuditor initialize the `Default` auditor.
-func SetupDefaultAuditor(c *Config, stopCh <-chan struct{}) {
-	Default = NewAuditor(c)
+func SetupDefaultAuditor(c *Config, stopCh <-chan struct{}, sinks ...EventWriter) {
+	Default = NewAuditor(c, sinks...)
 	go Default.Run(stopCh)
 }
 
@@ -19,17 +19,25 @@ package audit
 import (
 	"flag"
 	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
 )
 
 type Config struct {
-	LogDir               string
-	Verbose              int
-	MaxDiskSpaceMB       int
-	MaxConcurrentReaders int
-	ActiveReaderTTL      time.Duration
-	DefaultEventsLimit   int
-	MaxEventsLimit       int
-	TickerDuration       time.Duration
+	LogDir                string
+	Verbose               int
+	MaxDiskSpaceMB        int
+	MaxConcurrentReaders  int
+	ActiveReaderTTL       time.Duration
+	DefaultEventsLimit    int
+	MaxEventsLimit        int
+	TickerDuration        time.Duration
+	KubeEventReasons      []string
+	KubeEventQPS          float64
+	KubeEventBurst        int
+	KubeEventWarningQPS   float64
+	KubeEventWarningBurst int
+	KubeEventDedupWindow  time.Duration
 }
 
 func NewDefaultConfig() *Config {
@@ -42,6 +50,19 @@ func NewDefaultConfig() *Config {
 		DefaultEventsLimit:   256,
 		MaxEventsLimit:       2048,
 		TickerDuration:       time.Minute,
+		// the decisions on the BE pods, e.g. the evictions, the suppressions and the resctrl changes
+		KubeEventReasons: []string{
+			"EvictPodByNodeMemoryUsage",
+			"EvictPodByBECPUSatisfaction",
+			"AdjustBEByNodeCPUUsage",
+			"AdjustBEByMemoryPressure",
+			"UpdateResctrl",
+		},
+		KubeEventQPS:          1,
+		KubeEventBurst:        10,
+		KubeEventWarningQPS:   1,
+		KubeEventWarningBurst: 10,
+		KubeEventDedupWindow:  time.Minute * 10,
 	}
 }
 
@@ -51,4 +72,10 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.MaxDiskSpaceMB, "audit-max-disk-space-mb", c.MaxDiskSpaceMB, "Max disk space occupied of audit log")
 	fs.IntVar(&c.MaxConcurrentReaders, "audit-max-concurrent-readers", c.MaxConcurrentReaders, "Max concurrent readers of the audit log")
 	fs.IntVar(&c.MaxEventsLimit, "audit-max-events-limit", c.MaxEventsLimit, "Max events limit in one request of the audit log")
+	fs.Var(cliflag.NewStringSlice(&c.KubeEventReasons), "audit-kube-event-reasons", "The reasons of the audit events sent as kubernetes events, e.g. EvictPodByNodeMemoryUsage, it can be specified multiple times")
+	fs.Float64Var(&c.KubeEventQPS, "audit-kube-event-qps", c.KubeEventQPS, "The qps of the normal audit events sent as kubernetes events")
+	fs.IntVar(&c.KubeEventBurst, "audit-kube-event-burst", c.KubeEventBurst, "The burst of the normal audit events sent as kubernetes events")
+	fs.Float64Var(&c.KubeEventWarningQPS, "audit-kube-event-warning-qps", c.KubeEventWarningQPS, "The qps of the warning audit events (e.g. evictions) sent as kubernetes events")
+	fs.IntVar(&c.KubeEventWarningBurst, "audit-kube-event-warning-burst", c.KubeEventWarningBurst, "The burst of the warning audit events (e.g. evictions) sent as kubernetes events")
+	fs.DurationVar(&c.KubeEventDedupWindow, "audit-kube-event-dedup-window", c.KubeEventDedupWindow, "The audit events of the same reason and object in the window are sent as kubernetes events only once")
 }
@@ -34,6 +34,18 @@ func Test_NewDefaultConfig(t *testing.T) {
 		DefaultEventsLimit:   256,
 		MaxEventsLimit:       2048,
 		TickerDuration:       time.Minute,
+		KubeEventReasons: []string{
+			"EvictPodByNodeMemoryUsage",
+			"EvictPodByBECPUSatisfaction",
+			"AdjustBEByNodeCPUUsage",
+			"AdjustBEByMemoryPressure",
+			"UpdateResctrl",
+		},
+		KubeEventQPS:          1,
+		KubeEventBurst:        10,
+		KubeEventWarningQPS:   1,
+		KubeEventWarningBurst: 10,
+		KubeEventDedupWindow:  time.Minute * 10,
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -45,6 +57,10 @@ func Test_InitFlags(t *testing.T) {
 		"--audit-log-dir=/tmp/log/koordlet",
 		"--audit-verbose=4",
 		"--audit-max-disk-space-mb=32",
+		"--audit-kube-event-reasons=EvictPodByNodeMemoryUsage",
+		"--audit-kube-event-reasons=UpdateResctrl",
+		"--audit-kube-event-warning-burst=20",
+		"--audit-kube-event-dedup-window=5m",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
@@ -74,14 +90,20 @@ func Test_InitFlags(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			raw := &Config{
-				LogDir:               tt.fields.LogDir,
-				Verbose:              tt.fields.Verbose,
-				MaxDiskSpaceMB:       tt.fields.MaxDiskSpaceMB,
-				MaxConcurrentReaders: 4,
-				ActiveReaderTTL:      time.Minute * 10,
-				DefaultEventsLimit:   256,
-				MaxEventsLimit:       2048,
-				TickerDuration:       time.Minute,
+				LogDir:                tt.fields.LogDir,
+				Verbose:               tt.fields.Verbose,
+				MaxDiskSpaceMB:        tt.fields.MaxDiskSpaceMB,
+				MaxConcurrentReaders:  4,
+				ActiveReaderTTL:       time.Minute * 10,
+				DefaultEventsLimit:    256,
+				MaxEventsLimit:        2048,
+				TickerDuration:        time.Minute,
+				KubeEventReasons:      []string{"EvictPodByNodeMemoryUsage", "UpdateResctrl"},
+				KubeEventQPS:          1,
+				KubeEventBurst:        10,
+				KubeEventWarningQPS:   1,
+				KubeEventWarningBurst: 20,
+				KubeEventDedupWindow:  time.Minute * 5,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
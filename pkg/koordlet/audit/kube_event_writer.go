@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/cache"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+)
+
+const (
+	KubeEventComponent = "koordlet-audit"
+
+	// kubeEventDedupCacheSize is the max number of the recent event keys to deduplicate.
+	kubeEventDedupCacheSize = 1024
+)
+
+// NewKubeEventWriter creates an EventWriter which sends the audit events as kubernetes events, so that the audit
+// events of all nodes can be queried from the apiserver. Only the events of the KubeEventReasons are sent, the events
+// of the same reason and object in the dedup window are sent only once, and the events are dropped if the rate limit
+// exceeded. The warning events (e.g. evictions) have their own rate limit, so they are not starved by the others.
+func NewKubeEventWriter(c *Config, kubeClient clientset.Interface, nodeName string) EventWriter {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&clientcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: KubeEventComponent, Host: nodeName})
+	w := newKubeEventWriter(c, recorder, nodeName)
+	w.broadcaster = eventBroadcaster
+	return w
+}
+
+func newKubeEventWriter(c *Config, recorder record.EventRecorder, nodeName string) *kubeEventWriter {
+	reasons := sets.NewString()
+	for _, reason := range c.KubeEventReasons {
+		reasons.Insert(getEventReason(&Event{Reason: reason}))
+	}
+	return &kubeEventWriter{
+		config:             c,
+		nodeName:           nodeName,
+		recorder:           recorder,
+		reasons:            reasons,
+		rateLimiter:        flowcontrol.NewTokenBucketRateLimiter(float32(c.KubeEventQPS), c.KubeEventBurst),
+		warningRateLimiter: flowcontrol.NewTokenBucketRateLimiter(float32(c.KubeEventWarningQPS), c.KubeEventWarningBurst),
+		recentKeys:         cache.NewLRUExpireCache(kubeEventDedupCacheSize),
+	}
+}
+
+type kubeEventWriter struct {
+	config             *Config
+	nodeName           string
+	broadcaster        record.EventBroadcaster
+	recorder           record.EventRecorder
+	reasons            sets.String
+	rateLimiter        flowcontrol.RateLimiter
+	warningRateLimiter flowcontrol.RateLimiter
+	recentKeys         *cache.LRUExpireCache
+
+	closeOnce sync.Once
+}
+
+// Log sends the event to the apiserver asynchronously
+func (k *kubeEventWriter) Log(verbose int, event *Event) error {
+	if event == nil {
+		return nil
+	}
+	reason := getEventReason(event)
+	if !k.reasons.Has(reason) {
+		return nil
+	}
+
+	key := getEventDedupKey(event)
+	if _, ok := k.recentKeys.Get(key); ok {
+		klog.V(6).Infof("audit event %s is deduplicated", key)
+		return nil
+	}
+	eventType, rateLimiter := corev1.EventTypeNormal, k.rateLimiter
+	if verbose <= 0 {
+		eventType, rateLimiter = corev1.EventTypeWarning, k.warningRateLimiter
+	}
+	if !rateLimiter.TryAccept() {
+		klog.V(5).Infof("audit event %s is dropped since the rate limit exceeded", key)
+		return nil
+	}
+	k.recentKeys.Add(key, struct{}{}, k.config.KubeEventDedupWindow)
+
+	message := event.Message
+	if event.Container != "" {
+		message = fmt.Sprintf("container %s: %s", event.Container, message)
+	}
+	k.recorder.Event(k.getInvolvedObject(event), eventType, reason, message)
+	return nil
+}
+
+// Flush does nothing since the events are sent by the broadcaster asynchronously
+func (k *kubeEventWriter) Flush() error {
+	return nil
+}
+
+// Close shuts down the broadcaster
+func (k *kubeEventWriter) Close() error {
+	k.closeOnce.Do(func() {
+		if k.broadcaster != nil {
+			k.broadcaster.Shutdown()
+		}
+	})
+	return nil
+}
+
+// getInvolvedObject returns the pod for the pod events, and the node for the others
+func (k *kubeEventWriter) getInvolvedObject(event *Event) *corev1.ObjectReference {
+	if event.Type == "pod" && event.Name != "" {
+		ref := &corev1.ObjectReference{
+			Kind:            "Pod",
+			Namespace:       event.Namespace,
+			Name:            event.Name,
+			UID:             types.UID(event.UID),
+			ResourceVersion: event.ResourceVersion,
+		}
+		if event.Container != "" {
+			ref.FieldPath = fmt.Sprintf("spec.containers{%s}", event.Container)
+		}
+		return ref
+	}
+	// the same as the node events of the kubelet
+	return &corev1.ObjectReference{
+		Kind:      "Node",
+		Name:      k.nodeName,
+		UID:       types.UID(k.nodeName),
+		Namespace: "",
+	}
+}
+
+// getEventReason converts the reason to the CamelCase as the reasons of the kubernetes events,
+// e.g. "cgroup reconcile" -> "CgroupReconcile"
+func getEventReason(event *Event) string {
+	words := strings.Fields(event.Reason)
+	if len(words) <= 0 {
+		return "Unknown"
+	}
+	for i := range words {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
+
+// getEventDedupKey returns the key of the reason and the object of the event, the messages are not compared since they
+// usually carry the values changing all the time, e.g. the cfs quota.
+func getEventDedupKey(event *Event) string {
+	return strings.Join([]string{event.Type, event.Namespace, event.Name, event.UID, event.Container, getEventReason(event)}, "/")
+}
+
+// multiEventWriter writes the events to all the underlying writers
+type multiEventWriter struct {
+	writers []EventWriter
+}
+
+func newMultiEventWriter(writers ...EventWriter) EventWriter {
+	if len(writers) == 1 {
+		return writers[0]
+	}
+	return &multiEventWriter{writers: writers}
+}
+
+func (m *multiEventWriter) Log(verbose int, event *Event) error {
+	var errs []error
+	for _, w := range m.writers {
+		if err := w.Log(verbose, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (m *multiEventWriter) Flush() error {
+	var errs []error
+	for _, w := range m.writers {
+		if err := w.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (m *multiEventWriter) Close() error {
+	var errs []error
+	for _, w := range m.writers {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
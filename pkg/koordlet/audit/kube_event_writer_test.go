@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func drainFakeRecorder(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestKubeEventWriter(t *testing.T) {
+	c := NewDefaultConfig()
+	c.KubeEventBurst = 2
+	c.KubeEventQPS = 0.001
+	c.KubeEventWarningBurst = 2
+	c.KubeEventWarningQPS = 0.001
+	recorder := record.NewFakeRecorder(100)
+	w := newKubeEventWriter(c, recorder, "test-node")
+	logger := &eventFluentWriter{writer: w}
+
+	// the events are selected by the reasons instead of the verbose
+	assert.NoError(t, logger.V(3).Node().Reason("UpdateResctrl").Message("update l3 schemata").Do())
+	assert.NoError(t, logger.V(1).Node().Reason("UpdateCgroups").Message("update cpu.shares").Do())
+	// the events of the same reason and object are deduplicated even if the messages differ
+	assert.NoError(t, logger.V(5).Node().Reason("UpdateResctrl").Message("update mb schemata").Do())
+	assert.NoError(t, logger.V(3).Node().Reason("AdjustBEByNodeCPUUsage").Message("update BE group to cpuset: 0-3").Do())
+	// the rate limit of the normal events is exceeded
+	assert.NoError(t, logger.V(1).Group("BestEffort").Reason("AdjustBEByMemoryPressure").Message("update BE memory limit").Do())
+	// evict events are warnings, which are rate limited separately
+	assert.NoError(t, logger.V(0).Pod("default", "test-pod").Reason("evictPodByNodeMemoryUsage").Message("evict pod").Do())
+	assert.NoError(t, logger.V(0).Pod("default", "test-pod").Reason("evictPodByNodeMemoryUsage").Message("evict pod again").Do())
+	assert.NoError(t, logger.V(0).Pod("default", "test-pod-1").Reason("evictPodByNodeMemoryUsage").Message("evict pod").Do())
+	// the rate limit of the warning events is exceeded
+	assert.NoError(t, logger.V(0).Pod("default", "test-pod-2").Reason("evictPodByNodeMemoryUsage").Message("evict pod").Do())
+
+	expected := []string{
+		"Normal UpdateResctrl update l3 schemata",
+		"Normal AdjustBEByNodeCPUUsage update BE group to cpuset: 0-3",
+		"Warning EvictPodByNodeMemoryUsage evict pod",
+		"Warning EvictPodByNodeMemoryUsage evict pod",
+	}
+	assert.Equal(t, expected, drainFakeRecorder(recorder))
+	assert.NoError(t, w.Close())
+}
+
+func TestKubeEventWriter_getInvolvedObject(t *testing.T) {
+	w := newKubeEventWriter(NewDefaultConfig(), record.NewFakeRecorder(1), "test-node")
+	tests := []struct {
+		name  string
+		event *Event
+		want  *corev1.ObjectReference
+	}{
+		{
+			name:  "pod event",
+			event: &Event{Type: "pod", Namespace: "default", Name: "test-pod"},
+			want:  &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "test-pod"},
+		},
+		{
+			name:  "pod object event",
+			event: &Event{Type: "pod", Namespace: "default", Name: "test-pod", UID: "xxx-yyy", ResourceVersion: "100"},
+			want:  &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "test-pod", UID: "xxx-yyy", ResourceVersion: "100"},
+		},
+		{
+			name:  "container event",
+			event: &Event{Type: "pod", Namespace: "default", Name: "test-pod", Container: "main"},
+			want:  &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "test-pod", FieldPath: "spec.containers{main}"},
+		},
+		{
+			name:  "group event",
+			event: &Event{Type: "group", Name: "blkio"},
+			want:  &corev1.ObjectReference{Kind: "Node", Name: "test-node", UID: types.UID("test-node")},
+		},
+		{
+			name:  "node event",
+			event: &Event{Type: "node"},
+			want:  &corev1.ObjectReference{Kind: "Node", Name: "test-node", UID: types.UID("test-node")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, w.getInvolvedObject(tt.event))
+		})
+	}
+}
+
+func TestAuditorWithSinks(t *testing.T) {
+	c := NewDefaultConfig()
+	c.LogDir = t.TempDir()
+	recorder := record.NewFakeRecorder(10)
+	auditor := NewAuditor(c, newKubeEventWriter(c, recorder, "test-node"))
+	logger := auditor.LoggerWriter()
+	assert.NoError(t, logger.V(0).Pod("default", "test-pod").Reason("evictPodByBECPUSatisfaction").Message("evict pod").Do())
+	assert.NoError(t, logger.Flush())
+
+	assert.Equal(t, []string{"Warning EvictPodByBECPUSatisfaction evict pod"}, drainFakeRecorder(recorder))
+	iter := newReverseEventIterator(c.LogDir)
+	defer iter.Close()
+	event, err := iter.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "test-pod", event.Name)
+}
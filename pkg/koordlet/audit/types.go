@@ -19,6 +19,8 @@ package audit
 import (
 	"fmt"
 	"time"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 var (
@@ -41,9 +43,12 @@ type Event struct {
 	Level     string    `json:"level,omitempty"`
 	Namespace string    `json:"namespace,omitempty"`
 	Name      string    `json:"name,omitempty"`
-	Container string    `json:"container,omitempty"`
-	Reason    string    `json:"reason,omitempty"`
-	Message   string    `json:"message,omitempty"`
+	// UID and ResourceVersion identify the pod instance of the pod events if known
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Container       string `json:"container,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	Message         string `json:"message,omitempty"`
 }
 
 // EventHelper is a helper struct use to support fluent APIs
@@ -102,6 +107,14 @@ func (e *EventHelper) Pod(ns string, name string) *EventHelper {
 	return e
 }
 
+// PodObject set the event type to 'pod' with the identity of the pod instance
+func (e *EventHelper) PodObject(pod *corev1.Pod) *EventHelper {
+	e.Pod(pod.Namespace, pod.Name)
+	e.Event.UID = string(pod.UID)
+	e.Event.ResourceVersion = pod.ResourceVersion
+	return e
+}
+
 // Group set the event type to resource
 func (e *EventHelper) Group(name string) *EventHelper {
 	e.Event.Type = "group"
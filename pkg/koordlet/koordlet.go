@@ -90,7 +90,8 @@ func NewDaemon(config *config.Configuration) (Daemon, error) {
 		return nil, err
 	}
 	predictServer := prediction.NewPeakPredictServer(config.PredictionConf)
-	predictorFactory := prediction.NewPredictorFactory(predictServer, config.PredictionConf.ColdStartDuration, config.PredictionConf.SafetyMarginPercent)
+	predictorFactory := prediction.NewPredictorFactory(predictServer, config.PredictionConf.ColdStartDuration,
+		config.PredictionConf.SafetyMarginPercent, config.PredictionConf.SeasonalPredictionHorizon)
 
 	statesInformer := statesinformerimpl.NewStatesInformer(config.StatesInformerConf, kubeClient, crdClient, topologyClient, metricCache, nodeName, schedulingClient, predictorFactory)
 
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/prometheus/prometheus/tsdb"
+	cliflag "k8s.io/component-base/cli/flag"
 )
 
 type Config struct {
@@ -39,6 +40,21 @@ type Config struct {
 	TSDBMinBlockDuration          time.Duration
 	TSDBMaxBlockDuration          time.Duration
 	TSDBHeadChunksWriteBufferSize int
+
+	// RemoteWriteURL is the endpoint of the prometheus remote write, the remote write is disabled if empty.
+	RemoteWriteURL string
+	// RemoteWriteMetricKinds are the metric kinds to export, all metric kinds are exported if empty.
+	RemoteWriteMetricKinds []string
+	// RemoteWriteExternalLabels are the labels attached to all the exported series, e.g. node name.
+	RemoteWriteExternalLabels map[string]string
+	// RemoteWriteWALPath is the path of the WAL which keeps the samples not exported.
+	RemoteWriteWALPath           string
+	RemoteWriteBatchSize         int
+	RemoteWriteMaxPendingSamples int
+	RemoteWriteFlushInterval     time.Duration
+	RemoteWriteTimeout           time.Duration
+	RemoteWriteMinBackoff        time.Duration
+	RemoteWriteMaxBackoff        time.Duration
 }
 
 func NewDefaultConfig() *Config {
@@ -57,6 +73,14 @@ func NewDefaultConfig() *Config {
 		TSDBMinBlockDuration:          30 * time.Minute, // 30 minutes
 		TSDBMaxBlockDuration:          30 * time.Minute, // 30 minutes
 		TSDBHeadChunksWriteBufferSize: 1024 * 1024,      // 1 MB
+
+		RemoteWriteWALPath:           "/metric-data/remote-write-wal/",
+		RemoteWriteBatchSize:         500,
+		RemoteWriteMaxPendingSamples: 100000,
+		RemoteWriteFlushInterval:     5 * time.Second,
+		RemoteWriteTimeout:           30 * time.Second,
+		RemoteWriteMinBackoff:        time.Second,
+		RemoteWriteMaxBackoff:        time.Minute,
 	}
 }
 
@@ -76,4 +100,14 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.DurationVar(&c.TSDBMaxBlockDuration, "tsdb-max-block-duration", c.TSDBMaxBlockDuration, "The maximum timestamp range of compacted blocks, recommend >= 1h or this will cause chunks_head leak.")
 	fs.IntVar(&c.TSDBHeadChunksWriteBufferSize, "tsdb-head-chunks-write-buffer-size", c.TSDBHeadChunksWriteBufferSize, "Write buffer size used by the head chunks mapper.")
 
+	fs.StringVar(&c.RemoteWriteURL, "remote-write-url", c.RemoteWriteURL, "The endpoint of prometheus remote write to export metric samples, disabled if empty.")
+	fs.Var(cliflag.NewStringSlice(&c.RemoteWriteMetricKinds), "remote-write-metric-kinds", "The metric kind to export by remote write, e.g. container_cpi, it can be specified multiple times. All metric kinds are exported if not specified.")
+	fs.Var(cliflag.NewMapStringString(&c.RemoteWriteExternalLabels), "remote-write-external-labels", "The labels attached to all the series exported by remote write, e.g. node=node-0.")
+	fs.StringVar(&c.RemoteWriteWALPath, "remote-write-wal-path", c.RemoteWriteWALPath, "Base path of the WAL which keeps the samples not exported by remote write.")
+	fs.IntVar(&c.RemoteWriteBatchSize, "remote-write-batch-size", c.RemoteWriteBatchSize, "The maximum number of samples sent in one remote write request.")
+	fs.IntVar(&c.RemoteWriteMaxPendingSamples, "remote-write-max-pending-samples", c.RemoteWriteMaxPendingSamples, "The maximum number of samples waiting to be exported, the oldest samples are dropped if exceeded.")
+	fs.DurationVar(&c.RemoteWriteFlushInterval, "remote-write-flush-interval", c.RemoteWriteFlushInterval, "The interval to send the pending samples by remote write.")
+	fs.DurationVar(&c.RemoteWriteTimeout, "remote-write-timeout", c.RemoteWriteTimeout, "The timeout of remote write requests.")
+	fs.DurationVar(&c.RemoteWriteMinBackoff, "remote-write-min-backoff", c.RemoteWriteMinBackoff, "The initial backoff to retry the failed remote write requests.")
+	fs.DurationVar(&c.RemoteWriteMaxBackoff, "remote-write-max-backoff", c.RemoteWriteMaxBackoff, "The maximum backoff to retry the failed remote write requests.")
 }
@@ -41,6 +41,14 @@ func Test_NewDefaultConfig(t *testing.T) {
 		TSDBMinBlockDuration:          30 * time.Minute,
 		TSDBMaxBlockDuration:          30 * time.Minute,
 		TSDBHeadChunksWriteBufferSize: 1024 * 1024,
+
+		RemoteWriteWALPath:           "/metric-data/remote-write-wal/",
+		RemoteWriteBatchSize:         500,
+		RemoteWriteMaxPendingSamples: 100000,
+		RemoteWriteFlushInterval:     5 * time.Second,
+		RemoteWriteTimeout:           30 * time.Second,
+		RemoteWriteMinBackoff:        time.Second,
+		RemoteWriteMaxBackoff:        time.Minute,
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -63,6 +71,18 @@ func Test_InitFlags(t *testing.T) {
 		"--tsdb-min-block-duration=10m",
 		"--tsdb-max-block-duration=20m",
 		"--tsdb-head-chunks-write-buffer-size=512",
+
+		"--remote-write-url=http://localhost:9090/api/v1/write",
+		"--remote-write-metric-kinds=container_cpi",
+		"--remote-write-metric-kinds=pod_psi",
+		"--remote-write-external-labels=node=test-node",
+		"--remote-write-wal-path=/test-remote-write-wal/",
+		"--remote-write-batch-size=100",
+		"--remote-write-max-pending-samples=1000",
+		"--remote-write-flush-interval=10s",
+		"--remote-write-timeout=5s",
+		"--remote-write-min-backoff=2s",
+		"--remote-write-max-backoff=30s",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
@@ -81,6 +101,17 @@ func Test_InitFlags(t *testing.T) {
 		TSDBMinBlockDuration          time.Duration
 		TSDBMaxBlockDuration          time.Duration
 		TSDBHeadChunksWriteBufferSize int
+
+		RemoteWriteURL               string
+		RemoteWriteMetricKinds       []string
+		RemoteWriteExternalLabels    map[string]string
+		RemoteWriteWALPath           string
+		RemoteWriteBatchSize         int
+		RemoteWriteMaxPendingSamples int
+		RemoteWriteFlushInterval     time.Duration
+		RemoteWriteTimeout           time.Duration
+		RemoteWriteMinBackoff        time.Duration
+		RemoteWriteMaxBackoff        time.Duration
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -105,6 +136,16 @@ func Test_InitFlags(t *testing.T) {
 				TSDBMinBlockDuration:          10 * time.Minute,
 				TSDBMaxBlockDuration:          20 * time.Minute,
 				TSDBHeadChunksWriteBufferSize: 512,
+				RemoteWriteURL:                "http://localhost:9090/api/v1/write",
+				RemoteWriteMetricKinds:        []string{"container_cpi", "pod_psi"},
+				RemoteWriteExternalLabels:     map[string]string{"node": "test-node"},
+				RemoteWriteWALPath:            "/test-remote-write-wal/",
+				RemoteWriteBatchSize:          100,
+				RemoteWriteMaxPendingSamples:  1000,
+				RemoteWriteFlushInterval:      10 * time.Second,
+				RemoteWriteTimeout:            5 * time.Second,
+				RemoteWriteMinBackoff:         2 * time.Second,
+				RemoteWriteMaxBackoff:         30 * time.Second,
 			},
 			args: args{fs: fs},
 		},
@@ -126,6 +167,17 @@ func Test_InitFlags(t *testing.T) {
 				TSDBMinBlockDuration:          tt.fields.TSDBMinBlockDuration,
 				TSDBMaxBlockDuration:          tt.fields.TSDBMaxBlockDuration,
 				TSDBHeadChunksWriteBufferSize: tt.fields.TSDBHeadChunksWriteBufferSize,
+
+				RemoteWriteURL:               tt.fields.RemoteWriteURL,
+				RemoteWriteMetricKinds:       tt.fields.RemoteWriteMetricKinds,
+				RemoteWriteExternalLabels:    tt.fields.RemoteWriteExternalLabels,
+				RemoteWriteWALPath:           tt.fields.RemoteWriteWALPath,
+				RemoteWriteBatchSize:         tt.fields.RemoteWriteBatchSize,
+				RemoteWriteMaxPendingSamples: tt.fields.RemoteWriteMaxPendingSamples,
+				RemoteWriteFlushInterval:     tt.fields.RemoteWriteFlushInterval,
+				RemoteWriteTimeout:           tt.fields.RemoteWriteTimeout,
+				RemoteWriteMinBackoff:        tt.fields.RemoteWriteMinBackoff,
+				RemoteWriteMaxBackoff:        tt.fields.RemoteWriteMaxBackoff,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
@@ -63,6 +63,7 @@ type metricCache struct {
 	config *Config
 	TSDBStorage
 	KVStorage
+	remoteWriter RemoteWriter
 }
 
 func NewMetricCache(cfg *Config) (MetricCache, error) {
@@ -71,14 +72,27 @@ func NewMetricCache(cfg *Config) (MetricCache, error) {
 		return nil, err
 	}
 	kvdb := NewMemoryStorage()
-	return &metricCache{
+	m := &metricCache{
 		config:      cfg,
 		TSDBStorage: tsdb,
 		KVStorage:   kvdb,
-	}, nil
+	}
+	if cfg.RemoteWriteURL != "" {
+		writer, err := NewRemoteWriter(cfg)
+		if err != nil {
+			tsdb.Close()
+			return nil, err
+		}
+		m.remoteWriter = writer
+		m.TSDBStorage = &remoteWriteStorage{TSDBStorage: tsdb, writer: writer}
+	}
+	return m, nil
 }
 
 func (m *metricCache) Run(stopCh <-chan struct{}) error {
+	if m.remoteWriter != nil {
+		go m.remoteWriter.Run(stopCh)
+	}
 	<-stopCh
 	m.Close()
 	return nil
@@ -72,4 +72,13 @@ var (
 	HostAppCPUUsageMetric                 = defaultMetricFactory.New(HostAppCPUUsage).withPropertySchema(MetricPropertyHostAppName)
 	HostAppMemoryUsageMetric              = defaultMetricFactory.New(HostAppMemoryUsage).withPropertySchema(MetricPropertyHostAppName)
 	HostAppMemoryUsageWithPageCacheMetric = defaultMetricFactory.New(HostAppMemoryWithPageCacheUsage).withPropertySchema(MetricPropertyHostAppName)
+
+	// Network
+	NodeNetworkMetric = defaultMetricFactory.New(NodeMetricNetwork).withPropertySchema(MetricPropertyNetStat)
+	PodNetworkMetric  = defaultMetricFactory.New(PodMetricNetwork).withPropertySchema(MetricPropertyPodUID, MetricPropertyNetStat)
+
+	// Block I/O
+	NodeBlkIOMetric      = defaultMetricFactory.New(NodeMetricBlkIO).withPropertySchema(MetricPropertyBlkIODevice, MetricPropertyBlkIOStat)
+	PodBlkIOMetric       = defaultMetricFactory.New(PodMetricBlkIO).withPropertySchema(MetricPropertyPodUID, MetricPropertyBlkIODevice, MetricPropertyBlkIOStat)
+	ContainerBlkIOMetric = defaultMetricFactory.New(ContainerMetricBlkIO).withPropertySchema(MetricPropertyContainerID, MetricPropertyBlkIODevice, MetricPropertyBlkIOStat)
 )
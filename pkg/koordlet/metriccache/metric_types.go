@@ -25,6 +25,7 @@ const (
 	NodeCPUInfoKey          = "node_cpu_info"
 	NodeNUMAInfoKey         = "node_numa_info"
 	NodeLocalStorageInfoKey = "node_local_storage_info"
+	NodeBlockDevicesKey     = "node_block_devices"
 )
 
 const (
@@ -88,6 +89,15 @@ const (
 	HostAppMemoryColdPageSize       MetricKind = "host_application_memory_cold_page_size"
 	PodMemoryColdPageSize           MetricKind = "pod_memory_cold_page_size"
 	ContainerMemoryColdPageSize     MetricKind = "container_memory_cold_page_size"
+
+	// network traffic rates
+	NodeMetricNetwork MetricKind = "node_network"
+	PodMetricNetwork  MetricKind = "pod_network"
+
+	// block I/O rates
+	NodeMetricBlkIO      MetricKind = "node_blkio"
+	PodMetricBlkIO       MetricKind = "pod_blkio"
+	ContainerMetricBlkIO MetricKind = "container_blkio"
 )
 
 // MetricProperty is the property of metric
@@ -110,6 +120,11 @@ const (
 	MetricPropertyBEAllocation MetricProperty = "be_allocation"
 
 	MetricPropertyHostAppName MetricProperty = "host_app_name"
+
+	MetricPropertyNetStat MetricProperty = "net_stat"
+
+	MetricPropertyBlkIODevice MetricProperty = "blkio_device"
+	MetricPropertyBlkIOStat   MetricProperty = "blkio_stat"
 )
 
 // MetricPropertyValue is the property value
@@ -132,6 +147,20 @@ const (
 	BEResourceAllocationUsage     MetricPropertyValue = "usage"
 	BEResourceAllocationRealLimit MetricPropertyValue = "real-limit"
 	BEResourceAllocationRequest   MetricPropertyValue = "request"
+
+	NetStatRxBytes   MetricPropertyValue = "rx_bytes"
+	NetStatTxBytes   MetricPropertyValue = "tx_bytes"
+	NetStatRxPackets MetricPropertyValue = "rx_packets"
+	NetStatTxPackets MetricPropertyValue = "tx_packets"
+	NetStatRxDrops   MetricPropertyValue = "rx_drops"
+	NetStatTxDrops   MetricPropertyValue = "tx_drops"
+
+	BlkIOStatReadBytes    MetricPropertyValue = "read_bytes"
+	BlkIOStatWriteBytes   MetricPropertyValue = "write_bytes"
+	BlkIOStatReadIOPS     MetricPropertyValue = "read_iops"
+	BlkIOStatWriteIOPS    MetricPropertyValue = "write_iops"
+	BlkIOStatReadLatency  MetricPropertyValue = "read_latency"
+	BlkIOStatWriteLatency MetricPropertyValue = "write_latency"
 )
 
 // MetricPropertiesFunc is a collection of functions generating metric property k-v, for metric sample generation and query
@@ -147,6 +176,11 @@ var MetricPropertiesFunc = struct {
 	ContainerGPU        func(string, string, string) map[MetricProperty]string
 	NodeBE              func(string, string) map[MetricProperty]string
 	HostApplication     func(string) map[MetricProperty]string
+	NodeNetwork         func(string) map[MetricProperty]string
+	PodNetwork          func(string, string) map[MetricProperty]string
+	NodeBlkIO           func(string, string) map[MetricProperty]string
+	PodBlkIO            func(string, string, string) map[MetricProperty]string
+	ContainerBlkIO      func(string, string, string) map[MetricProperty]string
 }{
 	Pod: func(podUID string) map[MetricProperty]string {
 		return map[MetricProperty]string{MetricPropertyPodUID: podUID}
@@ -181,6 +215,21 @@ var MetricPropertiesFunc = struct {
 	HostApplication: func(appName string) map[MetricProperty]string {
 		return map[MetricProperty]string{MetricPropertyHostAppName: appName}
 	},
+	NodeNetwork: func(netStat string) map[MetricProperty]string {
+		return map[MetricProperty]string{MetricPropertyNetStat: netStat}
+	},
+	PodNetwork: func(podUID, netStat string) map[MetricProperty]string {
+		return map[MetricProperty]string{MetricPropertyPodUID: podUID, MetricPropertyNetStat: netStat}
+	},
+	NodeBlkIO: func(device, blkioStat string) map[MetricProperty]string {
+		return map[MetricProperty]string{MetricPropertyBlkIODevice: device, MetricPropertyBlkIOStat: blkioStat}
+	},
+	PodBlkIO: func(podUID, device, blkioStat string) map[MetricProperty]string {
+		return map[MetricProperty]string{MetricPropertyPodUID: podUID, MetricPropertyBlkIODevice: device, MetricPropertyBlkIOStat: blkioStat}
+	},
+	ContainerBlkIO: func(containerID, device, blkioStat string) map[MetricProperty]string {
+		return map[MetricProperty]string{MetricPropertyContainerID: containerID, MetricPropertyBlkIODevice: device, MetricPropertyBlkIOStat: blkioStat}
+	},
 }
 
 // point is the struct to describe metric
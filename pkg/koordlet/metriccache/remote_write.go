@@ -0,0 +1,447 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metriccache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/wal"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	remoteWriteVersion     = "0.1.0"
+	remoteWriteUserAgent   = "koordlet"
+	remoteWriteMaxErrorMsg = 256
+
+	// remoteWriteCheckpointFile records the position of the oldest sample not exported in the WAL. It is not a number,
+	// so it is not regarded as a segment of the WAL.
+	remoteWriteCheckpointFile = "checkpoint"
+)
+
+// RemoteWriter exports the metric samples to a prometheus remote write endpoint.
+// The samples are persisted in a WAL before exporting, so they survive the restarts of koordlet. The WAL is truncated
+// as the samples are exported or dropped, so it keeps about the pending samples only.
+type RemoteWriter interface {
+	// Write enqueues the samples to export, the samples of unselected metric kinds are ignored.
+	Write(samples []MetricSample) error
+	// Run sends the pending samples in batches until stopCh is closed.
+	Run(stopCh <-chan struct{})
+	// Close closes the WAL.
+	Close() error
+}
+
+var _ RemoteWriter = &remoteWriter{}
+
+type remoteWriter struct {
+	config         *Config
+	client         *http.Client
+	metricKinds    sets.String
+	externalLabels labels.Labels
+	wal            *wal.WAL
+
+	lock sync.Mutex
+	// pending is the series waiting to be exported, each series has one sample
+	pending []prompb.TimeSeries
+	// removed is the number of samples ever removed from the head of pending, i.e. exported or dropped
+	removed int
+	// segments are the WAL segments not truncated in order, and the last one is being written
+	segments []walSegment
+	// checkpointed is the value of removed when the last checkpoint is recorded
+	checkpointed int
+	notifyCh     chan struct{}
+}
+
+// walSegment is a segment of the WAL. The samples are numbered in the order they are appended to pending, starting
+// from the first sample replayed, and first is the number of the first sample logged in the segment.
+type walSegment struct {
+	index int
+	first int
+}
+
+func NewRemoteWriter(conf *Config) (RemoteWriter, error) {
+	w := &remoteWriter{
+		config:         conf,
+		client:         &http.Client{Timeout: conf.RemoteWriteTimeout},
+		metricKinds:    sets.NewString(conf.RemoteWriteMetricKinds...),
+		externalLabels: labels.FromMap(conf.RemoteWriteExternalLabels),
+		notifyCh:       make(chan struct{}, 1),
+	}
+	// replay the samples not exported before the restart
+	if err := w.replayWAL(); err != nil {
+		return nil, err
+	}
+	walLog, err := wal.New(nil, nil, conf.RemoteWriteWALPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote write wal %s, err: %w", conf.RemoteWriteWALPath, err)
+	}
+	// the WAL always writes a new segment after opening
+	_, current, err := wal.Segments(conf.RemoteWriteWALPath)
+	if err != nil {
+		walLog.Close()
+		return nil, fmt.Errorf("failed to list segments of remote write wal, err: %w", err)
+	}
+	w.wal = walLog
+	w.segments = append(w.segments, walSegment{index: current, first: w.removed + len(w.pending)})
+	return w, nil
+}
+
+func (w *remoteWriter) replayWAL() error {
+	if err := os.MkdirAll(w.config.RemoteWriteWALPath, 0777); err != nil {
+		return fmt.Errorf("failed to create remote write wal dir, err: %w", err)
+	}
+	first, last, err := wal.Segments(w.config.RemoteWriteWALPath)
+	if err != nil {
+		return fmt.Errorf("failed to list segments of remote write wal, err: %w", err)
+	}
+	if last < 0 {
+		return nil
+	}
+	// skip the samples exported or dropped before the checkpoint
+	start, skip := first, 0
+	if segment, offset, err := readWALCheckpoint(w.config.RemoteWriteWALPath); err != nil {
+		klog.Warningf("failed to read checkpoint of remote write wal, replay all samples, err: %v", err)
+	} else if segment >= first {
+		start, skip = segment, offset
+	}
+	if start > last {
+		return nil
+	}
+	reader, err := wal.NewSegmentsRangeReader(wal.SegmentRange{Dir: w.config.RemoteWriteWALPath, First: start, Last: last})
+	if err != nil {
+		return fmt.Errorf("failed to read remote write wal, err: %w", err)
+	}
+	defer reader.Close()
+
+	r := wal.NewReader(reader)
+	skipped := 0
+	for r.Next() {
+		req := &prompb.WriteRequest{}
+		if err := req.Unmarshal(r.Record()); err != nil {
+			klog.Warningf("failed to unmarshal record of remote write wal, skip it, err: %v", err)
+			continue
+		}
+		series := req.Timeseries
+		if n := skip - skipped; n > 0 {
+			if n > len(series) {
+				n = len(series)
+			}
+			series = series[n:]
+			skipped += n
+		}
+		w.pending = append(w.pending, series...)
+	}
+	if err := r.Err(); err != nil {
+		// the tail of the wal may be corrupted when koordlet exits unexpectedly, keep the replayed samples
+		klog.Warningf("failed to replay remote write wal completely, err: %v", err)
+	}
+	w.segments = append(w.segments, walSegment{index: start, first: -skipped})
+	w.trimPending()
+	klog.V(4).Infof("replay %d samples from remote write wal", len(w.pending))
+	return nil
+}
+
+func readWALCheckpoint(dir string) (segment int, offset int, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, remoteWriteCheckpointFile))
+	if os.IsNotExist(err) {
+		return -1, 0, nil
+	} else if err != nil {
+		return -1, 0, err
+	}
+	if _, err = fmt.Sscanf(string(data), "%d %d", &segment, &offset); err != nil {
+		return -1, 0, fmt.Errorf("invalid checkpoint %q, err: %w", string(data), err)
+	}
+	return segment, offset, nil
+}
+
+func writeWALCheckpoint(dir string, segment int, offset int) error {
+	path := filepath.Join(dir, remoteWriteCheckpointFile)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(fmt.Sprintf("%d %d\n", segment, offset)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (w *remoteWriter) Write(samples []MetricSample) error {
+	series := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		if w.metricKinds.Len() > 0 && !w.metricKinds.Has(s.GetKind()) {
+			continue
+		}
+		series = append(series, w.toTimeSeries(s))
+	}
+	if len(series) == 0 {
+		return nil
+	}
+	req := &prompb.WriteRequest{Timeseries: series}
+	record, err := req.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal samples, err: %w", err)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	// cut the segment in batches, so the WAL can be truncated as the batches are exported
+	appended := w.removed + len(w.pending)
+	if current := w.segments[len(w.segments)-1]; appended-current.first >= w.config.RemoteWriteBatchSize {
+		if index, err := w.wal.NextSegment(); err != nil {
+			klog.Warningf("failed to cut segment of remote write wal, err: %v", err)
+		} else {
+			w.segments = append(w.segments, walSegment{index: index, first: appended})
+		}
+	}
+	if err = w.wal.Log(record); err != nil {
+		return fmt.Errorf("failed to log samples to remote write wal, err: %w", err)
+	}
+	w.pending = append(w.pending, series...)
+	if w.trimPending() {
+		// truncate the WAL of the dropped samples, so it does not grow when the remote write endpoint is unavailable
+		w.checkpoint()
+	}
+	if len(w.pending) >= w.config.RemoteWriteBatchSize {
+		select {
+		case w.notifyCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (w *remoteWriter) toTimeSeries(s MetricSample) prompb.TimeSeries {
+	builder := labels.NewBuilder(w.externalLabels)
+	for k, v := range s.GetProperties() {
+		builder.Set(k, v)
+	}
+	builder.Set(metricLabelName, s.GetKind())
+	lset := builder.Labels(nil)
+
+	series := prompb.TimeSeries{
+		Labels:  make([]prompb.Label, 0, len(lset)),
+		Samples: []prompb.Sample{{Value: s.value(), Timestamp: s.timestamp()}},
+	}
+	for _, l := range lset {
+		series.Labels = append(series.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+	}
+	return series
+}
+
+// trimPending drops the oldest samples if the pending samples exceed the limit, lock must be held
+func (w *remoteWriter) trimPending() bool {
+	dropped := len(w.pending) - w.config.RemoteWriteMaxPendingSamples
+	if dropped <= 0 {
+		return false
+	}
+	klog.Warningf("remote write pending samples exceed limit %d, drop the oldest %d samples",
+		w.config.RemoteWriteMaxPendingSamples, dropped)
+	w.pending = append([]prompb.TimeSeries{}, w.pending[dropped:]...)
+	w.removed += dropped
+	return true
+}
+
+// checkpoint records the position of the oldest pending sample in the WAL, and truncates the segments before it,
+// lock must be held
+func (w *remoteWriter) checkpoint() {
+	if w.removed == w.checkpointed {
+		return
+	}
+	i := 0
+	for i+1 < len(w.segments) && w.segments[i+1].first <= w.removed {
+		i++
+	}
+	segment := w.segments[i]
+	if err := writeWALCheckpoint(w.config.RemoteWriteWALPath, segment.index, w.removed-segment.first); err != nil {
+		klog.Warningf("failed to write checkpoint of remote write wal, err: %v", err)
+		return
+	}
+	w.checkpointed = w.removed
+	if i == 0 {
+		return
+	}
+	if err := w.wal.Truncate(segment.index); err != nil {
+		klog.Warningf("failed to truncate remote write wal, err: %v", err)
+		return
+	}
+	w.segments = w.segments[i:]
+}
+
+func (w *remoteWriter) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(w.config.RemoteWriteFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		case <-w.notifyCh:
+		}
+		w.flush(stopCh)
+	}
+}
+
+// flush sends all the pending samples in batches, and checkpoints the WAL after each batch is exported.
+func (w *remoteWriter) flush(stopCh <-chan struct{}) {
+	for {
+		w.lock.Lock()
+		batch := w.pending
+		if len(batch) > w.config.RemoteWriteBatchSize {
+			batch = batch[:w.config.RemoteWriteBatchSize]
+		}
+		batchStart := w.removed
+		w.lock.Unlock()
+		if len(batch) == 0 {
+			break
+		}
+
+		if !w.sendWithRetry(batch, stopCh) {
+			return
+		}
+
+		w.lock.Lock()
+		// some samples of the batch may be dropped by trimming during sending
+		if sent := batchStart + len(batch) - w.removed; sent > 0 {
+			w.pending = w.pending[sent:]
+			w.removed += sent
+		}
+		w.checkpoint()
+		w.lock.Unlock()
+	}
+}
+
+// sendWithRetry sends the batch until it succeeds or the error is unrecoverable.
+// It returns false only if stopCh is closed before the batch is sent.
+func (w *remoteWriter) sendWithRetry(batch []prompb.TimeSeries, stopCh <-chan struct{}) bool {
+	backoff := w.config.RemoteWriteMinBackoff
+	for {
+		recoverable, err := w.send(batch)
+		if err == nil {
+			klog.V(6).Infof("remote write %d samples succeeded", len(batch))
+			return true
+		}
+		if !recoverable {
+			klog.Warningf("remote write %d samples failed with unrecoverable error, drop them, err: %v", len(batch), err)
+			return true
+		}
+		klog.V(4).Infof("remote write %d samples failed, retry after %v, err: %v", len(batch), backoff, err)
+		select {
+		case <-stopCh:
+			return false
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > w.config.RemoteWriteMaxBackoff {
+			backoff = w.config.RemoteWriteMaxBackoff
+		}
+	}
+}
+
+// send posts the batch to the remote write endpoint, it returns whether the error is recoverable if failed.
+func (w *remoteWriter) send(batch []prompb.TimeSeries) (bool, error) {
+	req := &prompb.WriteRequest{Timeseries: batch}
+	data, err := req.Marshal()
+	if err != nil {
+		return false, err
+	}
+	compressed := snappy.Encode(nil, data)
+
+	httpReq, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, w.config.RemoteWriteURL, bytes.NewReader(compressed))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("User-Agent", remoteWriteUserAgent)
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, remoteWriteMaxErrorMsg))
+	err = fmt.Errorf("server returned HTTP status %s: %s", resp.Status, string(body))
+	// retry on the server errors and the rate limiting
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+func (w *remoteWriter) Close() error {
+	return w.wal.Close()
+}
+
+var _ TSDBStorage = &remoteWriteStorage{}
+
+// remoteWriteStorage exports the samples committed to the underlying TSDBStorage by the RemoteWriter
+type remoteWriteStorage struct {
+	TSDBStorage
+	writer RemoteWriter
+}
+
+func (s *remoteWriteStorage) Appender() Appender {
+	return &remoteWriteAppender{
+		Appender: s.TSDBStorage.Appender(),
+		writer:   s.writer,
+	}
+}
+
+func (s *remoteWriteStorage) Close() error {
+	err := s.TSDBStorage.Close()
+	if writerErr := s.writer.Close(); writerErr != nil {
+		klog.Warningf("failed to close remote writer, err: %v", writerErr)
+	}
+	return err
+}
+
+var _ Appender = &remoteWriteAppender{}
+
+type remoteWriteAppender struct {
+	Appender
+	writer  RemoteWriter
+	samples []MetricSample
+}
+
+func (a *remoteWriteAppender) Append(samples []MetricSample) error {
+	if err := a.Appender.Append(samples); err != nil {
+		return err
+	}
+	a.samples = append(a.samples, samples...)
+	return nil
+}
+
+func (a *remoteWriteAppender) Commit() error {
+	if err := a.Appender.Commit(); err != nil {
+		return err
+	}
+	if err := a.writer.Write(a.samples); err != nil {
+		klog.Warningf("failed to export %d samples by remote write, err: %v", len(a.samples), err)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metriccache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/wal"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRemoteWriteServer struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	statuses []int
+	requests int
+	received []prompb.TimeSeries
+}
+
+// newFakeRemoteWriteServer returns a remote write server which responds the requests with the statuses in order,
+// and responds 200 after the statuses are used up.
+func newFakeRemoteWriteServer(t *testing.T, statuses ...int) *fakeRemoteWriteServer {
+	s := &fakeRemoteWriteServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.requests++
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, remoteWriteVersion, r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+		}
+		compressed, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		data, err := snappy.Decode(nil, compressed)
+		assert.NoError(t, err)
+		req := &prompb.WriteRequest{}
+		assert.NoError(t, req.Unmarshal(data))
+		s.received = append(s.received, req.Timeseries...)
+	}))
+	return s
+}
+
+func (s *fakeRemoteWriteServer) getReceived() []prompb.TimeSeries {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]prompb.TimeSeries{}, s.received...)
+}
+
+func (s *fakeRemoteWriteServer) getRequests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests
+}
+
+func newTestRemoteWriteConfig(url, walPath string) *Config {
+	conf := NewDefaultConfig()
+	conf.RemoteWriteURL = url
+	conf.RemoteWriteWALPath = walPath
+	conf.RemoteWriteMetricKinds = []string{string(NodeMetricCPUUsage)}
+	conf.RemoteWriteExternalLabels = map[string]string{"node": "test-node"}
+	conf.RemoteWriteBatchSize = 2
+	conf.RemoteWriteFlushInterval = 10 * time.Millisecond
+	conf.RemoteWriteMinBackoff = 10 * time.Millisecond
+	conf.RemoteWriteMaxBackoff = 20 * time.Millisecond
+	return conf
+}
+
+func generateTestNodeSamples(t *testing.T, now time.Time, cpuValues ...float64) []MetricSample {
+	var samples []MetricSample
+	for i, v := range cpuValues {
+		s, err := NodeCPUUsageMetric.GenerateSample(nil, now.Add(time.Duration(i)*time.Second), v)
+		assert.NoError(t, err)
+		samples = append(samples, s)
+	}
+	// node memory usage is not selected to export
+	s, err := NodeMemoryUsageMetric.GenerateSample(nil, now, 1024)
+	assert.NoError(t, err)
+	return append(samples, s)
+}
+
+func Test_remoteWriter_Run(t *testing.T) {
+	server := newFakeRemoteWriteServer(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	defer server.Close()
+
+	w, err := NewRemoteWriter(newTestRemoteWriteConfig(server.URL, t.TempDir()))
+	assert.NoError(t, err)
+	defer w.Close()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	now := time.UnixMilli(time.Now().UnixMilli())
+	assert.NoError(t, w.Write(generateTestNodeSamples(t, now, 1, 2, 3)))
+
+	assert.Eventually(t, func() bool {
+		return len(server.getReceived()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	// 2 failed requests and 2 batches
+	assert.Equal(t, 4, server.getRequests())
+	received := server.getReceived()
+	assert.Equal(t, []prompb.Label{
+		{Name: metricLabelName, Value: string(NodeMetricCPUUsage)},
+		{Name: "node", Value: "test-node"},
+	}, received[0].Labels)
+	for i, series := range received {
+		assert.Equal(t, []prompb.Sample{{Value: float64(i + 1), Timestamp: now.Add(time.Duration(i) * time.Second).UnixMilli()}}, series.Samples)
+	}
+}
+
+func Test_remoteWriter_DropUnrecoverable(t *testing.T) {
+	server := newFakeRemoteWriteServer(t, http.StatusBadRequest)
+	defer server.Close()
+
+	w, err := NewRemoteWriter(newTestRemoteWriteConfig(server.URL, t.TempDir()))
+	assert.NoError(t, err)
+	defer w.Close()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	assert.NoError(t, w.Write(generateTestNodeSamples(t, time.Now(), 1, 2, 3)))
+	assert.Eventually(t, func() bool {
+		return server.getRequests() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	// the first batch is dropped
+	assert.Eventually(t, func() bool {
+		return len(server.getReceived()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_remoteWriter_ReplayWAL(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "wal")
+	server := newFakeRemoteWriteServer(t)
+	defer server.Close()
+	conf := newTestRemoteWriteConfig(server.URL, walPath)
+
+	// the samples are not exported before the restart
+	w, err := NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(generateTestNodeSamples(t, time.Now(), 1, 2, 3)))
+	assert.NoError(t, w.Close())
+
+	w, err = NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(w.(*remoteWriter).pending))
+	stopCh := make(chan struct{})
+	go w.Run(stopCh)
+	assert.Eventually(t, func() bool {
+		return len(server.getReceived()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	// the wal is checkpointed after all samples exported
+	assert.Eventually(t, func() bool {
+		rw := w.(*remoteWriter)
+		rw.lock.Lock()
+		defer rw.lock.Unlock()
+		return rw.checkpointed == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	close(stopCh)
+	assert.NoError(t, w.Close())
+
+	w, err = NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(w.(*remoteWriter).pending))
+	assert.NoError(t, w.Close())
+}
+
+func Test_remoteWriter_ReplayWALAfterPartialExport(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "wal")
+	// the first batch is exported, and the second batch keeps failing
+	server := newFakeRemoteWriteServer(t, http.StatusOK, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	defer server.Close()
+	conf := newTestRemoteWriteConfig(server.URL, walPath)
+
+	w, err := NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(generateTestNodeSamples(t, time.Now(), 1, 2, 3)))
+	stopCh := make(chan struct{})
+	go w.Run(stopCh)
+	assert.Eventually(t, func() bool {
+		return server.getRequests() >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	close(stopCh)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, 2, len(server.getReceived()))
+
+	// only the sample not exported is replayed
+	w, err = NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	pending := w.(*remoteWriter).pending
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, float64(3), pending[0].Samples[0].Value)
+	assert.NoError(t, w.Close())
+}
+
+func Test_remoteWriter_TruncateWALWhenDropped(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "wal")
+	conf := newTestRemoteWriteConfig("http://127.0.0.1:0", walPath)
+	conf.RemoteWriteMaxPendingSamples = 2
+	w, err := NewRemoteWriter(conf)
+	assert.NoError(t, err)
+
+	// the remote write endpoint is unavailable, and the wal keeps about the pending samples only
+	now := time.UnixMilli(time.Now().UnixMilli())
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, w.Write(generateTestNodeSamples(t, now, float64(3*i+1), float64(3*i+2), float64(3*i+3))))
+	}
+	first, last, err := wal.Segments(walPath)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, last-first, 1)
+	assert.NoError(t, w.Close())
+
+	w, err = NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	pending := w.(*remoteWriter).pending
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, float64(29), pending[0].Samples[0].Value)
+	assert.Equal(t, float64(30), pending[1].Samples[0].Value)
+	assert.NoError(t, w.Close())
+}
+
+func Test_remoteWriter_MaxPendingSamples(t *testing.T) {
+	conf := newTestRemoteWriteConfig("http://127.0.0.1:0", t.TempDir())
+	conf.RemoteWriteMaxPendingSamples = 2
+	w, err := NewRemoteWriter(conf)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	now := time.UnixMilli(time.Now().UnixMilli())
+	assert.NoError(t, w.Write(generateTestNodeSamples(t, now, 1, 2, 3)))
+	pending := w.(*remoteWriter).pending
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, float64(2), pending[0].Samples[0].Value)
+}
+
+func Test_metricCache_RemoteWrite(t *testing.T) {
+	server := newFakeRemoteWriteServer(t)
+	defer server.Close()
+	conf := newTestRemoteWriteConfig(server.URL, t.TempDir())
+	conf.TSDBPath = t.TempDir()
+	conf.TSDBEnablePromMetrics = false
+
+	m, err := NewMetricCache(conf)
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	go m.Run(stopCh)
+	defer close(stopCh)
+
+	appender := m.Appender()
+	assert.NoError(t, appender.Append(generateTestNodeSamples(t, time.Now(), 1)))
+	assert.NoError(t, appender.Commit())
+	assert.Eventually(t, func() bool {
+		return len(server.getReceived()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blkioresource
+
+import (
+	"sort"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	CollectorName = "BlkIOCollector"
+
+	nodeStatKey = "node"
+)
+
+var (
+	timeNow = time.Now
+)
+
+// blkioSnapshot is the I/O counters of each device of a cgroup at a time
+type blkioSnapshot struct {
+	stats     map[string]system.BlkIOStatRaw
+	timestamp time.Time
+}
+
+// diskSnapshot is the I/O counters of each disk of the node at a time
+type diskSnapshot struct {
+	stats     map[string]system.DiskStat
+	timestamp time.Time
+}
+
+type blkioCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	metricCache     metriccache.MetricCache
+	statesInformer  statesinformer.StatesInformer
+	cgroupReader    resourceexecutor.CgroupReader
+	podFilter       framework.PodFilter
+
+	lastBlkIOStat *gocache.Cache
+}
+
+func New(opt *framework.Options) framework.Collector {
+	collectInterval := opt.Config.CollectResUsedInterval
+	podFilter := framework.DefaultPodFilter
+	if filter, ok := opt.PodFilters[CollectorName]; ok {
+		podFilter = filter
+	}
+	return &blkioCollector{
+		collectInterval: collectInterval,
+		started:         atomic.NewBool(false),
+		metricCache:     opt.MetricCache,
+		statesInformer:  opt.StatesInformer,
+		cgroupReader:    opt.CgroupReader,
+		podFilter:       podFilter,
+		lastBlkIOStat:   gocache.New(collectInterval*framework.ContextExpiredRatio, framework.CleanupInterval),
+	}
+}
+
+var _ framework.PodCollector = &blkioCollector{}
+
+func (c *blkioCollector) Enabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.BlkIOCollector)
+}
+
+func (c *blkioCollector) Setup(ctx *framework.Context) {}
+
+func (c *blkioCollector) Run(stopCh <-chan struct{}) {
+	if !cache.WaitForCacheSync(stopCh, c.statesInformer.HasSynced) {
+		// Koordlet exit because of statesInformer sync failed.
+		klog.Fatalf("timed out waiting for states informer caches to sync")
+	}
+	go wait.Until(c.collectBlkIOResource, c.collectInterval, stopCh)
+}
+
+func (c *blkioCollector) Started() bool {
+	return c.started.Load()
+}
+
+func (c *blkioCollector) FilterPod(meta *statesinformer.PodMeta) (bool, string) {
+	return c.podFilter.FilterPod(meta)
+}
+
+func (c *blkioCollector) collectBlkIOResource() {
+	klog.V(6).Info("start collectBlkIOResource")
+	diskStats, err := system.GetDiskStats()
+	if err != nil {
+		klog.Warningf("collect disk stats failed, err: %v", err)
+		return
+	}
+	// the cgroup counters are keyed by the device number, translate it into the device name for readability
+	deviceNames := make(map[string]string, len(diskStats))
+	for _, stat := range diskStats {
+		deviceNames[stat.Device] = stat.Name
+	}
+	devices := map[string]struct{}{}
+
+	metrics := c.collectNodeBlkIO(diskStats, devices)
+
+	podMetas := c.statesInformer.GetAllPods()
+	for _, meta := range podMetas {
+		pod := meta.Pod
+		if filtered, msg := c.FilterPod(meta); filtered {
+			klog.V(5).Infof("skip collect pod %s/%s, reason: %s", pod.Namespace, pod.Name, msg)
+			continue
+		}
+		metrics = append(metrics, c.collectPodBlkIO(meta, deviceNames, devices)...)
+		metrics = append(metrics, c.collectContainersBlkIO(meta, deviceNames)...)
+	}
+
+	appender := c.metricCache.Appender()
+	if err := appender.Append(metrics); err != nil {
+		klog.Warningf("append blkio metrics failed, reason: %v", err)
+		return
+	}
+	if err := appender.Commit(); err != nil {
+		klog.Warningf("commit blkio metrics failed, reason: %v", err)
+		return
+	}
+
+	deviceList := make([]string, 0, len(devices))
+	for device := range devices {
+		deviceList = append(deviceList, device)
+	}
+	sort.Strings(deviceList)
+	c.metricCache.Set(metriccache.NodeBlockDevicesKey, deviceList)
+	c.started.Store(true)
+	klog.V(5).Infof("collectBlkIOResource finished, pod num %d, metric num %d", len(podMetas), len(metrics))
+}
+
+func (c *blkioCollector) collectNodeBlkIO(diskStats []system.DiskStat, devices map[string]struct{}) []metriccache.MetricSample {
+	collectTime := timeNow()
+	current := &diskSnapshot{
+		stats:     map[string]system.DiskStat{},
+		timestamp: collectTime,
+	}
+	for _, stat := range diskStats {
+		if system.IsWholeDisk(stat.Name) {
+			current.stats[stat.Name] = stat
+		}
+	}
+	lastValue, ok := c.lastBlkIOStat.Get(nodeStatKey)
+	c.lastBlkIOStat.Set(nodeStatKey, current, gocache.DefaultExpiration)
+	if !ok {
+		klog.V(6).Infof("collect node blkio first point")
+		return nil
+	}
+	last := lastValue.(*diskSnapshot)
+	seconds := current.timestamp.Sub(last.timestamp).Seconds()
+	if seconds <= 0 {
+		return nil
+	}
+
+	var metrics []metriccache.MetricSample
+	for name, cur := range current.stats {
+		prev, ok := last.stats[name]
+		if !ok || cur.ReadIOs < prev.ReadIOs || cur.WriteIOs < prev.WriteIOs || cur.ReadBytes < prev.ReadBytes ||
+			cur.WriteBytes < prev.WriteBytes || cur.ReadTimeMs < prev.ReadTimeMs || cur.WriteTimeMs < prev.WriteTimeMs {
+			continue
+		}
+		devices[name] = struct{}{}
+		readIOs, writeIOs := cur.ReadIOs-prev.ReadIOs, cur.WriteIOs-prev.WriteIOs
+		values := map[metriccache.MetricPropertyValue]float64{
+			metriccache.BlkIOStatReadBytes:    float64(cur.ReadBytes-prev.ReadBytes) / seconds,
+			metriccache.BlkIOStatWriteBytes:   float64(cur.WriteBytes-prev.WriteBytes) / seconds,
+			metriccache.BlkIOStatReadIOPS:     float64(readIOs) / seconds,
+			metriccache.BlkIOStatWriteIOPS:    float64(writeIOs) / seconds,
+			metriccache.BlkIOStatReadLatency:  calcAvgLatency(cur.ReadTimeMs-prev.ReadTimeMs, readIOs),
+			metriccache.BlkIOStatWriteLatency: calcAvgLatency(cur.WriteTimeMs-prev.WriteTimeMs, writeIOs),
+		}
+		for blkioStat, value := range values {
+			sample, err := metriccache.NodeBlkIOMetric.GenerateSample(
+				metriccache.MetricPropertiesFunc.NodeBlkIO(name, string(blkioStat)), collectTime, value)
+			if err != nil {
+				klog.Warningf("generate node blkio %s metric of device %s failed, err %v", blkioStat, name, err)
+				continue
+			}
+			metrics = append(metrics, sample)
+		}
+	}
+	return metrics
+}
+
+// collectPodBlkIO collects the throughput and IOPS of the pod on each device. The I/O latency is only collected for
+// the node disks, since the cgroup does not account the I/O time without the debug stats of the kernel.
+func (c *blkioCollector) collectPodBlkIO(meta *statesinformer.PodMeta, deviceNames map[string]string, devices map[string]struct{}) []metriccache.MetricSample {
+	pod := meta.Pod
+	uid := string(pod.UID)
+	collectTime := timeNow()
+	stats, err := c.cgroupReader.ReadBlkIOStat(meta.CgroupDir)
+	if err != nil {
+		klog.V(4).Infof("collect pod %s blkio failed, err: %v", util.GetPodKey(pod), err)
+		return nil
+	}
+	rates := c.updateAndCalcRates(uid, stats, collectTime, deviceNames)
+
+	var metrics []metriccache.MetricSample
+	for device, values := range rates {
+		devices[device] = struct{}{}
+		for blkioStat, value := range values {
+			sample, err := metriccache.PodBlkIOMetric.GenerateSample(
+				metriccache.MetricPropertiesFunc.PodBlkIO(uid, device, string(blkioStat)), collectTime, value)
+			if err != nil {
+				klog.Warningf("generate pod %s blkio %s metric of device %s failed, err %v", util.GetPodKey(pod), blkioStat, device, err)
+				continue
+			}
+			metrics = append(metrics, sample)
+		}
+	}
+	klog.V(6).Infof("collect pod %s blkio finished, metric %v", util.GetPodKey(pod), rates)
+	return metrics
+}
+
+func (c *blkioCollector) collectContainersBlkIO(meta *statesinformer.PodMeta, deviceNames map[string]string) []metriccache.MetricSample {
+	pod := meta.Pod
+	var metrics []metriccache.MetricSample
+	for i := range pod.Status.ContainerStatuses {
+		containerStat := &pod.Status.ContainerStatuses[i]
+		if len(containerStat.ContainerID) == 0 {
+			klog.V(5).Infof("container %s/%s/%s id is empty, maybe not ready, skip this round",
+				pod.Namespace, pod.Name, containerStat.Name)
+			continue
+		}
+		containerCgroupDir, err := koordletutil.GetContainerCgroupParentDir(meta.CgroupDir, containerStat)
+		if err != nil {
+			klog.V(4).Infof("collect container %s/%s/%s blkio failed, cannot get container cgroup, err: %s",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		collectTime := timeNow()
+		stats, err := c.cgroupReader.ReadBlkIOStat(containerCgroupDir)
+		if err != nil {
+			klog.V(5).Infof("collect container %s/%s/%s blkio failed, err: %s",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		rates := c.updateAndCalcRates(containerStat.ContainerID, stats, collectTime, deviceNames)
+		for device, values := range rates {
+			for blkioStat, value := range values {
+				sample, err := metriccache.ContainerBlkIOMetric.GenerateSample(
+					metriccache.MetricPropertiesFunc.ContainerBlkIO(containerStat.ContainerID, device, string(blkioStat)), collectTime, value)
+				if err != nil {
+					klog.Warningf("generate container %s/%s/%s blkio %s metric of device %s failed, err %v",
+						pod.Namespace, pod.Name, containerStat.Name, blkioStat, device, err)
+					continue
+				}
+				metrics = append(metrics, sample)
+			}
+		}
+	}
+	return metrics
+}
+
+// updateAndCalcRates stores the current counters of the cgroup and returns the rates of each device since the last
+// collection. The devices without a previous point or with the counters reset are skipped.
+func (c *blkioCollector) updateAndCalcRates(key string, stats []system.BlkIOStatRaw, collectTime time.Time,
+	deviceNames map[string]string) map[string]map[metriccache.MetricPropertyValue]float64 {
+	current := &blkioSnapshot{
+		stats:     make(map[string]system.BlkIOStatRaw, len(stats)),
+		timestamp: collectTime,
+	}
+	for _, stat := range stats {
+		current.stats[stat.Device] = stat
+	}
+	lastValue, ok := c.lastBlkIOStat.Get(key)
+	c.lastBlkIOStat.Set(key, current, gocache.DefaultExpiration)
+	if !ok {
+		return nil
+	}
+	last := lastValue.(*blkioSnapshot)
+	seconds := current.timestamp.Sub(last.timestamp).Seconds()
+	if seconds <= 0 {
+		return nil
+	}
+
+	rates := map[string]map[metriccache.MetricPropertyValue]float64{}
+	for deviceNumber, cur := range current.stats {
+		prev, ok := last.stats[deviceNumber]
+		if !ok || cur.ReadBytes < prev.ReadBytes || cur.WriteBytes < prev.WriteBytes ||
+			cur.ReadIOs < prev.ReadIOs || cur.WriteIOs < prev.WriteIOs {
+			continue
+		}
+		device := deviceNumber
+		if name, ok := deviceNames[deviceNumber]; ok {
+			device = name
+		}
+		rates[device] = map[metriccache.MetricPropertyValue]float64{
+			metriccache.BlkIOStatReadBytes:  float64(cur.ReadBytes-prev.ReadBytes) / seconds,
+			metriccache.BlkIOStatWriteBytes: float64(cur.WriteBytes-prev.WriteBytes) / seconds,
+			metriccache.BlkIOStatReadIOPS:   float64(cur.ReadIOs-prev.ReadIOs) / seconds,
+			metriccache.BlkIOStatWriteIOPS:  float64(cur.WriteIOs-prev.WriteIOs) / seconds,
+		}
+	}
+	return rates
+}
+
+// calcAvgLatency returns the average milliseconds spent by each I/O
+func calcAvgLatency(timeMs, ios uint64) float64 {
+	if ios == 0 {
+		return 0
+	}
+	return float64(timeMs) / float64(ios)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blkioresource
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_blkioCollector_collectBlkIOResource(t *testing.T) {
+	testNow := time.Now()
+	timeNow = func() time.Time {
+		return testNow
+	}
+	testContainerID := "containerd://testContainerUID"
+	testPodMetaDir := "kubepods.slice/kubepods-podtest-pod-uid.slice"
+	testPodParentDir := "/kubepods.slice/kubepods-podtest-pod-uid.slice"
+	testContainerParentDir := "/kubepods.slice/kubepods-podtest-pod-uid.slice/cri-containerd-testContainerUID.scope"
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "test",
+			UID:       "test-pod-uid",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "test-container",
+					ContainerID: testContainerID,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	}
+	testDiskStats := `   8       0 sda 1000 10 20000 500 2000 20 40000 1500 0 1800 2000 0 0 0 0
+   8       1 sda1 900 10 18000 400 1900 20 38000 1400 0 1700 1800 0 0 0 0
+ 253       0 dm-0 100 0 200 10 200 0 400 20 0 30 30 0 0 0 0
+`
+	testServiceBytes := `8:0 Read 20480
+8:0 Write 40960
+8:0 Total 61440
+253:0 Read 1024
+253:0 Write 0
+253:0 Total 1024
+Total 62464`
+	testServiced := `8:0 Read 20
+8:0 Write 40
+8:0 Total 60
+253:0 Read 1
+253:0 Write 0
+253:0 Total 1
+Total 61`
+	prepareFiles := func(helper *system.FileTestUtil) {
+		helper.WriteFileContents(system.GetProcDiskStatsPath(), testDiskStats)
+		helper.MkDirAll(filepath.Join(system.SysBlockSubDir, "sda"))
+		helper.MkDirAll(filepath.Join(system.SysBlockSubDir, "dm-0"))
+		helper.WriteCgroupFileContents(testPodParentDir, system.BlkioIOServiceBytesRecursive, testServiceBytes)
+		helper.WriteCgroupFileContents(testPodParentDir, system.BlkioIOServicedRecursive, testServiced)
+		helper.WriteCgroupFileContents(testContainerParentDir, system.BlkioIOServiceBytesRecursive, testServiceBytes)
+		helper.WriteCgroupFileContents(testContainerParentDir, system.BlkioIOServicedRecursive, testServiced)
+	}
+
+	type fields struct {
+		initLastStat func(lastState *gocache.Cache)
+	}
+	type wants struct {
+		devices        []string
+		nodeBlkIO      map[string]map[metriccache.MetricPropertyValue]float64
+		podBlkIO       map[string]map[metriccache.MetricPropertyValue]float64
+		containerBlkIO map[string]map[metriccache.MetricPropertyValue]float64
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		wants  wants
+	}{
+		{
+			name:   "first point of node, pod and container",
+			fields: fields{},
+			wants: wants{
+				devices: []string{},
+			},
+		},
+		{
+			name: "collect node, pod and container rates",
+			fields: fields{
+				initLastStat: func(lastState *gocache.Cache) {
+					lastState.Set(nodeStatKey, &diskSnapshot{
+						stats: map[string]system.DiskStat{
+							"sda": {
+								Device:      "8:0",
+								Name:        "sda",
+								ReadIOs:     500,
+								ReadBytes:   10000 * system.DiskSectorSize,
+								ReadTimeMs:  250,
+								WriteIOs:    1000,
+								WriteBytes:  20000 * system.DiskSectorSize,
+								WriteTimeMs: 500,
+							},
+						},
+						timestamp: testNow.Add(-10 * time.Second),
+					}, gocache.DefaultExpiration)
+					lastStat := &blkioSnapshot{
+						stats: map[string]system.BlkIOStatRaw{
+							"8:0": {
+								Device:     "8:0",
+								ReadBytes:  10240,
+								WriteBytes: 20480,
+								ReadIOs:    10,
+								WriteIOs:   20,
+							},
+						},
+						timestamp: testNow.Add(-10 * time.Second),
+					}
+					lastState.Set(string(testPod.UID), lastStat, gocache.DefaultExpiration)
+					lastState.Set(testContainerID, lastStat, gocache.DefaultExpiration)
+				},
+			},
+			wants: wants{
+				devices: []string{"sda"},
+				nodeBlkIO: map[string]map[metriccache.MetricPropertyValue]float64{
+					"sda": {
+						metriccache.BlkIOStatReadBytes:    1000 * system.DiskSectorSize,
+						metriccache.BlkIOStatWriteBytes:   2000 * system.DiskSectorSize,
+						metriccache.BlkIOStatReadIOPS:     50,
+						metriccache.BlkIOStatWriteIOPS:    100,
+						metriccache.BlkIOStatReadLatency:  0.5,
+						metriccache.BlkIOStatWriteLatency: 1,
+					},
+				},
+				podBlkIO: map[string]map[metriccache.MetricPropertyValue]float64{
+					"sda": {
+						metriccache.BlkIOStatReadBytes:  1024,
+						metriccache.BlkIOStatWriteBytes: 2048,
+						metriccache.BlkIOStatReadIOPS:   1,
+						metriccache.BlkIOStatWriteIOPS:  2,
+					},
+				},
+				containerBlkIO: map[string]map[metriccache.MetricPropertyValue]float64{
+					"sda": {
+						metriccache.BlkIOStatReadBytes:  1024,
+						metriccache.BlkIOStatWriteBytes: 2048,
+						metriccache.BlkIOStatReadIOPS:   1,
+						metriccache.BlkIOStatWriteIOPS:  2,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			prepareFiles(helper)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			metricCache, err := metriccache.NewMetricCache(&metriccache.Config{
+				TSDBPath:              helper.TempDir,
+				TSDBEnablePromMetrics: false,
+			})
+			assert.NoError(t, err)
+			defer func() {
+				metricCache.Close()
+			}()
+			statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+			statesInformer.EXPECT().HasSynced().Return(true).AnyTimes()
+			statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{
+				{
+					CgroupDir: testPodMetaDir,
+					Pod:       testPod,
+				},
+			}).Times(1)
+
+			collector := New(&framework.Options{
+				Config: &framework.Config{
+					CollectResUsedInterval: time.Second,
+				},
+				StatesInformer: statesInformer,
+				MetricCache:    metricCache,
+				CgroupReader:   resourceexecutor.NewCgroupReader(),
+			})
+			c := collector.(*blkioCollector)
+			if tt.fields.initLastStat != nil {
+				tt.fields.initLastStat(c.lastBlkIOStat)
+			}
+
+			assert.NotPanics(t, func() {
+				c.collectBlkIOResource()
+			})
+			assert.True(t, c.Started())
+			gotDevices, ok := metricCache.Get(metriccache.NodeBlockDevicesKey)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wants.devices, gotDevices)
+
+			querier, err := metricCache.Querier(testNow.Add(-time.Minute), testNow)
+			assert.NoError(t, err)
+			queryValue := func(resource metriccache.MetricResource, properties map[metriccache.MetricProperty]string) float64 {
+				queryMeta, err := resource.BuildQueryMeta(properties)
+				assert.NoError(t, err)
+				aggregateResult := metriccache.DefaultAggregateResultFactory.New(queryMeta)
+				assert.NoError(t, querier.Query(queryMeta, nil, aggregateResult))
+				v, err := aggregateResult.Value(metriccache.AggregationTypeLast)
+				assert.NoError(t, err)
+				return v
+			}
+			for device, wantStats := range tt.wants.nodeBlkIO {
+				for blkioStat, want := range wantStats {
+					got := queryValue(metriccache.NodeBlkIOMetric, metriccache.MetricPropertiesFunc.NodeBlkIO(device, string(blkioStat)))
+					assert.InDelta(t, want, got, 0.0001, blkioStat)
+				}
+			}
+			for device, wantStats := range tt.wants.podBlkIO {
+				for blkioStat, want := range wantStats {
+					got := queryValue(metriccache.PodBlkIOMetric, metriccache.MetricPropertiesFunc.PodBlkIO(string(testPod.UID), device, string(blkioStat)))
+					assert.InDelta(t, want, got, 0.0001, blkioStat)
+				}
+			}
+			for device, wantStats := range tt.wants.containerBlkIO {
+				for blkioStat, want := range wantStats {
+					got := queryValue(metriccache.ContainerBlkIOMetric, metriccache.MetricPropertiesFunc.ContainerBlkIO(testContainerID, device, string(blkioStat)))
+					assert.InDelta(t, want, got, 0.0001, blkioStat)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netresource
+
+import (
+	"fmt"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	CollectorName = "NetworkCollector"
+
+	// hostInitPID is used to read the counters of the host network namespace
+	hostInitPID uint32 = 1
+	nodeStatKey        = "node"
+)
+
+var (
+	timeNow = time.Now
+)
+
+// netStatSnapshot is the summed interface counters of a network namespace at a time
+type netStatSnapshot struct {
+	stat      system.NetDevStat
+	timestamp time.Time
+}
+
+type netResourceCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	appendableDB    metriccache.Appendable
+	statesInformer  statesinformer.StatesInformer
+	cgroupReader    resourceexecutor.CgroupReader
+	podFilter       framework.PodFilter
+
+	lastNetStat *gocache.Cache
+}
+
+func New(opt *framework.Options) framework.Collector {
+	collectInterval := opt.Config.CollectResUsedInterval
+	podFilter := framework.DefaultPodFilter
+	if filter, ok := opt.PodFilters[CollectorName]; ok {
+		podFilter = filter
+	}
+	return &netResourceCollector{
+		collectInterval: collectInterval,
+		started:         atomic.NewBool(false),
+		appendableDB:    opt.MetricCache,
+		statesInformer:  opt.StatesInformer,
+		cgroupReader:    opt.CgroupReader,
+		podFilter:       podFilter,
+		lastNetStat:     gocache.New(collectInterval*framework.ContextExpiredRatio, framework.CleanupInterval),
+	}
+}
+
+var _ framework.PodCollector = &netResourceCollector{}
+
+func (c *netResourceCollector) Enabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.NetworkCollector)
+}
+
+func (c *netResourceCollector) Setup(ctx *framework.Context) {}
+
+func (c *netResourceCollector) Run(stopCh <-chan struct{}) {
+	if !cache.WaitForCacheSync(stopCh, c.statesInformer.HasSynced) {
+		// Koordlet exit because of statesInformer sync failed.
+		klog.Fatalf("timed out waiting for states informer caches to sync")
+	}
+	go wait.Until(c.collectNetResource, c.collectInterval, stopCh)
+}
+
+func (c *netResourceCollector) Started() bool {
+	return c.started.Load()
+}
+
+func (c *netResourceCollector) FilterPod(meta *statesinformer.PodMeta) (bool, string) {
+	return c.podFilter.FilterPod(meta)
+}
+
+func (c *netResourceCollector) collectNetResource() {
+	klog.V(6).Info("start collectNetResource")
+	var metrics []metriccache.MetricSample
+	metrics = append(metrics, c.collectNodeNetResource()...)
+
+	podMetas := c.statesInformer.GetAllPods()
+	for _, meta := range podMetas {
+		pod := meta.Pod
+		if filtered, msg := c.FilterPod(meta); filtered {
+			klog.V(5).Infof("skip collect pod %s/%s, reason: %s", pod.Namespace, pod.Name, msg)
+			continue
+		}
+		// host-network pods share the counters of the node
+		if pod.Spec.HostNetwork {
+			continue
+		}
+		metrics = append(metrics, c.collectPodNetResource(meta)...)
+	}
+
+	appender := c.appendableDB.Appender()
+	if err := appender.Append(metrics); err != nil {
+		klog.Warningf("append network metrics failed, reason: %v", err)
+		return
+	}
+	if err := appender.Commit(); err != nil {
+		klog.Warningf("commit network metrics failed, reason: %v", err)
+		return
+	}
+	c.started.Store(true)
+	klog.V(5).Infof("collectNetResource finished, pod num %d, metric num %d", len(podMetas), len(metrics))
+}
+
+func (c *netResourceCollector) collectNodeNetResource() []metriccache.MetricSample {
+	collectTime := timeNow()
+	stats, err := system.GetNetDevStats(hostInitPID)
+	if err != nil {
+		klog.V(4).Infof("collect node network stats failed, err: %v", err)
+		return nil
+	}
+	current := &netStatSnapshot{
+		stat:      sumNetDevStats(stats, system.IsPhysicalNetDevice),
+		timestamp: collectTime,
+	}
+	rates, ok := c.updateAndCalcRates(nodeStatKey, current)
+	if !ok {
+		return nil
+	}
+
+	metrics := make([]metriccache.MetricSample, 0, len(rates))
+	for netStat, rate := range rates {
+		sample, err := metriccache.NodeNetworkMetric.GenerateSample(
+			metriccache.MetricPropertiesFunc.NodeNetwork(string(netStat)), collectTime, rate)
+		if err != nil {
+			klog.Warningf("generate node network %s metric failed, err %v", netStat, err)
+			continue
+		}
+		metrics = append(metrics, sample)
+	}
+	return metrics
+}
+
+func (c *netResourceCollector) collectPodNetResource(meta *statesinformer.PodMeta) []metriccache.MetricSample {
+	pod := meta.Pod
+	uid := string(pod.UID)
+	collectTime := timeNow()
+	pid, err := c.getPodNetNSPID(meta)
+	if err != nil {
+		klog.V(5).Infof("collect pod %s network stats failed, err: %v", util.GetPodKey(pod), err)
+		return nil
+	}
+	stats, err := system.GetNetDevStats(pid)
+	if err != nil {
+		klog.V(4).Infof("collect pod %s network stats failed, pid %v, err: %v", util.GetPodKey(pod), pid, err)
+		return nil
+	}
+	current := &netStatSnapshot{
+		stat: sumNetDevStats(stats, func(iface string) bool {
+			return iface != system.LoopbackInterface
+		}),
+		timestamp: collectTime,
+	}
+	rates, ok := c.updateAndCalcRates(uid, current)
+	if !ok {
+		klog.V(6).Infof("collect pod %s network stats first point", util.GetPodKey(pod))
+		return nil
+	}
+
+	metrics := make([]metriccache.MetricSample, 0, len(rates))
+	for netStat, rate := range rates {
+		sample, err := metriccache.PodNetworkMetric.GenerateSample(
+			metriccache.MetricPropertiesFunc.PodNetwork(uid, string(netStat)), collectTime, rate)
+		if err != nil {
+			klog.Warningf("generate pod %s network %s metric failed, err %v", util.GetPodKey(pod), netStat, err)
+			continue
+		}
+		metrics = append(metrics, sample)
+	}
+	klog.V(6).Infof("collect pod %s network stats finished, metric %v", util.GetPodKey(pod), rates)
+	return metrics
+}
+
+// getPodNetNSPID returns a process of the running containers, which lives in the network namespace of the pod.
+func (c *netResourceCollector) getPodNetNSPID(meta *statesinformer.PodMeta) (uint32, error) {
+	pod := meta.Pod
+	for i := range pod.Status.ContainerStatuses {
+		containerStat := &pod.Status.ContainerStatuses[i]
+		if len(containerStat.ContainerID) == 0 || containerStat.State.Running == nil {
+			continue
+		}
+		containerCgroupDir, err := koordletutil.GetContainerCgroupParentDir(meta.CgroupDir, containerStat)
+		if err != nil {
+			klog.V(5).Infof("failed to get cgroup dir for container %s/%s/%s, err: %s",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		pids, err := c.cgroupReader.ReadCPUProcs(containerCgroupDir)
+		if err != nil {
+			klog.V(5).Infof("failed to read procs for container %s/%s/%s, err: %s",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		if len(pids) > 0 {
+			return pids[0], nil
+		}
+	}
+	return 0, fmt.Errorf("no running process found")
+}
+
+// updateAndCalcRates stores the current snapshot and returns the rates since the last one.
+// It returns false for the first point or the counters are reset, e.g. the network namespace is recreated.
+func (c *netResourceCollector) updateAndCalcRates(key string, current *netStatSnapshot) (map[metriccache.MetricPropertyValue]float64, bool) {
+	lastValue, ok := c.lastNetStat.Get(key)
+	c.lastNetStat.Set(key, current, gocache.DefaultExpiration)
+	if !ok {
+		return nil, false
+	}
+	last := lastValue.(*netStatSnapshot)
+	return calcNetStatRates(current, last)
+}
+
+func calcNetStatRates(current, last *netStatSnapshot) (map[metriccache.MetricPropertyValue]float64, bool) {
+	seconds := current.timestamp.Sub(last.timestamp).Seconds()
+	if seconds <= 0 {
+		return nil, false
+	}
+	cur, prev := current.stat, last.stat
+	if cur.RxBytes < prev.RxBytes || cur.TxBytes < prev.TxBytes ||
+		cur.RxPackets < prev.RxPackets || cur.TxPackets < prev.TxPackets ||
+		cur.RxDrops < prev.RxDrops || cur.TxDrops < prev.TxDrops {
+		return nil, false
+	}
+	return map[metriccache.MetricPropertyValue]float64{
+		metriccache.NetStatRxBytes:   float64(cur.RxBytes-prev.RxBytes) / seconds,
+		metriccache.NetStatTxBytes:   float64(cur.TxBytes-prev.TxBytes) / seconds,
+		metriccache.NetStatRxPackets: float64(cur.RxPackets-prev.RxPackets) / seconds,
+		metriccache.NetStatTxPackets: float64(cur.TxPackets-prev.TxPackets) / seconds,
+		metriccache.NetStatRxDrops:   float64(cur.RxDrops-prev.RxDrops) / seconds,
+		metriccache.NetStatTxDrops:   float64(cur.TxDrops-prev.TxDrops) / seconds,
+	}, true
+}
+
+func sumNetDevStats(stats []system.NetDevStat, filterFn func(iface string) bool) system.NetDevStat {
+	sum := system.NetDevStat{}
+	for _, s := range stats {
+		if !filterFn(s.Interface) {
+			continue
+		}
+		sum.RxBytes += s.RxBytes
+		sum.RxPackets += s.RxPackets
+		sum.RxDrops += s.RxDrops
+		sum.TxBytes += s.TxBytes
+		sum.TxPackets += s.TxPackets
+		sum.TxDrops += s.TxDrops
+	}
+	return sum
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netresource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	testHostNetDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  100000     100    0    0    0     0          0         0   100000     100    0    0    0     0       0          0
+  eth0:  200000    2000    0   20    0     0          0         0   100000    1000    0   10    0     0       0          0
+ veth0:  500000    5000    0    0    0     0          0         0   500000    5000    0    0    0     0       0          0
+`
+	testPodNetDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  100000     100    0    0    0     0          0         0   100000     100    0    0    0     0       0          0
+  eth0:   30000     300    0    3    0     0          0         0    10000     100    0    1    0     0       0          0
+`
+)
+
+func Test_netResourceCollector_collectNetResource(t *testing.T) {
+	testNow := time.Now()
+	timeNow = func() time.Time {
+		return testNow
+	}
+	testContainerID := "containerd://testContainerUID"
+	testPodMetaDir := "kubepods.slice/kubepods-podtest-pod-uid.slice"
+	testContainerParentDir := "/kubepods.slice/kubepods-podtest-pod-uid.slice/cri-containerd-testContainerUID.scope"
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "test",
+			UID:       "test-pod-uid",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "test-container",
+					ContainerID: testContainerID,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	}
+	testHostNetworkPod := testPod.DeepCopy()
+	testHostNetworkPod.Name = "test-host-network-pod"
+	testHostNetworkPod.UID = "test-host-network-pod-uid"
+	testHostNetworkPod.Spec.HostNetwork = true
+
+	type fields struct {
+		getPodMetas  []*statesinformer.PodMeta
+		initLastStat func(lastState *gocache.Cache)
+		SetSysUtil   func(helper *system.FileTestUtil)
+	}
+	type wants struct {
+		nodeNetwork map[metriccache.MetricPropertyValue]float64
+		podNetwork  map[string]map[metriccache.MetricPropertyValue]float64
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		wants  wants
+	}{
+		{
+			name: "first point of node and pod",
+			fields: fields{
+				getPodMetas: []*statesinformer.PodMeta{
+					{
+						CgroupDir: testPodMetaDir,
+						Pod:       testPod,
+					},
+				},
+				SetSysUtil: func(helper *system.FileTestUtil) {
+					helper.WriteFileContents(system.GetProcPIDNetDevPath(1), testHostNetDev)
+					helper.MkDirAll(system.GetSysNetDevicePath("eth0"))
+					helper.WriteCgroupFileContents(testContainerParentDir, system.CPUProcs, "12345\n")
+					helper.WriteFileContents(system.GetProcPIDNetDevPath(12345), testPodNetDev)
+				},
+			},
+			wants: wants{},
+		},
+		{
+			name: "collect node and pod rates",
+			fields: fields{
+				getPodMetas: []*statesinformer.PodMeta{
+					{
+						CgroupDir: testPodMetaDir,
+						Pod:       testPod,
+					},
+					{
+						CgroupDir: testPodMetaDir,
+						Pod:       testHostNetworkPod,
+					},
+				},
+				initLastStat: func(lastState *gocache.Cache) {
+					lastState.Set(nodeStatKey, &netStatSnapshot{
+						stat: system.NetDevStat{
+							RxBytes:   100000,
+							RxPackets: 1000,
+							RxDrops:   10,
+							TxBytes:   50000,
+							TxPackets: 500,
+							TxDrops:   5,
+						},
+						timestamp: testNow.Add(-10 * time.Second),
+					}, gocache.DefaultExpiration)
+					lastState.Set(string(testPod.UID), &netStatSnapshot{
+						stat: system.NetDevStat{
+							RxBytes:   10000,
+							RxPackets: 100,
+							RxDrops:   1,
+							TxBytes:   5000,
+							TxPackets: 50,
+							TxDrops:   1,
+						},
+						timestamp: testNow.Add(-10 * time.Second),
+					}, gocache.DefaultExpiration)
+				},
+				SetSysUtil: func(helper *system.FileTestUtil) {
+					helper.WriteFileContents(system.GetProcPIDNetDevPath(1), testHostNetDev)
+					helper.MkDirAll(system.GetSysNetDevicePath("eth0"))
+					helper.WriteCgroupFileContents(testContainerParentDir, system.CPUProcs, "12345\n")
+					helper.WriteFileContents(system.GetProcPIDNetDevPath(12345), testPodNetDev)
+				},
+			},
+			wants: wants{
+				nodeNetwork: map[metriccache.MetricPropertyValue]float64{
+					metriccache.NetStatRxBytes:   10000,
+					metriccache.NetStatTxBytes:   5000,
+					metriccache.NetStatRxPackets: 100,
+					metriccache.NetStatTxPackets: 50,
+					metriccache.NetStatRxDrops:   1,
+					metriccache.NetStatTxDrops:   0.5,
+				},
+				podNetwork: map[string]map[metriccache.MetricPropertyValue]float64{
+					string(testPod.UID): {
+						metriccache.NetStatRxBytes:   2000,
+						metriccache.NetStatTxBytes:   500,
+						metriccache.NetStatRxPackets: 20,
+						metriccache.NetStatTxPackets: 5,
+						metriccache.NetStatRxDrops:   0.2,
+						metriccache.NetStatTxDrops:   0,
+					},
+				},
+			},
+		},
+		{
+			name: "skip pod with counters reset",
+			fields: fields{
+				getPodMetas: []*statesinformer.PodMeta{
+					{
+						CgroupDir: testPodMetaDir,
+						Pod:       testPod,
+					},
+				},
+				initLastStat: func(lastState *gocache.Cache) {
+					lastState.Set(string(testPod.UID), &netStatSnapshot{
+						stat: system.NetDevStat{
+							RxBytes: 1000000,
+						},
+						timestamp: testNow.Add(-10 * time.Second),
+					}, gocache.DefaultExpiration)
+				},
+				SetSysUtil: func(helper *system.FileTestUtil) {
+					helper.WriteCgroupFileContents(testContainerParentDir, system.CPUProcs, "12345\n")
+					helper.WriteFileContents(system.GetProcPIDNetDevPath(12345), testPodNetDev)
+				},
+			},
+			wants: wants{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			if tt.fields.SetSysUtil != nil {
+				tt.fields.SetSysUtil(helper)
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			metricCache, err := metriccache.NewMetricCache(&metriccache.Config{
+				TSDBPath:              helper.TempDir,
+				TSDBEnablePromMetrics: false,
+			})
+			assert.NoError(t, err)
+			defer func() {
+				metricCache.Close()
+			}()
+			statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+			statesInformer.EXPECT().HasSynced().Return(true).AnyTimes()
+			statesInformer.EXPECT().GetAllPods().Return(tt.fields.getPodMetas).Times(1)
+
+			collector := New(&framework.Options{
+				Config: &framework.Config{
+					CollectResUsedInterval: time.Second,
+				},
+				StatesInformer: statesInformer,
+				MetricCache:    metricCache,
+				CgroupReader:   resourceexecutor.NewCgroupReader(),
+			})
+			c := collector.(*netResourceCollector)
+			if tt.fields.initLastStat != nil {
+				tt.fields.initLastStat(c.lastNetStat)
+			}
+
+			assert.NotPanics(t, func() {
+				c.collectNetResource()
+			})
+			assert.True(t, c.Started())
+
+			querier, err := metricCache.Querier(testNow.Add(-time.Minute), testNow)
+			assert.NoError(t, err)
+			queryValue := func(resource metriccache.MetricResource, properties map[metriccache.MetricProperty]string) (float64, int) {
+				queryMeta, err := resource.BuildQueryMeta(properties)
+				assert.NoError(t, err)
+				aggregateResult := metriccache.DefaultAggregateResultFactory.New(queryMeta)
+				assert.NoError(t, querier.Query(queryMeta, nil, aggregateResult))
+				if aggregateResult.Count() == 0 {
+					return 0, 0
+				}
+				v, err := aggregateResult.Value(metriccache.AggregationTypeLast)
+				assert.NoError(t, err)
+				return v, aggregateResult.Count()
+			}
+
+			_, count := queryValue(metriccache.NodeNetworkMetric, metriccache.MetricPropertiesFunc.NodeNetwork(string(metriccache.NetStatRxBytes)))
+			assert.Equal(t, len(tt.wants.nodeNetwork) > 0, count > 0)
+			for netStat, want := range tt.wants.nodeNetwork {
+				got, _ := queryValue(metriccache.NodeNetworkMetric, metriccache.MetricPropertiesFunc.NodeNetwork(string(netStat)))
+				assert.InDelta(t, want, got, 0.0001, netStat)
+			}
+			_, count = queryValue(metriccache.PodNetworkMetric, metriccache.MetricPropertiesFunc.PodNetwork(string(testPod.UID), string(metriccache.NetStatRxBytes)))
+			assert.Equal(t, len(tt.wants.podNetwork) > 0, count > 0)
+			_, count = queryValue(metriccache.PodNetworkMetric, metriccache.MetricPropertiesFunc.PodNetwork(string(testHostNetworkPod.UID), string(metriccache.NetStatRxBytes)))
+			assert.Equal(t, 0, count)
+			for podUID, wantStats := range tt.wants.podNetwork {
+				for netStat, want := range wantStats {
+					got, _ := queryValue(metriccache.PodNetworkMetric, metriccache.MetricPropertiesFunc.PodNetwork(podUID, string(netStat)))
+					assert.InDelta(t, want, got, 0.0001, netStat)
+				}
+			}
+		})
+	}
+}
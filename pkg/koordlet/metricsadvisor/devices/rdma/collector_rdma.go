@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdma
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+)
+
+const (
+	DeviceCollectorName = "RDMA"
+)
+
+// rdmaCollector discovers the RDMA NICs and the SR-IOV virtual functions from the sysfs periodically, so that the
+// health of the devices and the changes of the VFs can be reported.
+type rdmaCollector struct {
+	enabled         bool
+	collectInterval time.Duration
+
+	lock    sync.RWMutex
+	started bool
+	devices koordletutil.RDMADevices
+}
+
+func New(opt *framework.Options) framework.DeviceCollector {
+	return &rdmaCollector{
+		enabled:         features.DefaultKoordletFeatureGate.Enabled(features.RDMADevices),
+		collectInterval: opt.Config.CollectResUsedInterval,
+	}
+}
+
+func (r *rdmaCollector) Shutdown() {}
+
+func (r *rdmaCollector) Enabled() bool {
+	return r.enabled
+}
+
+func (r *rdmaCollector) Setup(c *framework.Context) {}
+
+func (r *rdmaCollector) Run(stopCh <-chan struct{}) {
+	go wait.Until(r.discoverDevices, r.collectInterval, stopCh)
+}
+
+func (r *rdmaCollector) Started() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.started
+}
+
+func (r *rdmaCollector) Infos() metriccache.Devices {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if !r.started {
+		return nil
+	}
+	// the empty devices are also returned to clean up the removed devices
+	devices := make(koordletutil.RDMADevices, len(r.devices))
+	copy(devices, r.devices)
+	return devices
+}
+
+func (r *rdmaCollector) GetNodeMetric() ([]metriccache.MetricSample, error) {
+	return nil, nil
+}
+
+func (r *rdmaCollector) GetPodMetric(uid, podParentDir string, cs []corev1.ContainerStatus) ([]metriccache.MetricSample, error) {
+	return nil, nil
+}
+
+func (r *rdmaCollector) GetContainerMetric(containerID, podParentDir string, c *corev1.ContainerStatus) ([]metriccache.MetricSample, error) {
+	return nil, nil
+}
+
+func (r *rdmaCollector) discoverDevices() {
+	devices, err := discoverRDMADevices()
+	if err != nil {
+		klog.Warningf("failed to discover rdma devices, err: %v", err)
+		return
+	}
+	klog.V(5).Infof("discover %d rdma devices", len(devices))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.devices = devices
+	r.started = true
+}
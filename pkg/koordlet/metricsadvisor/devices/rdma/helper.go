@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdma
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	// portStateActive is the state of an active infiniband port, e.g. `4: ACTIVE`
+	portStateActive = "ACTIVE"
+
+	virtualFunctionPrefix = "virtfn"
+	physicalFunctionLink  = "physfn"
+)
+
+var (
+	pcieRegexp = regexp.MustCompile(`pci\d{4}:[0-9a-fA-F]{2}`)
+)
+
+// discoverRDMADevices enumerates the RDMA devices in /sys/class/infiniband, the devices of the virtual functions are
+// skipped and reported as the VFs of their physical functions in /sys/bus/pci/devices/<bdf>/virtfn*.
+func discoverRDMADevices() (koordletutil.RDMADevices, error) {
+	ibDir := system.GetInfinibandDir()
+	entries, err := os.ReadDir(ibDir)
+	if os.IsNotExist(err) {
+		return koordletutil.RDMADevices{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir %s, err: %w", ibDir, err)
+	}
+
+	devices := koordletutil.RDMADevices{}
+	for _, entry := range entries {
+		name := entry.Name()
+		devicePath, err := filepath.EvalSymlinks(filepath.Join(ibDir, name, "device"))
+		if err != nil {
+			klog.V(4).Infof("failed to get the pci device of rdma device %s, err: %v", name, err)
+			continue
+		}
+		busID := filepath.Base(devicePath)
+		if system.FileExists(filepath.Join(devicePath, physicalFunctionLink)) {
+			klog.V(5).Infof("skip the rdma device %s of virtual function %s", name, busID)
+			continue
+		}
+
+		nodeID, err := getNUMANodeID(devicePath)
+		if err != nil {
+			klog.V(4).Infof("failed to get the numa node of rdma device %s, err: %v", name, err)
+			continue
+		}
+		vfs, err := getVirtualFunctions(devicePath)
+		if err != nil {
+			klog.V(4).Infof("failed to get the virtual functions of rdma device %s, err: %v", name, err)
+			continue
+		}
+		devices = append(devices, koordletutil.RDMADeviceInfo{
+			ID:     name,
+			NodeID: nodeID,
+			PCIE:   parsePCIEID(devicePath),
+			BusID:  busID,
+			Health: isPortActive(filepath.Join(ibDir, name)),
+			VFs:    vfs,
+		})
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].BusID < devices[j].BusID
+	})
+	for i := range devices {
+		devices[i].Minor = int32(i)
+	}
+	return devices, nil
+}
+
+// getVirtualFunctions returns the VFs of the physical function, a VF is unhealthy if its pci device is gone or the
+// port of its RDMA device is not active.
+func getVirtualFunctions(devicePath string) ([]koordletutil.RDMAVirtualFunction, error) {
+	links, err := filepath.Glob(filepath.Join(devicePath, virtualFunctionPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	var vfs []koordletutil.RDMAVirtualFunction
+	for _, link := range links {
+		minor, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(link), virtualFunctionPrefix))
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(link)
+		if err != nil {
+			return nil, err
+		}
+		vf := koordletutil.RDMAVirtualFunction{
+			Minor: int32(minor),
+			BusID: filepath.Base(target),
+		}
+		if vfPath, err := filepath.EvalSymlinks(link); err == nil {
+			vf.Health = isVirtualFunctionHealthy(vfPath)
+		}
+		vfs = append(vfs, vf)
+	}
+	sort.Slice(vfs, func(i, j int) bool {
+		return vfs[i].Minor < vfs[j].Minor
+	})
+	return vfs, nil
+}
+
+func isVirtualFunctionHealthy(vfPath string) bool {
+	// the VF without the RDMA device is not bound to the driver on the host, e.g. passed through to a VM
+	ibDevices, err := filepath.Glob(filepath.Join(vfPath, "infiniband", "*"))
+	if err != nil || len(ibDevices) == 0 {
+		return true
+	}
+	for _, ibDevice := range ibDevices {
+		if isPortActive(ibDevice) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPortActive returns whether any port of the RDMA device is active.
+func isPortActive(ibDevicePath string) bool {
+	states, err := filepath.Glob(filepath.Join(ibDevicePath, "ports", "*", "state"))
+	if err != nil {
+		return false
+	}
+	for _, state := range states {
+		data, err := os.ReadFile(state)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(data), portStateActive) {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePCIEID(path string) string {
+	result := pcieRegexp.FindAllStringSubmatch(path, -1)
+	if len(result) == 0 || len(result[0]) == 0 {
+		return ""
+	}
+	return result[0][0]
+}
+
+func getNUMANodeID(devicePath string) (int32, error) {
+	data, err := os.ReadFile(filepath.Join(devicePath, "numa_node"))
+	if err != nil {
+		return -1, err
+	}
+	nodeID, err := strconv.Atoi(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return -1, err
+	}
+	if nodeID == -1 {
+		nodeID = 0
+	}
+	return int32(nodeID), nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdma
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+type fakeRDMADevice struct {
+	name      string
+	pcie      string
+	busID     string
+	numaNode  string
+	portState string
+	// physFn is the bus id of the physical function if the device is a virtual function
+	physFn string
+	vfs    []string
+}
+
+func writeFakeRDMADevice(t *testing.T, helper *system.FileTestUtil, d *fakeRDMADevice) {
+	devicesDir := filepath.Join(helper.TempDir, "devices", d.pcie)
+	deviceDir := filepath.Join(devicesDir, d.busID)
+	helper.MkDirAll(deviceDir)
+	if d.numaNode != "" {
+		helper.WriteFileContents(filepath.Join(deviceDir, "numa_node"), d.numaNode+"\n")
+	}
+	if d.physFn != "" {
+		assert.NoError(t, os.Symlink(filepath.Join(devicesDir, d.physFn), filepath.Join(deviceDir, physicalFunctionLink)))
+	}
+	for i, vf := range d.vfs {
+		assert.NoError(t, os.Symlink(filepath.Join(devicesDir, vf), filepath.Join(deviceDir, virtualFunctionPrefix+string(rune('0'+i)))))
+	}
+	pciDeviceDir := system.GetPCIDeviceDir()
+	helper.MkDirAll(pciDeviceDir)
+	assert.NoError(t, os.Symlink(deviceDir, filepath.Join(pciDeviceDir, d.busID)))
+
+	if d.name == "" {
+		return
+	}
+	ibDeviceDir := filepath.Join(deviceDir, "infiniband", d.name)
+	helper.WriteFileContents(filepath.Join(ibDeviceDir, "ports", "1", "state"), d.portState+"\n")
+	assert.NoError(t, os.Symlink(deviceDir, filepath.Join(ibDeviceDir, "device")))
+	ibDir := system.GetInfinibandDir()
+	helper.MkDirAll(ibDir)
+	assert.NoError(t, os.Symlink(ibDeviceDir, filepath.Join(ibDir, d.name)))
+}
+
+func Test_discoverRDMADevices(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []*fakeRDMADevice
+		want    koordletutil.RDMADevices
+	}{
+		{
+			name: "no infiniband devices",
+			want: koordletutil.RDMADevices{},
+		},
+		{
+			name: "rdma devices with virtual functions",
+			devices: []*fakeRDMADevice{
+				{
+					name:      "mlx5_1",
+					pcie:      "pci0000:8e",
+					busID:     "0000:90:00.0",
+					numaNode:  "-1",
+					portState: "1: DOWN",
+				},
+				{
+					name:      "mlx5_0",
+					pcie:      "pci0000:1d",
+					busID:     "0000:1f:00.0",
+					numaNode:  "0",
+					portState: "4: ACTIVE",
+					vfs:       []string{"0000:1f:00.2", "0000:1f:00.3", "0000:1f:00.4", "0000:1f:00.5"},
+				},
+				{
+					name:      "mlx5_2",
+					pcie:      "pci0000:1d",
+					busID:     "0000:1f:00.2",
+					numaNode:  "0",
+					portState: "4: ACTIVE",
+					physFn:    "0000:1f:00.0",
+				},
+				{
+					name:      "mlx5_3",
+					pcie:      "pci0000:1d",
+					busID:     "0000:1f:00.3",
+					numaNode:  "0",
+					portState: "1: DOWN",
+					physFn:    "0000:1f:00.0",
+				},
+				{
+					// the VF is not bound to the rdma driver on the host
+					pcie:     "pci0000:1d",
+					busID:    "0000:1f:00.4",
+					numaNode: "0",
+					physFn:   "0000:1f:00.0",
+				},
+				// the VF 0000:1f:00.5 is gone
+			},
+			want: koordletutil.RDMADevices{
+				{
+					ID:     "mlx5_0",
+					Minor:  0,
+					NodeID: 0,
+					PCIE:   "pci0000:1d",
+					BusID:  "0000:1f:00.0",
+					Health: true,
+					VFs: []koordletutil.RDMAVirtualFunction{
+						{Minor: 0, BusID: "0000:1f:00.2", Health: true},
+						{Minor: 1, BusID: "0000:1f:00.3", Health: false},
+						{Minor: 2, BusID: "0000:1f:00.4", Health: true},
+						{Minor: 3, BusID: "0000:1f:00.5", Health: false},
+					},
+				},
+				{
+					ID:     "mlx5_1",
+					Minor:  1,
+					NodeID: 0,
+					PCIE:   "pci0000:8e",
+					BusID:  "0000:90:00.0",
+					Health: false,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			for _, d := range tt.devices {
+				writeFakeRDMADevice(t, helper, d)
+			}
+
+			got, err := discoverRDMADevices()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_rdmaCollector(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	writeFakeRDMADevice(t, helper, &fakeRDMADevice{
+		name:      "mlx5_0",
+		pcie:      "pci0000:1d",
+		busID:     "0000:1f:00.0",
+		numaNode:  "1",
+		portState: "4: ACTIVE",
+	})
+
+	c := &rdmaCollector{enabled: true}
+	assert.False(t, c.Started())
+	assert.Nil(t, c.Infos())
+	c.discoverDevices()
+	assert.True(t, c.Started())
+	assert.Equal(t, koordletutil.RDMADevices{
+		{ID: "mlx5_0", NodeID: 1, PCIE: "pci0000:1d", BusID: "0000:1f:00.0", Health: true},
+	}, c.Infos())
+}
@@ -18,8 +18,10 @@ package metricsadvisor
 
 import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/beresource"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/blkioresource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/coldmemoryresource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/hostapplication"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/netresource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/nodeinfo"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/noderesource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/nodestorageinfo"
@@ -29,6 +31,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/podthrottled"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/sysresource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/devices/gpu"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/devices/rdma"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 )
 
@@ -36,7 +39,8 @@ import (
 
 var (
 	devicePlugins = map[string]framework.DeviceFactory{
-		gpu.DeviceCollectorName: gpu.New,
+		gpu.DeviceCollectorName:  gpu.New,
+		rdma.DeviceCollectorName: rdma.New,
 	}
 
 	collectorPlugins = map[string]framework.CollectorFactory{
@@ -51,10 +55,14 @@ var (
 		coldmemoryresource.CollectorName: coldmemoryresource.New,
 		pagecache.CollectorName:          pagecache.New,
 		hostapplication.CollectorName:    hostapplication.New,
+		netresource.CollectorName:        netresource.New,
+		blkioresource.CollectorName:      blkioresource.New,
 	}
 
 	podFilters = map[string]framework.PodFilter{
-		podresource.CollectorName:  framework.DefaultPodFilter,
-		podthrottled.CollectorName: framework.DefaultPodFilter,
+		podresource.CollectorName:   framework.DefaultPodFilter,
+		podthrottled.CollectorName:  framework.DefaultPodFilter,
+		netresource.CollectorName:   framework.DefaultPodFilter,
+		blkioresource.CollectorName: framework.DefaultPodFilter,
 	}
 )
@@ -38,6 +38,9 @@ type ModelCheckpoint struct {
 	CPU         *histogram.HistogramCheckpoint
 	Memory      *histogram.HistogramCheckpoint
 	LastUpdated metav1.Time
+	// SeasonalCPU and SeasonalMemory are the checkpoints of the seasonal histograms indexed by the time slot.
+	SeasonalCPU    []*histogram.HistogramCheckpoint `json:",omitempty"`
+	SeasonalMemory []*histogram.HistogramCheckpoint `json:",omitempty"`
 
 	Error error `json:"-,omitempty"`
 }
@@ -31,19 +31,28 @@ type Config struct {
 	ModelExpirationDuration      time.Duration
 	ModelCheckpointInterval      time.Duration
 	ModelCheckpointMaxPerStep    int
+	// the seasonal model is trained only when the feature-gate SeasonalPrediction is enabled
+	SeasonalPeriod                 time.Duration
+	SeasonalSlotDuration           time.Duration
+	SeasonalHistogramDecayHalfLife time.Duration
+	SeasonalPredictionHorizon      time.Duration
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		CheckpointFilepath:           "/prediction-checkpoints",
-		ColdStartDuration:            24 * time.Hour,
-		SafetyMarginPercent:          10,
-		MemoryHistogramDecayHalfLife: 24 * time.Hour,
-		CPUHistogramDecayHalfLife:    12 * time.Hour,
-		TrainingInterval:             time.Minute,
-		ModelExpirationDuration:      30 * time.Minute,
-		ModelCheckpointInterval:      10 * time.Minute,
-		ModelCheckpointMaxPerStep:    12,
+		CheckpointFilepath:             "/prediction-checkpoints",
+		ColdStartDuration:              24 * time.Hour,
+		SafetyMarginPercent:            10,
+		MemoryHistogramDecayHalfLife:   24 * time.Hour,
+		CPUHistogramDecayHalfLife:      12 * time.Hour,
+		TrainingInterval:               time.Minute,
+		ModelExpirationDuration:        30 * time.Minute,
+		ModelCheckpointInterval:        10 * time.Minute,
+		ModelCheckpointMaxPerStep:      12,
+		SeasonalPeriod:                 24 * time.Hour,
+		SeasonalSlotDuration:           time.Hour,
+		SeasonalHistogramDecayHalfLife: 7 * 24 * time.Hour,
+		SeasonalPredictionHorizon:      6 * time.Hour,
 	}
 }
 
@@ -57,4 +66,8 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.DurationVar(&c.ModelExpirationDuration, "prediction-model-expiration-duration", c.ModelExpirationDuration, "Expiration of prediction model without updated")
 	fs.DurationVar(&c.ModelCheckpointInterval, "prediction-model-checkpoint-interval", c.ModelCheckpointInterval, "Interval of prediction model take checkpoint")
 	fs.IntVar(&c.ModelCheckpointMaxPerStep, "prediction-model-checkpoint-max-per-step", c.ModelCheckpointMaxPerStep, "The maximum number of prediction models saved at a time")
+	fs.DurationVar(&c.SeasonalPeriod, "prediction-seasonal-period", c.SeasonalPeriod, "Period of the seasonal prediction model, e.g. 24h for the daily pattern and 168h for the weekly pattern")
+	fs.DurationVar(&c.SeasonalSlotDuration, "prediction-seasonal-slot-duration", c.SeasonalSlotDuration, "Duration of each time slot in the seasonal period, the period must be a multiple of it")
+	fs.DurationVar(&c.SeasonalHistogramDecayHalfLife, "prediction-seasonal-histogram-decay-halflife", c.SeasonalHistogramDecayHalfLife, "Half-life of the histograms of the seasonal time slots, the older the data, the lower the weight")
+	fs.DurationVar(&c.SeasonalPredictionHorizon, "prediction-seasonal-horizon", c.SeasonalPredictionHorizon, "The seasonal predictor predicts the peak of the time slots within the horizon from now")
 }
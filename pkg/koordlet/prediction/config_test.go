@@ -62,4 +62,9 @@ func TestNewDefaultConfig(t *testing.T) {
 	if config.ColdStartDuration != expectedColdStartDuration {
 		t.Errorf("Expected default PredictionColdStartDuration: %s, but got: %s", expectedColdStartDuration, config.ColdStartDuration)
 	}
+
+	// Test if the SeasonalPeriod is a multiple of the SeasonalSlotDuration by default
+	if config.SeasonalPeriod%config.SeasonalSlotDuration != 0 {
+		t.Errorf("Expected default SeasonalPeriod %s to be a multiple of SeasonalSlotDuration %s", config.SeasonalPeriod, config.SeasonalSlotDuration)
+	}
 }
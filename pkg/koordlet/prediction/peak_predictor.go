@@ -36,6 +36,10 @@ type PredictorType int
 const (
 	// ProdReclaimablePredictor represents the type of a reclaimable production predictor.
 	ProdReclaimablePredictor PredictorType = iota
+	// ProdReclaimableSeasonalPredictor represents the type of a reclaimable production predictor which predicts the
+	// peak of the upcoming time slots with the seasonal models, e.g. more resources are reclaimable at night for the
+	// diurnal online services.
+	ProdReclaimableSeasonalPredictor
 )
 
 // PredictorFactory is an interface for creating predictors of different types.
@@ -53,14 +57,17 @@ type predictorFactory struct {
 	predictServer       PredictServer
 	coldStartDuration   time.Duration
 	safetyMarginPercent int
+	seasonalHorizon     time.Duration
 }
 
 // NewPredictorFactory creates a new instance of PredictorFactory.
-func NewPredictorFactory(predictServer PredictServer, coldStartDuration time.Duration, safetyMarginPercent int) PredictorFactory {
+func NewPredictorFactory(predictServer PredictServer, coldStartDuration time.Duration, safetyMarginPercent int,
+	seasonalHorizon time.Duration) PredictorFactory {
 	return &predictorFactory{
 		predictServer:       predictServer,
 		coldStartDuration:   coldStartDuration,
 		safetyMarginPercent: safetyMarginPercent,
+		seasonalHorizon:     seasonalHorizon,
 	}
 }
 
@@ -88,6 +95,20 @@ func (f *predictorFactory) New(t PredictorType) Predictor {
 				priorityPredictor,
 			},
 		}
+	case ProdReclaimableSeasonalPredictor:
+		// The pod-level histograms are not seasonal, so only the priority-level prediction is used. Otherwise, the
+		// minimal of the results would be as conservative as the non-seasonal one. The pods in cold start are still
+		// excluded like the podReclaimablePredictor.
+		return &seasonalReclaimablePredictor{
+			priorityReclaimablePredictor: &priorityReclaimablePredictor{
+				predictServer:         f.predictServer,
+				safetyMarginPercent:   f.safetyMarginPercent,
+				priorityClassFilterFn: isPriorityClassReclaimableForProd,
+				reclaimRequest:        util.NewZeroResourceList(),
+			},
+			horizon:           f.seasonalHorizon,
+			coldStartDuration: f.coldStartDuration,
+		}
 	default:
 		return &emptyPredictor{}
 	}
@@ -256,8 +277,20 @@ func (n *priorityReclaimablePredictor) AddPod(pod *v1.Pod) error {
 }
 
 func (n *priorityReclaimablePredictor) GetResult() (v1.ResourceList, error) {
+	reclaimable, err := n.calculateReclaimable(n.predictServer.GetPrediction)
+	if err != nil {
+		return nil, err
+	}
+	metrics.RecordNodePredictedResourceReclaimable(string(v1.ResourceCPU), metrics.UnitCore, n.GetPredictorName(), float64(reclaimable.Cpu().MilliValue())/1000)
+	metrics.RecordNodePredictedResourceReclaimable(string(v1.ResourceMemory), metrics.UnitByte, n.GetPredictorName(), float64(reclaimable.Memory().Value()))
+	return reclaimable, nil
+}
+
+// calculateReclaimable calculates the reclaimable resources with the peak predictions of the sys and the reclaimable
+// priority classes returned by getPrediction.
+func (n *priorityReclaimablePredictor) calculateReclaimable(getPrediction func(MetricDesc) (Result, error)) (v1.ResourceList, error) {
 	// get sys prediction
-	sysResult, err := n.predictServer.GetPrediction(MetricDesc{UID: getNodeItemUID(SystemItemID)})
+	sysResult, err := getPrediction(MetricDesc{UID: getNodeItemUID(SystemItemID)})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prediction of sys, err: %w", err)
 	}
@@ -274,7 +307,7 @@ func (n *priorityReclaimablePredictor) GetResult() (v1.ResourceList, error) {
 			continue
 		}
 
-		result, err := n.predictServer.GetPrediction(MetricDesc{UID: getNodeItemUID(string(priorityClass))})
+		result, err := getPrediction(MetricDesc{UID: getNodeItemUID(string(priorityClass))})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get prediction of priority %s, err: %s", priorityClass, err)
 		}
@@ -296,9 +329,44 @@ func (n *priorityReclaimablePredictor) GetResult() (v1.ResourceList, error) {
 	}
 
 	// reclaimable[P] := max(request[P] - peak[P], 0)
-	reclaimable := quotav1.Max(quotav1.Subtract(n.reclaimRequest, reclaimPredict), util.NewZeroResourceList())
-	metrics.RecordNodePredictedResourceReclaimable(string(v1.ResourceCPU), metrics.UnitCore, n.GetPredictorName(), float64(reclaimable.Cpu().MilliValue())/1000)
-	metrics.RecordNodePredictedResourceReclaimable(string(v1.ResourceMemory), metrics.UnitByte, n.GetPredictorName(), float64(reclaimable.Memory().Value()))
+	return quotav1.Max(quotav1.Subtract(n.reclaimRequest, reclaimPredict), util.NewZeroResourceList()), nil
+}
+
+var _ Predictor = (*seasonalReclaimablePredictor)(nil)
+
+// seasonalReclaimablePredictor predicts the peak like the priorityReclaimablePredictor, but the peak only considers
+// the time slots from now to the horizon according to the seasonal models.
+// e.g. A seasonalReclaimablePredictor with a 6-hour horizon at 0 a.m. calculates the result based on the usages
+// between 0 a.m. and 6 a.m. of the previous days, which is usually lower than the daily peak of the online services.
+type seasonalReclaimablePredictor struct {
+	*priorityReclaimablePredictor
+	horizon           time.Duration
+	coldStartDuration time.Duration
+}
+
+// GetPredictorName is used to obtain the predictor name.
+func (s *seasonalReclaimablePredictor) GetPredictorName() string {
+	return "seasonalReclaimablePredictor"
+}
+
+func (s *seasonalReclaimablePredictor) AddPod(pod *v1.Pod) error {
+	// Pods in cold start have 0 reclaimable resources since their usages are not learned by the models yet.
+	if time.Since(pod.CreationTimestamp.Time) <= s.coldStartDuration {
+		klog.V(6).Infof("seasonalReclaimablePredictor skip pod %s which is in cold start", util.GetPodKey(pod))
+		return nil
+	}
+	return s.priorityReclaimablePredictor.AddPod(pod)
+}
+
+func (s *seasonalReclaimablePredictor) GetResult() (v1.ResourceList, error) {
+	reclaimable, err := s.calculateReclaimable(func(desc MetricDesc) (Result, error) {
+		return s.predictServer.GetSeasonalPrediction(desc, s.horizon)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.RecordNodePredictedResourceReclaimable(string(v1.ResourceCPU), metrics.UnitCore, s.GetPredictorName(), float64(reclaimable.Cpu().MilliValue())/1000)
+	metrics.RecordNodePredictedResourceReclaimable(string(v1.ResourceMemory), metrics.UnitByte, s.GetPredictorName(), float64(reclaimable.Memory().Value()))
 	return reclaimable, nil
 }
 
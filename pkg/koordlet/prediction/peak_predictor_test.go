@@ -53,9 +53,10 @@ var testPredictionResult = Result{
 }
 
 type mockPredictServer struct {
-	ResultMap     map[UIDType]Result
-	DefaultResult Result
-	DefaultErr    error
+	ResultMap         map[UIDType]Result
+	SeasonalResultMap map[UIDType]Result
+	DefaultResult     Result
+	DefaultErr        error
 }
 
 func (m *mockPredictServer) Setup(statesInformer statesinformer.StatesInformer, metricCache metriccache.MetricCache) error {
@@ -80,6 +81,14 @@ func (m *mockPredictServer) GetPrediction(desc MetricDesc) (Result, error) {
 	return result, nil
 }
 
+func (m *mockPredictServer) GetSeasonalPrediction(desc MetricDesc, horizon time.Duration) (Result, error) {
+	result, ok := m.SeasonalResultMap[desc.UID]
+	if !ok {
+		return m.DefaultResult, m.DefaultErr
+	}
+	return result, nil
+}
+
 func TestProdReclaimablePredictor_AddPod(t *testing.T) {
 	priority := extension.PriorityProdValueMin
 	sysPrediction := Result{
@@ -188,7 +197,7 @@ func TestProdReclaimablePredictor_AddPod(t *testing.T) {
 	}
 	coldStartDuration := time.Hour
 
-	factory := NewPredictorFactory(predictServer, coldStartDuration, 10)
+	factory := NewPredictorFactory(predictServer, coldStartDuration, 10, 6*time.Hour)
 	predictor := factory.New(ProdReclaimablePredictor)
 	assert.Equal(t, 2, len(predictor.(*minPredictor).predictors))
 
@@ -457,3 +466,117 @@ func Test_priorityReclaimablePredictor(t *testing.T) {
 	}
 	assert.Equal(t, expected, got)
 }
+
+func Test_seasonalReclaimablePredictor(t *testing.T) {
+	sysPrediction := Result{
+		Data: map[string]v1.ResourceList{
+			"p95": {
+				v1.ResourceCPU:    *resource.NewMilliQuantity(300, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(512*1024*1024, resource.BinarySI),
+			},
+			"p98": {
+				v1.ResourceCPU:    *resource.NewMilliQuantity(500, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(512*1024*1024, resource.BinarySI),
+			},
+		},
+	}
+	// the daily peak of prod
+	prodPrediction := Result{
+		Data: map[string]v1.ResourceList{
+			"p95": {
+				v1.ResourceCPU:    *resource.NewMilliQuantity(3000, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(1536*1024*1024, resource.BinarySI),
+			},
+			"p98": {
+				v1.ResourceCPU:    *resource.NewMilliQuantity(3000, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(1536*1024*1024, resource.BinarySI),
+			},
+		},
+	}
+	// the peak of prod in the upcoming hours
+	prodSeasonalPrediction := Result{
+		Data: map[string]v1.ResourceList{
+			"p95": {
+				v1.ResourceCPU:    *resource.NewMilliQuantity(500, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(768*1024*1024, resource.BinarySI),
+			},
+			"p98": {
+				v1.ResourceCPU:    *resource.NewMilliQuantity(800, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(1024*1024*1024, resource.BinarySI),
+			},
+		},
+	}
+	podProd := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "prod-pod",
+			UID:               "pod-1-uid",
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    *resource.NewMilliQuantity(4000, resource.DecimalSI),
+							v1.ResourceMemory: *resource.NewQuantity(4096*1024*1024, resource.BinarySI),
+						},
+					},
+				},
+			},
+			Priority: pointer.Int32(extension.PriorityProdValueMin),
+		},
+	}
+
+	predictServer := &mockPredictServer{
+		ResultMap: map[UIDType]Result{
+			getNodeItemUID(string(extension.PriorityProd)): prodPrediction,
+			getNodeItemUID(SystemItemID):                   sysPrediction,
+			UIDType(podProd.UID):                           prodPrediction,
+		},
+		SeasonalResultMap: map[UIDType]Result{
+			getNodeItemUID(string(extension.PriorityProd)): prodSeasonalPrediction,
+			getNodeItemUID(SystemItemID):                   sysPrediction,
+		},
+		DefaultResult: testZeroResult,
+	}
+
+	factory := NewPredictorFactory(predictServer, time.Hour, 0, 6*time.Hour)
+	predictor := factory.New(ProdReclaimableSeasonalPredictor)
+	assert.Equal(t, "seasonalReclaimablePredictor", predictor.GetPredictorName())
+	assert.Equal(t, 6*time.Hour, predictor.(*seasonalReclaimablePredictor).horizon)
+
+	err := predictor.AddPod(podProd)
+	assert.NoError(t, err)
+
+	got, err := predictor.GetResult()
+	assert.NoError(t, err)
+	expected := v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(4000-(300+500), resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity((4096-(512+1024))*1024*1024, resource.BinarySI),
+	}
+	assert.Equal(t, expected, got)
+
+	// the pod in cold start is not reclaimable
+	podColdStart := podProd.DeepCopy()
+	podColdStart.Name = "prod-pod-cold-start"
+	podColdStart.UID = "pod-2-uid"
+	podColdStart.CreationTimestamp = metav1.Time{Time: time.Now().Add(-time.Minute)}
+	coldStartPredictor := factory.New(ProdReclaimableSeasonalPredictor)
+	assert.NoError(t, coldStartPredictor.AddPod(podProd))
+	assert.NoError(t, coldStartPredictor.AddPod(podColdStart))
+	got, err = coldStartPredictor.GetResult()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	// the non-seasonal predictor uses the daily peak
+	nonSeasonalPredictor := factory.New(ProdReclaimablePredictor)
+	err = nonSeasonalPredictor.AddPod(podProd)
+	assert.NoError(t, err)
+	got, err = nonSeasonalPredictor.GetResult()
+	assert.NoError(t, err)
+	expected = v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(4000-(300+3000), resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity((4096-(512+1536))*1024*1024, resource.BinarySI),
+	}
+	assert.Equal(t, expected, got)
+}
@@ -33,6 +33,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -61,17 +62,27 @@ to the predictive model.
 The predictive model currently provides histogram-based statistics with exponentially decaying
 weights over time periods. PredictServer is responsible for storing the intermediate results of
 the model and recovering when the process restarts.
+
+When the seasonal prediction is enabled, the node-level items additionally keep one histogram for
+each time slot of the seasonal period (e.g. each hour of a day), so the peak of the upcoming hours
+can be predicted according to the usages at the same time of the previous periods.
 */
 type PredictServer interface {
 	Setup(statesinformer.StatesInformer, metriccache.MetricCache) error
 	Run(stopCh <-chan struct{}) error
 	HasSynced() bool
 	GetPrediction(MetricDesc) (Result, error)
+	// GetSeasonalPrediction returns the peak of the time slots from now to the horizon.
+	GetSeasonalPrediction(desc MetricDesc, horizon time.Duration) (Result, error)
 }
 
 type PredictModel struct {
 	CPU    histogram.Histogram
 	Memory histogram.Histogram
+	// SeasonalCPU and SeasonalMemory are indexed by the time slot in the seasonal period.
+	// They are only kept for the node-level items when the seasonal prediction is enabled.
+	SeasonalCPU    []histogram.Histogram
+	SeasonalMemory []histogram.Histogram
 
 	LastUpdated      time.Time
 	LastCheckpointed time.Time
@@ -87,6 +98,9 @@ type peakPredictServer struct {
 	models       map[UIDType]*PredictModel
 	modelsLock   sync.Mutex
 
+	// seasonalSlots is the number of time slots in a seasonal period, 0 means the seasonal model is disabled.
+	seasonalSlots int
+
 	clock        clock.Clock
 	hasSynced    *atomic.Bool
 	checkpointer Checkpointer
@@ -94,13 +108,26 @@ type peakPredictServer struct {
 
 func NewPeakPredictServer(cfg *Config) PredictServer {
 	return &peakPredictServer{
-		cfg:          cfg,
-		uidGenerator: &generator{},
-		models:       make(map[UIDType]*PredictModel),
-		clock:        clock.RealClock{},
-		hasSynced:    &atomic.Bool{},
-		checkpointer: NewFileCheckpointer(cfg.CheckpointFilepath),
+		cfg:           cfg,
+		uidGenerator:  &generator{},
+		models:        make(map[UIDType]*PredictModel),
+		seasonalSlots: getSeasonalSlots(cfg),
+		clock:         clock.RealClock{},
+		hasSynced:     &atomic.Bool{},
+		checkpointer:  NewFileCheckpointer(cfg.CheckpointFilepath),
+	}
+}
+
+func getSeasonalSlots(cfg *Config) int {
+	if !features.DefaultKoordletFeatureGate.Enabled(features.SeasonalPrediction) {
+		return 0
+	}
+	if cfg.SeasonalPeriod <= 0 || cfg.SeasonalSlotDuration <= 0 || cfg.SeasonalPeriod%cfg.SeasonalSlotDuration != 0 {
+		klog.Errorf("invalid seasonal period %v and slot duration %v, the seasonal prediction is disabled",
+			cfg.SeasonalPeriod, cfg.SeasonalSlotDuration)
+		return 0
 	}
+	return int(cfg.SeasonalPeriod / cfg.SeasonalSlotDuration)
 }
 
 func (p *peakPredictServer) Setup(statesInformer statesinformer.StatesInformer, metricCache metriccache.MetricCache) error {
@@ -221,6 +248,46 @@ func (p *peakPredictServer) defaultMemoryHistogram() histogram.Histogram {
 	return histogram.NewDecayingHistogram(options, p.cfg.MemoryHistogramDecayHalfLife)
 }
 
+// The seasonal histograms have the same buckets with the default ones but decay slower, since each time slot only
+// receives samples once a period.
+func (p *peakPredictServer) seasonalHistograms() (cpuHistograms, memoryHistograms []histogram.Histogram) {
+	cpuOptions, err := histogram.NewExponentialHistogramOptions(1024, 0.025, 1.+DefaultHistogramBucketSizeGrowth, epsilon)
+	if err != nil {
+		klog.Fatal("failed to create seasonal CPU HistogramOptions")
+	}
+	memoryOptions, err := histogram.NewExponentialHistogramOptions(1<<31, 5<<20, 1.+DefaultHistogramBucketSizeGrowth, epsilon)
+	if err != nil {
+		klog.Fatal("failed to create seasonal Memory HistogramOptions")
+	}
+	cpuHistograms = make([]histogram.Histogram, p.seasonalSlots)
+	memoryHistograms = make([]histogram.Histogram, p.seasonalSlots)
+	for i := 0; i < p.seasonalSlots; i++ {
+		cpuHistograms[i] = histogram.NewDecayingHistogram(cpuOptions, p.cfg.SeasonalHistogramDecayHalfLife)
+		memoryHistograms[i] = histogram.NewDecayingHistogram(memoryOptions, p.cfg.SeasonalHistogramDecayHalfLife)
+	}
+	return cpuHistograms, memoryHistograms
+}
+
+// seasonalSlot returns the index of the time slot in the seasonal period which the time t belongs to.
+func (p *peakPredictServer) seasonalSlot(t time.Time) int {
+	return int(t.UnixNano() % int64(p.cfg.SeasonalPeriod) / int64(p.cfg.SeasonalSlotDuration))
+}
+
+func (p *peakPredictServer) isSeasonalModel(uid UIDType) bool {
+	if p.seasonalSlots <= 0 {
+		return false
+	}
+	if uid == p.uidGenerator.Node() || uid == p.uidGenerator.NodeItem(SystemItemID) {
+		return true
+	}
+	for _, priorityClass := range extension.KnownPriorityClasses {
+		if uid == p.uidGenerator.NodeItem(string(priorityClass)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *peakPredictServer) updateModel(uid UIDType, cpu, memory float64) {
 	p.modelsLock.Lock()
 	defer p.modelsLock.Unlock()
@@ -230,6 +297,9 @@ func (p *peakPredictServer) updateModel(uid UIDType, cpu, memory float64) {
 			CPU:    p.defaultCPUHistogram(),
 			Memory: p.defaultMemoryHistogram(),
 		}
+		if p.isSeasonalModel(uid) {
+			model.SeasonalCPU, model.SeasonalMemory = p.seasonalHistograms()
+		}
 		p.models[uid] = model
 	}
 	now := p.clock.Now()
@@ -239,6 +309,11 @@ func (p *peakPredictServer) updateModel(uid UIDType, cpu, memory float64) {
 	// TODO Add adjusted weights
 	model.CPU.AddSample(cpu, 1, now)
 	model.Memory.AddSample(memory, 1, now)
+	if len(model.SeasonalCPU) > 0 {
+		slot := p.seasonalSlot(now)
+		model.SeasonalCPU[slot].AddSample(cpu, 1, now)
+		model.SeasonalMemory[slot].AddSample(memory, 1, now)
+	}
 }
 
 func (p *peakPredictServer) GetPrediction(metric MetricDesc) (Result, error) {
@@ -250,31 +325,81 @@ func (p *peakPredictServer) GetPrediction(metric MetricDesc) (Result, error) {
 	}
 	model.Lock.Lock()
 	defer model.Lock.Unlock()
-	//
+	return newPercentileResult(model.CPU.Percentile, model.Memory.Percentile), nil
+}
+
+func (p *peakPredictServer) GetSeasonalPrediction(metric MetricDesc, horizon time.Duration) (Result, error) {
+	p.modelsLock.Lock()
+	defer p.modelsLock.Unlock()
+	model, ok := p.models[metric.UID]
+	if !ok {
+		return Result{}, fmt.Errorf("UID %v not found in predict server", metric.UID)
+	}
+	model.Lock.Lock()
+	defer model.Lock.Unlock()
+	if len(model.SeasonalCPU) <= 0 {
+		return Result{}, fmt.Errorf("UID %v has no seasonal model in predict server", metric.UID)
+	}
+
+	// collect the slots from now to the horizon, the slots without any sample are not trained yet since the seasonal
+	// prediction is enabled recently, so use the overall histograms of the model instead
+	slotsCount := int(horizon/p.cfg.SeasonalSlotDuration) + 1
+	if slotsCount > p.seasonalSlots {
+		slotsCount = p.seasonalSlots
+	}
+	cpuHistograms := make([]histogram.Histogram, 0, slotsCount)
+	memoryHistograms := make([]histogram.Histogram, 0, slotsCount)
+	currentSlot := p.seasonalSlot(p.clock.Now())
+	for i := 0; i < slotsCount; i++ {
+		slot := (currentSlot + i) % p.seasonalSlots
+		if model.SeasonalCPU[slot].IsEmpty() || model.SeasonalMemory[slot].IsEmpty() {
+			cpuHistograms = append(cpuHistograms, model.CPU)
+			memoryHistograms = append(memoryHistograms, model.Memory)
+			continue
+		}
+		cpuHistograms = append(cpuHistograms, model.SeasonalCPU[slot])
+		memoryHistograms = append(memoryHistograms, model.SeasonalMemory[slot])
+	}
+
+	return newPercentileResult(maxPercentileFn(cpuHistograms), maxPercentileFn(memoryHistograms)), nil
+}
+
+// maxPercentileFn returns the percentile function which takes the maximum percentile of the histograms.
+func maxPercentileFn(histograms []histogram.Histogram) func(float64) float64 {
+	return func(percentile float64) float64 {
+		peak := 0.0
+		for _, h := range histograms {
+			peak = math.Max(peak, h.Percentile(percentile))
+		}
+		return peak
+	}
+}
+
+func newPercentileResult(cpuPercentileFn, memoryPercentileFn func(float64) float64) Result {
 	return Result{
 		Data: map[string]v1.ResourceList{
 			"p60": {
-				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(model.CPU.Percentile(0.6)*1000.0), resource.DecimalSI),
-				v1.ResourceMemory: *resource.NewQuantity(int64(model.Memory.Percentile(0.6)), resource.BinarySI),
+				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpuPercentileFn(0.6)*1000.0), resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(int64(memoryPercentileFn(0.6)), resource.BinarySI),
 			},
 			"p90": {
-				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(model.CPU.Percentile(0.9)*1000.0), resource.DecimalSI),
-				v1.ResourceMemory: *resource.NewQuantity(int64(model.Memory.Percentile(0.9)), resource.BinarySI),
+				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpuPercentileFn(0.9)*1000.0), resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(int64(memoryPercentileFn(0.9)), resource.BinarySI),
 			},
 			"p95": {
-				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(model.CPU.Percentile(0.95)*1000.0), resource.DecimalSI),
-				v1.ResourceMemory: *resource.NewQuantity(int64(model.Memory.Percentile(0.95)), resource.BinarySI),
+				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpuPercentileFn(0.95)*1000.0), resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(int64(memoryPercentileFn(0.95)), resource.BinarySI),
 			},
 			"p98": {
-				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(model.CPU.Percentile(0.98)*1000.0), resource.DecimalSI),
-				v1.ResourceMemory: *resource.NewQuantity(int64(model.Memory.Percentile(0.98)), resource.BinarySI),
+				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpuPercentileFn(0.98)*1000.0), resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(int64(memoryPercentileFn(0.98)), resource.BinarySI),
 			},
 			"max": {
-				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(model.CPU.Percentile(1.0)*1000.0), resource.DecimalSI),
-				v1.ResourceMemory: *resource.NewQuantity(int64(model.Memory.Percentile(1.0)), resource.BinarySI),
+				v1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpuPercentileFn(1.0)*1000.0), resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(int64(memoryPercentileFn(1.0)), resource.BinarySI),
 			},
 		},
-	}, nil
+	}
 }
 
 func (p *peakPredictServer) gcModels() {
@@ -342,6 +467,19 @@ func (p *peakPredictServer) doCheckpoint() {
 		pair.Model.Lock.Lock()
 		ckpt.CPU, _ = pair.Model.CPU.SaveToCheckpoint()
 		ckpt.Memory, _ = pair.Model.Memory.SaveToCheckpoint()
+		if len(pair.Model.SeasonalCPU) > 0 {
+			ckpt.SeasonalCPU = make([]*histogram.HistogramCheckpoint, len(pair.Model.SeasonalCPU))
+			ckpt.SeasonalMemory = make([]*histogram.HistogramCheckpoint, len(pair.Model.SeasonalMemory))
+			for i := range pair.Model.SeasonalCPU {
+				// the slots not trained yet are left as nil
+				if !pair.Model.SeasonalCPU[i].IsEmpty() {
+					ckpt.SeasonalCPU[i], _ = pair.Model.SeasonalCPU[i].SaveToCheckpoint()
+				}
+				if !pair.Model.SeasonalMemory[i].IsEmpty() {
+					ckpt.SeasonalMemory[i], _ = pair.Model.SeasonalMemory[i].SaveToCheckpoint()
+				}
+			}
+		}
 		pair.Model.Lock.Unlock()
 
 		err := p.checkpointer.Save(ckpt)
@@ -400,6 +538,10 @@ func (p *peakPredictServer) restoreModels() (unknownUIDs []UIDType) {
 		if err := model.Memory.LoadFromCheckpoint(checkpoint.Memory); err != nil {
 			klog.Errorf("failed to Memory checkpoint %v, err %v", checkpoint.UID, err)
 		}
+		if p.isSeasonalModel(checkpoint.UID) {
+			model.SeasonalCPU, model.SeasonalMemory = p.seasonalHistograms()
+			p.restoreSeasonalHistograms(model, checkpoint)
+		}
 		klog.InfoS("restoring checkpoint", "uid", checkpoint.UID, "lastUpdated", checkpoint.LastUpdated)
 		p.modelsLock.Lock()
 		p.models[checkpoint.UID] = model
@@ -409,6 +551,30 @@ func (p *peakPredictServer) restoreModels() (unknownUIDs []UIDType) {
 	return unknownUIDs
 }
 
+// restoreSeasonalHistograms loads the seasonal histograms from the checkpoint. The checkpoint is ignored if the
+// number of slots mismatches, e.g. the seasonal period is changed.
+func (p *peakPredictServer) restoreSeasonalHistograms(model *PredictModel, checkpoint *ModelCheckpoint) {
+	if len(checkpoint.SeasonalCPU) != p.seasonalSlots || len(checkpoint.SeasonalMemory) != p.seasonalSlots {
+		if len(checkpoint.SeasonalCPU) > 0 {
+			klog.InfoS("skip restoring seasonal checkpoint since the slots mismatch", "uid", checkpoint.UID,
+				"slots", p.seasonalSlots, "checkpointSlots", len(checkpoint.SeasonalCPU))
+		}
+		return
+	}
+	for i := 0; i < p.seasonalSlots; i++ {
+		if checkpoint.SeasonalCPU[i] != nil {
+			if err := model.SeasonalCPU[i].LoadFromCheckpoint(checkpoint.SeasonalCPU[i]); err != nil {
+				klog.Errorf("failed to seasonal CPU checkpoint %v of slot %d, err %v", checkpoint.UID, i, err)
+			}
+		}
+		if checkpoint.SeasonalMemory[i] != nil {
+			if err := model.SeasonalMemory[i].LoadFromCheckpoint(checkpoint.SeasonalMemory[i]); err != nil {
+				klog.Errorf("failed to seasonal Memory checkpoint %v of slot %d, err %v", checkpoint.UID, i, err)
+			}
+		}
+	}
+}
+
 type PredictMetric struct {
 	LastCPUUsage    float64
 	LastMemoryUsage float64
@@ -30,6 +30,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/util/histogram"
 )
 
@@ -529,3 +531,115 @@ func TestDoCheckpoint_RestoreModels(t *testing.T) {
 	unknownUIDs := predictServer.restoreModels()
 	assert.Equal(t, 1, len(unknownUIDs), "unknown uids")
 }
+
+func newTestSeasonalPredictServer(t *testing.T, now time.Time, checkpointer Checkpointer) *peakPredictServer {
+	cfg := NewDefaultConfig()
+	cfg.ModelCheckpointMaxPerStep = 20
+	err := features.DefaultMutableKoordletFeatureGate.SetFromMap(map[string]bool{string(features.SeasonalPrediction): true})
+	assert.NoError(t, err)
+	defer func() {
+		err = features.DefaultMutableKoordletFeatureGate.SetFromMap(map[string]bool{string(features.SeasonalPrediction): false})
+		assert.NoError(t, err)
+	}()
+	return &peakPredictServer{
+		cfg:           cfg,
+		hasSynced:     &atomic.Bool{},
+		informer:      &mockInformer{Node: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "node1"}}},
+		uidGenerator:  &generator{},
+		clock:         clock.NewFakeClock(now),
+		models:        make(map[UIDType]*PredictModel),
+		seasonalSlots: getSeasonalSlots(cfg),
+		checkpointer:  checkpointer,
+	}
+}
+
+func TestPredictServerSeasonalPrediction(t *testing.T) {
+	// the node is busy from 8 a.m. to 8 p.m.
+	day := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	predictServer := newTestSeasonalPredictServer(t, day, &mockCheckpointer{})
+	assert.Equal(t, 24, predictServer.seasonalSlots)
+	mockClock := predictServer.clock.(*clock.FakeClock)
+	metricServer := &mockMetricServer{NodeMemoryUsage: 1 << 30}
+	predictServer.metricServer = metricServer
+
+	for ts := day; ts.Before(day.Add(24 * time.Hour)); ts = ts.Add(10 * time.Minute) {
+		mockClock.SetTime(ts)
+		metricServer.NodeCPUUsage = 1
+		if ts.Hour() >= 8 && ts.Hour() < 20 {
+			metricServer.NodeCPUUsage = 10
+		}
+		predictServer.training()
+	}
+
+	gen := &generator{}
+	nodeModel := predictServer.models[gen.Node()]
+	assert.Equal(t, 24, len(nodeModel.SeasonalCPU))
+	assert.Equal(t, 24, len(predictServer.models[gen.NodeItem(SystemItemID)].SeasonalCPU))
+	assert.Equal(t, 24, len(predictServer.models[gen.NodeItem(string(extension.PriorityProd))].SeasonalCPU))
+
+	// the overall peak is the busy usage
+	peak, err := predictServer.GetPrediction(MetricDesc{UID: gen.Node()})
+	assert.NoError(t, err)
+	p95CPU := peak.Data["p95"][v1.ResourceCPU]
+	assert.InDelta(t, 10000, p95CPU.MilliValue(), 1000)
+
+	// the peak of the next 6 hours at midnight is the idle usage
+	mockClock.SetTime(day.Add(24 * time.Hour))
+	peak, err = predictServer.GetSeasonalPrediction(MetricDesc{UID: gen.Node()}, 6*time.Hour)
+	assert.NoError(t, err)
+	p95CPU = peak.Data["p95"][v1.ResourceCPU]
+	assert.InDelta(t, 1000, p95CPU.MilliValue(), 100)
+
+	// the peak of the next 12 hours at midnight covers the busy hours
+	peak, err = predictServer.GetSeasonalPrediction(MetricDesc{UID: gen.Node()}, 12*time.Hour)
+	assert.NoError(t, err)
+	p95CPU = peak.Data["p95"][v1.ResourceCPU]
+	assert.InDelta(t, 10000, p95CPU.MilliValue(), 1000)
+
+	// the slots not trained fall back to the overall histogram
+	predictServer.models[gen.Node()].SeasonalCPU[1], predictServer.models[gen.Node()].SeasonalMemory[1] =
+		makeTestHistogram(), makeTestHistogram()
+	peak, err = predictServer.GetSeasonalPrediction(MetricDesc{UID: gen.Node()}, 3*time.Hour)
+	assert.NoError(t, err)
+	p95CPU = peak.Data["p95"][v1.ResourceCPU]
+	assert.InDelta(t, 10000, p95CPU.MilliValue(), 1000)
+
+	// pods have no seasonal model
+	_, err = predictServer.GetSeasonalPrediction(MetricDesc{UID: "pod1"}, 6*time.Hour)
+	assert.Error(t, err)
+}
+
+func TestPredictServerSeasonalCheckpoint(t *testing.T) {
+	tempDir := t.TempDir()
+	now := time.Date(2023, 6, 1, 3, 0, 0, 0, time.UTC)
+	predictServer := newTestSeasonalPredictServer(t, now, NewFileCheckpointer(tempDir))
+	predictServer.metricServer = &mockMetricServer{NodeCPUUsage: 4, NodeMemoryUsage: 1 << 30}
+	predictServer.training()
+	predictServer.doCheckpoint()
+
+	gen := &generator{}
+	predictServer.models = make(map[UIDType]*PredictModel)
+	unknownUIDs := predictServer.restoreModels()
+	assert.Equal(t, 0, len(unknownUIDs))
+	nodeModel := predictServer.models[gen.Node()]
+	assert.Equal(t, 24, len(nodeModel.SeasonalCPU))
+	assert.False(t, nodeModel.SeasonalCPU[3].IsEmpty())
+	assert.True(t, nodeModel.SeasonalCPU[4].IsEmpty())
+
+	peak, err := predictServer.GetSeasonalPrediction(MetricDesc{UID: gen.Node()}, 0)
+	assert.NoError(t, err)
+	p95CPU := peak.Data["p95"][v1.ResourceCPU]
+	assert.InDelta(t, 4000, p95CPU.MilliValue(), 400)
+
+	// the seasonal checkpoints are dropped when the slots mismatch
+	predictServer.cfg.SeasonalSlotDuration = 30 * time.Minute
+	predictServer.seasonalSlots = 48
+	predictServer.models = make(map[UIDType]*PredictModel)
+	predictServer.restoreModels()
+	nodeModel = predictServer.models[gen.Node()]
+	assert.Equal(t, 48, len(nodeModel.SeasonalCPU))
+	for i := range nodeModel.SeasonalCPU {
+		assert.True(t, nodeModel.SeasonalCPU[i].IsEmpty())
+	}
+	assert.False(t, nodeModel.CPU.IsEmpty())
+}
@@ -21,24 +21,26 @@ import (
 )
 
 type Config struct {
-	ReconcileIntervalSeconds   int
-	CPUSuppressIntervalSeconds int
-	CPUEvictIntervalSeconds    int
-	MemoryEvictIntervalSeconds int
-	MemoryEvictCoolTimeSeconds int
-	CPUEvictCoolTimeSeconds    int
-	QOSExtensionCfg            *QOSExtensionConfig
+	ReconcileIntervalSeconds      int
+	CPUSuppressIntervalSeconds    int
+	CPUEvictIntervalSeconds       int
+	MemoryEvictIntervalSeconds    int
+	MemoryEvictCoolTimeSeconds    int
+	CPUEvictCoolTimeSeconds       int
+	MemoryThrottleIntervalSeconds int
+	QOSExtensionCfg               *QOSExtensionConfig
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		ReconcileIntervalSeconds:   1,
-		CPUSuppressIntervalSeconds: 1,
-		CPUEvictIntervalSeconds:    1,
-		MemoryEvictIntervalSeconds: 1,
-		MemoryEvictCoolTimeSeconds: 4,
-		CPUEvictCoolTimeSeconds:    20,
-		QOSExtensionCfg:            &QOSExtensionConfig{FeatureGates: map[string]bool{}},
+		ReconcileIntervalSeconds:      1,
+		CPUSuppressIntervalSeconds:    1,
+		CPUEvictIntervalSeconds:       1,
+		MemoryEvictIntervalSeconds:    1,
+		MemoryEvictCoolTimeSeconds:    4,
+		CPUEvictCoolTimeSeconds:       20,
+		MemoryThrottleIntervalSeconds: 10,
+		QOSExtensionCfg:               &QOSExtensionConfig{FeatureGates: map[string]bool{}},
 	}
 }
 
@@ -49,5 +51,6 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.MemoryEvictIntervalSeconds, "memory-evict-interval-seconds", c.MemoryEvictIntervalSeconds, "evict be pod(memory) interval by seconds")
 	fs.IntVar(&c.MemoryEvictCoolTimeSeconds, "memory-evict-cool-time-seconds", c.MemoryEvictCoolTimeSeconds, "cooling time: memory next evict time should after lastEvictTime + MemoryEvictCoolTimeSeconds")
 	fs.IntVar(&c.CPUEvictCoolTimeSeconds, "cpu-evict-cool-time-seconds", c.CPUEvictCoolTimeSeconds, "cooltime: CPU next evict time should after lastEvictTime + CPUEvictCoolTimeSeconds")
+	fs.IntVar(&c.MemoryThrottleIntervalSeconds, "memory-throttle-interval-seconds", c.MemoryThrottleIntervalSeconds, "throttle be pod memory by the memory pressure interval by seconds")
 	c.QOSExtensionCfg.InitFlags(fs)
 }
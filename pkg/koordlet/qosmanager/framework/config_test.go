@@ -25,13 +25,14 @@ import (
 
 func Test_NewDefaultConfig(t *testing.T) {
 	expectConfig := &Config{
-		ReconcileIntervalSeconds:   1,
-		CPUSuppressIntervalSeconds: 1,
-		CPUEvictIntervalSeconds:    1,
-		MemoryEvictIntervalSeconds: 1,
-		MemoryEvictCoolTimeSeconds: 4,
-		CPUEvictCoolTimeSeconds:    20,
-		QOSExtensionCfg:            &QOSExtensionConfig{FeatureGates: map[string]bool{}},
+		ReconcileIntervalSeconds:      1,
+		CPUSuppressIntervalSeconds:    1,
+		CPUEvictIntervalSeconds:       1,
+		MemoryEvictIntervalSeconds:    1,
+		MemoryEvictCoolTimeSeconds:    4,
+		CPUEvictCoolTimeSeconds:       20,
+		MemoryThrottleIntervalSeconds: 10,
+		QOSExtensionCfg:               &QOSExtensionConfig{FeatureGates: map[string]bool{}},
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -46,18 +47,20 @@ func Test_InitFlags(t *testing.T) {
 		"--memory-evict-interval-seconds=2",
 		"--memory-evict-cool-time-seconds=8",
 		"--cpu-evict-cool-time-seconds=40",
+		"--memory-throttle-interval-seconds=5",
 		"--qos-extension-plugins=test-plugin=true",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
 	type fields struct {
-		ReconcileIntervalSeconds   int
-		CPUSuppressIntervalSeconds int
-		CPUEvictIntervalSeconds    int
-		MemoryEvictIntervalSeconds int
-		MemoryEvictCoolTimeSeconds int
-		CPUEvictCoolTimeSeconds    int
-		QOSExtensionCfg            *QOSExtensionConfig
+		ReconcileIntervalSeconds      int
+		CPUSuppressIntervalSeconds    int
+		CPUEvictIntervalSeconds       int
+		MemoryEvictIntervalSeconds    int
+		MemoryEvictCoolTimeSeconds    int
+		CPUEvictCoolTimeSeconds       int
+		MemoryThrottleIntervalSeconds int
+		QOSExtensionCfg               *QOSExtensionConfig
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -70,13 +73,14 @@ func Test_InitFlags(t *testing.T) {
 		{
 			name: "not default",
 			fields: fields{
-				ReconcileIntervalSeconds:   2,
-				CPUSuppressIntervalSeconds: 2,
-				CPUEvictIntervalSeconds:    2,
-				MemoryEvictIntervalSeconds: 2,
-				MemoryEvictCoolTimeSeconds: 8,
-				CPUEvictCoolTimeSeconds:    40,
-				QOSExtensionCfg:            &QOSExtensionConfig{FeatureGates: map[string]bool{"test-plugin": true}},
+				ReconcileIntervalSeconds:      2,
+				CPUSuppressIntervalSeconds:    2,
+				CPUEvictIntervalSeconds:       2,
+				MemoryEvictIntervalSeconds:    2,
+				MemoryEvictCoolTimeSeconds:    8,
+				CPUEvictCoolTimeSeconds:       40,
+				MemoryThrottleIntervalSeconds: 5,
+				QOSExtensionCfg:               &QOSExtensionConfig{FeatureGates: map[string]bool{"test-plugin": true}},
 			},
 			args: args{fs: fs},
 		},
@@ -84,13 +88,14 @@ func Test_InitFlags(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			raw := &Config{
-				ReconcileIntervalSeconds:   tt.fields.ReconcileIntervalSeconds,
-				CPUSuppressIntervalSeconds: tt.fields.CPUSuppressIntervalSeconds,
-				CPUEvictIntervalSeconds:    tt.fields.CPUEvictIntervalSeconds,
-				MemoryEvictIntervalSeconds: tt.fields.MemoryEvictIntervalSeconds,
-				MemoryEvictCoolTimeSeconds: tt.fields.MemoryEvictCoolTimeSeconds,
-				CPUEvictCoolTimeSeconds:    tt.fields.CPUEvictCoolTimeSeconds,
-				QOSExtensionCfg:            tt.fields.QOSExtensionCfg,
+				ReconcileIntervalSeconds:      tt.fields.ReconcileIntervalSeconds,
+				CPUSuppressIntervalSeconds:    tt.fields.CPUSuppressIntervalSeconds,
+				CPUEvictIntervalSeconds:       tt.fields.CPUEvictIntervalSeconds,
+				MemoryEvictIntervalSeconds:    tt.fields.MemoryEvictIntervalSeconds,
+				MemoryEvictCoolTimeSeconds:    tt.fields.MemoryEvictCoolTimeSeconds,
+				CPUEvictCoolTimeSeconds:       tt.fields.CPUEvictCoolTimeSeconds,
+				MemoryThrottleIntervalSeconds: tt.fields.MemoryThrottleIntervalSeconds,
+				QOSExtensionCfg:               tt.fields.QOSExtensionCfg,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
@@ -38,8 +38,7 @@ func KillContainers(pod *corev1.Pod, message string) {
 		}
 
 		if containerID != "" {
-			runtimeType, _, _ := util.ParseContainerId(containerStatus.ContainerID)
-			runtimeHandler, err := runtime.GetRuntimeHandler(runtimeType)
+			runtimeHandler, err := runtime.GetRuntimeHandlerByContainerID(containerStatus.ContainerID)
 			if err != nil || runtimeHandler == nil {
 				klog.Errorf("%s, kill container(%s) error! GetRuntimeHandler fail! error: %v", message, containerStatus.ContainerID, err)
 				continue
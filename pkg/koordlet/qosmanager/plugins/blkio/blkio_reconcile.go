@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -82,7 +83,7 @@ func (b *blkIOReconcile) Run(stopCh <-chan struct{}) {
 type (
 	GetUpdaterFunc      func(block *slov1alpha1.BlockCfg, diskNumber string, dynamicPath string) (resources []resourceexecutor.ResourceUpdater)
 	GetRemoverFunc      func(diskNumber string, dynamicPath string) (resources []resourceexecutor.ResourceUpdater)
-	GetDiskRecorderFunc func(absolutePath string) (map[string]bool, error)
+	GetDiskRecorderFunc func(dynamicPath string) (map[string]bool, error)
 )
 
 func New(opt *framework.Options) framework.QOSStrategy {
@@ -140,12 +141,10 @@ func (b *blkIOReconcile) reconcile() {
 			blocks = strategy.BEClass.BlkIOQOS.Blocks
 		}
 		beClassRelativeDir := util.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
-		beClassPath := util.GetPodCgroupBlkIOAbsoluteDir(corev1.PodQOSBestEffort)
 		err := b.updateBlkIOConfig(
 			blocks,
 			nil,
 			blkioUpdater{
-				absolutePath:    beClassPath,
 				getDiskRecorder: getBlkIORecorder,
 				dynamicPath:     beClassRelativeDir,
 				getUpdaterFunc:  getBlkIOUpdaterFromBlockCfg,
@@ -166,12 +165,10 @@ func (b *blkIOReconcile) reconcile() {
 			blocks = strategy.CgroupRoot.BlkIOQOS.Blocks
 		}
 		rootClassRelativePath := ""
-		rootClassPath := util.GetCgroupRootBlkIOAbsoluteDir()
 		err := b.updateBlkIOConfig(
 			blocks,
 			nil,
 			blkioUpdater{
-				absolutePath:    rootClassPath,
 				getDiskRecorder: getDiskConfigRecorder,
 				dynamicPath:     rootClassRelativePath,
 				getUpdaterFunc:  getDiskConfigUpdaterFromBlockCfg,
@@ -213,7 +210,6 @@ func (b *blkIOReconcile) reconcile() {
 			podBlkIOQoS.Blocks,
 			podMeta,
 			blkioUpdater{
-				absolutePath:    util.GetPodCgroupBlkIOAbsolutePath(podMeta.CgroupDir),
 				dynamicPath:     podMeta.CgroupDir,
 				getDiskRecorder: getBlkIORecorder,
 				getUpdaterFunc:  getBlkIOUpdaterFromBlockCfg,
@@ -229,8 +225,7 @@ func (b *blkIOReconcile) reconcile() {
 }
 
 type blkioUpdater struct {
-	dynamicPath  string
-	absolutePath string
+	dynamicPath string
 
 	getDiskRecorder GetDiskRecorderFunc
 	getUpdaterFunc  GetUpdaterFunc
@@ -248,7 +243,7 @@ func (b *blkIOReconcile) updateBlkIOConfig(blocks []*slov1alpha1.BlockCfg, podMe
 		return fmt.Errorf("getUpdaterFunc or getRemoverFunc can not be nil")
 	}
 	var resources []resourceexecutor.ResourceUpdater
-	diskConfigRecorder, err := blkioUpdater.getDiskRecorder(blkioUpdater.absolutePath)
+	diskConfigRecorder, err := blkioUpdater.getDiskRecorder(blkioUpdater.dynamicPath)
 	if err != nil {
 		return fmt.Errorf("fail to get disk config recorder: %s", err.Error())
 	}
@@ -329,6 +324,10 @@ func getBlkIOUpdaterFromBlockCfg(block *slov1alpha1.BlockCfg, diskNumber string,
 
 	}
 
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		return getBlkIOUpdatersV2(diskNumber, dynamicPath, readIOPS, writeIOPS, readBPS, writeBPS, ioweight)
+	}
+
 	readIOPSUpdater, _ := resourceexecutor.NewBlkIOResourceUpdater(
 		system.BlkioTRIopsName,
 		dynamicPath,
@@ -450,10 +449,14 @@ func parseBlkIOResult(blkioResult string) (*slov1alpha1.BlkIOQOS, error) {
 
 // key of recorder is disk number
 // value of recorder means whether to remove cgroup config of this disk
-func getDiskRecorder(parentDir string, fileNames []string) (map[string]bool, error) {
+func getDiskRecorder(dynamicPath string, resourceTypes []system.ResourceType) (map[string]bool, error) {
 	recorder := make(map[string]bool)
-	for _, fileName := range fileNames {
-		diskNumbers, err := getDiskNumbersFromCgroupFile(filepath.Join(parentDir, fileName))
+	for _, resourceType := range resourceTypes {
+		resource, err := system.GetCgroupResource(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		diskNumbers, err := getDiskNumbersFromCgroupFile(resource.Path(dynamicPath))
 		if err != nil {
 			return nil, err
 		}
@@ -484,26 +487,33 @@ func getDiskNumbersFromCgroupFile(filePath string) ([]string, error) {
 	return diskNumbers, nil
 }
 
-func getBlkIORecorder(path string) (map[string]bool, error) {
-	fileNames := []string{
+func getBlkIORecorder(dynamicPath string) (map[string]bool, error) {
+	resourceTypes := []system.ResourceType{
 		system.BlkioTRIopsName,
 		system.BlkioTRBpsName,
 		system.BlkioTWIopsName,
 		system.BlkioTWBpsName,
 		system.BlkioIOWeightName,
 	}
-	recorder, err := getDiskRecorder(path, fileNames)
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		// io.max merges the read/write bps and iops throttling of cgroups-v1
+		resourceTypes = []system.ResourceType{
+			system.IOMaxName,
+			system.BlkioIOWeightName,
+		}
+	}
+	recorder, err := getDiskRecorder(dynamicPath, resourceTypes)
 	if err != nil {
 		return nil, err
 	}
 	return recorder, nil
 }
 
-func getDiskConfigRecorder(path string) (map[string]bool, error) {
-	fileNames := []string{
+func getDiskConfigRecorder(dynamicPath string) (map[string]bool, error) {
+	resourceTypes := []system.ResourceType{
 		system.BlkioIOQoSName,
 	}
-	recorder, err := getDiskRecorder(path, fileNames)
+	recorder, err := getDiskRecorder(dynamicPath, resourceTypes)
 	if err != nil {
 		return nil, err
 	}
@@ -511,6 +521,9 @@ func getDiskConfigRecorder(path string) (map[string]bool, error) {
 }
 
 func getBlkIORemoverFromDiskNumber(diskNumber string, dynamicPath string) (resources []resourceexecutor.ResourceUpdater) {
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		return getBlkIOUpdatersV2(diskNumber, dynamicPath, DefaultReadIOPS, DefaultWriteIOPS, DefaultReadBPS, DefaultWriteBPS, DefaultIOWeightPercentage)
+	}
 
 	readIOPSUpdater, _ := resourceexecutor.NewBlkIOResourceUpdater(
 		system.BlkioTRIopsName,
@@ -554,6 +567,53 @@ func getBlkIORemoverFromDiskNumber(diskNumber string, dynamicPath string) (resou
 	return
 }
 
+// getBlkIOUpdatersV2 translates the blkio configs into the cgroups-v2 io.max and io.weight,
+// where a zero bps/iops, which means no throttling, is written as "max".
+// dynamicPath for be: kubepods.slice/kubepods-besteffort.slice/
+func getBlkIOUpdatersV2(diskNumber string, dynamicPath string, readIOPS, writeIOPS, readBPS, writeBPS, ioweight int64) (resources []resourceexecutor.ResourceUpdater) {
+	ioMaxValue := fmt.Sprintf("%s rbps=%s wbps=%s riops=%s wiops=%s", diskNumber,
+		formatIOMaxLimit(readBPS), formatIOMaxLimit(writeBPS), formatIOMaxLimit(readIOPS), formatIOMaxLimit(writeIOPS))
+	ioMaxUpdater, _ := resourceexecutor.NewBlkIOResourceUpdater(
+		system.IOMaxName,
+		dynamicPath,
+		ioMaxValue,
+		audit.V(3).Group("blkio").Reason("UpdateBlkIO").Message("update %s/%s to %s", dynamicPath, system.IOMaxName, ioMaxValue),
+	)
+	ioWeightValue := fmt.Sprintf("%s %d", diskNumber, convertIOWeightToV2(ioweight))
+	ioWeightUpdater, _ := resourceexecutor.NewBlkIOResourceUpdater(
+		system.BlkioIOWeightName,
+		dynamicPath,
+		ioWeightValue,
+		audit.V(3).Group("blkio").Reason("UpdateBlkIO").Message("update %s/%s to %s", dynamicPath, system.IOWeightName, ioWeightValue),
+	)
+
+	resources = append(resources,
+		ioMaxUpdater,
+		ioWeightUpdater,
+	)
+
+	return
+}
+
+// convertIOWeightToV2 maps the io weight percentage [1, 100] of cgroups-v1 onto the io.weight range [1, 10000]
+// of cgroups-v2, so that the weight keeps its proportion to the maximum.
+func convertIOWeightToV2(ioweight int64) int64 {
+	if ioweight <= system.IOWeightMinValue {
+		return system.IOWeightMinValueV2
+	}
+	if ioweight >= system.IOWeightMaxValue {
+		return system.IOWeightMaxValueV2
+	}
+	return system.IOWeightMinValueV2 + (ioweight-system.IOWeightMinValue)*(system.IOWeightMaxValueV2-system.IOWeightMinValueV2)/(system.IOWeightMaxValue-system.IOWeightMinValue)
+}
+
+func formatIOMaxLimit(value int64) string {
+	if value <= 0 {
+		return system.CgroupMaxSymbolStr
+	}
+	return strconv.FormatInt(value, 10)
+}
+
 func getDiskConfigRemoverFromDiskNumber(diskNumber string, dynamicPath string) (resources []resourceexecutor.ResourceUpdater) {
 	ioQoSUpdater, _ := resourceexecutor.NewBlkIOResourceUpdater(
 		system.BlkioIOQoSName,
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -179,6 +180,129 @@ func TestBlkIOReconcile_reconcile(t *testing.T) {
 	})
 }
 
+func TestBlkIOReconcile_reconcileCgroupsV2(t *testing.T) {
+	testingNodeSLO := newNodeSLO()
+	pod0 := newPodWithPVC(PodName0, PVCName)
+	testingPodMeta0 := &statesinformer.PodMeta{
+		Pod:       pod0,
+		CgroupDir: filepath.Join(system.CgroupPathFormatter.ParentDir, system.CgroupPathFormatter.QOSDirFn(corev1.PodQOSBestEffort), PodName0),
+	}
+	system.Conf.CgroupKubePath = KubePath
+	localStorageInfo := &metriccache.NodeLocalStorageInfo{
+		DiskNumberMap: map[string]string{
+			"/dev/vda": "253:0",
+			"/dev/vdb": "253:16",
+		},
+		NumberDiskMap: map[string]string{
+			"253:0":  "/dev/vda",
+			"253:16": "/dev/vdb",
+		},
+		VGDiskMap: map[string]string{
+			"yoda-pool0": "/dev/vdb",
+		},
+		LVMapperVGMap: map[string]string{
+			"/dev/mapper/yoda--pool0-yoda--87d8625a--dcc9--47bf--a14a--994cf2971193": "yoda-pool0",
+		},
+		MPDiskMap: map[string]string{
+			fmt.Sprintf("%s/pods/%s/volumes/kubernetes.io~csi/%s/mount", KubePath, pod0.UID, PVName): "/dev/mapper/yoda--pool0-yoda--87d8625a--dcc9--47bf--a14a--994cf2971193",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPodMeta0}).AnyTimes()
+	statesInformer.EXPECT().GetNodeSLO().Return(testingNodeSLO).AnyTimes()
+	statesInformer.EXPECT().GetVolumeName("default", PVCName).Return(PVName).AnyTimes()
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctrl)
+	mockMetricCache.EXPECT().Get(metriccache.NodeLocalStorageInfoKey).Return(localStorageInfo, true).AnyTimes()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	rootClassDir := ""
+	kubepodsDir := system.CgroupPathFormatter.ParentDir
+	beClassDir := filepath.Join(system.CgroupPathFormatter.ParentDir, system.CgroupPathFormatter.QOSDirFn(corev1.PodQOSBestEffort))
+	helper.WriteCgroupFileContents(rootClassDir, system.BlkioIOQoSV2, "253:16 enable=1 ctrl=user rpct=95.00 rlat=2000 wpct=95.00 wlat=2000 min=1.00 max=10000.00")
+	helper.CreateCgroupFile(kubepodsDir, system.BlkioIOMaxV2)
+	helper.WriteCgroupFileContents(kubepodsDir, system.BlkioIOWeightV2, "default 100")
+	helper.WriteCgroupFileContents(beClassDir, system.BlkioIOMaxV2, "253:16 rbps=2048 wbps=2048 riops=max wiops=max")
+	helper.WriteCgroupFileContents(beClassDir, system.BlkioIOWeightV2, "default 100")
+	helper.WriteCgroupFileContents(testingPodMeta0.CgroupDir, system.BlkioIOMaxV2, "253:16 rbps=max wbps=max riops=2048 wiops=2048")
+	helper.WriteCgroupFileContents(testingPodMeta0.CgroupDir, system.BlkioIOWeightV2, "default 100\n253:16 100")
+
+	b := New(&framework.Options{
+		MetricCache:    mockMetricCache,
+		StatesInformer: statesInformer,
+		Config:         framework.NewDefaultConfig(),
+	}).(*blkIOReconcile)
+	b.executor = &resourceexecutor.ResourceUpdateExecutorImpl{
+		Config:        resourceexecutor.NewDefaultConfig(),
+		ResourceCache: cache.NewCacheDefault(),
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, b.init(stop))
+
+	b.reconcile()
+
+	assert.Equal(t, "253:16 enable=1 ctrl=user rlat=1000 wlat=1000", helper.ReadCgroupFileContents(rootClassDir, system.BlkioIOQoSV2))
+	assert.Equal(t, "253:16 rbps=1048576 wbps=1048576 riops=1024 wiops=1024", helper.ReadCgroupFileContents(beClassDir, system.BlkioIOMaxV2))
+	assert.Equal(t, "253:16 3940", helper.ReadCgroupFileContents(beClassDir, system.BlkioIOWeightV2))
+	assert.Equal(t, "253:16 rbps=max wbps=max riops=1024 wiops=512", helper.ReadCgroupFileContents(testingPodMeta0.CgroupDir, system.BlkioIOMaxV2))
+	assert.Equal(t, "253:16 10000", helper.ReadCgroupFileContents(testingPodMeta0.CgroupDir, system.BlkioIOWeightV2))
+}
+
+func Test_getBlkIOUpdaterFromBlockCfg(t *testing.T) {
+	block := &slov1alpha1.BlockCfg{
+		Name:      "/dev/vdb",
+		BlockType: slov1alpha1.BlockTypeDevice,
+		IOCfg: slov1alpha1.IOCfg{
+			ReadIOPS:        pointer.Int64(1024),
+			WriteBPS:        pointer.Int64(1048576),
+			IOWeightPercent: pointer.Int64(60),
+		},
+	}
+	dynamicPath := "kubepods.slice/kubepods-besteffort.slice"
+	tests := []struct {
+		name         string
+		useCgroupsV2 bool
+		want         map[string]string
+	}{
+		{
+			name:         "translate into cgroups-v1 blkio files",
+			useCgroupsV2: false,
+			want: map[string]string{
+				system.BlkioTRIopsName:   "253:16 1024",
+				system.BlkioTRBpsName:    "253:16 0",
+				system.BlkioTWIopsName:   "253:16 0",
+				system.BlkioTWBpsName:    "253:16 1048576",
+				system.BlkioIOWeightName: "253:16 60",
+			},
+		},
+		{
+			name:         "translate into cgroups-v2 io files",
+			useCgroupsV2: true,
+			want: map[string]string{
+				system.IOMaxName:         "253:16 rbps=max wbps=1048576 riops=1024 wiops=max",
+				system.BlkioIOWeightName: "253:16 5960",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.useCgroupsV2)
+
+			got := map[string]string{}
+			for _, updater := range getBlkIOUpdaterFromBlockCfg(block, "253:16", dynamicPath) {
+				got[string(updater.ResourceType())] = updater.Value()
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func newNodeSLO() *slov1alpha1.NodeSLO {
 	return &slov1alpha1.NodeSLO{
 		Spec: slov1alpha1.NodeSLOSpec{
@@ -301,6 +425,45 @@ func newPodWithPVC(podName string, pvcName string) *corev1.Pod {
 	}
 }
 
+func Test_convertIOWeightToV2(t *testing.T) {
+	tests := []struct {
+		name     string
+		ioweight int64
+		want     int64
+	}{
+		{
+			name:     "minimum weight",
+			ioweight: 1,
+			want:     1,
+		},
+		{
+			name:     "default weight",
+			ioweight: DefaultIOWeightPercentage,
+			want:     10000,
+		},
+		{
+			name:     "weight in the range",
+			ioweight: 60,
+			want:     5960,
+		},
+		{
+			name:     "weight below the range",
+			ioweight: 0,
+			want:     1,
+		},
+		{
+			name:     "weight above the range",
+			ioweight: 200,
+			want:     10000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, convertIOWeightToV2(tt.ioweight))
+		})
+	}
+}
+
 func newPodWithEphemeralVolume(podName string) *corev1.Pod {
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
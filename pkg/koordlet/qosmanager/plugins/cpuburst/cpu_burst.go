@@ -521,7 +521,7 @@ func (b *cpuBurst) applyContainerCFSQuota(podMeta *statesinformer.PodMeta, conta
 
 		targetPodCFS := curPodCFS + deltaContainerCFS
 		podCFSValStr := strconv.FormatInt(targetPodCFS, 10)
-		eventHelper := audit.V(3).Pod(podMeta.Pod.Namespace, podMeta.Pod.Name).Reason("CFSQuotaBurst").Message("update pod CFSQuota: %v", podCFSValStr)
+		eventHelper := audit.V(3).PodObject(podMeta.Pod).Reason("CFSQuotaBurst").Message("update pod CFSQuota: %v", podCFSValStr)
 		updater, _ := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.CPUCFSQuotaName, podDir, podCFSValStr, eventHelper)
 		if _, err := b.executor.Update(true, updater); err != nil {
 			return fmt.Errorf("update pod cgroup %v failed, error %v", podMeta.CgroupDir, err)
@@ -615,7 +615,7 @@ func (b *cpuBurst) applyCPUBurst(burstCfg *slov1alpha1.CPUBurstConfig, podMeta *
 
 	podDir := podMeta.CgroupDir
 	podCFSBurstValStr := strconv.FormatInt(podCFSBurstVal, 10)
-	eventHelper := audit.V(3).Pod(podMeta.Pod.Namespace, podMeta.Pod.Name).Reason("CPUBurst").Message("update pod CFSQuota: %v", podCFSBurstValStr)
+	eventHelper := audit.V(3).PodObject(podMeta.Pod).Reason("CPUBurst").Message("update pod CFSQuota: %v", podCFSBurstValStr)
 	updater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.CPUBurstName, podDir, podCFSBurstValStr, eventHelper)
 	if err != nil { // normally cpu burst resource not supported on current system
 		klog.V(5).Infof("get cpu burst updater for pod %s/%s failed, maybe system unsupported, err: %v",
@@ -0,0 +1,320 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memorythrottle
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/helpers"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	MemoryPressureThrottleName = "memoryPressureThrottle"
+
+	defaultPSISomeUpperPercent  = 20
+	defaultPSISomeLowerPercent  = 5
+	defaultPSIFullUpperPercent  = 5
+	defaultPSIFullLowerPercent  = 1
+	defaultStepPercent          = 10
+	defaultMinMemoryHighPercent = 10
+
+	// memoryHighUnlimited means the memory.high of the besteffort cgroup is not throttled
+	memoryHighUnlimited int64 = -1
+)
+
+type throttleAction string
+
+const (
+	throttleActionHold     throttleAction = "hold"
+	throttleActionThrottle throttleAction = "throttle"
+	throttleActionRelax    throttleAction = "relax"
+)
+
+var _ framework.QOSStrategy = &memoryPressureThrottle{}
+
+// memoryPressureThrottle lowers the memory.high of the besteffort cgroup step by step when the memory PSI of the LS
+// pods is above the upper thresholds, and raises it step by step when the PSI is below the lower thresholds. It
+// holds the memory.high between the thresholds to avoid the oscillation.
+type memoryPressureThrottle struct {
+	interval           time.Duration
+	psiCollectInterval time.Duration
+	statesInformer     statesinformer.StatesInformer
+	metricCache        metriccache.MetricCache
+	cgroupReader       resourceexecutor.CgroupReader
+	executor           resourceexecutor.ResourceUpdateExecutor
+}
+
+func New(opt *framework.Options) framework.QOSStrategy {
+	return &memoryPressureThrottle{
+		interval:           time.Duration(opt.Config.MemoryThrottleIntervalSeconds) * time.Second,
+		psiCollectInterval: opt.MetricAdvisorConfig.PSICollectorInterval,
+		statesInformer:     opt.StatesInformer,
+		metricCache:        opt.MetricCache,
+		cgroupReader:       opt.CgroupReader,
+		executor:           resourceexecutor.NewResourceUpdateExecutor(),
+	}
+}
+
+func (m *memoryPressureThrottle) Enabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.BEMemoryPressureThrottle) &&
+		features.DefaultKoordletFeatureGate.Enabled(features.PSICollector) && m.interval > 0
+}
+
+func (m *memoryPressureThrottle) Setup(*framework.Context) {
+}
+
+func (m *memoryPressureThrottle) Run(stopCh <-chan struct{}) {
+	m.executor.Run(stopCh)
+	go wait.Until(m.throttle, m.interval, stopCh)
+}
+
+func (m *memoryPressureThrottle) throttle() {
+	klog.V(5).Infof("starting memory pressure throttle process")
+	defer klog.V(5).Infof("memory pressure throttle process completed")
+
+	nodeSLO := m.statesInformer.GetNodeSLO()
+	if disabled, err := features.IsFeatureDisabled(nodeSLO, features.BEMemoryPressureThrottle); err != nil {
+		klog.Warningf("skip memory pressure throttle, failed to acquire the feature-gate, error: %v", err)
+		return
+	} else if disabled {
+		m.recoverIfNeed()
+		klog.V(5).Infof("skip memory pressure throttle, disabled in NodeSLO")
+		return
+	}
+	strategy := getMergedStrategy(nodeSLO.Spec.ResourceUsedThresholdWithBE.MemoryPressureThrottle)
+
+	node := m.statesInformer.GetNode()
+	if node == nil {
+		klog.Warningf("skip memory pressure throttle, Node is nil")
+		return
+	}
+	memoryCapacity := node.Status.Capacity.Memory().Value()
+	if memoryCapacity <= 0 {
+		klog.Warningf("skip memory pressure throttle, memory capacity(%v) should greater than 0", memoryCapacity)
+		return
+	}
+
+	psiSome, psiFull := m.getLSPodsMemoryPressure()
+	action := getThrottleAction(strategy, psiSome, psiFull)
+	klog.V(5).Infof("memory pressure of LS pods, some %.2f, full %.2f, action %v", psiSome, psiFull, action)
+
+	if action == throttleActionHold {
+		return
+	}
+	// read the memory.high from the cgroup rather than keeping it in memory, since the throttled memory.high remains
+	// after koordlet restarts
+	beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	curMemoryHigh, err := m.readBEMemoryHigh(memoryCapacity)
+	if err != nil {
+		klog.Warningf("skip memory pressure throttle, failed to read memory.high of BE, error: %v", err)
+		return
+	}
+
+	var newMemoryHigh int64
+	switch action {
+	case throttleActionThrottle:
+		memStat, err := m.cgroupReader.ReadMemoryStat(beDir)
+		if err != nil {
+			klog.Warningf("skip memory pressure throttle, failed to read memory stat of BE, error: %v", err)
+			return
+		}
+		newMemoryHigh = calculateThrottledMemoryHigh(curMemoryHigh, memStat.UsageWithPageCache(), memoryCapacity,
+			*strategy.StepPercent, *strategy.MinMemoryHighPercent)
+	case throttleActionRelax:
+		if curMemoryHigh == memoryHighUnlimited {
+			return
+		}
+		newMemoryHigh = calculateRelaxedMemoryHigh(curMemoryHigh, memoryCapacity, *strategy.StepPercent)
+	default:
+		return
+	}
+	if newMemoryHigh == curMemoryHigh {
+		return
+	}
+
+	if err := m.updateBEMemoryHigh(newMemoryHigh); err != nil {
+		klog.Errorf("failed to update memory.high of BE, error: %v", err)
+		return
+	}
+	if action == throttleActionThrottle {
+		_ = audit.V(1).Group(string(corev1.PodQOSBestEffort)).Reason(resourceexecutor.AdjustBEByMemoryPressure).
+			Message("throttle BE group to memory.high: %v, LS memory pressure some %.2f full %.2f", newMemoryHigh, psiSome, psiFull).Do()
+	} else {
+		_ = audit.V(2).Group(string(corev1.PodQOSBestEffort)).Reason(resourceexecutor.AdjustBEByMemoryPressure).
+			Message("relax BE group to memory.high: %v, LS memory pressure some %.2f full %.2f", newMemoryHigh, psiSome, psiFull).Do()
+	}
+	klog.Infof("memory pressure throttle succeeded to update memory.high of BE from %v to %v", curMemoryHigh, newMemoryHigh)
+}
+
+// getLSPodsMemoryPressure returns the max memory PSI some and full avg10 of the LS pods
+func (m *memoryPressureThrottle) getLSPodsMemoryPressure() (float64, float64) {
+	var maxSome, maxFull float64
+	for _, podMeta := range m.statesInformer.GetAllPods() {
+		if podMeta == nil || podMeta.Pod == nil || !isLSPod(podMeta.Pod) {
+			continue
+		}
+		podUID := string(podMeta.Pod.UID)
+		if some, err := m.getPodMemoryPSI(podUID, metriccache.PSIDegreeSome); err == nil {
+			maxSome = math.Max(maxSome, some)
+		} else {
+			klog.V(6).Infof("failed to get memory psi some of pod %s, error: %v", podUID, err)
+		}
+		if full, err := m.getPodMemoryPSI(podUID, metriccache.PSIDegreeFull); err == nil {
+			maxFull = math.Max(maxFull, full)
+		} else {
+			klog.V(6).Infof("failed to get memory psi full of pod %s, error: %v", podUID, err)
+		}
+	}
+	return maxSome, maxFull
+}
+
+func (m *memoryPressureThrottle) getPodMemoryPSI(podUID string, degree metriccache.MetricPropertyValue) (float64, error) {
+	queryMeta, err := metriccache.PodPSIMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.PodPSI(podUID,
+		string(metriccache.PSIResourceMem), string(metriccache.PSIPrecision10), string(degree)))
+	if err != nil {
+		return 0, err
+	}
+	return helpers.CollectPodMetricLast(m.metricCache, queryMeta, m.psiCollectInterval)
+}
+
+// readBEMemoryHigh returns the memory.high of the besteffort cgroup, and memoryHighUnlimited if it is not less than
+// the memory capacity, e.g. "max".
+func (m *memoryPressureThrottle) readBEMemoryHigh(memoryCapacity int64) (int64, error) {
+	beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	memoryHigh, err := m.cgroupReader.ReadMemoryHigh(beDir)
+	if err != nil {
+		return memoryHighUnlimited, err
+	}
+	if memoryHigh < 0 || (memoryCapacity > 0 && memoryHigh >= memoryCapacity) {
+		return memoryHighUnlimited, nil
+	}
+	return memoryHigh, nil
+}
+
+func (m *memoryPressureThrottle) updateBEMemoryHigh(memoryHigh int64) error {
+	value := memoryHigh
+	if memoryHigh == memoryHighUnlimited {
+		value = math.MaxInt64 // writing MaxInt64 is equal to write "max"
+	}
+	beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	eventHelper := audit.V(3).Group(string(corev1.PodQOSBestEffort)).Reason(resourceexecutor.AdjustBEByMemoryPressure).Message("update BE group to memory.high: %v", value)
+	updater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.MemoryHighName, beDir, strconv.FormatInt(value, 10), eventHelper)
+	if err != nil {
+		return err
+	}
+	_, err = m.executor.Update(true, updater)
+	return err
+}
+
+// recoverIfNeed resets the memory.high of the besteffort cgroup if it is throttled
+func (m *memoryPressureThrottle) recoverIfNeed() {
+	memoryHigh, err := m.readBEMemoryHigh(0)
+	if err != nil {
+		klog.V(4).Infof("skip recovering memory.high of BE, failed to read it, error: %v", err)
+		return
+	}
+	if memoryHigh == memoryHighUnlimited {
+		return
+	}
+	if err := m.updateBEMemoryHigh(memoryHighUnlimited); err != nil {
+		klog.Errorf("failed to recover memory.high of BE, error: %v", err)
+		return
+	}
+	klog.V(4).Infof("memory.high of BE is recovered")
+}
+
+func isLSPod(pod *corev1.Pod) bool {
+	qosClass := extension.GetPodQoSClassWithDefault(pod)
+	return qosClass == extension.QoSLSE || qosClass == extension.QoSLSR || qosClass == extension.QoSLS
+}
+
+func getMergedStrategy(strategy *slov1alpha1.MemoryPressureThrottleStrategy) *slov1alpha1.MemoryPressureThrottleStrategy {
+	merged := strategy.DeepCopy()
+	if merged.PSISomeUpperPercent == nil {
+		merged.PSISomeUpperPercent = pointer.Int64(defaultPSISomeUpperPercent)
+	}
+	if merged.PSISomeLowerPercent == nil {
+		merged.PSISomeLowerPercent = pointer.Int64(defaultPSISomeLowerPercent)
+	}
+	if merged.PSIFullUpperPercent == nil {
+		merged.PSIFullUpperPercent = pointer.Int64(defaultPSIFullUpperPercent)
+	}
+	if merged.PSIFullLowerPercent == nil {
+		merged.PSIFullLowerPercent = pointer.Int64(defaultPSIFullLowerPercent)
+	}
+	if merged.StepPercent == nil {
+		merged.StepPercent = pointer.Int64(defaultStepPercent)
+	}
+	if merged.MinMemoryHighPercent == nil {
+		merged.MinMemoryHighPercent = pointer.Int64(defaultMinMemoryHighPercent)
+	}
+	return merged
+}
+
+// getThrottleAction throttles if any PSI reaches the upper threshold, relaxes if all PSI are below the lower
+// thresholds, otherwise holds.
+func getThrottleAction(strategy *slov1alpha1.MemoryPressureThrottleStrategy, psiSome, psiFull float64) throttleAction {
+	if psiSome >= float64(*strategy.PSISomeUpperPercent) || psiFull >= float64(*strategy.PSIFullUpperPercent) {
+		return throttleActionThrottle
+	}
+	if psiSome < float64(*strategy.PSISomeLowerPercent) && psiFull < float64(*strategy.PSIFullLowerPercent) {
+		return throttleActionRelax
+	}
+	return throttleActionHold
+}
+
+// calculateThrottledMemoryHigh lowers the memory.high by the step from the current memory.high or the BE usage,
+// whichever is smaller, and no less than the min memory.high.
+func calculateThrottledMemoryHigh(curMemoryHigh, beUsage, memoryCapacity, stepPercent, minPercent int64) int64 {
+	base := beUsage
+	if curMemoryHigh != memoryHighUnlimited && curMemoryHigh < base {
+		base = curMemoryHigh
+	}
+	memoryHigh := base * (100 - stepPercent) / 100
+	minMemoryHigh := memoryCapacity * minPercent / 100
+	if memoryHigh < minMemoryHigh {
+		memoryHigh = minMemoryHigh
+	}
+	return memoryHigh / system.PageSize * system.PageSize
+}
+
+// calculateRelaxedMemoryHigh raises the memory.high by the step of the node memory capacity, and removes the limit
+// when it reaches the capacity.
+func calculateRelaxedMemoryHigh(curMemoryHigh, memoryCapacity, stepPercent int64) int64 {
+	memoryHigh := curMemoryHigh + memoryCapacity*stepPercent/100
+	if memoryHigh >= memoryCapacity {
+		return memoryHighUnlimited
+	}
+	return memoryHigh / system.PageSize * system.PageSize
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memorythrottle
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_getThrottleAction(t *testing.T) {
+	strategy := getMergedStrategy(&slov1alpha1.MemoryPressureThrottleStrategy{Enable: pointer.Bool(true)})
+	tests := []struct {
+		name    string
+		psiSome float64
+		psiFull float64
+		want    throttleAction
+	}{
+		{
+			name:    "some reaches upper",
+			psiSome: 20,
+			want:    throttleActionThrottle,
+		},
+		{
+			name:    "full reaches upper",
+			psiSome: 10,
+			psiFull: 6,
+			want:    throttleActionThrottle,
+		},
+		{
+			name:    "between the thresholds",
+			psiSome: 10,
+			psiFull: 0,
+			want:    throttleActionHold,
+		},
+		{
+			name:    "full between the thresholds",
+			psiSome: 1,
+			psiFull: 2,
+			want:    throttleActionHold,
+		},
+		{
+			name:    "below lower",
+			psiSome: 1,
+			psiFull: 0.5,
+			want:    throttleActionRelax,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getThrottleAction(strategy, tt.psiSome, tt.psiFull))
+		})
+	}
+}
+
+func Test_calculateMemoryHigh(t *testing.T) {
+	capacity := int64(100 << 30)
+	// unlimited, lowered from the usage
+	assert.Equal(t, int64(18<<30), calculateThrottledMemoryHigh(memoryHighUnlimited, 20<<30, capacity, 10, 10))
+	// lowered from the current memory.high
+	assert.Equal(t, int64(9<<30)/system.PageSize*system.PageSize, calculateThrottledMemoryHigh(10<<30, 20<<30, capacity, 10, 5))
+	// no less than the min
+	assert.Equal(t, int64(10<<30), calculateThrottledMemoryHigh(10<<30, 20<<30, capacity, 10, 10))
+
+	assert.Equal(t, int64(20<<30), calculateRelaxedMemoryHigh(10<<30, capacity, 10))
+	assert.Equal(t, memoryHighUnlimited, calculateRelaxedMemoryHigh(95<<30, capacity, 10))
+}
+
+func Test_memoryPressureThrottle(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.SetCgroupsV2(false)
+	beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	helper.WriteCgroupFileContents(beDir, system.MemoryStat, `
+total_cache 4294967296
+total_rss 17179869184
+total_inactive_anon 17179869184
+total_active_anon 0
+total_inactive_file 4294967296
+total_active_file 0
+total_unevictable 0
+`)
+	// memory.high is supported if the file exists in the kubepods cgroup
+	helper.WriteCgroupFileContents(system.CgroupPathFormatter.ParentDir, system.MemoryHigh, strconv.FormatInt(math.MaxInt64, 10))
+	helper.WriteCgroupFileContents(beDir, system.MemoryHigh, strconv.FormatInt(math.MaxInt64, 10))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cfg := metriccache.NewDefaultConfig()
+	cfg.TSDBPath = t.TempDir()
+	cfg.TSDBEnablePromMetrics = false
+	metricCache, err := metriccache.NewMetricCache(cfg)
+	assert.NoError(t, err)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("100Gi")},
+		},
+	}
+	lsPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "ls-pod",
+			UID:    "ls-pod-uid",
+			Labels: map[string]string{apiext.LabelPodQoS: string(apiext.QoSLS)},
+		},
+	}
+	bePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "be-pod",
+			UID:    "be-pod-uid",
+			Labels: map[string]string{apiext.LabelPodQoS: string(apiext.QoSBE)},
+		},
+	}
+	nodeSLO := &slov1alpha1.NodeSLO{
+		Spec: slov1alpha1.NodeSLOSpec{
+			ResourceUsedThresholdWithBE: &slov1alpha1.ResourceThresholdStrategy{
+				Enable: pointer.Bool(true),
+				MemoryPressureThrottle: &slov1alpha1.MemoryPressureThrottleStrategy{
+					Enable: pointer.Bool(true),
+				},
+			},
+		},
+	}
+	si := mock_statesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetNode().Return(node).AnyTimes()
+	si.EXPECT().GetNodeSLO().DoAndReturn(func() *slov1alpha1.NodeSLO { return nodeSLO }).AnyTimes()
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{Pod: lsPod}, {Pod: bePod}}).AnyTimes()
+
+	// the samples are appended with increasing timestamps to make the latest one as the last
+	sampleTime := time.Now().Add(-10 * time.Second)
+	appendPSI := func(podUID string, some, full float64) {
+		sampleTime = sampleTime.Add(time.Second)
+		now := sampleTime
+		someSample, err := metriccache.PodPSIMetric.GenerateSample(metriccache.MetricPropertiesFunc.PodPSI(podUID,
+			string(metriccache.PSIResourceMem), string(metriccache.PSIPrecision10), string(metriccache.PSIDegreeSome)), now, some)
+		assert.NoError(t, err)
+		fullSample, err := metriccache.PodPSIMetric.GenerateSample(metriccache.MetricPropertiesFunc.PodPSI(podUID,
+			string(metriccache.PSIResourceMem), string(metriccache.PSIPrecision10), string(metriccache.PSIDegreeFull)), now, full)
+		assert.NoError(t, err)
+		appender := metricCache.Appender()
+		assert.NoError(t, appender.Append([]metriccache.MetricSample{someSample, fullSample}))
+		assert.NoError(t, appender.Commit())
+	}
+
+	m := &memoryPressureThrottle{
+		interval:           time.Second,
+		psiCollectInterval: 10 * time.Second,
+		statesInformer:     si,
+		metricCache:        metricCache,
+		cgroupReader:       resourceexecutor.NewCgroupReader(),
+		executor:           resourceexecutor.NewTestResourceExecutor(),
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	m.executor.Run(stopCh)
+	getMemoryHigh := func() int64 {
+		memoryHigh, err := m.readBEMemoryHigh(node.Status.Capacity.Memory().Value())
+		assert.NoError(t, err)
+		return memoryHigh
+	}
+
+	// the pressure of BE pods is ignored
+	appendPSI(string(bePod.UID), 50, 50)
+	m.throttle()
+	assert.Equal(t, memoryHighUnlimited, getMemoryHigh())
+
+	// LS pods under pressure, lowered from the usage with page cache
+	appendPSI(string(lsPod.UID), 30, 2)
+	m.throttle()
+	assert.Equal(t, int64(18<<30), getMemoryHigh())
+	assert.Equal(t, strconv.FormatInt(18<<30, 10), helper.ReadCgroupFileContents(beDir, system.MemoryHigh))
+
+	// keep throttling
+	m.throttle()
+	wantMemoryHigh := int64(18<<30) * 90 / 100 / system.PageSize * system.PageSize
+	assert.Equal(t, wantMemoryHigh, getMemoryHigh())
+
+	// hold between the thresholds
+	appendPSI(string(lsPod.UID), 10, 0)
+	m.throttle()
+	assert.Equal(t, wantMemoryHigh, getMemoryHigh())
+
+	// relax
+	appendPSI(string(lsPod.UID), 1, 0)
+	m.throttle()
+	wantMemoryHigh = wantMemoryHigh + int64(10<<30)
+	assert.Equal(t, wantMemoryHigh, getMemoryHigh())
+	assert.Equal(t, strconv.FormatInt(wantMemoryHigh, 10), helper.ReadCgroupFileContents(beDir, system.MemoryHigh))
+
+	// koordlet restarts while BE is throttled, keep relaxing from the memory.high in the cgroup
+	m = &memoryPressureThrottle{
+		interval:           time.Second,
+		psiCollectInterval: 10 * time.Second,
+		statesInformer:     si,
+		metricCache:        metricCache,
+		cgroupReader:       resourceexecutor.NewCgroupReader(),
+		executor:           resourceexecutor.NewTestResourceExecutor(),
+	}
+	m.executor.Run(stopCh)
+	appendPSI(string(lsPod.UID), 1, 0)
+	m.throttle()
+	wantMemoryHigh = wantMemoryHigh + int64(10<<30)
+	assert.Equal(t, wantMemoryHigh, getMemoryHigh())
+
+	// recover when disabled
+	nodeSLO = nodeSLO.DeepCopy()
+	nodeSLO.Spec.ResourceUsedThresholdWithBE.MemoryPressureThrottle.Enable = pointer.Bool(false)
+	m.throttle()
+	assert.Equal(t, memoryHighUnlimited, getMemoryHigh())
+	assert.Equal(t, strconv.FormatInt(math.MaxInt64, 10), helper.ReadCgroupFileContents(beDir, system.MemoryHigh))
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netqos
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	NetQoSReconcileName = "NetQoSReconcile"
+
+	// minor handles of the leaf classes under the root htb class
+	lsrClassMinor     uint16 = 2
+	lsClassMinor      uint16 = 3
+	beClassMinor      uint16 = 4
+	defaultClassMinor uint16 = 5
+
+	// minClassRate is the minimal guaranteed rate of a class, since the htb class does not accept a zero rate.
+	minClassRate uint64 = 8 * 1000
+)
+
+var _ framework.QOSStrategy = &netQoSReconcile{}
+
+// netQoSReconcile enforces the egress bandwidth guarantees (EgressRequest) and limits (EgressLimit) of the LSR, LS
+// and BE classes with a htb hierarchy on the link of the default route. The packets of the pods in the host network
+// are classified by the net_cls.classid of the pod and container cgroups, so it requires the net_cls subsystem of
+// cgroups-v1. The packets forwarded from the other pods lose their cgroups, and are classified by the pod IPs, so they
+// are classified only if the CNI neither masquerades nor encapsulates the pod IPs before the link.
+// The ingress fields are not enforced yet.
+type netQoSReconcile struct {
+	reconcileInterval time.Duration
+	statesInformer    statesinformer.StatesInformer
+	executor          resourceexecutor.ResourceUpdateExecutor
+	tcExecutor        TCExecutor
+	// cleanedUp is true when no htb hierarchy is left on the links after the network qos is disabled.
+	// The htb hierarchy is recognized from the kernel state, so it is cleaned up after koordlet restarts.
+	cleanedUp bool
+}
+
+func New(opt *framework.Options) framework.QOSStrategy {
+	return &netQoSReconcile{
+		reconcileInterval: time.Duration(opt.Config.ReconcileIntervalSeconds) * time.Second,
+		statesInformer:    opt.StatesInformer,
+		executor:          resourceexecutor.NewResourceUpdateExecutor(),
+		tcExecutor:        NewTCExecutor(),
+	}
+}
+
+// Enabled does not check the feature-gate NetQoSReconcile, since the htb hierarchy applied before should be cleaned up
+// after the feature-gate is disabled.
+func (n *netQoSReconcile) Enabled() bool {
+	return n.reconcileInterval > 0
+}
+
+func (n *netQoSReconcile) Setup(context *framework.Context) {
+}
+
+func (n *netQoSReconcile) Run(stopCh <-chan struct{}) {
+	n.init(stopCh)
+	go wait.Until(n.reconcile, n.reconcileInterval, stopCh)
+}
+
+func (n *netQoSReconcile) init(stopCh <-chan struct{}) {
+	n.executor.Run(stopCh)
+}
+
+func (n *netQoSReconcile) reconcile() {
+	if !features.DefaultKoordletFeatureGate.Enabled(features.NetQoSReconcile) {
+		n.cleanupAll()
+		return
+	}
+	nodeSLO := n.statesInformer.GetNodeSLO()
+	if nodeSLO == nil || nodeSLO.Spec.ResourceQOSStrategy == nil {
+		klog.Warningf("%s: nodeSLO or resourceQOSStrategy is nil, skip reconcile network qos!", NetQoSReconcileName)
+		return
+	}
+	strategy := nodeSLO.Spec.ResourceQOSStrategy
+
+	if !isNetQoSEnabled(strategy) {
+		n.cleanupAll()
+		return
+	}
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		klog.V(4).Infof("%s: net_cls is not supported on cgroups-v2, skip reconcile network qos", NetQoSReconcileName)
+		return
+	}
+
+	linkName, err := n.tcExecutor.GetDefaultLink()
+	if err != nil {
+		klog.Errorf("%s: failed to get the default link, err: %v", NetQoSReconcileName, err)
+		return
+	}
+	// the default route may be moved to another link
+	n.cleanup(linkName)
+
+	totalRate, err := getTotalBandwidth(nodeSLO.Spec.SystemStrategy, linkName)
+	if err != nil {
+		klog.Errorf("%s: failed to get the total bandwidth of link %s, err: %v", NetQoSReconcileName, linkName, err)
+		return
+	}
+
+	podMetas := n.statesInformer.GetAllPods()
+	htbConfig := calculateHTBConfig(strategy, totalRate)
+	htbConfig.SourceIPClasses = calculateSourceIPClasses(podMetas)
+	if err = n.tcExecutor.EnsureHTB(linkName, htbConfig); err != nil {
+		klog.Errorf("%s: failed to ensure htb of link %s, err: %v", NetQoSReconcileName, linkName, err)
+		return
+	}
+	n.cleanedUp = false
+
+	resources := n.calculateClassIDResources(podMetas)
+	n.executor.UpdateBatch(true, resources...)
+	klog.V(5).Infof("%s: finish to reconcile network qos on link %s", NetQoSReconcileName, linkName)
+}
+
+// cleanupAll removes the htb hierarchy managed by koordlet on all links until they are all removed.
+func (n *netQoSReconcile) cleanupAll() {
+	if n.cleanedUp {
+		return
+	}
+	n.cleanedUp = n.cleanup("")
+}
+
+// cleanup removes the htb hierarchy managed by koordlet on the links except the keepLink, and returns true if all of
+// them are removed.
+func (n *netQoSReconcile) cleanup(keepLink string) bool {
+	linkNames, err := n.tcExecutor.GetManagedLinks()
+	if err != nil {
+		klog.Errorf("%s: failed to get the links with htb, err: %v", NetQoSReconcileName, err)
+		return false
+	}
+	cleanedUp := true
+	for _, linkName := range linkNames {
+		if linkName == keepLink {
+			continue
+		}
+		if err = n.tcExecutor.CleanupHTB(linkName); err != nil {
+			klog.Errorf("%s: failed to cleanup htb of link %s, err: %v", NetQoSReconcileName, linkName, err)
+			cleanedUp = false
+			continue
+		}
+		klog.V(4).Infof("%s: htb of link %s is cleaned up", NetQoSReconcileName, linkName)
+	}
+	return cleanedUp
+}
+
+func (n *netQoSReconcile) calculateClassIDResources(podMetas []*statesinformer.PodMeta) []resourceexecutor.ResourceUpdater {
+	var resources []resourceexecutor.ResourceUpdater
+	for _, podMeta := range podMetas {
+		pod := podMeta.Pod
+		classID := strconv.FormatUint(uint64(ClassID(HTBMajor, getClassMinor(apiext.GetPodQoSClassWithDefault(pod)))), 10)
+
+		eventHelper := audit.V(3).PodObject(pod).Reason(NetQoSReconcileName).Message("update pod net_cls.classid: %v", classID)
+		r, err := resourceexecutor.NewCommonCgroupUpdater(system.NetClsClassIDName, podMeta.CgroupDir, classID, eventHelper)
+		if err != nil {
+			klog.V(4).Infof("%s: failed to get classid updater for pod %s, err: %v", NetQoSReconcileName, util.GetPodKey(pod), err)
+			continue
+		}
+		resources = append(resources, r)
+
+		// the classid of the existing child cgroups are not changed along with the parent
+		for i := range pod.Status.ContainerStatuses {
+			containerStatus := &pod.Status.ContainerStatuses[i]
+			containerDir, err := koordletutil.GetContainerCgroupParentDir(podMeta.CgroupDir, containerStatus)
+			if err != nil {
+				klog.V(4).Infof("%s: failed to get cgroup dir of container %s/%s, err: %v",
+					NetQoSReconcileName, util.GetPodKey(pod), containerStatus.Name, err)
+				continue
+			}
+			eventHelper := audit.V(3).Container(containerStatus.Name).Reason(NetQoSReconcileName).Message("update container net_cls.classid: %v", classID)
+			r, err := resourceexecutor.NewCommonCgroupUpdater(system.NetClsClassIDName, containerDir, classID, eventHelper)
+			if err != nil {
+				klog.V(4).Infof("%s: failed to get classid updater for container %s/%s, err: %v",
+					NetQoSReconcileName, util.GetPodKey(pod), containerStatus.Name, err)
+				continue
+			}
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// calculateSourceIPClasses returns the classes of the pods not in the host network by their IPs.
+func calculateSourceIPClasses(podMetas []*statesinformer.PodMeta) map[string]uint16 {
+	sourceIPClasses := map[string]uint16{}
+	for _, podMeta := range podMetas {
+		pod := podMeta.Pod
+		if pod.Spec.HostNetwork || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		minor := getClassMinor(apiext.GetPodQoSClassWithDefault(pod))
+		for _, podIP := range pod.Status.PodIPs {
+			if ip := net.ParseIP(podIP.IP); ip != nil {
+				sourceIPClasses[ip.String()] = minor
+			}
+		}
+		if ip := net.ParseIP(pod.Status.PodIP); ip != nil {
+			sourceIPClasses[ip.String()] = minor
+		}
+	}
+	return sourceIPClasses
+}
+
+func calculateHTBConfig(strategy *slov1alpha1.ResourceQOSStrategy, totalRate uint64) *HTBConfig {
+	lsr := calculateHTBClass("LSR", lsrClassMinor, 0, strategy.LSRClass, totalRate)
+	ls := calculateHTBClass("LS", lsClassMinor, 1, strategy.LSClass, totalRate)
+	be := calculateHTBClass("BE", beClassMinor, 2, strategy.BEClass, totalRate)
+
+	// the unclassified traffic (e.g. system daemons) is guaranteed with the bandwidth left
+	defaultRate := minClassRate
+	if requested := lsr.Rate + ls.Rate + be.Rate; requested+minClassRate < totalRate {
+		defaultRate = totalRate - requested
+	}
+	others := &HTBClass{
+		Name:  "default",
+		Minor: defaultClassMinor,
+		Rate:  defaultRate,
+		Ceil:  totalRate,
+		Prio:  1,
+	}
+
+	return &HTBConfig{
+		TotalRate:    totalRate,
+		DefaultMinor: defaultClassMinor,
+		Classes:      []*HTBClass{lsr, ls, be, others},
+	}
+}
+
+func calculateHTBClass(name string, minor uint16, prio uint32, qos *slov1alpha1.ResourceQOS, totalRate uint64) *HTBClass {
+	class := &HTBClass{
+		Name:  name,
+		Minor: minor,
+		Rate:  minClassRate,
+		Ceil:  totalRate,
+		Prio:  prio,
+	}
+	if qos == nil || qos.NetworkQOS == nil || qos.NetworkQOS.Enable == nil || !*qos.NetworkQOS.Enable {
+		return class
+	}
+
+	class.Rate = parseBandwidth(qos.NetworkQOS.EgressRequest, totalRate, minClassRate)
+	class.Ceil = parseBandwidth(qos.NetworkQOS.EgressLimit, totalRate, totalRate)
+	if class.Rate < minClassRate {
+		class.Rate = minClassRate
+	}
+	if class.Ceil < class.Rate {
+		class.Ceil = class.Rate
+	}
+	return class
+}
+
+// parseBandwidth parses the bandwidth in bits per second from a percentage of the total bandwidth or a quantity in
+// bytes per second.
+func parseBandwidth(value *intstr.IntOrString, totalRate uint64, defaultRate uint64) uint64 {
+	if value == nil {
+		return defaultRate
+	}
+
+	var rate uint64
+	switch value.Type {
+	case intstr.Int:
+		percent := value.IntValue()
+		if percent < 0 || percent > 100 {
+			klog.V(4).Infof("%s: invalid bandwidth percentage %d, use the default", NetQoSReconcileName, percent)
+			return defaultRate
+		}
+		rate = totalRate * uint64(percent) / 100
+	case intstr.String:
+		q, err := resource.ParseQuantity(value.StrVal)
+		if err != nil || q.Value() < 0 {
+			klog.V(4).Infof("%s: invalid bandwidth quantity %s, use the default, err: %v", NetQoSReconcileName, value.StrVal, err)
+			return defaultRate
+		}
+		// compare in bytes to avoid overflowing
+		if bytes := uint64(q.Value()); bytes > totalRate/8 {
+			rate = totalRate
+		} else {
+			rate = bytes * 8
+		}
+	default:
+		return defaultRate
+	}
+
+	if rate > totalRate {
+		rate = totalRate
+	}
+	return rate
+}
+
+// getTotalBandwidth returns the bandwidth in bits per second, which is the TotalNetworkBandwidth (unit: Mbps) if
+// specified, otherwise the speed of the link.
+func getTotalBandwidth(strategy *slov1alpha1.SystemStrategy, linkName string) (uint64, error) {
+	if strategy != nil && strategy.TotalNetworkBandwidth.Value() > 0 {
+		return uint64(strategy.TotalNetworkBandwidth.Value()) * 1000 * 1000, nil
+	}
+	return getLinkSpeed(linkName)
+}
+
+// getLinkSpeed reads the speed of the link from /sys/class/net/${NIC_NAME}/speed, unit: Mbps
+func getLinkSpeed(linkName string) (uint64, error) {
+	speedPath := filepath.Join(system.Conf.SysRootDir, "class", "net", linkName, "speed")
+	content, err := os.ReadFile(speedPath)
+	if err != nil {
+		return 0, err
+	}
+	speed, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	// virtual links without a speed report -1
+	if speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %d of link %s", speed, linkName)
+	}
+	return uint64(speed) * 1000 * 1000, nil
+}
+
+func isNetQoSEnabled(strategy *slov1alpha1.ResourceQOSStrategy) bool {
+	for _, qos := range []*slov1alpha1.ResourceQOS{strategy.LSRClass, strategy.LSClass, strategy.BEClass} {
+		if qos != nil && qos.NetworkQOS != nil && qos.NetworkQOS.Enable != nil && *qos.NetworkQOS.Enable {
+			return true
+		}
+	}
+	return false
+}
+
+func getClassMinor(qosClass apiext.QoSClass) uint16 {
+	switch qosClass {
+	case apiext.QoSLSE, apiext.QoSLSR:
+		return lsrClassMinor
+	case apiext.QoSLS:
+		return lsClassMinor
+	case apiext.QoSBE:
+		return beClassMinor
+	default:
+		return defaultClassMinor
+	}
+}
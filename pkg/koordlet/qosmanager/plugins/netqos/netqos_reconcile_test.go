@@ -0,0 +1,343 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netqos
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/testutil"
+	"github.com/koordinator-sh/koordinator/pkg/util/cache"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
+)
+
+type fakeTCExecutor struct {
+	defaultLink string
+	htb         map[string]*HTBConfig
+}
+
+func newFakeTCExecutor(defaultLink string) *fakeTCExecutor {
+	return &fakeTCExecutor{
+		defaultLink: defaultLink,
+		htb:         map[string]*HTBConfig{},
+	}
+}
+
+func (f *fakeTCExecutor) GetDefaultLink() (string, error) {
+	if f.defaultLink == "" {
+		return "", fmt.Errorf("default route not found")
+	}
+	return f.defaultLink, nil
+}
+
+func (f *fakeTCExecutor) EnsureHTB(linkName string, config *HTBConfig) error {
+	f.htb[linkName] = config
+	return nil
+}
+
+func (f *fakeTCExecutor) CleanupHTB(linkName string) error {
+	delete(f.htb, linkName)
+	return nil
+}
+
+func (f *fakeTCExecutor) GetManagedLinks() ([]string, error) {
+	var linkNames []string
+	for linkName := range f.htb {
+		linkNames = append(linkNames, linkName)
+	}
+	return linkNames, nil
+}
+
+func TestNewNetQoSReconcile(t *testing.T) {
+	opt := &framework.Options{
+		Config: framework.NewDefaultConfig(),
+	}
+	assert.NotPanics(t, func() {
+		r := New(opt)
+		assert.NotNil(t, r)
+		// enabled to clean up the htb even if the feature-gate is disabled
+		assert.True(t, r.Enabled())
+	})
+}
+
+func Test_calculateHTBConfig(t *testing.T) {
+	total := uint64(1000 * 1000 * 1000)
+	tests := []struct {
+		name     string
+		strategy *slov1alpha1.ResourceQOSStrategy
+		want     *HTBConfig
+	}{
+		{
+			name:     "all classes disabled",
+			strategy: &slov1alpha1.ResourceQOSStrategy{},
+			want: &HTBConfig{
+				TotalRate:    total,
+				DefaultMinor: defaultClassMinor,
+				Classes: []*HTBClass{
+					{Name: "LSR", Minor: lsrClassMinor, Rate: minClassRate, Ceil: total, Prio: 0},
+					{Name: "LS", Minor: lsClassMinor, Rate: minClassRate, Ceil: total, Prio: 1},
+					{Name: "BE", Minor: beClassMinor, Rate: minClassRate, Ceil: total, Prio: 2},
+					{Name: "default", Minor: defaultClassMinor, Rate: total - 3*minClassRate, Ceil: total, Prio: 1},
+				},
+			},
+		},
+		{
+			name: "percentages",
+			strategy: &slov1alpha1.ResourceQOSStrategy{
+				LSRClass: newNetworkQOS(true, intstr.FromInt(30), intstr.FromInt(100)),
+				LSClass:  newNetworkQOS(true, intstr.FromInt(50), intstr.FromInt(100)),
+				BEClass:  newNetworkQOS(true, intstr.FromInt(10), intstr.FromInt(20)),
+			},
+			want: &HTBConfig{
+				TotalRate:    total,
+				DefaultMinor: defaultClassMinor,
+				Classes: []*HTBClass{
+					{Name: "LSR", Minor: lsrClassMinor, Rate: total * 30 / 100, Ceil: total, Prio: 0},
+					{Name: "LS", Minor: lsClassMinor, Rate: total * 50 / 100, Ceil: total, Prio: 1},
+					{Name: "BE", Minor: beClassMinor, Rate: total * 10 / 100, Ceil: total * 20 / 100, Prio: 2},
+					{Name: "default", Minor: defaultClassMinor, Rate: total * 10 / 100, Ceil: total, Prio: 1},
+				},
+			},
+		},
+		{
+			name: "quantities in bytes per second",
+			strategy: &slov1alpha1.ResourceQOSStrategy{
+				LSClass: newNetworkQOS(true, intstr.FromString("50M"), intstr.FromString("100M")),
+				BEClass: newNetworkQOS(true, intstr.FromString("30M"), intstr.FromString("10M")),
+			},
+			want: &HTBConfig{
+				TotalRate:    total,
+				DefaultMinor: defaultClassMinor,
+				Classes: []*HTBClass{
+					{Name: "LSR", Minor: lsrClassMinor, Rate: minClassRate, Ceil: total, Prio: 0},
+					{Name: "LS", Minor: lsClassMinor, Rate: 400 * 1000 * 1000, Ceil: 800 * 1000 * 1000, Prio: 1},
+					{Name: "BE", Minor: beClassMinor, Rate: 240 * 1000 * 1000, Ceil: 240 * 1000 * 1000, Prio: 2},
+					{Name: "default", Minor: defaultClassMinor, Rate: total - minClassRate - 640*1000*1000, Ceil: total, Prio: 1},
+				},
+			},
+		},
+		{
+			name: "quantities exceed the total",
+			strategy: &slov1alpha1.ResourceQOSStrategy{
+				LSClass: newNetworkQOS(true, intstr.FromString("200M"), intstr.FromString("2G")),
+				BEClass: newNetworkQOS(true, intstr.FromString("30M"), intstr.FromString("10M")),
+			},
+			want: &HTBConfig{
+				TotalRate:    total,
+				DefaultMinor: defaultClassMinor,
+				Classes: []*HTBClass{
+					{Name: "LSR", Minor: lsrClassMinor, Rate: minClassRate, Ceil: total, Prio: 0},
+					{Name: "LS", Minor: lsClassMinor, Rate: total, Ceil: total, Prio: 1},
+					{Name: "BE", Minor: beClassMinor, Rate: 240 * 1000 * 1000, Ceil: 240 * 1000 * 1000, Prio: 2},
+					{Name: "default", Minor: defaultClassMinor, Rate: minClassRate, Ceil: total, Prio: 1},
+				},
+			},
+		},
+		{
+			name: "invalid values fallback to the defaults",
+			strategy: &slov1alpha1.ResourceQOSStrategy{
+				BEClass: newNetworkQOS(true, intstr.FromInt(200), intstr.FromString("invalid")),
+			},
+			want: &HTBConfig{
+				TotalRate:    total,
+				DefaultMinor: defaultClassMinor,
+				Classes: []*HTBClass{
+					{Name: "LSR", Minor: lsrClassMinor, Rate: minClassRate, Ceil: total, Prio: 0},
+					{Name: "LS", Minor: lsClassMinor, Rate: minClassRate, Ceil: total, Prio: 1},
+					{Name: "BE", Minor: beClassMinor, Rate: minClassRate, Ceil: total, Prio: 2},
+					{Name: "default", Minor: defaultClassMinor, Rate: total - 3*minClassRate, Ceil: total, Prio: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateHTBConfig(tt.strategy, total)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_calculateSourceIPClasses(t *testing.T) {
+	lsPod := testutil.MockTestPodWithQOS(corev1.PodQOSBurstable, apiext.QoSLS)
+	lsPod.Pod.Status.PodIP = "10.0.0.2"
+	lsPod.Pod.Status.PodIPs = []corev1.PodIP{{IP: "10.0.0.2"}, {IP: "fd00::2"}}
+	bePod := testutil.MockTestPodWithQOS(corev1.PodQOSBestEffort, apiext.QoSBE)
+	bePod.Pod.Status.PodIP = "10.0.0.3"
+	hostNetworkPod := testutil.MockTestPodWithQOS(corev1.PodQOSBurstable, apiext.QoSLS)
+	hostNetworkPod.Pod.Spec.HostNetwork = true
+	hostNetworkPod.Pod.Status.PodIP = "192.168.0.1"
+	completedPod := testutil.MockTestPodWithQOS(corev1.PodQOSBestEffort, apiext.QoSBE)
+	completedPod.Pod.Status.Phase = corev1.PodSucceeded
+	completedPod.Pod.Status.PodIP = "10.0.0.4"
+
+	got := calculateSourceIPClasses([]*statesinformer.PodMeta{lsPod, bePod, hostNetworkPod, completedPod})
+	assert.Equal(t, map[string]uint16{
+		"10.0.0.2": lsClassMinor,
+		"fd00::2":  lsClassMinor,
+		"10.0.0.3": beClassMinor,
+	}, got)
+}
+
+func Test_getTotalBandwidth(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteFileContents("class/net/eth0/speed", "10000\n")
+	helper.WriteFileContents("class/net/veth0/speed", "-1\n")
+
+	// the TotalNetworkBandwidth is in Mbps
+	got, err := getTotalBandwidth(&slov1alpha1.SystemStrategy{TotalNetworkBandwidth: resource.MustParse("1000")}, "eth0")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000*1000*1000), got)
+
+	got, err = getTotalBandwidth(&slov1alpha1.SystemStrategy{}, "eth0")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10000*1000*1000), got)
+
+	_, err = getTotalBandwidth(nil, "veth0")
+	assert.Error(t, err)
+
+	_, err = getTotalBandwidth(nil, "eth1")
+	assert.Error(t, err)
+}
+
+func Test_netQoSReconcile_reconcile(t *testing.T) {
+	defer utilfeature.SetFeatureGateDuringTest(t, features.DefaultMutableKoordletFeatureGate, features.NetQoSReconcile, true)()
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.WriteFileContents("class/net/eth0/speed", "1000")
+
+	lsPod := testutil.MockTestPodWithQOS(corev1.PodQOSBurstable, apiext.QoSLS)
+	bePod := testutil.MockTestPodWithQOS(corev1.PodQOSBestEffort, apiext.QoSBE)
+	bePod.Pod.Name, bePod.Pod.UID = "test_be_pod", "test_be_pod"
+	bePod.CgroupDir = koordletutil.GetPodCgroupParentDir(bePod.Pod)
+	lsPod.Pod.Status.PodIP, bePod.Pod.Status.PodIP = "10.0.0.2", "10.0.0.3"
+	lsPod.CgroupDir = koordletutil.GetPodCgroupParentDir(lsPod.Pod)
+	podMetas := []*statesinformer.PodMeta{lsPod, bePod}
+	helper.SetResourcesSupported(true, system.NetClsClassID)
+	for _, podMeta := range podMetas {
+		helper.WriteCgroupFileContents(podMeta.CgroupDir, system.NetClsClassID, "0")
+		for i := range podMeta.Pod.Status.ContainerStatuses {
+			containerDir, err := koordletutil.GetContainerCgroupParentDir(podMeta.CgroupDir, &podMeta.Pod.Status.ContainerStatuses[i])
+			assert.NoError(t, err)
+			helper.WriteCgroupFileContents(containerDir, system.NetClsClassID, "0")
+		}
+	}
+
+	nodeSLO := &slov1alpha1.NodeSLO{
+		Spec: slov1alpha1.NodeSLOSpec{
+			ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+				LSClass: newNetworkQOS(true, intstr.FromInt(60), intstr.FromInt(100)),
+				BEClass: newNetworkQOS(true, intstr.FromInt(10), intstr.FromInt(30)),
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetNodeSLO().DoAndReturn(func() *slov1alpha1.NodeSLO { return nodeSLO }).AnyTimes()
+	si.EXPECT().GetAllPods().Return(podMetas).AnyTimes()
+
+	tcExecutor := newFakeTCExecutor("eth0")
+	r := &netQoSReconcile{
+		statesInformer: si,
+		executor: &resourceexecutor.ResourceUpdateExecutorImpl{
+			Config:        resourceexecutor.NewDefaultConfig(),
+			ResourceCache: cache.NewCacheDefault(),
+		},
+		tcExecutor: tcExecutor,
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	r.executor.Run(stopCh)
+
+	// the htb applied before the restart is left on the link which no longer holds the default route
+	tcExecutor.htb["eth1"] = &HTBConfig{}
+	r.reconcile()
+	total := uint64(1000 * 1000 * 1000)
+	assert.Len(t, tcExecutor.htb, 1)
+	expectHTBConfig := calculateHTBConfig(nodeSLO.Spec.ResourceQOSStrategy, total)
+	expectHTBConfig.SourceIPClasses = map[string]uint16{
+		"10.0.0.2": lsClassMinor,
+		"10.0.0.3": beClassMinor,
+	}
+	assert.Equal(t, expectHTBConfig, tcExecutor.htb["eth0"])
+	expectClassIDs := map[*statesinformer.PodMeta]string{
+		lsPod: "1802436611", // 6b6f:3
+		bePod: "1802436612", // 6b6f:4
+	}
+	for podMeta, classID := range expectClassIDs {
+		assert.Equal(t, classID, helper.ReadCgroupFileContents(podMeta.CgroupDir, system.NetClsClassID))
+		for i := range podMeta.Pod.Status.ContainerStatuses {
+			containerDir, _ := koordletutil.GetContainerCgroupParentDir(podMeta.CgroupDir, &podMeta.Pod.Status.ContainerStatuses[i])
+			assert.Equal(t, classID, helper.ReadCgroupFileContents(containerDir, system.NetClsClassID))
+		}
+	}
+
+	// disable the network qos and the htb should be cleaned up
+	nodeSLO = &slov1alpha1.NodeSLO{
+		Spec: slov1alpha1.NodeSLOSpec{
+			ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+				BEClass: newNetworkQOS(false, intstr.FromInt(10), intstr.FromInt(30)),
+			},
+		},
+	}
+	r.reconcile()
+	assert.True(t, r.cleanedUp)
+	assert.Empty(t, tcExecutor.htb)
+
+	// koordlet restarts after the feature-gate is disabled, and the htb left should be cleaned up
+	defer utilfeature.SetFeatureGateDuringTest(t, features.DefaultMutableKoordletFeatureGate, features.NetQoSReconcile, false)()
+	tcExecutor.htb["eth0"] = &HTBConfig{}
+	r = &netQoSReconcile{
+		statesInformer: si,
+		executor:       r.executor,
+		tcExecutor:     tcExecutor,
+	}
+	r.reconcile()
+	assert.True(t, r.cleanedUp)
+	assert.Empty(t, tcExecutor.htb)
+}
+
+func newNetworkQOS(enable bool, request, limit intstr.IntOrString) *slov1alpha1.ResourceQOS {
+	return &slov1alpha1.ResourceQOS{
+		NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+			Enable: pointer.Bool(enable),
+			NetworkQOS: slov1alpha1.NetworkQOS{
+				EgressRequest: &request,
+				EgressLimit:   &limit,
+			},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netqos
+
+const (
+	// HTBMajor is the major handle of the root htb qdisc managed by koordlet, i.e. `6b6f:` ("ko"). It is distinct
+	// from the handles commonly used, so the qdisc applied by koordlet can be recognized from the kernel state.
+	HTBMajor uint16 = 0x6b6f
+	// HTBRootClassMinor is the minor handle of the htb class which all qos classes borrow from, i.e. `1:1`.
+	HTBRootClassMinor uint16 = 1
+)
+
+// HTBClass describes a leaf htb class of a qos class.
+type HTBClass struct {
+	// Name is a readable name of the class, e.g. LSR, LS, BE.
+	Name string
+	// Minor is the minor handle of the class under the root htb qdisc.
+	Minor uint16
+	// Rate is the guaranteed bandwidth in bits per second.
+	Rate uint64
+	// Ceil is the maximum bandwidth in bits per second.
+	Ceil uint64
+	// Prio is the priority to borrow the spare bandwidth, lower is preferred.
+	Prio uint32
+}
+
+// HTBConfig is the desired htb hierarchy of a link.
+type HTBConfig struct {
+	// TotalRate is the rate and ceil of the root class in bits per second.
+	TotalRate uint64
+	// DefaultMinor is the minor handle of the class for the unclassified traffic.
+	DefaultMinor uint16
+	// Classes are the leaf classes under the root class.
+	Classes []*HTBClass
+	// SourceIPClasses maps the IPs of the pods not in the host network to the minor handles of their classes.
+	SourceIPClasses map[string]uint16
+}
+
+// TCExecutor applies the traffic control rules on the host network.
+// The htb qdisc is attached to the egress of the link. The packets sent by the local sockets (e.g. the pods in the host
+// network) are classified with the cgroup filter according to the net_cls.classid of the sender's cgroup, and the
+// packets forwarded from the pods are classified with the u32 filters according to their source IPs.
+type TCExecutor interface {
+	// GetDefaultLink returns the name of the link which holds the default route.
+	GetDefaultLink() (string, error)
+	// EnsureHTB creates or updates the root htb qdisc, the classes and the filters on the link.
+	EnsureHTB(linkName string, config *HTBConfig) error
+	// CleanupHTB removes the root htb qdisc managed by koordlet on the link if exists.
+	CleanupHTB(linkName string) error
+	// GetManagedLinks returns the names of the links whose root qdisc is managed by koordlet.
+	GetManagedLinks() ([]string, error)
+}
+
+// ClassID returns the value of net_cls.classid (0xAAAABBBB) which classifies the packets into the class major:minor.
+func ClassID(major, minor uint16) uint32 {
+	return uint32(major)<<16 | uint32(minor)
+}
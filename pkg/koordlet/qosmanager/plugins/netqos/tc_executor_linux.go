@@ -0,0 +1,331 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netqos
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+const (
+	cgroupFilterType = "cgroup"
+
+	// the filters of different protocols must have different priorities
+	cgroupFilterPriority uint16 = 1
+	ipv4FilterPriority   uint16 = 2
+	ipv6FilterPriority   uint16 = 3
+)
+
+type netlinkTCExecutor struct{}
+
+func NewTCExecutor() TCExecutor {
+	return &netlinkTCExecutor{}
+}
+
+func (e *netlinkTCExecutor) GetDefaultLink() (string, error) {
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return "", fmt.Errorf("failed to list routes, err: %w", err)
+	}
+	for _, route := range routes {
+		if route.Dst != nil {
+			continue
+		}
+		link, err := netlink.LinkByIndex(route.LinkIndex)
+		if err != nil {
+			return "", fmt.Errorf("failed to get link of the default route, err: %w", err)
+		}
+		return link.Attrs().Name, nil
+	}
+	return "", fmt.Errorf("default route not found")
+}
+
+func (e *netlinkTCExecutor) EnsureHTB(linkName string, config *HTBConfig) error {
+	link, err := netlink.LinkByName(linkName)
+	if err != nil {
+		return fmt.Errorf("failed to get link %s, err: %w", linkName, err)
+	}
+	if err = e.ensureQdisc(link, config); err != nil {
+		return err
+	}
+	if err = e.ensureClasses(link, config); err != nil {
+		return err
+	}
+	if err = e.ensureCgroupFilter(link); err != nil {
+		return err
+	}
+	return e.ensureSourceIPFilters(link, config)
+}
+
+func (e *netlinkTCExecutor) CleanupHTB(linkName string) error {
+	link, err := netlink.LinkByName(linkName)
+	if err != nil {
+		return fmt.Errorf("failed to get link %s, err: %w", linkName, err)
+	}
+	qdisc, err := getRootQdisc(link)
+	if err != nil {
+		return err
+	}
+	if !isManagedQdisc(qdisc) {
+		return nil
+	}
+	if err = netlink.QdiscDel(qdisc); err != nil {
+		return fmt.Errorf("failed to delete root qdisc of link %s, err: %w", linkName, err)
+	}
+	klog.V(4).Infof("root htb qdisc of link %s is deleted", linkName)
+	return nil
+}
+
+func (e *netlinkTCExecutor) GetManagedLinks() ([]string, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links, err: %w", err)
+	}
+	var linkNames []string
+	for _, link := range links {
+		qdisc, err := getRootQdisc(link)
+		if err != nil {
+			return nil, err
+		}
+		if isManagedQdisc(qdisc) {
+			linkNames = append(linkNames, link.Attrs().Name)
+		}
+	}
+	return linkNames, nil
+}
+
+func (e *netlinkTCExecutor) ensureQdisc(link netlink.Link, config *HTBConfig) error {
+	qdisc, err := getRootQdisc(link)
+	if err != nil {
+		return err
+	}
+	if htb, ok := qdisc.(*netlink.Htb); ok && isManagedQdisc(qdisc) &&
+		htb.Defcls == uint32(config.DefaultMinor) {
+		return nil
+	}
+
+	htb := netlink.NewHtb(netlink.QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    netlink.MakeHandle(HTBMajor, 0),
+		Parent:    netlink.HANDLE_ROOT,
+	})
+	htb.Defcls = uint32(config.DefaultMinor)
+	if err = netlink.QdiscReplace(htb); err != nil {
+		return fmt.Errorf("failed to replace root qdisc of link %s, err: %w", link.Attrs().Name, err)
+	}
+	klog.V(4).Infof("root htb qdisc of link %s is replaced, default class %d:%d",
+		link.Attrs().Name, HTBMajor, config.DefaultMinor)
+	return nil
+}
+
+func (e *netlinkTCExecutor) ensureClasses(link netlink.Link, config *HTBConfig) error {
+	classes, err := netlink.ClassList(link, netlink.MakeHandle(HTBMajor, 0))
+	if err != nil {
+		return fmt.Errorf("failed to list classes of link %s, err: %w", link.Attrs().Name, err)
+	}
+	existing := map[uint32]*netlink.HtbClass{}
+	for _, class := range classes {
+		if htbClass, ok := class.(*netlink.HtbClass); ok {
+			existing[htbClass.Handle] = htbClass
+		}
+	}
+
+	// the root class must be updated before the leaves since the rates of leaves are bounded by their parent
+	desired := []*HTBClass{{Name: "root", Minor: HTBRootClassMinor, Rate: config.TotalRate, Ceil: config.TotalRate}}
+	desired = append(desired, config.Classes...)
+	for _, c := range desired {
+		parent := netlink.MakeHandle(HTBMajor, HTBRootClassMinor)
+		if c.Minor == HTBRootClassMinor {
+			parent = netlink.MakeHandle(HTBMajor, 0)
+		}
+		handle := netlink.MakeHandle(HTBMajor, c.Minor)
+		// the rates of the netlink class are in bytes per second
+		if old, ok := existing[handle]; ok && old.Parent == parent && old.Rate == c.Rate/8 &&
+			old.Ceil == c.Ceil/8 && old.Prio == c.Prio {
+			continue
+		}
+		class := netlink.NewHtbClass(netlink.ClassAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    handle,
+			Parent:    parent,
+		}, netlink.HtbClassAttrs{
+			Rate: c.Rate,
+			Ceil: c.Ceil,
+			Prio: c.Prio,
+		})
+		if err = netlink.ClassReplace(class); err != nil {
+			return fmt.Errorf("failed to replace class %s(%d:%d) of link %s, err: %w",
+				c.Name, HTBMajor, c.Minor, link.Attrs().Name, err)
+		}
+		klog.V(4).Infof("htb class %s(%d:%d) of link %s is updated, rate %d, ceil %d",
+			c.Name, HTBMajor, c.Minor, link.Attrs().Name, c.Rate, c.Ceil)
+	}
+	return nil
+}
+
+func (e *netlinkTCExecutor) ensureCgroupFilter(link netlink.Link) error {
+	filters, err := netlink.FilterList(link, netlink.MakeHandle(HTBMajor, 0))
+	if err != nil {
+		return fmt.Errorf("failed to list filters of link %s, err: %w", link.Attrs().Name, err)
+	}
+	for _, filter := range filters {
+		if filter.Type() == cgroupFilterType {
+			return nil
+		}
+	}
+	filter := &netlink.GenericFilter{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    netlink.MakeHandle(HTBMajor, 0),
+			Priority:  cgroupFilterPriority,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		FilterType: cgroupFilterType,
+	}
+	if err = netlink.FilterAdd(filter); err != nil {
+		return fmt.Errorf("failed to add cgroup filter of link %s, err: %w", link.Attrs().Name, err)
+	}
+	klog.V(4).Infof("cgroup filter of link %s is added", link.Attrs().Name)
+	return nil
+}
+
+// ensureSourceIPFilters keeps a u32 filter for each source IP in the config, and removes the filters of the IPs gone.
+func (e *netlinkTCExecutor) ensureSourceIPFilters(link netlink.Link, config *HTBConfig) error {
+	filters, err := netlink.FilterList(link, netlink.MakeHandle(HTBMajor, 0))
+	if err != nil {
+		return fmt.Errorf("failed to list filters of link %s, err: %w", link.Attrs().Name, err)
+	}
+	existing := map[string]bool{}
+	for _, filter := range filters {
+		u32, ok := filter.(*netlink.U32)
+		if !ok {
+			continue
+		}
+		ip := getSourceIP(u32)
+		if ip == "" {
+			continue
+		}
+		if minor, ok := config.SourceIPClasses[ip]; ok && !existing[ip] && u32.ClassId == netlink.MakeHandle(HTBMajor, minor) {
+			existing[ip] = true
+			continue
+		}
+		if err = netlink.FilterDel(u32); err != nil {
+			return fmt.Errorf("failed to delete u32 filter of ip %s of link %s, err: %w", ip, link.Attrs().Name, err)
+		}
+		klog.V(5).Infof("u32 filter of ip %s of link %s is deleted", ip, link.Attrs().Name)
+	}
+
+	for ip, minor := range config.SourceIPClasses {
+		if existing[ip] {
+			continue
+		}
+		filter := newSourceIPFilter(link, net.ParseIP(ip), minor)
+		if filter == nil {
+			continue
+		}
+		if err = netlink.FilterAdd(filter); err != nil {
+			return fmt.Errorf("failed to add u32 filter of ip %s of link %s, err: %w", ip, link.Attrs().Name, err)
+		}
+		klog.V(5).Infof("u32 filter of ip %s of link %s is added, class %d:%d", ip, link.Attrs().Name, HTBMajor, minor)
+	}
+	return nil
+}
+
+// newSourceIPFilter returns a u32 filter which classifies the packets from the ip into the class, i.e.
+// `match ip src ${IP}/32 classid 6b6f:${MINOR}` or `match ip6 src ${IP}/128 classid 6b6f:${MINOR}`.
+func newSourceIPFilter(link netlink.Link, ip net.IP, minor uint16) *netlink.U32 {
+	priority, protocol := ipv4FilterPriority, uint16(unix.ETH_P_IP)
+	// the source address is at the offset 12 of the ipv4 header and at the offset 8 of the ipv6 header
+	addr, offset := ip.To4(), int32(12)
+	if addr == nil {
+		priority, protocol = ipv6FilterPriority, unix.ETH_P_IPV6
+		addr, offset = ip.To16(), 8
+	}
+	if addr == nil {
+		return nil
+	}
+	var keys []netlink.TcU32Key
+	for i := 0; i < len(addr); i += 4 {
+		keys = append(keys, netlink.TcU32Key{
+			Mask: 0xffffffff,
+			Val:  binary.BigEndian.Uint32(addr[i : i+4]),
+			Off:  offset + int32(i),
+		})
+	}
+	return &netlink.U32{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    netlink.MakeHandle(HTBMajor, 0),
+			Priority:  priority,
+			Protocol:  protocol,
+		},
+		ClassId: netlink.MakeHandle(HTBMajor, minor),
+		Sel: &netlink.TcU32Sel{
+			Flags: netlink.TC_U32_TERMINAL,
+			Keys:  keys,
+		},
+	}
+}
+
+// getSourceIP returns the source IP matched by the u32 filter created by newSourceIPFilter, or empty if not.
+func getSourceIP(filter *netlink.U32) string {
+	if filter.Sel == nil {
+		return ""
+	}
+	var addr net.IP
+	var offset int32
+	switch {
+	case filter.Priority == ipv4FilterPriority && filter.Protocol == unix.ETH_P_IP && len(filter.Sel.Keys) == 1:
+		addr, offset = make(net.IP, net.IPv4len), 12
+	case filter.Priority == ipv6FilterPriority && filter.Protocol == unix.ETH_P_IPV6 && len(filter.Sel.Keys) == 4:
+		addr, offset = make(net.IP, net.IPv6len), 8
+	default:
+		return ""
+	}
+	for i, key := range filter.Sel.Keys {
+		if key.Mask != 0xffffffff || key.Off != offset+int32(4*i) {
+			return ""
+		}
+		binary.BigEndian.PutUint32(addr[4*i:], key.Val)
+	}
+	return addr.String()
+}
+
+func getRootQdisc(link netlink.Link) (netlink.Qdisc, error) {
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list qdiscs of link %s, err: %w", link.Attrs().Name, err)
+	}
+	for _, qdisc := range qdiscs {
+		if qdisc.Attrs().Parent == netlink.HANDLE_ROOT {
+			return qdisc, nil
+		}
+	}
+	return nil, nil
+}
+
+func isManagedQdisc(qdisc netlink.Qdisc) bool {
+	return qdisc != nil && qdisc.Type() == "htb" && qdisc.Attrs().Handle == netlink.MakeHandle(HTBMajor, 0)
+}
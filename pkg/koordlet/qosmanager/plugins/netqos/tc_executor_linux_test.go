@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netqos
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func Test_newSourceIPFilter(t *testing.T) {
+	link := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Index: 2, Name: "eth0"}}
+
+	filter := newSourceIPFilter(link, net.ParseIP("10.0.0.2"), lsClassMinor)
+	assert.Equal(t, ipv4FilterPriority, filter.Priority)
+	assert.Equal(t, uint16(unix.ETH_P_IP), filter.Protocol)
+	assert.Equal(t, netlink.MakeHandle(HTBMajor, lsClassMinor), filter.ClassId)
+	assert.Equal(t, []netlink.TcU32Key{{Mask: 0xffffffff, Val: 0x0a000002, Off: 12}}, filter.Sel.Keys)
+	assert.Equal(t, "10.0.0.2", getSourceIP(filter))
+
+	filter = newSourceIPFilter(link, net.ParseIP("fd00::2"), beClassMinor)
+	assert.Equal(t, ipv6FilterPriority, filter.Priority)
+	assert.Equal(t, uint16(unix.ETH_P_IPV6), filter.Protocol)
+	assert.Len(t, filter.Sel.Keys, 4)
+	assert.Equal(t, "fd00::2", getSourceIP(filter))
+
+	// the filters not created by koordlet are ignored
+	assert.Empty(t, getSourceIP(&netlink.U32{}))
+	filter.Sel.Keys[0].Off = 12
+	assert.Empty(t, getSourceIP(filter))
+}
@@ -0,0 +1,44 @@
+//go:build !linux
+// +build !linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netqos
+
+import "fmt"
+
+type unsupportedTCExecutor struct{}
+
+func NewTCExecutor() TCExecutor {
+	return &unsupportedTCExecutor{}
+}
+
+func (e *unsupportedTCExecutor) GetDefaultLink() (string, error) {
+	return "", fmt.Errorf("traffic control is only supported on linux")
+}
+
+func (e *unsupportedTCExecutor) EnsureHTB(linkName string, config *HTBConfig) error {
+	return fmt.Errorf("traffic control is only supported on linux")
+}
+
+func (e *unsupportedTCExecutor) CleanupHTB(linkName string) error {
+	return fmt.Errorf("traffic control is only supported on linux")
+}
+
+func (e *unsupportedTCExecutor) GetManagedLinks() ([]string, error) {
+	return nil, fmt.Errorf("traffic control is only supported on linux")
+}
@@ -24,19 +24,23 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/cpuevict"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/cpusuppress"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/memoryevict"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/memorythrottle"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/netqos"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/resctrl"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/plugins/sysreconcile"
 )
 
 var (
 	StrategyPlugins = map[string]framework.QOSStrategyFactory{
-		blkio.BlkIOReconcileName:               blkio.New,
-		cgreconcile.CgroupReconcileName:        cgreconcile.New,
-		cpuburst.CPUBurstName:                  cpuburst.New,
-		cpuevict.CPUEvictName:                  cpuevict.New,
-		cpusuppress.CPUSuppressName:            cpusuppress.New,
-		memoryevict.MemoryEvictName:            memoryevict.New,
-		resctrl.ResctrlReconcileName:           resctrl.New,
-		sysreconcile.SystemConfigReconcileName: sysreconcile.New,
+		blkio.BlkIOReconcileName:                  blkio.New,
+		cgreconcile.CgroupReconcileName:           cgreconcile.New,
+		cpuburst.CPUBurstName:                     cpuburst.New,
+		cpuevict.CPUEvictName:                     cpuevict.New,
+		cpusuppress.CPUSuppressName:               cpusuppress.New,
+		memoryevict.MemoryEvictName:               memoryevict.New,
+		memorythrottle.MemoryPressureThrottleName: memorythrottle.New,
+		netqos.NetQoSReconcileName:                netqos.New,
+		resctrl.ResctrlReconcileName:              resctrl.New,
+		sysreconcile.SystemConfigReconcileName:    sysreconcile.New,
 	}
 )
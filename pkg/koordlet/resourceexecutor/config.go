@@ -26,7 +26,8 @@ const (
 	EvictPodByNodeMemoryUsage   = "EvictPodByNodeMemoryUsage"
 	EvictPodByBECPUSatisfaction = "EvictPodByBECPUSatisfaction"
 
-	AdjustBEByNodeCPUUsage = "AdjustBEByNodeCPUUsage"
+	AdjustBEByNodeCPUUsage   = "AdjustBEByNodeCPUUsage"
+	AdjustBEByMemoryPressure = "AdjustBEByMemoryPressure"
 )
 
 var Conf = NewDefaultConfig()
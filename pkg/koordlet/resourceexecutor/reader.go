@@ -34,12 +34,14 @@ type CgroupReader interface {
 	ReadCPUAcctUsage(parentDir string) (uint64, error)
 	ReadCPUStat(parentDir string) (*sysutil.CPUStatRaw, error)
 	ReadMemoryLimit(parentDir string) (int64, error)
+	ReadMemoryHigh(parentDir string) (int64, error)
 	ReadMemoryStat(parentDir string) (*sysutil.MemoryStatRaw, error)
 	ReadMemoryNumaStat(parentDir string) ([]sysutil.NumaMemoryPages, error)
 	ReadCPUTasks(parentDir string) ([]int32, error)
 	ReadCPUProcs(parentDir string) ([]uint32, error)
 	ReadPSI(parentDir string) (*PSIByResource, error)
 	ReadMemoryColdPageUsage(parentDir string) (uint64, error)
+	ReadBlkIOStat(parentDir string) ([]sysutil.BlkIOStatRaw, error)
 }
 
 var _ CgroupReader = &CgroupV1Reader{}
@@ -128,6 +130,22 @@ func (r *CgroupV1Reader) ReadMemoryLimit(parentDir string) (int64, error) {
 	return v, nil
 }
 
+func (r *CgroupV1Reader) ReadMemoryHigh(parentDir string) (int64, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.MemoryHighName)
+	if !ok {
+		return -1, ErrResourceNotRegistered
+	}
+	v, err := readCgroupAndParseInt64(parentDir, resource)
+	if err != nil {
+		return -1, err
+	}
+	// unlimited memory.high is shown as `9223372036854771712` on anolis os, consider as value -1
+	if v >= sysutil.MemoryLimitUnlimitedValue {
+		return -1, nil
+	}
+	return v, nil
+}
+
 func (r *CgroupV1Reader) ReadMemoryStat(parentDir string) (*sysutil.MemoryStatRaw, error) {
 	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.MemoryStatName)
 	if !ok {
@@ -181,6 +199,31 @@ func (r *CgroupV1Reader) ReadMemoryColdPageUsage(parentDir string) (uint64, erro
 	return v.GetColdPageTotalBytes(), nil
 }
 
+func (r *CgroupV1Reader) ReadBlkIOStat(parentDir string) ([]sysutil.BlkIOStatRaw, error) {
+	serviceBytesResource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.BlkioIOServiceBytesRecursiveName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	servicedResource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.BlkioIOServicedRecursiveName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	serviceBytes, err := cgroupFileRead(parentDir, serviceBytesResource)
+	if err != nil {
+		return nil, err
+	}
+	serviced, err := cgroupFileRead(parentDir, servicedResource)
+	if err != nil {
+		return nil, err
+	}
+	// content: "8:0 Read 1024\n8:0 Write 2048\n...\nTotal 3072"
+	v, err := sysutil.ParseBlkIOThrottleStat(serviceBytes, serviced)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup value, err: %v", err)
+	}
+	return v, nil
+}
+
 func (r *CgroupV1Reader) ReadCPUTasks(parentDir string) ([]int32, error) {
 	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.CPUTasksName)
 	if !ok {
@@ -347,6 +390,14 @@ func (r *CgroupV2Reader) ReadMemoryLimit(parentDir string) (int64, error) {
 	return readCgroupAndParseInt64(parentDir, resource)
 }
 
+func (r *CgroupV2Reader) ReadMemoryHigh(parentDir string) (int64, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.MemoryHighName)
+	if !ok {
+		return -1, ErrResourceNotRegistered
+	}
+	return readCgroupAndParseInt64(parentDir, resource)
+}
+
 func (r *CgroupV2Reader) ReadMemoryStat(parentDir string) (*sysutil.MemoryStatRaw, error) {
 	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.MemoryStatName)
 	if !ok {
@@ -387,6 +438,23 @@ func (r *CgroupV2Reader) ReadMemoryColdPageUsage(parentDir string) (uint64, erro
 	return 0, ErrResourceNotRegistered
 }
 
+func (r *CgroupV2Reader) ReadBlkIOStat(parentDir string) ([]sysutil.BlkIOStatRaw, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.IOStatName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	s, err := cgroupFileRead(parentDir, resource)
+	if err != nil {
+		return nil, err
+	}
+	// content: "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0\n..."
+	v, err := sysutil.ParseIOStatV2(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup value %s, err: %v", s, err)
+	}
+	return v, nil
+}
+
 func (r *CgroupV2Reader) ReadCPUTasks(parentDir string) ([]int32, error) {
 	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.CPUTasksName)
 	if !ok {
@@ -866,6 +866,69 @@ func TestCgroupReader_ReadMemoryLimit(t *testing.T) {
 	}
 }
 
+func TestCgroupReader_ReadMemoryHigh(t *testing.T) {
+	tests := []struct {
+		name         string
+		useCgroupsV2 bool
+		value        string
+		want         int64
+		wantErr      bool
+	}{
+		{
+			name:    "v1 path not exist",
+			want:    -1,
+			wantErr: true,
+		},
+		{
+			name:  "parse v1 value successfully",
+			value: "2147483648",
+			want:  2147483648,
+		},
+		{
+			name:  "parse v1 unlimited value successfully",
+			value: "9223372036854771712",
+			want:  -1,
+		},
+		{
+			name:         "v2 path not exist",
+			useCgroupsV2: true,
+			want:         -1,
+			wantErr:      true,
+		},
+		{
+			name:         "parse v2 value successfully",
+			useCgroupsV2: true,
+			value:        "2147483648",
+			want:         2147483648,
+		},
+		{
+			name:         "parse v2 unlimited value successfully",
+			useCgroupsV2: true,
+			value:        "max",
+			want:         -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.useCgroupsV2)
+			if tt.value != "" {
+				if tt.useCgroupsV2 {
+					helper.WriteCgroupFileContents(sysutil.CgroupPathFormatter.ParentDir, sysutil.MemoryHighV2, tt.value)
+				} else {
+					sysutil.MemoryHigh.WithSupported(true, "")
+					helper.WriteCgroupFileContents(sysutil.CgroupPathFormatter.ParentDir, sysutil.MemoryHigh, tt.value)
+				}
+			}
+
+			got, gotErr := NewCgroupReader().ReadMemoryHigh(sysutil.CgroupPathFormatter.ParentDir)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestCgroupReader_ReadMemoryStat(t *testing.T) {
 	type fields struct {
 		UseCgroupsV2       bool
@@ -1446,3 +1509,115 @@ func TestCgroupReader_ReadColdPageUsage(t *testing.T) {
 		})
 	}
 }
+
+func TestCgroupReader_ReadBlkIOStat(t *testing.T) {
+	type fields struct {
+		UseCgroupsV2      bool
+		ServiceBytesValue string
+		ServicedValue     string
+		IOStatV2Value     string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []sysutil.BlkIOStatRaw
+		wantErr bool
+	}{
+		{
+			name:    "v1 path not exist",
+			fields:  fields{},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse v1 value successfully",
+			fields: fields{
+				ServiceBytesValue: `8:16 Read 4096
+8:16 Write 8192
+8:16 Sync 12288
+8:16 Async 0
+8:16 Discard 0
+8:16 Total 12288
+8:0 Read 1024
+8:0 Write 2048
+8:0 Sync 3072
+8:0 Async 0
+8:0 Discard 0
+8:0 Total 3072
+Total 15360`,
+				ServicedValue: `8:16 Read 4
+8:16 Write 8
+8:16 Total 12
+8:0 Read 1
+8:0 Write 2
+8:0 Total 3
+Total 15`,
+			},
+			want: []sysutil.BlkIOStatRaw{
+				{Device: "8:0", ReadBytes: 1024, WriteBytes: 2048, ReadIOs: 1, WriteIOs: 2},
+				{Device: "8:16", ReadBytes: 4096, WriteBytes: 8192, ReadIOs: 4, WriteIOs: 8},
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse v1 value failed",
+			fields: fields{
+				ServiceBytesValue: `8:0 Read abc`,
+				ServicedValue:     `8:0 Read 1`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "v2 path not exist",
+			fields: fields{
+				UseCgroupsV2: true,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse v2 value successfully",
+			fields: fields{
+				UseCgroupsV2: true,
+				IOStatV2Value: `8:16 rbytes=4096 wbytes=8192 rios=4 wios=8 dbytes=0 dios=0
+8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0`,
+			},
+			want: []sysutil.BlkIOStatRaw{
+				{Device: "8:0", ReadBytes: 1024, WriteBytes: 2048, ReadIOs: 1, WriteIOs: 2},
+				{Device: "8:16", ReadBytes: 4096, WriteBytes: 8192, ReadIOs: 4, WriteIOs: 8},
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse v2 value failed",
+			fields: fields{
+				UseCgroupsV2:  true,
+				IOStatV2Value: `8:0 rbytes=abc wbytes=2048 rios=1 wios=2 dbytes=0 dios=0`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.fields.UseCgroupsV2)
+			parentDir := "/kubepods.slice"
+			if tt.fields.ServiceBytesValue != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.BlkioIOServiceBytesRecursive, tt.fields.ServiceBytesValue)
+			}
+			if tt.fields.ServicedValue != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.BlkioIOServicedRecursive, tt.fields.ServicedValue)
+			}
+			if tt.fields.IOStatV2Value != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.IOStatV2, tt.fields.IOStatV2Value)
+			}
+
+			got, gotErr := NewCgroupReader().ReadBlkIOStat(parentDir)
+			assert.Equal(t, tt.wantErr, gotErr != nil, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -76,6 +77,7 @@ func init() {
 		sysutil.BlkioTWBpsName,
 		sysutil.BlkioIOQoSName,
 		sysutil.BlkioIOWeightName,
+		sysutil.IOMaxName,
 	)
 }
 
@@ -559,9 +561,16 @@ func cgroupBlkIOFileWriteIfDifferent(cgroupTaskDir string, file sysutil.Resource
 
 	switch file.ResourceType() {
 	case sysutil.BlkioIOQoSName:
-		needUpdate = CheckIfBlkRootConfigNeedUpdate(currentValue, value)
+		if sysutil.IsCgroupV2Resource(file) {
+			// io.cost.qos prints all the keys of the model, e.g. "253:0 enable=1 ctrl=user rpct=95.00 rlat=3000 ..."
+			needUpdate = CheckIfBlkIOKeyedConfigNeedUpdate(currentValue, value)
+		} else {
+			needUpdate = CheckIfBlkRootConfigNeedUpdate(currentValue, value)
+		}
 	case sysutil.BlkioTRIopsName, sysutil.BlkioTRBpsName, sysutil.BlkioTWIopsName, sysutil.BlkioTWBpsName, sysutil.BlkioIOWeightName:
 		needUpdate = CheckIfBlkQOSNeedUpdate(currentValue, value)
+	case sysutil.IOMaxName:
+		needUpdate = CheckIfBlkIOKeyedConfigNeedUpdate(currentValue, value)
 	default:
 		return fmt.Errorf("unknown blkio resource file %s", file.ResourceType())
 	}
@@ -628,3 +637,41 @@ func CheckIfBlkQOSNeedUpdate(oldValue string, newValue string) bool {
 
 	return needUpdate
 }
+
+// io.max: configure read/write bps and iops of cgroups-v2, e.g. "253:0 rbps=2048 wbps=max riops=max wiops=max"
+// io.cost.qos: configure iocost of cgroups-v2, e.g. "253:0 enable=1 ctrl=user rlat=3000 wlat=3000"
+// The kernel only lists the devices with any limit, and a key absent from the current value is considered as "max".
+func CheckIfBlkIOKeyedConfigNeedUpdate(oldValue string, newValue string) bool {
+	newFields := strings.Fields(newValue)
+	if len(newFields) < 2 {
+		return true
+	}
+	oldConfig := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader([]byte(oldValue)))
+	for scanner.Scan() {
+		oldFields := strings.Fields(scanner.Text())
+		if len(oldFields) < 2 || oldFields[0] != newFields[0] {
+			continue
+		}
+		for _, kv := range oldFields[1:] {
+			if pair := strings.SplitN(kv, "=", 2); len(pair) == 2 {
+				oldConfig[pair[0]] = pair[1]
+			}
+		}
+		break
+	}
+	for _, kv := range newFields[1:] {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 {
+			return true
+		}
+		oldV, ok := oldConfig[pair[0]]
+		if !ok {
+			oldV = sysutil.CgroupMaxSymbolStr
+		}
+		if oldV != pair[1] {
+			return true
+		}
+	}
+	return false
+}
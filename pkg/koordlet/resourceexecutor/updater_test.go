@@ -404,3 +404,48 @@ func TestDefaultResourceUpdater_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckIfBlkIOKeyedConfigNeedUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldValue string
+		newValue string
+		want     bool
+	}{
+		{
+			name:     "device not limited yet",
+			oldValue: "",
+			newValue: "253:16 rbps=2048 wbps=max riops=max wiops=max",
+			want:     true,
+		},
+		{
+			name:     "remove limits of a device not limited",
+			oldValue: "253:0 rbps=2048 wbps=max riops=max wiops=max",
+			newValue: "253:16 rbps=max wbps=max riops=max wiops=max",
+			want:     false,
+		},
+		{
+			name:     "limits unchanged",
+			oldValue: "253:0 rbps=max wbps=max riops=1024 wiops=max\n253:16 rbps=2048 wbps=max riops=max wiops=512",
+			newValue: "253:16 rbps=2048 wbps=max riops=max wiops=512",
+			want:     false,
+		},
+		{
+			name:     "limits changed",
+			oldValue: "253:16 rbps=2048 wbps=max riops=max wiops=512",
+			newValue: "253:16 rbps=4096 wbps=max riops=max wiops=512",
+			want:     true,
+		},
+		{
+			name:     "io.cost.qos unchanged with more keys printed",
+			oldValue: "253:16 enable=1 ctrl=user rpct=95.00 rlat=3000 wpct=95.00 wlat=3000 min=1.00 max=10000.00",
+			newValue: "253:16 enable=1 ctrl=user rlat=3000 wlat=3000",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CheckIfBlkIOKeyedConfigNeedUpdate(tt.oldValue, tt.newValue))
+		})
+	}
+}
@@ -21,9 +21,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/containerd/nri/pkg/api"
 	"github.com/containerd/nri/pkg/stub"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
 
@@ -58,14 +61,22 @@ func (o Options) Validate() error {
 }
 
 type NriServer struct {
-	cfg     nriConfig
-	stub    stub.Stub
-	mask    stub.EventMask
-	options Options // server options
+	cfg      nriConfig
+	stub     stub.Stub
+	stubOpts []stub.Option
+	mask     stub.EventMask
+	options  Options // server options
+
+	// reconnectBackoff is the backoff to re-register the plugin after the connection to the runtime is closed,
+	// e.g. the containerd restarts
+	reconnectBackoff wait.Backoff
+	lock             sync.Mutex
+	reconnecting     bool
+	stopCh           chan struct{}
 }
 
 const (
-	events     = "RunPodSandbox,CreateContainer,UpdateContainer"
+	events     = "RunPodSandbox,StopPodSandbox,CreateContainer,UpdateContainer,RemoveContainer"
 	pluginName = "koordlet_nri"
 	pluginIdx  = "00"
 )
@@ -74,8 +85,18 @@ var (
 	_ = stub.ConfigureInterface(&NriServer{})
 	_ = stub.SynchronizeInterface(&NriServer{})
 	_ = stub.RunPodInterface(&NriServer{})
+	_ = stub.StopPodInterface(&NriServer{})
 	_ = stub.CreateContainerInterface(&NriServer{})
 	_ = stub.UpdateContainerInterface(&NriServer{})
+	_ = stub.RemoveContainerInterface(&NriServer{})
+
+	defaultReconnectBackoff = wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    10,
+		Cap:      time.Minute,
+	}
 )
 
 func NewNriServer(opt Options) (*NriServer, error) {
@@ -88,14 +109,19 @@ func NewNriServer(opt Options) (*NriServer, error) {
 	opts = append(opts, stub.WithPluginName(pluginName))
 	opts = append(opts, stub.WithPluginIdx(pluginIdx))
 	opts = append(opts, stub.WithSocketPath(filepath.Join(system.Conf.VarRunRootDir, opt.NriSocketPath)))
-	p := &NriServer{options: opt}
+	p := &NriServer{
+		stubOpts:         opts,
+		options:          opt,
+		reconnectBackoff: defaultReconnectBackoff,
+		stopCh:           make(chan struct{}),
+	}
 	if p.mask, err = api.ParseEventMask(events); err != nil {
 		klog.Errorf("failed to parse events %v", err)
 		return p, err
 	}
 	p.cfg.Events = strings.Split(events, ",")
 
-	if p.stub, err = stub.New(p, append(opts, stub.WithOnClose(p.onClose))...); err != nil {
+	if p.stub, err = p.newStub(); err != nil {
 		klog.Errorf("failed to create plugin stub: %v", err)
 		return nil, err
 	}
@@ -103,12 +129,18 @@ func NewNriServer(opt Options) (*NriServer, error) {
 	return p, nil
 }
 
+func (p *NriServer) newStub() (stub.Stub, error) {
+	return stub.New(p, append(p.stubOpts, stub.WithOnClose(p.onClose))...)
+}
+
 func (p *NriServer) Start() error {
 	go func() {
 		if p.stub != nil {
 			err := p.stub.Run(context.Background())
 			if err != nil {
 				klog.Errorf("nri server exited with error: %v", err)
+				// e.g. the runtime is not ready when the koordlet starts
+				p.onClose()
 			} else {
 				klog.V(4).Info("nri server started")
 			}
@@ -140,9 +172,62 @@ func (p *NriServer) Configure(config, runtime, version string) (stub.EventMask,
 	return p.mask, nil
 }
 
+// Synchronize runs the hooks for the existing pods and containers when the plugin is registered, so the resources of
+// them are updated after the runtime or the koordlet restarts.
 func (p *NriServer) Synchronize(pods []*api.PodSandbox, containers []*api.Container) ([]*api.ContainerUpdate, error) {
-	// todo: update existed containers configure
-	return nil, nil
+	podMap := make(map[string]*api.PodSandbox, len(pods))
+	for _, pod := range pods {
+		podMap[pod.GetId()] = pod
+
+		podCtx := &protocol.PodContext{}
+		podCtx.FromNri(pod)
+		err := hooks.RunHooks(p.options.PluginFailurePolicy, rmconfig.PreRunPodSandbox, podCtx)
+		if err != nil {
+			klog.Errorf("nri hooks run error: %v", err)
+			if p.options.PluginFailurePolicy == rmconfig.PolicyFail {
+				return nil, err
+			}
+		}
+		podCtx.NriDone(p.options.Executor)
+	}
+
+	var updates []*api.ContainerUpdate
+	for _, container := range containers {
+		if container.GetState() != api.ContainerState_CONTAINER_CREATED && container.GetState() != api.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		pod, ok := podMap[container.GetPodSandboxId()]
+		if !ok {
+			klog.V(4).Infof("pod sandbox %s of container %s not found during NRI Synchronize, skip it",
+				container.GetPodSandboxId(), container.GetId())
+			continue
+		}
+
+		containerCtx := &protocol.ContainerContext{}
+		containerCtx.FromNri(pod, container)
+		err := hooks.RunHooks(p.options.PluginFailurePolicy, rmconfig.PreUpdateContainerResources, containerCtx)
+		if err != nil {
+			klog.Errorf("nri run hooks error: %v", err)
+			if p.options.PluginFailurePolicy == rmconfig.PolicyFail {
+				return nil, err
+			}
+		}
+
+		_, update, err := containerCtx.NriDone(p.options.Executor)
+		if err != nil {
+			klog.Errorf("containerCtx nri done failed: %v", err)
+			continue
+		}
+		if update.GetLinux().GetResources() == nil {
+			continue
+		}
+		update.SetContainerId(container.GetId())
+		updates = append(updates, update)
+	}
+
+	klog.V(6).Infof("handle NRI Synchronize successfully, pods %d, containers %d, updates %d",
+		len(pods), len(containers), len(updates))
+	return updates, nil
 }
 
 func (p *NriServer) RunPodSandbox(pod *api.PodSandbox) error {
@@ -162,6 +247,22 @@ func (p *NriServer) RunPodSandbox(pod *api.PodSandbox) error {
 	return nil
 }
 
+func (p *NriServer) StopPodSandbox(pod *api.PodSandbox) error {
+	podCtx := &protocol.PodContext{}
+	podCtx.FromNri(pod)
+	err := hooks.RunHooks(p.options.PluginFailurePolicy, rmconfig.PostStopPodSandbox, podCtx)
+	if err != nil {
+		klog.Errorf("nri hooks run error: %v", err)
+		if p.options.PluginFailurePolicy == rmconfig.PolicyFail {
+			return err
+		}
+	}
+	podCtx.NriDone(p.options.Executor)
+
+	klog.V(6).Infof("handle NRI StopPodSandbox successfully, pod %s/%s", pod.GetNamespace(), pod.GetName())
+	return nil
+}
+
 func (p *NriServer) CreateContainer(pod *api.PodSandbox, container *api.Container) (*api.ContainerAdjustment, []*api.ContainerUpdate, error) {
 	containerCtx := &protocol.ContainerContext{}
 	containerCtx.FromNri(pod, container)
@@ -208,7 +309,89 @@ func (p *NriServer) UpdateContainer(pod *api.PodSandbox, container *api.Containe
 	return []*api.ContainerUpdate{update}, nil
 }
 
+func (p *NriServer) RemoveContainer(pod *api.PodSandbox, container *api.Container) error {
+	containerCtx := &protocol.ContainerContext{}
+	containerCtx.FromNri(pod, container)
+	err := hooks.RunHooks(p.options.PluginFailurePolicy, rmconfig.PostStopContainer, containerCtx)
+	if err != nil {
+		klog.Errorf("nri run hooks error: %v", err)
+		if p.options.PluginFailurePolicy == rmconfig.PolicyFail {
+			return err
+		}
+	}
+
+	if _, _, err = containerCtx.NriDone(p.options.Executor); err != nil {
+		klog.Errorf("containerCtx nri done failed: %v", err)
+		return nil
+	}
+
+	klog.V(6).Infof("handle NRI RemoveContainer successfully, container %s/%s/%s",
+		pod.GetNamespace(), pod.GetName(), container.GetName())
+	return nil
+}
+
+// Stop stops the plugin and the reconnection.
+func (p *NriServer) Stop() {
+	p.lock.Lock()
+	select {
+	case <-p.stopCh:
+		p.lock.Unlock()
+		return
+	default:
+		close(p.stopCh)
+	}
+	s := p.stub
+	p.lock.Unlock()
+
+	// stopping the stub calls the onClose
+	if s != nil {
+		s.Stop()
+	}
+}
+
+// onClose is called when the connection to the runtime is lost. The stub has been closed and cannot be started again,
+// so a new stub is created and registered to the runtime until it succeeds.
 func (p *NriServer) onClose() {
-	p.stub.Stop()
-	klog.V(6).Infof("NRI server closes")
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	select {
+	case <-p.stopCh:
+		klog.V(4).Infof("NRI server closes")
+		return
+	default:
+	}
+	if p.reconnecting {
+		return
+	}
+	p.reconnecting = true
+	klog.Warningf("NRI server connection closed, try to reconnect")
+	go p.reconnect()
+}
+
+func (p *NriServer) reconnect() {
+	backoff := p.reconnectBackoff
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(backoff.Step()):
+		}
+
+		newStub, err := p.newStub()
+		if err == nil {
+			// the runtime calls Synchronize after the plugin is registered
+			err = newStub.Start(context.Background())
+		}
+		if err != nil {
+			klog.Warningf("failed to reconnect NRI server, retry later, err: %v", err)
+			continue
+		}
+
+		p.lock.Lock()
+		p.stub = newStub
+		p.reconnecting = false
+		p.lock.Unlock()
+		klog.Infof("NRI server reconnected")
+		return
+	}
 }
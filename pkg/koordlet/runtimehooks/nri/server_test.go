@@ -18,16 +18,42 @@ package nri
 
 import (
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/containerd/nri/pkg/api"
 	"github.com/containerd/nri/pkg/stub"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
+const testSyncContainerName = "test-sync-container"
+
+var registerTestHookOnce sync.Once
+
+// registerTestHook registers a hook to set the cpu shares of the test container, which is only registered once since
+// the hooks are global.
+func registerTestHook() {
+	registerTestHookOnce.Do(func() {
+		hooks.Register(config.PreUpdateContainerResources, "test-nri-sync", "set cpu shares for test",
+			func(p protocol.HooksProtocol) error {
+				containerCtx := p.(*protocol.ContainerContext)
+				if containerCtx.Request.ContainerMeta.Name == testSyncContainerName {
+					containerCtx.Response.Resources.CPUShares = pointer.Int64(1024)
+				}
+				return nil
+			})
+	})
+}
+
 func getDisableStagesMap(stagesSlice []string) map[string]struct{} {
 	stagesMap := map[string]struct{}{}
 	for _, item := range stagesSlice {
@@ -293,7 +319,64 @@ func TestNriServer_Synchronize(t *testing.T) {
 			want:    nil,
 			wantErr: false,
 		},
+		{
+			name: "synchronize existing containers",
+			fields: fields{
+				options: Options{
+					Executor: resourceexecutor.NewTestResourceExecutor(),
+				},
+			},
+			args: args{
+				pods: []*api.PodSandbox{
+					{
+						Id:        "pod-sandbox-1",
+						Name:      "test-pod",
+						Uid:       "test-pod-uid",
+						Namespace: "test",
+						Linux: &api.LinuxPodSandbox{
+							CgroupParent: "kubepods/besteffort/podtest-pod-uid",
+						},
+					},
+				},
+				containers: []*api.Container{
+					{
+						Id:           "container-1",
+						PodSandboxId: "pod-sandbox-1",
+						Name:         testSyncContainerName,
+						State:        api.ContainerState_CONTAINER_RUNNING,
+					},
+					{
+						Id:           "container-2",
+						PodSandboxId: "pod-sandbox-1",
+						Name:         "test-container-without-update",
+						State:        api.ContainerState_CONTAINER_RUNNING,
+					},
+					{
+						Id:           "container-3",
+						PodSandboxId: "pod-sandbox-1",
+						Name:         testSyncContainerName,
+						State:        api.ContainerState_CONTAINER_STOPPED,
+					},
+					{
+						Id:           "container-4",
+						PodSandboxId: "pod-sandbox-not-found",
+						Name:         testSyncContainerName,
+						State:        api.ContainerState_CONTAINER_RUNNING,
+					},
+				},
+			},
+			want: []*api.ContainerUpdate{
+				func() *api.ContainerUpdate {
+					update := &api.ContainerUpdate{}
+					update.SetLinuxCPUShares(1024)
+					update.SetContainerId("container-1")
+					return update
+				}(),
+			},
+			wantErr: false,
+		},
 	}
+	registerTestHook()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &NriServer{
@@ -507,3 +590,77 @@ func TestNriServer_UpdateContainer(t *testing.T) {
 		})
 	}
 }
+
+func TestNriServer_StopPodSandbox(t *testing.T) {
+	p := &NriServer{
+		options: Options{
+			PluginFailurePolicy: config.PolicyIgnore,
+			Executor:            resourceexecutor.NewTestResourceExecutor(),
+		},
+	}
+	err := p.StopPodSandbox(&api.PodSandbox{
+		Id:        "test",
+		Name:      "test",
+		Uid:       "test",
+		Namespace: "test",
+		Linux:     &api.LinuxPodSandbox{},
+	})
+	assert.NoError(t, err)
+}
+
+func TestNriServer_RemoveContainer(t *testing.T) {
+	p := &NriServer{
+		options: Options{
+			PluginFailurePolicy: config.PolicyIgnore,
+			Executor:            resourceexecutor.NewTestResourceExecutor(),
+		},
+	}
+	err := p.RemoveContainer(&api.PodSandbox{
+		Id:        "test",
+		Name:      "test",
+		Uid:       "test",
+		Namespace: "test",
+		Linux:     &api.LinuxPodSandbox{},
+	}, &api.Container{
+		Id:           "test-container",
+		PodSandboxId: "test",
+		Name:         "test-container",
+	})
+	assert.NoError(t, err)
+}
+
+func TestNriServer_Reconnect(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	// the socket file exists but nobody listens on it
+	helper.WriteFileContents("nri/nri.sock", "")
+
+	p, err := NewNriServer(Options{
+		NriSocketPath:       "nri/nri.sock",
+		PluginFailurePolicy: config.PolicyIgnore,
+		Executor:            resourceexecutor.NewTestResourceExecutor(),
+	})
+	assert.NoError(t, err)
+	p.reconnectBackoff = wait.Backoff{Duration: 10 * time.Millisecond, Factor: 1, Steps: 1}
+
+	// the connection is refused, so it keeps reconnecting
+	assert.NoError(t, p.Start())
+	assert.Eventually(t, func() bool {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return p.reconnecting
+	}, time.Second, 10*time.Millisecond)
+	// only one reconnection runs at the same time
+	p.onClose()
+	p.lock.Lock()
+	assert.True(t, p.reconnecting)
+	p.lock.Unlock()
+
+	p.Stop()
+	p.Stop()
+	select {
+	case <-p.stopCh:
+	default:
+		t.Errorf("NRI server is not stopped")
+	}
+}
@@ -75,6 +75,9 @@ func (r *runtimeHook) Run(stopCh <-chan struct{}) error {
 	}
 	klog.V(5).Infof("runtime hook server has started")
 	<-stopCh
+	if r.nriServer != nil {
+		r.nriServer.Stop()
+	}
 	klog.Infof("runtime hook is stopped")
 	return nil
 }
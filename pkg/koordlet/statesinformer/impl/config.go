@@ -33,6 +33,8 @@ type Config struct {
 	DisableQueryKubeletConfig   bool
 	EnableNodeMetricReport      bool
 	MetricReportInterval        time.Duration // Deprecated
+	PodResourcesSocketPath      string
+	PodResourcesSyncInterval    time.Duration
 }
 
 func NewDefaultConfig() *Config {
@@ -45,6 +47,8 @@ func NewDefaultConfig() *Config {
 		NodeTopologySyncInterval:    3 * time.Second,
 		DisableQueryKubeletConfig:   false,
 		EnableNodeMetricReport:      true,
+		PodResourcesSocketPath:      "/var/lib/kubelet/pod-resources/kubelet.sock",
+		PodResourcesSyncInterval:    10 * time.Second,
 	}
 }
 
@@ -58,4 +62,6 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.DisableQueryKubeletConfig, "disable-query-kubelet-config", c.DisableQueryKubeletConfig, "Disables querying the kubelet configuration from kubelet. Flag must be set to true if kubelet-insecure-tls=true is configured")
 	fs.DurationVar(&c.MetricReportInterval, "report-interval", c.MetricReportInterval, "Deprecated since v1.1, use ColocationStrategy.MetricReportIntervalSeconds in config map of slo-controller")
 	fs.BoolVar(&c.EnableNodeMetricReport, "enable-node-metric-report", c.EnableNodeMetricReport, "Enable status update of node metric crd.")
+	fs.StringVar(&c.PodResourcesSocketPath, "kubelet-pod-resources-socket", c.PodResourcesSocketPath, "The unix socket of the kubelet PodResources API, which reports the exclusive cpus and devices allocated by kubelet.")
+	fs.DurationVar(&c.PodResourcesSyncInterval, "kubelet-pod-resources-sync-interval", c.PodResourcesSyncInterval, "The interval at which Koordlet will list the pod resources from the kubelet PodResources API. Zero means disabled.")
 }
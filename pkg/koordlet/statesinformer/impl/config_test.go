@@ -42,6 +42,8 @@ func TestNewDefaultConfig(t *testing.T) {
 				DisableQueryKubeletConfig:   false,
 				EnableNodeMetricReport:      true,
 				MetricReportInterval:        0,
+				PodResourcesSocketPath:      "/var/lib/kubelet/pod-resources/kubelet.sock",
+				PodResourcesSyncInterval:    10 * time.Second,
 			},
 		},
 	}
@@ -64,6 +66,8 @@ func TestConfig_InitFlags(t *testing.T) {
 		"--node-topology-sync-interval=10s",
 		"--disable-query-kubelet-config=true",
 		"--enable-node-metric-report=false",
+		"--kubelet-pod-resources-socket=/var/run/kubelet/pod-resources/kubelet.sock",
+		"--kubelet-pod-resources-sync-interval=0s",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
@@ -76,6 +80,8 @@ func TestConfig_InitFlags(t *testing.T) {
 		NodeTopologySyncInterval    time.Duration
 		DisableQueryKubeletConfig   bool
 		EnableNodeMetricReport      bool
+		PodResourcesSocketPath      string
+		PodResourcesSyncInterval    time.Duration
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -96,6 +102,8 @@ func TestConfig_InitFlags(t *testing.T) {
 				NodeTopologySyncInterval:    10 * time.Second,
 				DisableQueryKubeletConfig:   true,
 				EnableNodeMetricReport:      false,
+				PodResourcesSocketPath:      "/var/run/kubelet/pod-resources/kubelet.sock",
+				PodResourcesSyncInterval:    0,
 			},
 			args: args{fs: fs},
 		},
@@ -111,6 +119,8 @@ func TestConfig_InitFlags(t *testing.T) {
 				NodeTopologySyncInterval:    tt.fields.NodeTopologySyncInterval,
 				DisableQueryKubeletConfig:   tt.fields.DisableQueryKubeletConfig,
 				EnableNodeMetricReport:      tt.fields.EnableNodeMetricReport,
+				PodResourcesSocketPath:      tt.fields.PodResourcesSocketPath,
+				PodResourcesSyncInterval:    tt.fields.PodResourcesSyncInterval,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
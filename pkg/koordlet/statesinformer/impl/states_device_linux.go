@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -43,14 +44,19 @@ func (s *statesInformer) reportDevice() {
 		return
 	}
 	gpuDevices := s.buildGPUDevice()
-	if len(gpuDevices) == 0 {
+	// the empty rdma devices are also reported to clean up the removed devices
+	rdmaDevices, rdmaReported := s.buildRDMADevice()
+	if len(gpuDevices) == 0 && !rdmaReported {
 		return
 	}
 
-	gpuModel, gpuDriverVer := s.getGPUDriverAndModelFunc()
-
 	device := s.buildBasicDevice(node)
-	s.fillGPUDevice(device, gpuDevices, gpuModel, gpuDriverVer)
+	if len(gpuDevices) > 0 {
+		gpuModel, gpuDriverVer := s.getGPUDriverAndModelFunc()
+		s.fillGPUDevice(device, gpuDevices, gpuModel, gpuDriverVer)
+		device.Status.Allocations = s.buildKubeletGPUAllocations(gpuDevices)
+	}
+	device.Spec.Devices = append(device.Spec.Devices, rdmaDevices...)
 
 	err := s.updateDevice(device)
 	if err == nil {
@@ -61,6 +67,9 @@ func (s *statesInformer) reportDevice() {
 		klog.Errorf("Failed to updateDevice %s, err: %v", node.Name, err)
 		return
 	}
+	if len(device.Spec.Devices) == 0 {
+		return
+	}
 
 	err = s.createDevice(device)
 	if err == nil {
@@ -114,6 +123,9 @@ func (s *statesInformer) createDevice(device *schedulingv1alpha1.Device) error {
 func (s *statesInformer) updateDevice(device *schedulingv1alpha1.Device) error {
 	sorter := func(devices []schedulingv1alpha1.DeviceInfo) {
 		sort.Slice(devices, func(i, j int) bool {
+			if devices[i].Type != devices[j].Type {
+				return devices[i].Type < devices[j].Type
+			}
 			return *(devices[i].Minor) < *(devices[j].Minor)
 		})
 	}
@@ -127,13 +139,15 @@ func (s *statesInformer) updateDevice(device *schedulingv1alpha1.Device) error {
 		sorter(latestDevice.Spec.Devices)
 
 		if apiequality.Semantic.DeepEqual(device.Spec.Devices, latestDevice.Spec.Devices) &&
-			apiequality.Semantic.DeepEqual(device.Labels, latestDevice.Labels) {
+			apiequality.Semantic.DeepEqual(device.Labels, latestDevice.Labels) &&
+			apiequality.Semantic.DeepEqual(device.Status.Allocations, latestDevice.Status.Allocations) {
 			klog.V(4).Infof("Device %s has not changed and does not need to be updated", device.Name)
 			return nil
 		}
 
 		latestDevice.Spec.Devices = device.Spec.Devices
 		latestDevice.Labels = device.Labels
+		latestDevice.Status.Allocations = device.Status.Allocations
 
 		_, err = s.deviceClient.Update(context.TODO(), latestDevice, metav1.UpdateOptions{})
 		return err
@@ -189,6 +203,111 @@ func (s *statesInformer) buildGPUDevice() []schedulingv1alpha1.DeviceInfo {
 	return deviceInfos
 }
 
+// buildKubeletGPUAllocations returns the gpus allocated by the kubelet device plugin, which are reported in the status
+// of the Device, so the scheduler will not allocate them to the pods again.
+func (s *statesInformer) buildKubeletGPUAllocations(gpuDevices []schedulingv1alpha1.DeviceInfo) []schedulingv1alpha1.DeviceAllocation {
+	allocatedDevices := s.getKubeletAllocatedDevices()
+	if len(allocatedDevices) == 0 {
+		return nil
+	}
+	podMinors := map[types.NamespacedName][]int32{}
+	for _, gpu := range gpuDevices {
+		if pod, ok := allocatedDevices[gpu.UUID]; ok {
+			klog.V(5).Infof("gpu %s is allocated by kubelet device plugin to pod %s", gpu.UUID, pod)
+			podMinors[pod] = append(podMinors[pod], *gpu.Minor)
+		}
+	}
+	if len(podMinors) == 0 {
+		return nil
+	}
+
+	entries := make([]schedulingv1alpha1.DeviceAllocationItem, 0, len(podMinors))
+	for pod, minors := range podMinors {
+		sort.Slice(minors, func(i, j int) bool {
+			return minors[i] < minors[j]
+		})
+		entries = append(entries, schedulingv1alpha1.DeviceAllocationItem{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Minors:    minors,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return []schedulingv1alpha1.DeviceAllocation{{Type: schedulingv1alpha1.GPU, Entries: entries}}
+}
+
+func (s *statesInformer) getKubeletAllocatedDevices() map[string]types.NamespacedName {
+	if s.states == nil {
+		return nil
+	}
+	podResourcesInformer, ok := s.states.informerPlugins[podResourcesInformerName].(*podResourcesInformer)
+	if !ok {
+		return nil
+	}
+	return podResourcesInformer.GetAllocatedDevices()
+}
+
+// buildRDMADevice returns the rdma devices and whether the rdma devices are collected, which may be empty.
+func (s *statesInformer) buildRDMADevice() ([]schedulingv1alpha1.DeviceInfo, bool) {
+	rdmaDeviceInfo, exist := s.metricsCache.Get(koordletuti.RDMADeviceType)
+	if !exist {
+		klog.V(4).Infof("rdma device not exist")
+		return nil, false
+	}
+	rdmas, ok := rdmaDeviceInfo.(koordletuti.RDMADevices)
+	if !ok {
+		klog.Errorf("value type error, expect: %T, got %T", koordletuti.RDMADevices{}, rdmaDeviceInfo)
+		return nil, false
+	}
+
+	var deviceInfos []schedulingv1alpha1.DeviceInfo
+	for idx := range rdmas {
+		rdma := rdmas[idx]
+		var topology *schedulingv1alpha1.DeviceTopology
+		if rdma.NodeID >= 0 && rdma.PCIE != "" && rdma.BusID != "" {
+			topology = &schedulingv1alpha1.DeviceTopology{
+				SocketID: -1,
+				NodeID:   rdma.NodeID,
+				PCIEID:   rdma.PCIE,
+				BusID:    rdma.BusID,
+			}
+		}
+
+		// the unhealthy VFs are not reported to avoid being allocated
+		var vfs []schedulingv1alpha1.VirtualFunction
+		for _, vf := range rdma.VFs {
+			if vf.Health {
+				vfs = append(vfs, schedulingv1alpha1.VirtualFunction{
+					Minor: vf.Minor,
+					BusID: vf.BusID,
+				})
+			}
+		}
+		var vfGroups []schedulingv1alpha1.VirtualFunctionGroup
+		if len(vfs) > 0 {
+			vfGroups = []schedulingv1alpha1.VirtualFunctionGroup{{VFs: vfs}}
+		}
+
+		deviceInfos = append(deviceInfos, schedulingv1alpha1.DeviceInfo{
+			UUID:   rdma.BusID,
+			Minor:  &rdma.Minor,
+			Type:   schedulingv1alpha1.RDMA,
+			Health: rdma.Health,
+			Resources: map[corev1.ResourceName]resource.Quantity{
+				extension.ResourceRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+			Topology: topology,
+			VFGroups: vfGroups,
+		})
+	}
+	return deviceInfos, true
+}
+
 func (s *statesInformer) initGPU() bool {
 	if ret := nvml.Init(); ret != nvml.SUCCESS {
 		if ret == nvml.ERROR_LIBRARY_NOT_FOUND {
@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -51,6 +52,7 @@ func Test_reportGPUDevice(t *testing.T) {
 		{UUID: "3", Minor: 3, MemoryTotal: 8000, BusID: "0000:00:08.0", NodeID: 0, PCIE: "pci0000:00"},
 	}
 	mockMetricCache.EXPECT().Get(koordletutil.GPUDeviceType).Return(gpuDeviceInfo, true)
+	mockMetricCache.EXPECT().Get(koordletutil.RDMADeviceType).Return(nil, false).AnyTimes()
 	r := &statesInformer{
 		deviceClient: fakeClient,
 		metricsCache: mockMetricCache,
@@ -136,4 +138,144 @@ func Test_reportGPUDevice(t *testing.T) {
 	assert.Equal(t, device.Spec.Devices, expectedDevices)
 	assert.Equal(t, device.Labels[extension.LabelGPUModel], "A100")
 	assert.Equal(t, device.Labels[extension.LabelGPUDriverVersion], "470")
+
+	// gpu allocated by the kubelet device plugin
+	podResourcesInformer := NewPodResourcesInformer()
+	podResourcesInformer.updatePodResources([]*podresourcesapi.PodResources{
+		{
+			Name:      "test-pod",
+			Namespace: "default",
+			Containers: []*podresourcesapi.ContainerResources{
+				{
+					Name: "test-container",
+					Devices: []*podresourcesapi.ContainerDevices{
+						{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"2"}},
+					},
+				},
+			},
+		},
+	}, nil)
+	r.states.informerPlugins[podResourcesInformerName] = podResourcesInformer
+	mockMetricCache.EXPECT().Get(koordletutil.GPUDeviceType).Return(gpuDeviceInfo, true)
+	r.reportDevice()
+
+	// the allocated gpu is still healthy, and it is reported in the status
+	device, err = fakeClient.Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, device.Spec.Devices, expectedDevices)
+	assert.Equal(t, []schedulingv1alpha1.DeviceAllocation{
+		{
+			Type: schedulingv1alpha1.GPU,
+			Entries: []schedulingv1alpha1.DeviceAllocationItem{
+				{Name: "test-pod", Namespace: "default", Minors: []int32{2}},
+			},
+		},
+	}, device.Status.Allocations)
+
+	// the gpu is released by the kubelet
+	podResourcesInformer.updatePodResources([]*podresourcesapi.PodResources{}, nil)
+	mockMetricCache.EXPECT().Get(koordletutil.GPUDeviceType).Return(gpuDeviceInfo, true)
+	r.reportDevice()
+	device, err = fakeClient.Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Empty(t, device.Status.Allocations)
+}
+
+func Test_reportRDMADevice(t *testing.T) {
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+	fakeClient := schedulingfake.NewSimpleClientset().SchedulingV1alpha1().Devices()
+	ctl := gomock.NewController(t)
+	mockMetricCache := mock_metriccache.NewMockMetricCache(ctl)
+	rdmaDeviceInfo := koordletutil.RDMADevices{
+		{ID: "mlx5_0", Minor: 0, BusID: "0000:1f:00.0", NodeID: 0, PCIE: "pci0000:1d", Health: true,
+			VFs: []koordletutil.RDMAVirtualFunction{
+				{Minor: 0, BusID: "0000:1f:00.2", Health: true},
+				{Minor: 1, BusID: "0000:1f:00.3", Health: false},
+			}},
+		{ID: "mlx5_1", Minor: 1, BusID: "0000:90:00.0", NodeID: 1, PCIE: "pci0000:8e", Health: false},
+	}
+	mockMetricCache.EXPECT().Get(koordletutil.GPUDeviceType).Return(nil, false)
+	mockMetricCache.EXPECT().Get(koordletutil.RDMADeviceType).Return(rdmaDeviceInfo, true)
+	r := &statesInformer{
+		deviceClient: fakeClient,
+		metricsCache: mockMetricCache,
+		states: &PluginState{
+			informerPlugins: map[PluginName]informerPlugin{
+				nodeInformerName: &nodeInformer{
+					node: testNode,
+				},
+			},
+		},
+		getGPUDriverAndModelFunc: func() (string, string) {
+			t.Error("should not get gpu driver and model without gpu")
+			return "", ""
+		},
+	}
+	r.reportDevice()
+	expectedDevices := []schedulingv1alpha1.DeviceInfo{
+		{
+			UUID:   "0000:1f:00.0",
+			Minor:  pointer.Int32(0),
+			Type:   schedulingv1alpha1.RDMA,
+			Health: true,
+			Resources: map[corev1.ResourceName]resource.Quantity{
+				extension.ResourceRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+			Topology: &schedulingv1alpha1.DeviceTopology{
+				SocketID: -1,
+				NodeID:   0,
+				PCIEID:   "pci0000:1d",
+				BusID:    "0000:1f:00.0",
+			},
+			VFGroups: []schedulingv1alpha1.VirtualFunctionGroup{
+				{
+					VFs: []schedulingv1alpha1.VirtualFunction{
+						{Minor: 0, BusID: "0000:1f:00.2"},
+					},
+				},
+			},
+		},
+		{
+			UUID:   "0000:90:00.0",
+			Minor:  pointer.Int32(1),
+			Type:   schedulingv1alpha1.RDMA,
+			Health: false,
+			Resources: map[corev1.ResourceName]resource.Quantity{
+				extension.ResourceRDMA: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+			Topology: &schedulingv1alpha1.DeviceTopology{
+				SocketID: -1,
+				NodeID:   1,
+				PCIEID:   "pci0000:8e",
+				BusID:    "0000:90:00.0",
+			},
+		},
+	}
+	device, err := fakeClient.Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDevices, device.Spec.Devices)
+	assert.Empty(t, device.Labels[extension.LabelGPUModel])
+
+	// the VF becomes healthy
+	rdmaDeviceInfo[0].VFs[1].Health = true
+	mockMetricCache.EXPECT().Get(koordletutil.GPUDeviceType).Return(nil, false)
+	mockMetricCache.EXPECT().Get(koordletutil.RDMADeviceType).Return(rdmaDeviceInfo, true)
+	r.reportDevice()
+	expectedDevices[0].VFGroups[0].VFs = append(expectedDevices[0].VFGroups[0].VFs,
+		schedulingv1alpha1.VirtualFunction{Minor: 1, BusID: "0000:1f:00.3"})
+	device, err = fakeClient.Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDevices, device.Spec.Devices)
+
+	// the removed devices are cleaned up
+	mockMetricCache.EXPECT().Get(koordletutil.GPUDeviceType).Return(nil, false)
+	mockMetricCache.EXPECT().Get(koordletutil.RDMADeviceType).Return(koordletutil.RDMADevices{}, true)
+	r.reportDevice()
+	device, err = fakeClient.Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Empty(t, device.Spec.Devices)
 }
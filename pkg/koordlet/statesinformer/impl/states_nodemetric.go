@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"sync"
 	"time"
@@ -45,6 +46,7 @@ import (
 	clientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	clientsetv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1"
 	listerv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/prediction"
@@ -100,6 +102,8 @@ type nodeMetricInformer struct {
 	nodeSLOInformer  *nodeSLOInformer
 	metricCache      metriccache.MetricCache
 	predictorFactory prediction.PredictorFactory
+	// podCPIBaselines is only accessed by the metric collection, indexed by the pod uid
+	podCPIBaselines map[string]*podCPIBaseline
 
 	rwMutex    sync.RWMutex
 	nodeMetric *slov1alpha1.NodeMetric
@@ -360,7 +364,28 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 		Start:     &startTime,
 		End:       &endTime,
 	}
-	prodPredictor := r.predictorFactory.New(prediction.ProdReclaimablePredictor)
+	if features.DefaultKoordletFeatureGate.Enabled(features.NetworkCollector) {
+		nodeNetworkUsage, err := r.collectNodeNetworkMetric(queryParam)
+		if err != nil {
+			klog.V(4).Infof("collect node network metric failed, error: %v", err)
+		} else {
+			nodeMetricInfo.NetworkUsage = nodeNetworkUsage
+		}
+	}
+	var blockDevices []string
+	if features.DefaultKoordletFeatureGate.Enabled(features.BlkIOCollector) {
+		if value, ok := r.metricCache.Get(metriccache.NodeBlockDevicesKey); ok {
+			if blockDevices, ok = value.([]string); !ok {
+				klog.Errorf("value type error, expect: %T, got %T", []string{}, value)
+			}
+		}
+		nodeMetricInfo.BlockIOUsages = r.collectNodeBlkIOMetric(queryParam, blockDevices)
+	}
+	prodPredictorType := prediction.ProdReclaimablePredictor
+	if features.DefaultKoordletFeatureGate.Enabled(features.SeasonalPrediction) {
+		prodPredictorType = prediction.ProdReclaimableSeasonalPredictor
+	}
+	prodPredictor := r.predictorFactory.New(prodPredictorType)
 
 	for _, podMeta := range podsMeta {
 		podMetric, err := r.collectPodMetric(podMeta, queryParam)
@@ -378,8 +403,18 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 		if len(gpus) > 0 {
 			r.fillGPUMetrics(queryParam, podMetric, string(podMeta.Pod.UID), gpus)
 		}
+		if features.DefaultKoordletFeatureGate.Enabled(features.NetworkCollector) && !podMeta.Pod.Spec.HostNetwork {
+			r.fillNetworkMetrics(queryParam, podMetric, string(podMeta.Pod.UID))
+		}
+		if len(blockDevices) > 0 {
+			r.fillBlkIOMetrics(queryParam, podMetric, string(podMeta.Pod.UID), blockDevices)
+		}
+		if features.DefaultKoordletFeatureGate.Enabled(features.CPICollector) || features.DefaultKoordletFeatureGate.Enabled(features.PSICollector) {
+			r.fillInterferenceMetrics(queryParam, podMetric, string(podMeta.Pod.UID))
+		}
 		podsMetricInfo = append(podsMetricInfo, podMetric)
 	}
+	r.cleanupPodCPIBaselines(podsMeta)
 	for _, hostApp := range nodeSLO.Spec.HostApplications {
 		appMetric, err := r.collectHostAppMetric(&hostApp, queryParam)
 		if err != nil {
@@ -825,6 +860,272 @@ func (r *nodeMetricInformer) fillGPUMetrics(queryparam metriccache.QueryParam, i
 	info.PodUsage.Devices = podGPUMetrics
 }
 
+func (r *nodeMetricInformer) fillNetworkMetrics(queryparam metriccache.QueryParam, info *slov1alpha1.PodMetricInfo, uid string) {
+	networkUsage, err := r.queryNetworkUsage(queryparam, metriccache.PodNetworkMetric, func(netStat string) map[metriccache.MetricProperty]string {
+		return metriccache.MetricPropertiesFunc.PodNetwork(uid, netStat)
+	})
+	if err != nil {
+		klog.V(5).Infof("collect pod UID(%s) network metric failed, error: %v", uid, err)
+		return
+	}
+
+	info.NetworkUsage = networkUsage
+}
+
+func (r *nodeMetricInformer) collectNodeNetworkMetric(queryparam metriccache.QueryParam) (*slov1alpha1.NetworkUsage, error) {
+	return r.queryNetworkUsage(queryparam, metriccache.NodeNetworkMetric, metriccache.MetricPropertiesFunc.NodeNetwork)
+}
+
+// queryNetworkUsage aggregates the traffic rates of each network stat, all stats are required.
+func (r *nodeMetricInformer) queryNetworkUsage(queryparam metriccache.QueryParam, metricResource metriccache.MetricResource,
+	propertiesFn func(netStat string) map[metriccache.MetricProperty]string) (*slov1alpha1.NetworkUsage, error) {
+	querier, err := r.metricCache.Querier(*queryparam.Start, *queryparam.End)
+	if err != nil {
+		return nil, err
+	}
+
+	networkUsage := &slov1alpha1.NetworkUsage{}
+	for netStat, field := range map[metriccache.MetricPropertyValue]*resource.Quantity{
+		metriccache.NetStatRxBytes:   &networkUsage.RxBytes,
+		metriccache.NetStatTxBytes:   &networkUsage.TxBytes,
+		metriccache.NetStatRxPackets: &networkUsage.RxPackets,
+		metriccache.NetStatTxPackets: &networkUsage.TxPackets,
+		metriccache.NetStatRxDrops:   &networkUsage.RxDrops,
+		metriccache.NetStatTxDrops:   &networkUsage.TxDrops,
+	} {
+		aggregateResult, err := doQuery(querier, metricResource, propertiesFn(string(netStat)))
+		if err != nil {
+			return nil, err
+		}
+		value, err := aggregateResult.Value(queryparam.Aggregate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate %s, err: %w", netStat, err)
+		}
+		*field = *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI)
+	}
+	return networkUsage, nil
+}
+
+func (r *nodeMetricInformer) fillBlkIOMetrics(queryparam metriccache.QueryParam, info *slov1alpha1.PodMetricInfo, uid string, devices []string) {
+	querier, err := r.metricCache.Querier(*queryparam.Start, *queryparam.End)
+	if err != nil {
+		klog.V(5).Infof("collect pod UID(%s) blkio metric failed, error: %v", uid, err)
+		return
+	}
+	for _, device := range devices {
+		// the pod may have no I/O on the device
+		usage, err := queryBlockIOUsage(querier, queryparam.Aggregate, device, false, metriccache.PodBlkIOMetric,
+			func(blkioStat string) map[metriccache.MetricProperty]string {
+				return metriccache.MetricPropertiesFunc.PodBlkIO(uid, device, blkioStat)
+			})
+		if err != nil {
+			klog.V(6).Infof("collect pod UID(%s) blkio metric on device %s failed, error: %v", uid, device, err)
+			continue
+		}
+		info.BlockIOUsages = append(info.BlockIOUsages, *usage)
+	}
+}
+
+func (r *nodeMetricInformer) collectNodeBlkIOMetric(queryparam metriccache.QueryParam, devices []string) []slov1alpha1.BlockIOUsage {
+	querier, err := r.metricCache.Querier(*queryparam.Start, *queryparam.End)
+	if err != nil {
+		klog.V(4).Infof("collect node blkio metric failed, error: %v", err)
+		return nil
+	}
+	var usages []slov1alpha1.BlockIOUsage
+	for _, device := range devices {
+		usage, err := queryBlockIOUsage(querier, queryparam.Aggregate, device, true, metriccache.NodeBlkIOMetric,
+			func(blkioStat string) map[metriccache.MetricProperty]string {
+				return metriccache.MetricPropertiesFunc.NodeBlkIO(device, blkioStat)
+			})
+		if err != nil {
+			// the device is used by pods but not a whole disk of node, e.g. a partition or a device mapper
+			klog.V(6).Infof("collect node blkio metric on device %s failed, error: %v", device, err)
+			continue
+		}
+		usages = append(usages, *usage)
+	}
+	return usages
+}
+
+// queryBlockIOUsage aggregates the block I/O rates of the device, all stats are required.
+func queryBlockIOUsage(querier metriccache.Querier, aggregate metriccache.AggregationType, device string, withLatency bool,
+	metricResource metriccache.MetricResource, propertiesFn func(blkioStat string) map[metriccache.MetricProperty]string) (*slov1alpha1.BlockIOUsage, error) {
+	usage := &slov1alpha1.BlockIOUsage{Device: device}
+	fields := map[metriccache.MetricPropertyValue]*resource.Quantity{
+		metriccache.BlkIOStatReadBytes:  &usage.ReadBytes,
+		metriccache.BlkIOStatWriteBytes: &usage.WriteBytes,
+		metriccache.BlkIOStatReadIOPS:   &usage.ReadIOPS,
+		metriccache.BlkIOStatWriteIOPS:  &usage.WriteIOPS,
+	}
+	if withLatency {
+		usage.ReadLatency, usage.WriteLatency = &resource.Quantity{}, &resource.Quantity{}
+		fields[metriccache.BlkIOStatReadLatency] = usage.ReadLatency
+		fields[metriccache.BlkIOStatWriteLatency] = usage.WriteLatency
+	}
+	for blkioStat, field := range fields {
+		aggregateResult, err := doQuery(querier, metricResource, propertiesFn(string(blkioStat)))
+		if err != nil {
+			return nil, err
+		}
+		value, err := aggregateResult.Value(aggregate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate %s, err: %w", blkioStat, err)
+		}
+		*field = *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI)
+	}
+	return usage, nil
+}
+
+const (
+	// podCPIBaselineHalfLife is the half-life of the slow EWMA of the pod CPI as the baseline. It is much longer than
+	// the aggregate duration and the metric expiration, so the sustained interference does not become the baseline.
+	podCPIBaselineHalfLife = 6 * time.Hour
+	// podCPIBaselineWarmUp is the duration to observe the pod CPI before reporting the baseline.
+	podCPIBaselineWarmUp = 30 * time.Minute
+)
+
+// podCPIBaseline is the long-lived baseline CPI of a pod, which is kept in memory apart from the metric cache.
+type podCPIBaseline struct {
+	value      float64
+	firstSeen  time.Time
+	lastUpdate time.Time
+}
+
+// update merges the CPI observed at the time into the baseline, weighted by the time elapsed since the last update.
+func (b *podCPIBaseline) update(cpi float64, now time.Time) {
+	if b.lastUpdate.IsZero() {
+		b.value = cpi
+		b.firstSeen = now
+		b.lastUpdate = now
+		return
+	}
+	if !now.After(b.lastUpdate) {
+		return
+	}
+	weight := 1 - math.Exp2(-float64(now.Sub(b.lastUpdate))/float64(podCPIBaselineHalfLife))
+	b.value += (cpi - b.value) * weight
+	b.lastUpdate = now
+}
+
+func (r *nodeMetricInformer) fillInterferenceMetrics(queryparam metriccache.QueryParam, info *slov1alpha1.PodMetricInfo, uid string) {
+	interference := &slov1alpha1.PodInterference{}
+	if features.DefaultKoordletFeatureGate.Enabled(features.CPICollector) {
+		cpi, err := r.queryPodCPI(*queryparam.Start, *queryparam.End, uid)
+		if err != nil {
+			klog.V(5).Infof("collect pod UID(%s) cpi metric failed, error: %v", uid, err)
+		} else {
+			interference.CPI = resource.NewMilliQuantity(int64(cpi*1000), resource.DecimalSI)
+			if r.podCPIBaselines == nil {
+				r.podCPIBaselines = map[string]*podCPIBaseline{}
+			}
+			baseline, ok := r.podCPIBaselines[uid]
+			if !ok {
+				baseline = &podCPIBaseline{}
+				r.podCPIBaselines[uid] = baseline
+			}
+			// the baseline is missing for the pod started recently, and it is compared before merging the current CPI
+			if queryparam.End.Sub(baseline.firstSeen) >= podCPIBaselineWarmUp && baseline.value > 0 {
+				interference.BaselineCPI = resource.NewMilliQuantity(int64(baseline.value*1000), resource.DecimalSI)
+				interference.CPIDeviation = resource.NewMilliQuantity(int64((cpi-baseline.value)/baseline.value*1000), resource.DecimalSI)
+			}
+			baseline.update(cpi, *queryparam.End)
+		}
+	}
+	if features.DefaultKoordletFeatureGate.Enabled(features.PSICollector) {
+		psi, err := r.queryPodPSI(queryparam, uid)
+		if err != nil {
+			klog.V(5).Infof("collect pod UID(%s) psi metric failed, error: %v", uid, err)
+		} else {
+			interference.PSI = psi
+		}
+	}
+	if interference.CPI == nil && interference.PSI == nil {
+		return
+	}
+	info.Interference = interference
+}
+
+// cleanupPodCPIBaselines removes the baselines of the pods not existing.
+func (r *nodeMetricInformer) cleanupPodCPIBaselines(podsMeta []*statesinformer.PodMeta) {
+	if len(r.podCPIBaselines) == 0 {
+		return
+	}
+	podUIDs := make(map[string]struct{}, len(podsMeta))
+	for _, podMeta := range podsMeta {
+		podUIDs[string(podMeta.Pod.UID)] = struct{}{}
+	}
+	for uid := range r.podCPIBaselines {
+		if _, ok := podUIDs[uid]; !ok {
+			delete(r.podCPIBaselines, uid)
+		}
+	}
+}
+
+// queryPodCPI returns the CPI of all containers of the pod in the time range.
+func (r *nodeMetricInformer) queryPodCPI(start, end time.Time, uid string) (float64, error) {
+	var cycles, instructions float64
+	for cpiResource, field := range map[metriccache.MetricPropertyValue]*float64{
+		metriccache.CPIResourceCycle:       &cycles,
+		metriccache.CPIResourceInstruction: &instructions,
+	} {
+		querier, err := r.metricCache.Querier(start, end)
+		if err != nil {
+			return 0, err
+		}
+		// the pod uid matches the samples of all containers
+		aggregateResult, err := doQuery(querier, metriccache.ContainerCPI, map[metriccache.MetricProperty]string{
+			metriccache.MetricPropertyPodUID:      uid,
+			metriccache.MetricPropertyCPIResource: string(cpiResource),
+		})
+		if err != nil {
+			return 0, err
+		}
+		value, err := aggregateResult.Value(metriccache.AggregationTypeAVG)
+		if err != nil {
+			return 0, fmt.Errorf("failed to aggregate %s, err: %w", cpiResource, err)
+		}
+		*field = value
+	}
+	if instructions <= 0 {
+		return 0, fmt.Errorf("no instruction is retired")
+	}
+	return cycles / instructions, nil
+}
+
+// queryPodPSI aggregates the PSI avg10 of the pod, all stats are required.
+func (r *nodeMetricInformer) queryPodPSI(queryparam metriccache.QueryParam, uid string) (*slov1alpha1.PressureStall, error) {
+	psi := &slov1alpha1.PressureStall{}
+	type psiStat struct {
+		resource metriccache.MetricPropertyValue
+		degree   metriccache.MetricPropertyValue
+	}
+	for stat, field := range map[psiStat]*resource.Quantity{
+		{metriccache.PSIResourceCPU, metriccache.PSIDegreeSome}: &psi.CPUSome,
+		{metriccache.PSIResourceCPU, metriccache.PSIDegreeFull}: &psi.CPUFull,
+		{metriccache.PSIResourceMem, metriccache.PSIDegreeSome}: &psi.MemorySome,
+		{metriccache.PSIResourceMem, metriccache.PSIDegreeFull}: &psi.MemoryFull,
+		{metriccache.PSIResourceIO, metriccache.PSIDegreeSome}:  &psi.IOSome,
+		{metriccache.PSIResourceIO, metriccache.PSIDegreeFull}:  &psi.IOFull,
+	} {
+		querier, err := r.metricCache.Querier(*queryparam.Start, *queryparam.End)
+		if err != nil {
+			return nil, err
+		}
+		aggregateResult, err := doQuery(querier, metriccache.PodPSIMetric, metriccache.MetricPropertiesFunc.PodPSI(uid,
+			string(stat.resource), string(metriccache.PSIPrecision10), string(stat.degree)))
+		if err != nil {
+			return nil, err
+		}
+		value, err := aggregateResult.Value(queryparam.Aggregate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate psi %s %s, err: %w", stat.resource, stat.degree, err)
+		}
+		*field = *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI)
+	}
+	return psi, nil
+}
+
 const (
 	statusUpdateQPS   = 0.1
 	statusUpdateBurst = 2
@@ -42,12 +42,14 @@ import (
 	clientsetv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1"
 	fakeclientslov1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1/fake"
 	listerv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/prediction"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
 )
 
 var _ listerv1alpha1.NodeMetricLister = &fakeNodeMetricLister{}
@@ -1469,3 +1471,242 @@ func Test_nodeMetricInformer_collectHostAppMetric(t *testing.T) {
 		})
 	}
 }
+
+func Test_nodeMetricInformer_fillNetworkMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.Now()
+	startTime := now.Add(-time.Second * 120)
+	queryParam := metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeAVG,
+		End:       &now,
+		Start:     &startTime,
+	}
+	testPodUID := "test-pod-uid"
+	samples := map[metriccache.MetricPropertyValue]float64{
+		metriccache.NetStatRxBytes:   2000,
+		metriccache.NetStatTxBytes:   500,
+		metriccache.NetStatRxPackets: 20,
+		metriccache.NetStatTxPackets: 5,
+		metriccache.NetStatRxDrops:   0.2,
+		metriccache.NetStatTxDrops:   0,
+	}
+	want := &slov1alpha1.NetworkUsage{
+		RxBytes:   *resource.NewMilliQuantity(2000000, resource.DecimalSI),
+		TxBytes:   *resource.NewMilliQuantity(500000, resource.DecimalSI),
+		RxPackets: *resource.NewMilliQuantity(20000, resource.DecimalSI),
+		TxPackets: *resource.NewMilliQuantity(5000, resource.DecimalSI),
+		RxDrops:   *resource.NewMilliQuantity(200, resource.DecimalSI),
+		TxDrops:   *resource.NewMilliQuantity(0, resource.DecimalSI),
+	}
+
+	mockMetricCache := mockmetriccache.NewMockMetricCache(ctrl)
+	mockResultFactory := mockmetriccache.NewMockAggregateResultFactory(ctrl)
+	metriccache.DefaultAggregateResultFactory = mockResultFactory
+	mockQuerier := mockmetriccache.NewMockQuerier(ctrl)
+	mockMetricCache.EXPECT().Querier(gomock.Any(), gomock.Any()).Return(mockQuerier, nil).AnyTimes()
+	duration := now.Sub(startTime)
+	for netStat, value := range samples {
+		podQueryMeta, err := metriccache.PodNetworkMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.PodNetwork(testPodUID, string(netStat)))
+		assert.NoError(t, err)
+		buildMockQueryResult(ctrl, mockQuerier, mockResultFactory, podQueryMeta, value, duration)
+		nodeQueryMeta, err := metriccache.NodeNetworkMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.NodeNetwork(string(netStat)))
+		assert.NoError(t, err)
+		buildMockQueryResult(ctrl, mockQuerier, mockResultFactory, nodeQueryMeta, value*10, duration)
+	}
+
+	r := &nodeMetricInformer{
+		metricCache: mockMetricCache,
+	}
+	podMetric := &slov1alpha1.PodMetricInfo{}
+	r.fillNetworkMetrics(queryParam, podMetric, testPodUID)
+	assert.Equal(t, want, podMetric.NetworkUsage)
+
+	got, err := r.collectNodeNetworkMetric(queryParam)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20000), got.RxBytes.Value())
+	assert.Equal(t, int64(2000), got.RxDrops.MilliValue())
+}
+
+func Test_nodeMetricInformer_fillBlkIOMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.Now()
+	startTime := now.Add(-time.Second * 120)
+	queryParam := metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeAVG,
+		End:       &now,
+		Start:     &startTime,
+	}
+	testPodUID := "test-pod-uid"
+	samples := map[metriccache.MetricPropertyValue]float64{
+		metriccache.BlkIOStatReadBytes:    1024,
+		metriccache.BlkIOStatWriteBytes:   2048,
+		metriccache.BlkIOStatReadIOPS:     1,
+		metriccache.BlkIOStatWriteIOPS:    2.5,
+		metriccache.BlkIOStatReadLatency:  0.5,
+		metriccache.BlkIOStatWriteLatency: 1,
+	}
+
+	mockMetricCache := mockmetriccache.NewMockMetricCache(ctrl)
+	mockResultFactory := mockmetriccache.NewMockAggregateResultFactory(ctrl)
+	metriccache.DefaultAggregateResultFactory = mockResultFactory
+	mockQuerier := mockmetriccache.NewMockQuerier(ctrl)
+	mockMetricCache.EXPECT().Querier(gomock.Any(), gomock.Any()).Return(mockQuerier, nil).AnyTimes()
+	duration := now.Sub(startTime)
+	for blkioStat, value := range samples {
+		nodeQueryMeta, err := metriccache.NodeBlkIOMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.NodeBlkIO("sda", string(blkioStat)))
+		assert.NoError(t, err)
+		buildMockQueryResult(ctrl, mockQuerier, mockResultFactory, nodeQueryMeta, value, duration)
+		if blkioStat == metriccache.BlkIOStatReadLatency || blkioStat == metriccache.BlkIOStatWriteLatency {
+			continue
+		}
+		podQueryMeta, err := metriccache.PodBlkIOMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.PodBlkIO(testPodUID, "sda", string(blkioStat)))
+		assert.NoError(t, err)
+		buildMockQueryResult(ctrl, mockQuerier, mockResultFactory, podQueryMeta, value, duration)
+	}
+	// the pod has no I/O on dm-0, and dm-0 is not a disk of node
+	for blkioStat := range samples {
+		nodeQueryMeta, err := metriccache.NodeBlkIOMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.NodeBlkIO("dm-0", string(blkioStat)))
+		assert.NoError(t, err)
+		podQueryMeta, err := metriccache.PodBlkIOMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.PodBlkIO(testPodUID, "dm-0", string(blkioStat)))
+		assert.NoError(t, err)
+		for _, queryMeta := range []metriccache.MetricMeta{nodeQueryMeta, podQueryMeta} {
+			result := mockmetriccache.NewMockAggregateResult(ctrl)
+			result.EXPECT().Value(gomock.Any()).Return(float64(0), fmt.Errorf("metric input is empty")).AnyTimes()
+			mockResultFactory.EXPECT().New(queryMeta).Return(result).AnyTimes()
+			mockQuerier.EXPECT().Query(queryMeta, gomock.Any(), result).Return(nil).AnyTimes()
+		}
+	}
+
+	r := &nodeMetricInformer{
+		metricCache: mockMetricCache,
+	}
+	podMetric := &slov1alpha1.PodMetricInfo{}
+	r.fillBlkIOMetrics(queryParam, podMetric, testPodUID, []string{"dm-0", "sda"})
+	assert.Equal(t, []slov1alpha1.BlockIOUsage{
+		{
+			Device:     "sda",
+			ReadBytes:  *resource.NewMilliQuantity(1024000, resource.DecimalSI),
+			WriteBytes: *resource.NewMilliQuantity(2048000, resource.DecimalSI),
+			ReadIOPS:   *resource.NewMilliQuantity(1000, resource.DecimalSI),
+			WriteIOPS:  *resource.NewMilliQuantity(2500, resource.DecimalSI),
+		},
+	}, podMetric.BlockIOUsages)
+
+	got := r.collectNodeBlkIOMetric(queryParam, []string{"dm-0", "sda"})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "sda", got[0].Device)
+	assert.Equal(t, int64(500), got[0].ReadLatency.MilliValue())
+	assert.Equal(t, int64(1000), got[0].WriteLatency.MilliValue())
+}
+
+func Test_nodeMetricInformer_fillInterferenceMetrics(t *testing.T) {
+	defer utilfeature.SetFeatureGateDuringTest(t, features.DefaultMutableKoordletFeatureGate, features.CPICollector, true)()
+	defer utilfeature.SetFeatureGateDuringTest(t, features.DefaultMutableKoordletFeatureGate, features.PSICollector, true)()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.Now()
+	startTime := now.Add(-time.Second * 120)
+	queryParam := metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeAVG,
+		End:       &now,
+		Start:     &startTime,
+	}
+	testPodUID := "test-pod-uid"
+
+	mockMetricCache := mockmetriccache.NewMockMetricCache(ctrl)
+	mockResultFactory := mockmetriccache.NewMockAggregateResultFactory(ctrl)
+	metriccache.DefaultAggregateResultFactory = mockResultFactory
+	mockQuerier := mockmetriccache.NewMockQuerier(ctrl)
+	mockMetricCache.EXPECT().Querier(startTime, now).Return(mockQuerier, nil).AnyTimes()
+	duration := now.Sub(startTime)
+	for cpiResource, value := range map[metriccache.MetricPropertyValue]float64{
+		// cpi is 1.5
+		metriccache.CPIResourceCycle:       3000,
+		metriccache.CPIResourceInstruction: 2000,
+	} {
+		queryMeta, err := metriccache.ContainerCPI.BuildQueryMeta(map[metriccache.MetricProperty]string{
+			metriccache.MetricPropertyPodUID:      testPodUID,
+			metriccache.MetricPropertyCPIResource: string(cpiResource),
+		})
+		assert.NoError(t, err)
+		result := mockmetriccache.NewMockAggregateResult(ctrl)
+		result.EXPECT().Value(gomock.Any()).Return(value, nil).Times(1)
+		mockResultFactory.EXPECT().New(queryMeta).Return(result).AnyTimes()
+		mockQuerier.EXPECT().Query(queryMeta, gomock.Any(), result).Return(nil).Times(1)
+	}
+	psiValues := map[metriccache.MetricPropertyValue]map[metriccache.MetricPropertyValue]float64{
+		metriccache.PSIResourceCPU: {metriccache.PSIDegreeSome: 20.5, metriccache.PSIDegreeFull: 0},
+		metriccache.PSIResourceMem: {metriccache.PSIDegreeSome: 3, metriccache.PSIDegreeFull: 1},
+		metriccache.PSIResourceIO:  {metriccache.PSIDegreeSome: 10, metriccache.PSIDegreeFull: 5},
+	}
+	for psiResource, degrees := range psiValues {
+		for psiDegree, value := range degrees {
+			queryMeta, err := metriccache.PodPSIMetric.BuildQueryMeta(metriccache.MetricPropertiesFunc.PodPSI(testPodUID,
+				string(psiResource), string(metriccache.PSIPrecision10), string(psiDegree)))
+			assert.NoError(t, err)
+			buildMockQueryResult(ctrl, mockQuerier, mockResultFactory, queryMeta, value, duration)
+		}
+	}
+
+	// the baseline is 1.0 learned in the last hour
+	r := &nodeMetricInformer{
+		metricCache: mockMetricCache,
+		podCPIBaselines: map[string]*podCPIBaseline{
+			testPodUID: {value: 1.0, firstSeen: now.Add(-time.Hour), lastUpdate: startTime},
+		},
+	}
+	podMetric := &slov1alpha1.PodMetricInfo{}
+	r.fillInterferenceMetrics(queryParam, podMetric, testPodUID)
+	assert.NotNil(t, podMetric.Interference)
+	assert.Equal(t, int64(1500), podMetric.Interference.CPI.MilliValue())
+	assert.Equal(t, int64(1000), podMetric.Interference.BaselineCPI.MilliValue())
+	assert.Equal(t, int64(500), podMetric.Interference.CPIDeviation.MilliValue())
+	assert.Equal(t, &slov1alpha1.PressureStall{
+		CPUSome:    *resource.NewMilliQuantity(20500, resource.DecimalSI),
+		CPUFull:    *resource.NewMilliQuantity(0, resource.DecimalSI),
+		MemorySome: *resource.NewMilliQuantity(3000, resource.DecimalSI),
+		MemoryFull: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+		IOSome:     *resource.NewMilliQuantity(10000, resource.DecimalSI),
+		IOFull:     *resource.NewMilliQuantity(5000, resource.DecimalSI),
+	}, podMetric.Interference.PSI)
+	// the baseline moves slowly to the current cpi
+	assert.Greater(t, r.podCPIBaselines[testPodUID].value, 1.0)
+	assert.Less(t, r.podCPIBaselines[testPodUID].value, 1.01)
+}
+
+func Test_podCPIBaseline(t *testing.T) {
+	now := time.Now()
+	b := &podCPIBaseline{}
+	b.update(1.0, now)
+	assert.Equal(t, 1.0, b.value)
+	assert.Equal(t, now, b.firstSeen)
+	// the outdated cpi is ignored
+	b.update(2.0, now.Add(-time.Minute))
+	assert.Equal(t, 1.0, b.value)
+
+	// the sustained interference for longer than the metric expiration does not become the baseline
+	for i := 1; i <= 60; i++ {
+		b.update(1.5, now.Add(time.Duration(i)*time.Minute))
+	}
+	assert.Less(t, b.value, 1.1)
+	// the baseline is halved to the new cpi after the half-life
+	b.update(1.5, b.lastUpdate.Add(podCPIBaselineHalfLife))
+	assert.Greater(t, b.value, 1.25)
+	assert.Less(t, b.value, 1.3)
+}
+
+func Test_nodeMetricInformer_cleanupPodCPIBaselines(t *testing.T) {
+	r := &nodeMetricInformer{
+		podCPIBaselines: map[string]*podCPIBaseline{
+			"pod-a": {value: 1.0},
+			"pod-b": {value: 1.0},
+		},
+	}
+	r.cleanupPodCPIBaselines([]*statesinformer.PodMeta{
+		{Pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "pod-a"}}},
+	})
+	assert.Len(t, r.podCPIBaselines, 1)
+	assert.NotNil(t, r.podCPIBaselines["pod-a"])
+}
@@ -40,10 +40,12 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
 	kubeletconfiginternal "k8s.io/kubernetes/pkg/kubelet/apis/config"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
+	kubeletcpuset "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/features"
@@ -114,9 +116,10 @@ type nodeTopoInformer struct {
 	nodeResourceTopologyInformer cache.SharedIndexInformer
 	nodeResourceTopologyLister   topologylister.NodeResourceTopologyLister
 
-	kubelet      KubeletStub
-	nodeInformer *nodeInformer
-	podsInformer *podsInformer
+	kubelet              KubeletStub
+	nodeInformer         *nodeInformer
+	podsInformer         *podsInformer
+	podResourcesInformer *podResourcesInformer
 }
 
 func NewNodeTopoInformer() *nodeTopoInformer {
@@ -151,6 +154,11 @@ func (s *nodeTopoInformer) Setup(ctx *PluginOption, state *PluginState) {
 		klog.Fatalf("pods informer format error")
 	}
 	s.podsInformer = podsInformer
+
+	// the pod resources informer is optional, and the checkpoint file of kubelet is used when it is missing
+	if podResourcesInformer, ok := state.informerPlugins[podResourcesInformerName].(*podResourcesInformer); ok {
+		s.podResourcesInformer = podResourcesInformer
+	}
 }
 
 func (s *nodeTopoInformer) Start(stopCh <-chan struct{}) {
@@ -283,6 +291,10 @@ func (s *nodeTopoInformer) calcNodeTopo() (*nodeTopologyStatus, error) {
 			if err != nil {
 				klog.Errorf("Failed to GetStaticCPUManagerPolicyReservedCPUs, err: %v", err)
 			}
+			// the cpus not allocatable by kubelet are exactly the reserved cpus
+			if allocatableCPUs, ok := s.getKubeletAllocatableCPUs(); ok {
+				reservedCPUs = topo.CPUDetails.CPUs().Difference(allocatableCPUs)
+			}
 			cpuManagerPolicy.ReservedCPUs = reservedCPUs.String()
 
 			// NOTE: We should not remove reservedCPUs from sharedPoolCPUs to
@@ -319,27 +331,16 @@ func (s *nodeTopoInformer) calcNodeTopo() (*nodeTopologyStatus, error) {
 		return nil, fmt.Errorf("failed to marshal system qos resource, error %v", err)
 	}
 
-	// Users can specify the kubelet RootDirectory on the host in the koordlet DaemonSet,
-	// but inside koordlet it is always mounted to the path /var/lib/kubelet
-	stateFilePath := kubelet.GetCPUManagerStateFilePath("/var/lib/kubelet")
-	data, err := os.ReadFile(stateFilePath)
+	podAllocs, err := s.calKubeletCPUAllocs(sharedPoolCPUs)
 	if err != nil {
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("failed to read state file, err: %v", err)
-		}
+		return nil, err
 	}
 	// TODO: report lse/lsr pod from cgroup
 	var podAllocsJSON []byte
-	if len(data) > 0 {
-		podAllocs, err := s.calGuaranteedCpu(sharedPoolCPUs, string(data))
+	if len(podAllocs) != 0 {
+		podAllocsJSON, err = json.Marshal(podAllocs)
 		if err != nil {
-			return nil, fmt.Errorf("failed to cal GuaranteedCpu, err: %v", err)
-		}
-		if len(podAllocs) != 0 {
-			podAllocsJSON, err = json.Marshal(podAllocs)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pod allocs, err: %v", err)
-			}
+			return nil, fmt.Errorf("failed to marshal pod allocs, err: %v", err)
 		}
 	}
 
@@ -389,6 +390,54 @@ func (s *nodeTopoInformer) calcNodeTopo() (*nodeTopologyStatus, error) {
 	return nodeTopoStatus, nil
 }
 
+// calKubeletCPUAllocs returns the cpus allocated by kubelet from the kubelet PodResources API if it is available,
+// otherwise from the checkpoint file of the cpu manager.
+func (s *nodeTopoInformer) calKubeletCPUAllocs(sharedPoolCPUs map[int32]*extension.CPUInfo) ([]extension.PodCPUAlloc, error) {
+	if s.podResourcesInformer != nil {
+		if podResources, ok := s.podResourcesInformer.GetPodResources(); ok {
+			return s.calGuaranteedCpuFromPodResources(sharedPoolCPUs, podResources), nil
+		}
+	}
+
+	// Users can specify the kubelet RootDirectory on the host in the koordlet DaemonSet,
+	// but inside koordlet it is always mounted to the path /var/lib/kubelet
+	stateFilePath := kubelet.GetCPUManagerStateFilePath("/var/lib/kubelet")
+	data, err := os.ReadFile(stateFilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read state file, err: %v", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	podAllocs, err := s.calGuaranteedCpu(sharedPoolCPUs, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cal GuaranteedCpu, err: %v", err)
+	}
+	return podAllocs, nil
+}
+
+// getKubeletAllocatableCPUs returns the cpus allocatable by kubelet from the kubelet PodResources API.
+func (s *nodeTopoInformer) getKubeletAllocatableCPUs() (kubeletcpuset.CPUSet, bool) {
+	if s.podResourcesInformer == nil {
+		return kubeletcpuset.CPUSet{}, false
+	}
+	allocatable := s.podResourcesInformer.GetAllocatableResources()
+	if allocatable == nil || len(allocatable.GetCpuIds()) == 0 {
+		return kubeletcpuset.CPUSet{}, false
+	}
+	return kubeletcpuset.NewCPUSet(int64sToInts(allocatable.GetCpuIds())...), true
+}
+
+func int64sToInts(values []int64) []int {
+	ints := make([]int, 0, len(values))
+	for _, v := range values {
+		ints = append(ints, int(v))
+	}
+	return ints
+}
+
 // removeNodeReservedCPUs filter out cpus that reserved by annotation of node.
 func removeNodeReservedCPUs(cpuSharePools []extension.CPUSharedPool, reservedCPUs cpuset.CPUSet) []extension.CPUSharedPool {
 	newCPUSharePools := make([]extension.CPUSharedPool, len(cpuSharePools))
@@ -461,6 +510,75 @@ func (s *nodeTopoInformer) calGuaranteedCpu(usedCPUs map[int32]*extension.CPUInf
 		return nil, err
 	}
 
+	podCPUSets := make(map[types.UID]cpuset.CPUSet)
+	for podUID := range checkpoint.Entries {
+		cpuSet := cpuset.NewCPUSet()
+		for container, cpuString := range checkpoint.Entries[podUID] {
+			if containerCPUSet, err := cpuset.Parse(cpuString); err != nil {
+				klog.Errorf("could not parse cpuset %q for container %q in pod %q: %v", cpuString, container, podUID, err)
+				continue
+			} else if containerCPUSet.Size() > 0 {
+				cpuSet = cpuSet.Union(containerCPUSet)
+			}
+		}
+		podCPUSets[types.UID(podUID)] = cpuSet
+	}
+	// TODO: It is possible that the data in the checkpoint file is invalid
+	//  and should be checked with the data in the cgroup to determine whether it is consistent
+	return s.calKubeletPodCPUAllocs(usedCPUs, podCPUSets), nil
+}
+
+// calGuaranteedCpuFromPodResources calculates the cpus allocated by kubelet with the pod resources of the kubelet
+// PodResources API, which is more accurate than the checkpoint file of the cpu manager.
+func (s *nodeTopoInformer) calGuaranteedCpuFromPodResources(usedCPUs map[int32]*extension.CPUInfo, podResources []*podresourcesapi.PodResources) []extension.PodCPUAlloc {
+	pods := make(map[string]*statesinformer.PodMeta)
+	for _, podMeta := range s.podsInformer.GetAllPods() {
+		pods[util.GetNamespacedName(podMeta.Pod.Namespace, podMeta.Pod.Name)] = podMeta
+	}
+
+	// Some kubelet versions report the shared pool for the containers without exclusive cpus. The shared pool is
+	// reported by all these containers and contains the reserved cpus which are not allocatable, while the exclusive
+	// cpus are allocatable and only reported by the container which they are assigned to.
+	cpuRefs := make(map[int64]int)
+	for _, podResource := range podResources {
+		for _, container := range podResource.GetContainers() {
+			for _, cpuID := range container.GetCpuIds() {
+				cpuRefs[cpuID]++
+			}
+		}
+	}
+	allocatableCPUs, hasAllocatable := s.getKubeletAllocatableCPUs()
+	isExclusiveCPUs := func(cpuIDs []int64) bool {
+		for _, cpuID := range cpuIDs {
+			if cpuRefs[cpuID] > 1 || (hasAllocatable && !allocatableCPUs.Contains(int(cpuID))) {
+				return false
+			}
+		}
+		return len(cpuIDs) > 0
+	}
+
+	podCPUSets := make(map[types.UID]cpuset.CPUSet)
+	for _, podResource := range podResources {
+		podMeta := pods[util.GetNamespacedName(podResource.GetNamespace(), podResource.GetName())]
+		if podMeta == nil {
+			klog.V(5).Infof("pod %s/%s of kubelet pod resources not found", podResource.GetNamespace(), podResource.GetName())
+			continue
+		}
+		cpuSet := cpuset.NewCPUSet()
+		for _, container := range podResource.GetContainers() {
+			if !isExclusiveCPUs(container.GetCpuIds()) {
+				continue
+			}
+			cpuSet = cpuSet.Union(cpuset.NewCPUSet(int64sToInts(container.GetCpuIds())...))
+		}
+		podCPUSets[podMeta.Pod.UID] = cpuSet
+	}
+	return s.calKubeletPodCPUAllocs(usedCPUs, podCPUSets)
+}
+
+// calKubeletPodCPUAllocs returns the cpus allocated by kubelet of the pods not managed by koordinator,
+// and removes these cpus from usedCPUs.
+func (s *nodeTopoInformer) calKubeletPodCPUAllocs(usedCPUs map[int32]*extension.CPUInfo, podCPUSets map[types.UID]cpuset.CPUSet) []extension.PodCPUAlloc {
 	pods := make(map[types.UID]*statesinformer.PodMeta)
 	managedPods := make(map[types.UID]struct{})
 	for _, podMeta := range s.podsInformer.GetAllPods() {
@@ -480,31 +598,20 @@ func (s *nodeTopoInformer) calGuaranteedCpu(usedCPUs map[int32]*extension.CPUInf
 	}
 
 	var podAllocs []extension.PodCPUAlloc
-	for podUID := range checkpoint.Entries {
-		if _, ok := managedPods[types.UID(podUID)]; ok {
+	for podUID, cpuSet := range podCPUSets {
+		if _, ok := managedPods[podUID]; ok {
 			continue
 		}
-		cpuSet := cpuset.NewCPUSet()
-		for container, cpuString := range checkpoint.Entries[podUID] {
-			if containerCPUSet, err := cpuset.Parse(cpuString); err != nil {
-				klog.Errorf("could not parse cpuset %q for container %q in pod %q: %v", cpuString, container, podUID, err)
-				continue
-			} else if containerCPUSet.Size() > 0 {
-				cpuSet = cpuSet.Union(containerCPUSet)
-			}
-		}
 		if cpuSet.IsEmpty() {
 			continue
 		}
 
-		// TODO: It is possible that the data in the checkpoint file is invalid
-		//  and should be checked with the data in the cgroup to determine whether it is consistent
 		podCPUAlloc := extension.PodCPUAlloc{
-			UID:              types.UID(podUID),
+			UID:              podUID,
 			CPUSet:           cpuSet.String(),
 			ManagedByKubelet: true,
 		}
-		podMeta := pods[types.UID(podUID)]
+		podMeta := pods[podUID]
 		if podMeta != nil {
 			podCPUAlloc.Namespace = podMeta.Pod.Namespace
 			podCPUAlloc.Name = podMeta.Pod.Name
@@ -518,7 +625,7 @@ func (s *nodeTopoInformer) calGuaranteedCpu(usedCPUs map[int32]*extension.CPUInf
 	sort.Slice(podAllocs, func(i, j int) bool {
 		return string(podAllocs[i].UID) < string(podAllocs[j].UID)
 	})
-	return podAllocs, nil
+	return podAllocs
 }
 
 func (s *nodeTopoInformer) reportNodeTopology() {
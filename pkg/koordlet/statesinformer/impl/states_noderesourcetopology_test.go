@@ -35,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
 	kubeletconfiginternal "k8s.io/kubernetes/pkg/kubelet/apis/config"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/utils/pointer"
@@ -306,6 +307,114 @@ func Test_calGuaranteedCpu(t *testing.T) {
 	}
 }
 
+func Test_calGuaranteedCpuFromPodResources(t *testing.T) {
+	newTestPod := func(uid, name string, qosClass corev1.PodQOSClass, cpu string) *statesinformer.PodMeta {
+		return &statesinformer.PodMeta{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      name,
+					UID:       types.UID(uid),
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "container1",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse(cpu),
+								},
+							},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					QOSClass: qosClass,
+				},
+			},
+		}
+	}
+	newTestPodResources := func(name string, cpuIDs ...int64) *podresourcesapi.PodResources {
+		return &podresourcesapi.PodResources{
+			Namespace: "default",
+			Name:      name,
+			Containers: []*podresourcesapi.ContainerResources{
+				{Name: "container1", CpuIds: cpuIDs},
+			},
+		}
+	}
+	lsPod := newTestPod("ls-pod", "ls-pod", corev1.PodQOSGuaranteed, "2")
+	lsPod.Pod.Labels = map[string]string{extension.LabelPodQoS: string(extension.QoSLS)}
+	podMap := map[string]*statesinformer.PodMeta{
+		"guaranteed-pod": newTestPod("guaranteed-pod", "guaranteed-pod", corev1.PodQOSGuaranteed, "2"),
+		"fractional-pod": newTestPod("fractional-pod", "fractional-pod", corev1.PodQOSGuaranteed, "1500m"),
+		"burstable-pod":  newTestPod("burstable-pod", "burstable-pod", corev1.PodQOSBurstable, "2"),
+		"ls-pod":         lsPod,
+	}
+	podResources := []*podresourcesapi.PodResources{
+		newTestPodResources("guaranteed-pod", 2, 3),
+		// shared pool reported by the kubelet without exclusive cpus
+		newTestPodResources("fractional-pod", 0, 1, 4, 5),
+		newTestPodResources("burstable-pod", 0, 1, 4, 5),
+		newTestPodResources("ls-pod", 4, 5),
+		newTestPodResources("unknown-pod", 6),
+	}
+	usedCPUs := map[int32]*extension.CPUInfo{}
+	for i := int32(0); i < 8; i++ {
+		usedCPUs[i] = &extension.CPUInfo{ID: i}
+	}
+
+	s := &nodeTopoInformer{
+		podsInformer: &podsInformer{
+			podMap: podMap,
+		},
+	}
+	podAllocs := s.calGuaranteedCpuFromPodResources(usedCPUs, podResources)
+	expectedPodAllocs := []extension.PodCPUAlloc{
+		{
+			Namespace:        "default",
+			Name:             "guaranteed-pod",
+			UID:              "guaranteed-pod",
+			CPUSet:           "2-3",
+			ManagedByKubelet: true,
+		},
+	}
+	assert.Equal(t, expectedPodAllocs, podAllocs)
+	assert.Len(t, usedCPUs, 6)
+	assert.Nil(t, usedCPUs[2])
+	assert.Nil(t, usedCPUs[3])
+
+	// the shared pool reported by only one container contains the reserved cpu 0 which is not allocatable
+	s.podResourcesInformer = NewPodResourcesInformer()
+	s.podResourcesInformer.updatePodResources(nil, &podresourcesapi.AllocatableResourcesResponse{CpuIds: []int64{1, 2, 3, 4, 5, 6, 7}})
+	usedCPUs = map[int32]*extension.CPUInfo{}
+	for i := int32(0); i < 8; i++ {
+		usedCPUs[i] = &extension.CPUInfo{ID: i}
+	}
+	podAllocs = s.calGuaranteedCpuFromPodResources(usedCPUs, []*podresourcesapi.PodResources{
+		newTestPodResources("guaranteed-pod", 2, 3),
+		newTestPodResources("burstable-pod", 0, 1, 4, 5, 6, 7),
+	})
+	assert.Equal(t, expectedPodAllocs, podAllocs)
+	assert.Len(t, usedCPUs, 6)
+}
+
+func Test_getKubeletAllocatableCPUs(t *testing.T) {
+	s := &nodeTopoInformer{}
+	_, ok := s.getKubeletAllocatableCPUs()
+	assert.False(t, ok)
+
+	s.podResourcesInformer = NewPodResourcesInformer()
+	s.podResourcesInformer.updatePodResources(nil, &podresourcesapi.AllocatableResourcesResponse{})
+	_, ok = s.getKubeletAllocatableCPUs()
+	assert.False(t, ok)
+
+	s.podResourcesInformer.updatePodResources(nil, &podresourcesapi.AllocatableResourcesResponse{CpuIds: []int64{1, 2, 3, 5}})
+	cpus, ok := s.getKubeletAllocatableCPUs()
+	assert.True(t, ok)
+	assert.Equal(t, "1-3,5", cpus.String())
+}
+
 func Test_reportNodeTopology(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kubelet"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	podResourcesInformerName PluginName = "podResourcesInformer"
+
+	// podResourcesMaxListFailures is the number of the consecutive list failures to invalidate the pod resources
+	podResourcesMaxListFailures = 3
+)
+
+// podResourcesInformer caches the resources allocated by kubelet from the kubelet PodResources API, including the
+// exclusive cpus of the static cpu manager policy and the devices of the device plugins. They are the ground truth of
+// the resources kubelet manages, which the checkpoint files of kubelet may fall behind.
+type podResourcesInformer struct {
+	config    *Config
+	newClient func(socketPath string, timeout time.Duration) (kubelet.PodResourcesClient, error)
+	client    kubelet.PodResourcesClient
+
+	podResourcesRWMutex sync.RWMutex
+	// podResources is nil when the PodResources API is unavailable
+	podResources []*podresourcesapi.PodResources
+	allocatable  *podresourcesapi.AllocatableResourcesResponse
+
+	synced *atomic.Bool
+}
+
+func NewPodResourcesInformer() *podResourcesInformer {
+	return &podResourcesInformer{
+		newClient: kubelet.NewPodResourcesClient,
+		synced:    atomic.NewBool(false),
+	}
+}
+
+// GetPodResources returns the resources allocated by kubelet to the pods, and false if it is not available.
+func (s *podResourcesInformer) GetPodResources() ([]*podresourcesapi.PodResources, bool) {
+	s.podResourcesRWMutex.RLock()
+	defer s.podResourcesRWMutex.RUnlock()
+	if s.podResources == nil {
+		return nil, false
+	}
+	return s.podResources, true
+}
+
+// GetAllocatableResources returns the resources that kubelet can allocate, and nil if it is not available.
+func (s *podResourcesInformer) GetAllocatableResources() *podresourcesapi.AllocatableResourcesResponse {
+	s.podResourcesRWMutex.RLock()
+	defer s.podResourcesRWMutex.RUnlock()
+	return s.allocatable
+}
+
+// GetAllocatedDevices returns the pods of the devices allocated by the kubelet device plugins by the device ids,
+// e.g. the uuids of the gpus allocated by the nvidia device plugin.
+func (s *podResourcesInformer) GetAllocatedDevices() map[string]types.NamespacedName {
+	podResources, ok := s.GetPodResources()
+	if !ok {
+		return nil
+	}
+	devices := map[string]types.NamespacedName{}
+	for _, pod := range podResources {
+		for _, container := range pod.GetContainers() {
+			for _, device := range container.GetDevices() {
+				for _, id := range device.GetDeviceIds() {
+					devices[id] = types.NamespacedName{Namespace: pod.GetNamespace(), Name: pod.GetName()}
+				}
+			}
+		}
+	}
+	return devices
+}
+
+func (s *podResourcesInformer) Setup(ctx *PluginOption, state *PluginState) {
+	s.config = ctx.config
+}
+
+func (s *podResourcesInformer) enabled() bool {
+	return s.config != nil && s.config.PodResourcesSyncInterval > 0 && s.config.PodResourcesSocketPath != ""
+}
+
+func (s *podResourcesInformer) Start(stopCh <-chan struct{}) {
+	if !s.enabled() {
+		klog.V(4).Infof("pod resources sync is disabled, skip running pod resources informer")
+		return
+	}
+	klog.V(2).Infof("starting pod resources informer")
+	// the first sync is done synchronously, and it is regarded as synced even if it fails, since the PodResources API
+	// may be disabled or unsupported by the kubelet, or the kubelet is not ready yet
+	if s.connect() {
+		s.syncPodResources()
+	}
+	s.synced.Store(true)
+
+	go s.run(stopCh)
+	klog.V(2).Infof("pod resources informer started")
+}
+
+// connect creates the client of the kubelet PodResources API, and returns false if the kubelet is unavailable.
+func (s *podResourcesInformer) connect() bool {
+	if !system.FileExists(s.config.PodResourcesSocketPath) {
+		klog.V(4).Infof("kubelet pod resources socket %s not exist", s.config.PodResourcesSocketPath)
+		return false
+	}
+	client, err := s.newClient(s.config.PodResourcesSocketPath, s.config.KubeletSyncTimeout)
+	if err != nil {
+		klog.Errorf("failed to create kubelet pod resources client, err: %v", err)
+		return false
+	}
+	s.client = client
+	return true
+}
+
+// run retries to connect the kubelet in the sync interval until it succeeds, and then watches the pod resources.
+// The pod resources are invalidated when the list fails repeatedly, so the users fall back to the checkpoint files.
+func (s *podResourcesInformer) run(stopCh <-chan struct{}) {
+	if s.client == nil {
+		err := wait.PollImmediateUntil(s.config.PodResourcesSyncInterval, func() (bool, error) {
+			return s.connect(), nil
+		}, stopCh)
+		if err != nil {
+			return
+		}
+		klog.V(4).Infof("kubelet pod resources client connected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	failures := 0
+	for podResources := range s.client.Watch(ctx, s.config.PodResourcesSyncInterval) {
+		if podResources == nil {
+			failures++
+			if failures == podResourcesMaxListFailures {
+				klog.Warningf("failed to list pod resources from kubelet for %d times, invalidate the pod resources", failures)
+				s.updatePodResources(nil, nil)
+			}
+			continue
+		}
+		failures = 0
+		s.updatePodResources(podResources, s.listAllocatableResources())
+	}
+	if err := s.client.Close(); err != nil {
+		klog.V(4).Infof("failed to close kubelet pod resources client, err: %v", err)
+	}
+}
+
+func (s *podResourcesInformer) HasSynced() bool {
+	if !s.enabled() {
+		return true
+	}
+	synced := s.synced.Load()
+	klog.V(5).Infof("pod resources informer has synced %v", synced)
+	return synced
+}
+
+func (s *podResourcesInformer) syncPodResources() {
+	podResources, err := s.client.List(context.TODO())
+	if err != nil {
+		klog.Warningf("failed to list pod resources from kubelet, err: %v", err)
+		return
+	}
+	if podResources == nil {
+		podResources = []*podresourcesapi.PodResources{}
+	}
+	s.updatePodResources(podResources, s.listAllocatableResources())
+}
+
+func (s *podResourcesInformer) listAllocatableResources() *podresourcesapi.AllocatableResourcesResponse {
+	allocatable, err := s.client.GetAllocatableResources(context.TODO())
+	if err != nil {
+		// GetAllocatableResources is disabled when the feature KubeletPodResourcesGetAllocatable of kubelet is off
+		klog.V(4).Infof("failed to get allocatable resources from kubelet, err: %v", err)
+		return nil
+	}
+	return allocatable
+}
+
+// updatePodResources updates the cached pod resources, and nil podResources marks them unavailable.
+func (s *podResourcesInformer) updatePodResources(podResources []*podresourcesapi.PodResources, allocatable *podresourcesapi.AllocatableResourcesResponse) {
+	s.podResourcesRWMutex.Lock()
+	defer s.podResourcesRWMutex.Unlock()
+	s.podResources = podResources
+	s.allocatable = allocatable
+	klog.V(5).Infof("update pod resources from kubelet, pods %v", len(podResources))
+}
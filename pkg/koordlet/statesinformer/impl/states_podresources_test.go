@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kubelet"
+)
+
+type fakePodResourcesClient struct {
+	podResources   []*podresourcesapi.PodResources
+	allocatable    *podresourcesapi.AllocatableResourcesResponse
+	listErr        error
+	allocatableErr error
+	// watchCh sends the results of the watch if it is set
+	watchCh chan []*podresourcesapi.PodResources
+}
+
+func (f *fakePodResourcesClient) List(ctx context.Context) ([]*podresourcesapi.PodResources, error) {
+	return f.podResources, f.listErr
+}
+
+func (f *fakePodResourcesClient) GetAllocatableResources(ctx context.Context) (*podresourcesapi.AllocatableResourcesResponse, error) {
+	return f.allocatable, f.allocatableErr
+}
+
+func (f *fakePodResourcesClient) Watch(ctx context.Context, interval time.Duration) <-chan []*podresourcesapi.PodResources {
+	ch := make(chan []*podresourcesapi.PodResources)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case podResources := <-f.watchCh:
+				ch <- podResources
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func (f *fakePodResourcesClient) Close() error {
+	return nil
+}
+
+func Test_podResourcesInformer(t *testing.T) {
+	testPodResources := []*podresourcesapi.PodResources{
+		{
+			Name:      "test-pod",
+			Namespace: "default",
+			Containers: []*podresourcesapi.ContainerResources{
+				{
+					Name:   "test-container",
+					CpuIds: []int64{2, 3},
+					Devices: []*podresourcesapi.ContainerDevices{
+						{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"GPU-1", "GPU-2"}},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name              string
+		disabled          bool
+		socketNotExist    bool
+		client            *fakePodResourcesClient
+		wantSynced        bool
+		wantPodResources  []*podresourcesapi.PodResources
+		wantAvailable     bool
+		wantAllocatable   *podresourcesapi.AllocatableResourcesResponse
+		wantAllocatedDevs map[string]types.NamespacedName
+	}{
+		{
+			name:       "disabled",
+			disabled:   true,
+			wantSynced: true,
+		},
+		{
+			name:           "socket not exist",
+			socketNotExist: true,
+			wantSynced:     true,
+		},
+		{
+			name: "list failed",
+			client: &fakePodResourcesClient{
+				listErr: fmt.Errorf("unimplemented"),
+			},
+			wantSynced: true,
+		},
+		{
+			name: "get allocatable failed",
+			client: &fakePodResourcesClient{
+				podResources:   testPodResources,
+				allocatableErr: fmt.Errorf("unimplemented"),
+			},
+			wantSynced:       true,
+			wantPodResources: testPodResources,
+			wantAvailable:    true,
+			wantAllocatedDevs: map[string]types.NamespacedName{
+				"GPU-1": {Namespace: "default", Name: "test-pod"},
+				"GPU-2": {Namespace: "default", Name: "test-pod"},
+			},
+		},
+		{
+			name: "list pod resources and allocatable",
+			client: &fakePodResourcesClient{
+				podResources: testPodResources,
+				allocatable:  &podresourcesapi.AllocatableResourcesResponse{CpuIds: []int64{1, 2, 3}},
+			},
+			wantSynced:       true,
+			wantPodResources: testPodResources,
+			wantAvailable:    true,
+			wantAllocatable:  &podresourcesapi.AllocatableResourcesResponse{CpuIds: []int64{1, 2, 3}},
+			wantAllocatedDevs: map[string]types.NamespacedName{
+				"GPU-1": {Namespace: "default", Name: "test-pod"},
+				"GPU-2": {Namespace: "default", Name: "test-pod"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socketPath := filepath.Join(t.TempDir(), "kubelet.sock")
+			if !tt.socketNotExist {
+				assert.NoError(t, os.WriteFile(socketPath, nil, 0644))
+			}
+			config := NewDefaultConfig()
+			config.PodResourcesSocketPath = socketPath
+			if tt.disabled {
+				config.PodResourcesSyncInterval = 0
+			}
+
+			s := NewPodResourcesInformer()
+			s.newClient = func(socketPath string, timeout time.Duration) (kubelet.PodResourcesClient, error) {
+				return tt.client, nil
+			}
+			s.Setup(&PluginOption{config: config}, &PluginState{})
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			s.Start(stopCh)
+
+			assert.Equal(t, tt.wantSynced, s.HasSynced())
+			podResources, ok := s.GetPodResources()
+			assert.Equal(t, tt.wantAvailable, ok)
+			assert.Equal(t, tt.wantPodResources, podResources)
+			assert.Equal(t, tt.wantAllocatable, s.GetAllocatableResources())
+			assert.Equal(t, tt.wantAllocatedDevs, s.GetAllocatedDevices())
+		})
+	}
+}
+
+func Test_podResourcesInformerRetryAndInvalidate(t *testing.T) {
+	testPodResources := []*podresourcesapi.PodResources{
+		{
+			Name:      "test-pod",
+			Namespace: "default",
+			Containers: []*podresourcesapi.ContainerResources{
+				{Name: "test-container", CpuIds: []int64{2, 3}},
+			},
+		},
+	}
+	socketPath := filepath.Join(t.TempDir(), "kubelet.sock")
+	config := NewDefaultConfig()
+	config.PodResourcesSocketPath = socketPath
+	config.PodResourcesSyncInterval = 10 * time.Millisecond
+
+	client := &fakePodResourcesClient{
+		podResources: testPodResources,
+		watchCh:      make(chan []*podresourcesapi.PodResources),
+	}
+	s := NewPodResourcesInformer()
+	s.newClient = func(socketPath string, timeout time.Duration) (kubelet.PodResourcesClient, error) {
+		return client, nil
+	}
+	s.Setup(&PluginOption{config: config}, &PluginState{})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	// the socket does not exist when starting
+	s.Start(stopCh)
+	assert.True(t, s.HasSynced())
+	_, ok := s.GetPodResources()
+	assert.False(t, ok)
+
+	// connect when the socket is created
+	assert.NoError(t, os.WriteFile(socketPath, nil, 0644))
+	client.watchCh <- testPodResources
+	assert.Eventually(t, func() bool {
+		_, ok := s.GetPodResources()
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	podResources, _ := s.GetPodResources()
+	assert.Equal(t, testPodResources, podResources)
+
+	// keep the pod resources until the list fails repeatedly
+	for i := 0; i < podResourcesMaxListFailures-1; i++ {
+		client.watchCh <- nil
+	}
+	client.watchCh <- testPodResources
+	for i := 0; i < podResourcesMaxListFailures-1; i++ {
+		client.watchCh <- nil
+	}
+	_, ok = s.GetPodResources()
+	assert.True(t, ok)
+	client.watchCh <- nil
+	assert.Eventually(t, func() bool {
+		_, ok := s.GetPodResources()
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -75,6 +75,8 @@ func GetContainerCgroupParentDir(podParentDir string, c *corev1.ContainerStatus)
 // GetContainerCgroupParentDirByID gets the full container cgroup parent dir with the podParentDir and the container ID.
 // @parentDir kubepods.slice/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/
 // @return kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod7712555c_ce62_454a_9e18_9ff0217b8941.slice/****.scope
+// The container dir depends on the runtime of the container ID, e.g. cri-containerd-****.scope for containerd, and
+// crio-****.scope for cri-o (crio-**** with the cgroupfs driver).
 func GetContainerCgroupParentDirByID(podParentDir string, containerID string) (string, error) {
 	_, containerDir, err := system.CgroupPathFormatter.ContainerDirFn(containerID)
 	if err != nil {
@@ -179,6 +179,17 @@ func TestGetContainerCgroupParentDir_SystemdDriver(t *testing.T) {
 			want:    "kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod6553a60b_2b97_442a_b6da_a5704d81dd98.slice/cri-containerd-413715dc061efe71c16ef989f2f2ff5cf999a7dc905bb7078eda82cbb38210ec.scope",
 			wantErr: false,
 		},
+		{
+			name: "crio-container",
+			args: args{
+				podParentDir: "kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod6553a60b_2b97_442a_b6da_a5704d81dd98.slice/",
+				c: &corev1.ContainerStatus{
+					ContainerID: "cri-o://5d1fde1eab5de8d8a4e7d3cdbe8bb3e52e9cf3d2a1f2c1e0b6a48ec1b4ad3e20",
+				},
+			},
+			want:    "kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod6553a60b_2b97_442a_b6da_a5704d81dd98.slice/crio-5d1fde1eab5de8d8a4e7d3cdbe8bb3e52e9cf3d2a1f2c1e0b6a48ec1b4ad3e20.scope",
+			wantErr: false,
+		},
 		{
 			name: "invalid-container",
 			args: args{
@@ -247,6 +258,17 @@ func TestGetContainerCgroupParentDir_CgroupfsDriver(t *testing.T) {
 			want:    "kubepods/besteffort/pod6553a60b-2b97-442a-b6da-a5704d81dd98/413715dc061efe71c16ef989f2f2ff5cf999a7dc905bb7078eda82cbb38210ec",
 			wantErr: false,
 		},
+		{
+			name: "crio-container",
+			args: args{
+				podParentDir: "kubepods/besteffort/pod6553a60b-2b97-442a-b6da-a5704d81dd98/",
+				c: &corev1.ContainerStatus{
+					ContainerID: "cri-o://5d1fde1eab5de8d8a4e7d3cdbe8bb3e52e9cf3d2a1f2c1e0b6a48ec1b4ad3e20",
+				},
+			},
+			want:    "kubepods/besteffort/pod6553a60b-2b97-442a-b6da-a5704d81dd98/crio-5d1fde1eab5de8d8a4e7d3cdbe8bb3e52e9cf3d2a1f2c1e0b6a48ec1b4ad3e20",
+			wantErr: false,
+		},
 		{
 			name: "invalid-container",
 			args: args{
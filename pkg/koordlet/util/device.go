@@ -19,7 +19,8 @@ package util
 type DeviceType string
 
 const (
-	GPUDeviceType DeviceType = "GPU"
+	GPUDeviceType  DeviceType = "GPU"
+	RDMADeviceType DeviceType = "RDMA"
 )
 
 type Devices interface {
@@ -42,3 +43,31 @@ type GPUDeviceInfo struct {
 	PCIE        string `json:"pcie,omitempty"`
 	BusID       string `json:"busID,omitempty"`
 }
+
+type RDMADevices []RDMADeviceInfo
+
+func (r RDMADevices) Type() DeviceType {
+	return RDMADeviceType
+}
+
+// RDMADeviceInfo is a physical RDMA NIC, i.e. the physical function of a SR-IOV capable NIC.
+type RDMADeviceInfo struct {
+	// ID is the name of the RDMA device, e.g. mlx5_0
+	ID string `json:"id,omitempty"`
+	// Minor represents the Minor number of Devices, starting from 0
+	Minor  int32  `json:"minor,omitempty"`
+	NodeID int32  `json:"nodeID"`
+	PCIE   string `json:"pcie,omitempty"`
+	BusID  string `json:"busID,omitempty"`
+	// Health is false if none of the ports of the device is active
+	Health bool                  `json:"health"`
+	VFs    []RDMAVirtualFunction `json:"vfs,omitempty"`
+}
+
+type RDMAVirtualFunction struct {
+	// Minor is the index of the virtual function of the physical function, i.e. N in virtfnN
+	Minor int32  `json:"minor"`
+	BusID string `json:"busID,omitempty"`
+	// Health is false if the virtual function is gone or its port is not active
+	Health bool `json:"health"`
+}
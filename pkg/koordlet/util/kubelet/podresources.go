@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/klog/v2"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+const (
+	// PodResourcesSocketName is the socket name of the kubelet PodResources API under the kubelet root dir.
+	PodResourcesSocketName = "pod-resources/kubelet.sock"
+
+	podResourcesMaxMsgSize = 1024 * 1024 * 16
+)
+
+// GetPodResourcesSocketPath returns the socket path of the kubelet PodResources API.
+func GetPodResourcesSocketPath(kubeletRootDir string) string {
+	return filepath.Join(kubeletRootDir, PodResourcesSocketName)
+}
+
+// PodResourcesClient talks to the kubelet PodResources API, which reports the exclusive CPUs assigned by the static
+// CPU manager and the devices assigned by the device plugins.
+type PodResourcesClient interface {
+	// List lists the resources assigned to the pods.
+	List(ctx context.Context) ([]*podresourcesapi.PodResources, error)
+	// GetAllocatableResources gets the resources which can be assigned by the kubelet.
+	GetAllocatableResources(ctx context.Context) (*podresourcesapi.AllocatableResourcesResponse, error)
+	// Watch sends the resources assigned to the pods when the assignment changes until the context is done, and sends
+	// nil when it fails to list. The kubelet does not serve a watch API yet, so the pod resources are listed in the
+	// interval.
+	Watch(ctx context.Context, interval time.Duration) <-chan []*podresourcesapi.PodResources
+	Close() error
+}
+
+type podResourcesClient struct {
+	client  podresourcesapi.PodResourcesListerClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewPodResourcesClient connects to the kubelet PodResources API with the unix socket.
+func NewPodResourcesClient(socketPath string, timeout time.Duration) (PodResourcesClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+	}
+	conn, err := grpc.DialContext(ctx, socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialer), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(podResourcesMaxMsgSize)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial pod resources socket %s, err: %w", socketPath, err)
+	}
+	return &podResourcesClient{
+		client:  podresourcesapi.NewPodResourcesListerClient(conn),
+		conn:    conn,
+		timeout: timeout,
+	}, nil
+}
+
+func (c *podResourcesClient) List(ctx context.Context) ([]*podresourcesapi.PodResources, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	rsp, err := c.client.List(ctx, &podresourcesapi.ListPodResourcesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return rsp.GetPodResources(), nil
+}
+
+func (c *podResourcesClient) GetAllocatableResources(ctx context.Context) (*podresourcesapi.AllocatableResourcesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.GetAllocatableResources(ctx, &podresourcesapi.AllocatableResourcesRequest{})
+}
+
+func (c *podResourcesClient) Watch(ctx context.Context, interval time.Duration) <-chan []*podresourcesapi.PodResources {
+	ch := make(chan []*podresourcesapi.PodResources)
+	go func() {
+		defer close(ch)
+		var last []*podresourcesapi.PodResources
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			podResources, err := c.List(ctx)
+			if err != nil {
+				klog.V(4).Infof("failed to list pod resources from kubelet, err: %v", err)
+				select {
+				case ch <- nil:
+					last = nil
+				case <-ctx.Done():
+					return
+				}
+			} else if last == nil || !reflect.DeepEqual(last, podResources) {
+				if podResources == nil {
+					podResources = []*podresourcesapi.PodResources{}
+				}
+				select {
+				case ch <- podResources:
+					last = podResources
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func (c *podResourcesClient) Close() error {
+	return c.conn.Close()
+}
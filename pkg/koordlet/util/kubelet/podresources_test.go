@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+type fakePodResourcesServer struct {
+	lock         sync.Mutex
+	podResources []*podresourcesapi.PodResources
+	allocatable  *podresourcesapi.AllocatableResourcesResponse
+	listErr      error
+}
+
+func (s *fakePodResourcesServer) List(ctx context.Context, req *podresourcesapi.ListPodResourcesRequest) (*podresourcesapi.ListPodResourcesResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	return &podresourcesapi.ListPodResourcesResponse{PodResources: s.podResources}, nil
+}
+
+func (s *fakePodResourcesServer) GetAllocatableResources(ctx context.Context, req *podresourcesapi.AllocatableResourcesRequest) (*podresourcesapi.AllocatableResourcesResponse, error) {
+	return s.allocatable, nil
+}
+
+func (s *fakePodResourcesServer) setPodResources(podResources []*podresourcesapi.PodResources) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.podResources = podResources
+}
+
+func (s *fakePodResourcesServer) setListErr(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.listErr = err
+}
+
+func startFakePodResourcesServer(t *testing.T, fake *fakePodResourcesServer) (string, func()) {
+	socketPath := filepath.Join(t.TempDir(), "kubelet.sock")
+	listener, err := net.Listen("unix", socketPath)
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	podresourcesapi.RegisterPodResourcesListerServer(server, fake)
+	go server.Serve(listener)
+	return socketPath, server.Stop
+}
+
+func TestPodResourcesClient(t *testing.T) {
+	fake := &fakePodResourcesServer{
+		podResources: []*podresourcesapi.PodResources{
+			{
+				Name:      "test-pod",
+				Namespace: "default",
+				Containers: []*podresourcesapi.ContainerResources{
+					{
+						Name:   "test-container",
+						CpuIds: []int64{2, 3},
+						Devices: []*podresourcesapi.ContainerDevices{
+							{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"GPU-1"}},
+						},
+					},
+				},
+			},
+		},
+		allocatable: &podresourcesapi.AllocatableResourcesResponse{
+			CpuIds: []int64{1, 2, 3},
+		},
+	}
+	socketPath, stop := startFakePodResourcesServer(t, fake)
+	defer stop()
+
+	client, err := NewPodResourcesClient(socketPath, time.Second)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	podResources, err := client.List(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, podResources, 1)
+	assert.Equal(t, "test-pod", podResources[0].Name)
+	assert.Equal(t, []int64{2, 3}, podResources[0].Containers[0].CpuIds)
+	assert.Equal(t, []string{"GPU-1"}, podResources[0].Containers[0].Devices[0].DeviceIds)
+
+	allocatable, err := client.GetAllocatableResources(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, allocatable.CpuIds)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := client.Watch(ctx, 10*time.Millisecond)
+	got := <-ch
+	assert.Len(t, got, 1)
+	fake.setPodResources(nil)
+	got = <-ch
+	assert.Len(t, got, 0)
+	assert.NotNil(t, got)
+	// nil is sent when the list fails
+	fake.setListErr(fmt.Errorf("unavailable"))
+	got = <-ch
+	assert.Nil(t, got)
+	cancel()
+	for range ch {
+	}
+}
@@ -78,6 +78,10 @@ func GetPodSandboxContainerID(pod *corev1.Pod) (string, error) {
 		if !containerDir.IsDir() {
 			continue
 		}
+		// skip the cgroups not of containers, e.g. the conmon cgroups of cri-o
+		if _, err := system.CgroupPathFormatter.ContainerIDParser(containerDir.Name()); err != nil {
+			continue
+		}
 		if _, exist := containerSubDirNames[containerDir.Name()]; !exist {
 			sandboxCandidates = append(sandboxCandidates, containerDir.Name())
 		}
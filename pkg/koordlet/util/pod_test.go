@@ -181,6 +181,7 @@ func writeCgroupContent(filePath string, content []byte) error {
 func TestGetPodSandboxContainerID(t *testing.T) {
 	type fields struct {
 		otherContaienrIDs []string
+		otherCgroupDirs   []string
 	}
 	type args struct {
 		pod *corev1.Pod
@@ -284,6 +285,38 @@ func TestGetPodSandboxContainerID(t *testing.T) {
 			want:    "docker://testPodSandboxHashID",
 			wantErr: false,
 		},
+		{
+			name: "pod-start-crio-sandbox-and-container-with-conmon",
+			fields: fields{
+				otherContaienrIDs: []string{
+					"cri-o://testPodSandboxHashID",
+					"cri-o://testContainerHashID",
+				},
+				otherCgroupDirs: []string{
+					"crio-conmon-testPodSandboxHashID.scope",
+					"crio-conmon-testContainerHashID.scope",
+				},
+			},
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-pod",
+						UID:  "test-pod-uid",
+					},
+					Status: corev1.PodStatus{
+						ContainerStatuses: []corev1.ContainerStatus{
+							{
+								Name:        "test-container-name",
+								ContainerID: "cri-o://testContainerHashID",
+								Started:     nil,
+							},
+						},
+					},
+				},
+			},
+			want:    "cri-o://testPodSandboxHashID",
+			wantErr: false,
+		},
 		{
 			name: "pod-start-new-container-but-status-has-not-synced",
 			fields: fields{
@@ -325,6 +358,9 @@ func TestGetPodSandboxContainerID(t *testing.T) {
 				assert.NoError(t, err)
 				testHelper.WriteCgroupFileContents(containerPath, system.CPUSet, "")
 			}
+			for _, cgroupDir := range tt.fields.otherCgroupDirs {
+				testHelper.WriteCgroupFileContents(filepath.Join(podCgroupDir, cgroupDir), system.CPUSet, "")
+			}
 
 			got, err := GetPodSandboxContainerID(tt.args.pod)
 			if (err != nil) != tt.wantErr {
@@ -45,12 +45,25 @@ func GetContainerdEndpoint2() string {
 }
 
 type ContainerdRuntimeHandler struct {
+	criRuntimeHandler
+}
+
+func NewContainerdRuntimeHandler(endpoint string) (ContainerRuntimeHandler, error) {
+	h, err := newCRIRuntimeHandler(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerdRuntimeHandler{criRuntimeHandler: *h}, nil
+}
+
+// criRuntimeHandler talks to a CRI runtime service, which is shared by the runtimes only differing in the endpoint.
+type criRuntimeHandler struct {
 	runtimeServiceClient runtimeapi.RuntimeServiceClient
 	timeout              time.Duration
 	endpoint             string
 }
 
-func NewContainerdRuntimeHandler(endpoint string) (ContainerRuntimeHandler, error) {
+func newCRIRuntimeHandler(endpoint string) (*criRuntimeHandler, error) {
 	ep := strings.TrimPrefix(endpoint, "unix://")
 	if _, err := os.Stat(ep); err != nil {
 		return nil, err
@@ -61,18 +74,19 @@ func NewContainerdRuntimeHandler(endpoint string) (ContainerRuntimeHandler, erro
 		return nil, err
 	}
 
-	return &ContainerdRuntimeHandler{
+	return &criRuntimeHandler{
 		runtimeServiceClient: client,
 		timeout:              defaultConnectionTimeout,
 		endpoint:             endpoint,
 	}, nil
 }
 
-func (c *ContainerdRuntimeHandler) StopContainer(containerID string, timeout int64) error {
+func (c *criRuntimeHandler) StopContainer(containerID string, timeout int64) error {
 	if containerID == "" {
 		return fmt.Errorf("containerID cannot be empty")
 	}
-	t := c.timeout + time.Duration(timeout)
+	// the stop timeout of CRI is in seconds
+	t := c.timeout + time.Duration(timeout)*time.Second
 	ctx, cancel := context.WithTimeout(context.Background(), t)
 	defer cancel()
 
@@ -84,7 +98,7 @@ func (c *ContainerdRuntimeHandler) StopContainer(containerID string, timeout int
 	return err
 }
 
-func (c *ContainerdRuntimeHandler) UpdateContainerResources(containerID string, opts UpdateOptions) error {
+func (c *criRuntimeHandler) UpdateContainerResources(containerID string, opts UpdateOptions) error {
 	if containerID == "" {
 		return fmt.Errorf("containerID cannot be empty")
 	}
@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/prashantv/gostub"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
 
 	mockclient "github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime/handler/mockclient"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
@@ -86,7 +88,7 @@ func Test_Containerd_StopContainer(t *testing.T) {
 			mockRuntimeClient := mockclient.NewMockRuntimeServiceClient(ctl)
 			mockRuntimeClient.EXPECT().StopContainer(gomock.Any(), gomock.Any()).Return(nil, tt.runtimeError)
 
-			runtimeHandler := ContainerdRuntimeHandler{runtimeServiceClient: mockRuntimeClient, timeout: 1, endpoint: GetContainerdEndpoint()}
+			runtimeHandler := ContainerdRuntimeHandler{criRuntimeHandler{runtimeServiceClient: mockRuntimeClient, timeout: 1, endpoint: GetContainerdEndpoint()}}
 			gotErr := runtimeHandler.StopContainer(tt.containerId, 1)
 			assert.Equal(t, gotErr != nil, tt.expectError)
 
@@ -94,6 +96,24 @@ func Test_Containerd_StopContainer(t *testing.T) {
 	}
 }
 
+func Test_Containerd_StopContainerTimeout(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockRuntimeClient := mockclient.NewMockRuntimeServiceClient(ctl)
+	mockRuntimeClient.EXPECT().StopContainer(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, in *runtimeapi.StopContainerRequest, opts ...grpc.CallOption) (*runtimeapi.StopContainerResponse, error) {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			// the stop timeout is in seconds
+			assert.True(t, time.Until(deadline) > 9*time.Second)
+			assert.Equal(t, int64(10), in.Timeout)
+			return &runtimeapi.StopContainerResponse{}, nil
+		})
+
+	runtimeHandler := ContainerdRuntimeHandler{criRuntimeHandler{runtimeServiceClient: mockRuntimeClient, timeout: time.Second, endpoint: GetContainerdEndpoint()}}
+	assert.NoError(t, runtimeHandler.StopContainer("test_container_id", 10))
+}
+
 func Test_Containerd_UpdateContainerResources(t *testing.T) {
 	type args struct {
 		name         string
@@ -122,7 +142,7 @@ func Test_Containerd_UpdateContainerResources(t *testing.T) {
 			mockRuntimeClient := mockclient.NewMockRuntimeServiceClient(ctl)
 			mockRuntimeClient.EXPECT().UpdateContainerResources(gomock.Any(), gomock.Any()).Return(nil, tt.runtimeError)
 
-			runtimeHandler := ContainerdRuntimeHandler{runtimeServiceClient: mockRuntimeClient, timeout: 1, endpoint: GetContainerdEndpoint()}
+			runtimeHandler := ContainerdRuntimeHandler{criRuntimeHandler{runtimeServiceClient: mockRuntimeClient, timeout: 1, endpoint: GetContainerdEndpoint()}}
 			gotErr := runtimeHandler.UpdateContainerResources(tt.containerId, UpdateOptions{})
 			assert.Equal(t, tt.expectError, gotErr != nil)
 		})
@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"path/filepath"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	CrioEndpointSubFilepath = "crio/crio.sock"
+)
+
+func GetCrioEndpoint() string {
+	return filepath.Join(system.Conf.VarRunRootDir, CrioEndpointSubFilepath)
+}
+
+type CrioRuntimeHandler struct {
+	criRuntimeHandler
+}
+
+func NewCrioRuntimeHandler(endpoint string) (ContainerRuntimeHandler, error) {
+	h, err := newCRIRuntimeHandler(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &CrioRuntimeHandler{criRuntimeHandler: *h}, nil
+}
@@ -26,12 +26,14 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
 var (
 	DockerHandler     handler.ContainerRuntimeHandler
 	ContainerdHandler handler.ContainerRuntimeHandler
 	PouchHandler      handler.ContainerRuntimeHandler
+	CrioHandler       handler.ContainerRuntimeHandler
 	mutex             = &sync.Mutex{}
 )
 
@@ -46,11 +48,23 @@ func GetRuntimeHandler(runtimeType string) (handler.ContainerRuntimeHandler, err
 		return getContainerdHandler()
 	case system.RuntimeTypePouch:
 		return getPouchHandler()
+	case system.RuntimeTypeCrio:
+		return getCrioHandler()
 	default:
 		return nil, fmt.Errorf("runtime type %v is not supported", runtimeType)
 	}
 }
 
+// GetRuntimeHandlerByContainerID gets the runtime handler with the scheme of the container ID,
+// e.g. "cri-o" of "cri-o://<id>".
+func GetRuntimeHandlerByContainerID(containerID string) (handler.ContainerRuntimeHandler, error) {
+	runtimeType, _, err := util.ParseContainerId(containerID)
+	if err != nil {
+		return nil, err
+	}
+	return GetRuntimeHandler(runtimeType)
+}
+
 func getDockerHandler() (handler.ContainerRuntimeHandler, error) {
 	if DockerHandler != nil {
 		return DockerHandler, nil
@@ -152,6 +166,39 @@ func getPouchEndpoint() (string, error) {
 	return "", fmt.Errorf("pouch endpoint does not exist")
 }
 
+func getCrioHandler() (handler.ContainerRuntimeHandler, error) {
+	if CrioHandler != nil {
+		return CrioHandler, nil
+	}
+
+	unixEndpoint, err := getCrioEndpoint()
+	if err != nil {
+		klog.Errorf("failed to get cri-o endpoint, error: %v", err)
+		return nil, err
+	}
+
+	CrioHandler, err = handler.NewCrioRuntimeHandler(unixEndpoint)
+	if err != nil {
+		klog.Errorf("failed to create cri-o runtime handler, error: %v", err)
+		return nil, err
+	}
+
+	return CrioHandler, nil
+}
+
+func getCrioEndpoint() (string, error) {
+	if crioEndpoint := handler.GetCrioEndpoint(); isFile(crioEndpoint) {
+		return fmt.Sprintf("unix://%s", crioEndpoint), nil
+	}
+
+	if len(system.Conf.CrioEndpoint) > 0 && isFile(system.Conf.CrioEndpoint) {
+		klog.Infof("find cri-o Endpoint : %v", system.Conf.CrioEndpoint)
+		return fmt.Sprintf("unix://%s", system.Conf.CrioEndpoint), nil
+	}
+
+	return "", fmt.Errorf("cri-o endpoint does not exist")
+}
+
 func isFile(path string) bool {
 	s, err := os.Stat(path)
 	if err != nil || s == nil {
@@ -95,6 +95,19 @@ func Test_GetRuntimeHandler(t *testing.T) {
 			expectRuntimeHandler: "PouchRuntimeHandler",
 			expectErr:            false,
 		},
+		{
+			name:                 "test_/var/run/crio/crio.sock",
+			endPoint:             "/var/run/crio/crio.sock",
+			runtimeType:          "cri-o",
+			expectRuntimeHandler: "CrioRuntimeHandler",
+			expectErr:            false,
+		},
+		{
+			name:        "test_have_containerdRuntime_but_need_crio",
+			endPoint:    "/var/run/containerd/containerd.sock",
+			runtimeType: "cri-o",
+			expectErr:   true,
+		},
 		{
 			name:                 "custom containerd",
 			endPoint:             "/var/run/test1/containerd.sock",
@@ -119,6 +132,14 @@ func Test_GetRuntimeHandler(t *testing.T) {
 			expectRuntimeHandler: "PouchRuntimeHandler",
 			expectErr:            false,
 		},
+		{
+			name:                 "custom cri-o",
+			endPoint:             "/var/run/test4/crio.sock",
+			flag:                 "test4/crio.sock",
+			runtimeType:          "cri-o",
+			expectRuntimeHandler: "CrioRuntimeHandler",
+			expectErr:            false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +148,7 @@ func Test_GetRuntimeHandler(t *testing.T) {
 			DockerHandler = nil
 			ContainerdHandler = nil
 			PouchHandler = nil
+			CrioHandler = nil
 			system.Conf.VarRunRootDir = filepath.Join(helper.TempDir, "/var/run")
 			if tt.endPoint != "" {
 				helper.CreateFile(tt.endPoint)
@@ -139,6 +161,8 @@ func Test_GetRuntimeHandler(t *testing.T) {
 					system.Conf.DockerEndPoint = filepath.Join(system.Conf.VarRunRootDir, tt.flag)
 				} else if tt.runtimeType == "pouch" {
 					system.Conf.PouchEndpoint = filepath.Join(system.Conf.VarRunRootDir, tt.flag)
+				} else if tt.runtimeType == "cri-o" {
+					system.Conf.CrioEndpoint = filepath.Join(system.Conf.VarRunRootDir, tt.flag)
 				}
 			}
 			resetEndpoint()
@@ -163,6 +187,28 @@ func Test_GetRuntimeHandler(t *testing.T) {
 	}
 }
 
+func Test_GetRuntimeHandlerByContainerID(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	CrioHandler = nil
+	defer func() {
+		CrioHandler = nil
+	}()
+	system.Conf.VarRunRootDir = filepath.Join(helper.TempDir, "/var/run")
+	helper.WriteFileContents("/var/run/crio/crio.sock", "test")
+	stubs := gostub.Stub(&handler.GrpcDial, func(context context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		return &grpc.ClientConn{}, nil
+	})
+	defer stubs.Reset()
+
+	gotHandler, err := GetRuntimeHandlerByContainerID("cri-o://413715dc061efe71c16ef989f2f2ff5cf999a7dc905bb7078eda82cbb38210ec")
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(reflect.TypeOf(gotHandler).String(), "CrioRuntimeHandler"))
+
+	_, err = GetRuntimeHandlerByContainerID("invalid")
+	assert.Error(t, err)
+}
+
 func dockerStub() *gostub.Stubs {
 	return gostub.Stub(&handler.GetDockerClient, func(httpClient *http.Client, endPoint string) (*dclient.Client, error) {
 		info := func(req *http.Request) (*http.Response, error) {
@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProcDiskStatsName = "diskstats"
+
+	SysBlockSubDir = "block"
+
+	// DiskSectorSize is the sector size used by /proc/diskstats regardless of the hardware
+	DiskSectorSize = 512
+)
+
+// BlkIOStatRaw is the I/O counters of a cgroup on a block device.
+type BlkIOStatRaw struct {
+	// Device is the device number in format `major:minor`
+	Device     string
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadIOs    uint64
+	WriteIOs   uint64
+}
+
+// ParseBlkIOThrottleStat parses the cgroup-v1 blkio.throttle.io_service_bytes_recursive and
+// blkio.throttle.io_serviced_recursive, the result is sorted by the device number.
+func ParseBlkIOThrottleStat(serviceBytesContent, servicedContent string) ([]BlkIOStatRaw, error) {
+	serviceBytes, err := parseBlkIOThrottleFile(serviceBytesContent)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed, err: %v", BlkioIOServiceBytesRecursiveName, err)
+	}
+	serviced, err := parseBlkIOThrottleFile(servicedContent)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed, err: %v", BlkioIOServicedRecursiveName, err)
+	}
+
+	statMap := map[string]*BlkIOStatRaw{}
+	getStat := func(device string) *BlkIOStatRaw {
+		stat, ok := statMap[device]
+		if !ok {
+			stat = &BlkIOStatRaw{Device: device}
+			statMap[device] = stat
+		}
+		return stat
+	}
+	for device, rw := range serviceBytes {
+		stat := getStat(device)
+		stat.ReadBytes, stat.WriteBytes = rw[0], rw[1]
+	}
+	for device, rw := range serviced {
+		stat := getStat(device)
+		stat.ReadIOs, stat.WriteIOs = rw[0], rw[1]
+	}
+	return sortBlkIOStats(statMap), nil
+}
+
+// parseBlkIOThrottleFile returns the read and write counters of each device, e.g.
+//
+//	8:0 Read 1024
+//	8:0 Write 2048
+//	8:0 Sync 3072
+//	8:0 Async 0
+//	8:0 Discard 0
+//	8:0 Total 3072
+//	Total 3072
+func parseBlkIOThrottleFile(content string) (map[string][2]uint64, error) {
+	result := map[string][2]uint64{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 { // skip the empty line and the summary line
+			continue
+		}
+		var idx int
+		switch fields[1] {
+		case "Read":
+			idx = 0
+		case "Write":
+			idx = 1
+		default:
+			continue
+		}
+		v, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s of device %s", fields[2], fields[0])
+		}
+		rw := result[fields[0]]
+		rw[idx] = v
+		result[fields[0]] = rw
+	}
+	return result, nil
+}
+
+// ParseIOStatV2 parses the cgroup-v2 io.stat, the result is sorted by the device number, e.g.
+//
+//	8:16 rbytes=1459200 wbytes=314773504 rios=192 wios=353 dbytes=0 dios=0
+//	8:0 rbytes=90430464 wbytes=299008000 rios=8950 wios=1252 dbytes=50331648 dios=3021
+func ParseIOStatV2(content string) ([]BlkIOStatRaw, error) {
+	statMap := map[string]*BlkIOStatRaw{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		stat := &BlkIOStatRaw{Device: fields[0]}
+		for _, kv := range fields[1:] {
+			pair := strings.SplitN(kv, "=", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("parse io.stat failed, invalid field %s of device %s", kv, fields[0])
+			}
+			var value *uint64
+			switch pair[0] {
+			case "rbytes":
+				value = &stat.ReadBytes
+			case "wbytes":
+				value = &stat.WriteBytes
+			case "rios":
+				value = &stat.ReadIOs
+			case "wios":
+				value = &stat.WriteIOs
+			default: // ignore dbytes, dios and the fields of io controllers
+				continue
+			}
+			v, err := strconv.ParseUint(pair[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse io.stat failed, invalid field %s of device %s", kv, fields[0])
+			}
+			*value = v
+		}
+		statMap[stat.Device] = stat
+	}
+	return sortBlkIOStats(statMap), nil
+}
+
+func sortBlkIOStats(statMap map[string]*BlkIOStatRaw) []BlkIOStatRaw {
+	stats := make([]BlkIOStatRaw, 0, len(statMap))
+	for _, stat := range statMap {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Device < stats[j].Device
+	})
+	return stats
+}
+
+// DiskStat is the I/O counters of a block device in /proc/diskstats.
+// https://www.kernel.org/doc/Documentation/ABI/testing/procfs-diskstats
+type DiskStat struct {
+	// Device is the device number in format `major:minor`
+	Device      string
+	Name        string
+	ReadIOs     uint64
+	ReadBytes   uint64
+	ReadTimeMs  uint64
+	WriteIOs    uint64
+	WriteBytes  uint64
+	WriteTimeMs uint64
+}
+
+func GetProcDiskStatsPath() string {
+	return filepath.Join(Conf.ProcRootDir, ProcDiskStatsName)
+}
+
+// ParseDiskStats parses the content of /proc/diskstats, e.g.
+//
+//	8       0 sda 1000 10 20000 500 2000 20 40000 1500 0 1800 2000 0 0 0 0
+func ParseDiskStats(content string) ([]DiskStat, error) {
+	var stats []DiskStat
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 14 {
+			return nil, fmt.Errorf("parse diskstats failed, fields not enough for line %s", line)
+		}
+		values := make([]uint64, 8)
+		for i := range values {
+			v, err := strconv.ParseUint(fields[i+3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse diskstats failed, invalid field %s of device %s", fields[i+3], fields[2])
+			}
+			values[i] = v
+		}
+		stats = append(stats, DiskStat{
+			Device:      fields[0] + ":" + fields[1],
+			Name:        fields[2],
+			ReadIOs:     values[0],
+			ReadBytes:   values[2] * DiskSectorSize,
+			ReadTimeMs:  values[3],
+			WriteIOs:    values[4],
+			WriteBytes:  values[6] * DiskSectorSize,
+			WriteTimeMs: values[7],
+		})
+	}
+	return stats, nil
+}
+
+// GetDiskStats reads the counters of all block devices in /proc/diskstats, including the partitions.
+func GetDiskStats() ([]DiskStat, error) {
+	content, err := os.ReadFile(GetProcDiskStatsPath())
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiskStats(string(content))
+}
+
+// IsWholeDisk checks if the block device is a whole disk, where the partitions and the loop or ram devices are excluded.
+func IsWholeDisk(name string) bool {
+	if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+		return false
+	}
+	// only the whole disks are listed in /sys/block
+	exist, _ := PathExists(filepath.Join(Conf.SysRootDir, SysBlockSubDir, name))
+	return exist
+}
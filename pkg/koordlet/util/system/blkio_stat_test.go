@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiskStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    []DiskStat
+		wantErr bool
+	}{
+		{
+			name:    "parse empty content",
+			arg:     "",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "parse failed for fields not enough",
+			arg:     `   8       0 sda 1000 10 20000 500`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "parse failed for invalid field",
+			arg:     `   8       0 sda 1000 10 x 500 2000 20 40000 1500 0 1800 2000 0 0 0 0`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse correctly",
+			arg: `   8       0 sda 1000 10 20000 500 2000 20 40000 1500 0 1800 2000 0 0 0 0
+   8       1 sda1 900 10 18000 400 1900 20 38000 1400 0 1700 1800
+`,
+			want: []DiskStat{
+				{
+					Device:      "8:0",
+					Name:        "sda",
+					ReadIOs:     1000,
+					ReadBytes:   20000 * DiskSectorSize,
+					ReadTimeMs:  500,
+					WriteIOs:    2000,
+					WriteBytes:  40000 * DiskSectorSize,
+					WriteTimeMs: 1500,
+				},
+				{
+					Device:      "8:1",
+					Name:        "sda1",
+					ReadIOs:     900,
+					ReadBytes:   18000 * DiskSectorSize,
+					ReadTimeMs:  400,
+					WriteIOs:    1900,
+					WriteBytes:  38000 * DiskSectorSize,
+					WriteTimeMs: 1400,
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := ParseDiskStats(tt.arg)
+			assert.Equal(t, tt.wantErr, gotErr != nil, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetDiskStats(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	_, err := GetDiskStats()
+	assert.Error(t, err)
+
+	helper.WriteFileContents(GetProcDiskStatsPath(), `   7       0 loop0 10 0 20 0 0 0 0 0 0 0 0 0 0 0 0
+   8       0 sda 1000 10 20000 500 2000 20 40000 1500 0 1800 2000 0 0 0 0
+   8       1 sda1 900 10 18000 400 1900 20 38000 1400 0 1700 1800 0 0 0 0
+`)
+	helper.MkDirAll(filepath.Join(SysBlockSubDir, "loop0"))
+	helper.MkDirAll(filepath.Join(SysBlockSubDir, "sda"))
+	got, err := GetDiskStats()
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, "sda", got[1].Name)
+	assert.False(t, IsWholeDisk("loop0"))
+	assert.True(t, IsWholeDisk("sda"))
+	assert.False(t, IsWholeDisk("sda1"))
+}
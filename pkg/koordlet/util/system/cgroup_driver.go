@@ -50,7 +50,12 @@ const (
 	RuntimeTypeDocker     = "docker"
 	RuntimeTypeContainerd = "containerd"
 	RuntimeTypePouch      = "pouch"
+	RuntimeTypeCrio       = "cri-o"
 	RuntimeTypeUnknown    = "unknown"
+
+	// crioConmonPrefix is the cgroup prefix of the conmon process which cri-o creates for each container
+	// besides the container cgroup, e.g. crio-conmon-<id>.scope
+	crioConmonPrefix = "crio-conmon-"
 )
 
 func (c CgroupDriverType) Validate() bool {
@@ -106,6 +111,8 @@ var cgroupPathFormatterInSystemd = Formatter{
 			return RuntimeTypeContainerd, fmt.Sprintf("cri-containerd-%s.scope", hashID[1]), nil
 		case RuntimeTypePouch:
 			return RuntimeTypePouch, fmt.Sprintf("pouch-%s.scope", hashID[1]), nil
+		case RuntimeTypeCrio:
+			return RuntimeTypeCrio, fmt.Sprintf("crio-%s.scope", hashID[1]), nil
 		default:
 			return RuntimeTypeUnknown, "", fmt.Errorf("unknown container protocol %s", id)
 		}
@@ -150,8 +157,15 @@ var cgroupPathFormatterInSystemd = Formatter{
 				prefix: "cri-containerd-",
 				suffix: ".scope",
 			},
+			{
+				prefix: "crio-",
+				suffix: ".scope",
+			},
 		}
 
+		if strings.HasPrefix(basename, crioConmonPrefix) {
+			return "", fmt.Errorf("fail to parse container id: %v", basename)
+		}
 		for i := range patterns {
 			if strings.HasPrefix(basename, patterns[i].prefix) && strings.HasSuffix(basename, patterns[i].suffix) {
 				return basename[len(patterns[i].prefix) : len(basename)-len(patterns[i].suffix)], nil
@@ -184,6 +198,8 @@ var cgroupPathFormatterInCgroupfs = Formatter{
 		}
 		if hashID[0] == RuntimeTypeDocker || hashID[0] == RuntimeTypeContainerd || hashID[0] == RuntimeTypePouch {
 			return hashID[0], fmt.Sprintf("%s", hashID[1]), nil
+		} else if hashID[0] == RuntimeTypeCrio {
+			return RuntimeTypeCrio, fmt.Sprintf("crio-%s", hashID[1]), nil
 		} else {
 			return RuntimeTypeUnknown, "", fmt.Errorf("unknown container protocol %s", id)
 		}
@@ -195,7 +211,10 @@ var cgroupPathFormatterInCgroupfs = Formatter{
 		return "", fmt.Errorf("fail to parse pod id: %v", basename)
 	},
 	ContainerIDParser: func(basename string) (string, error) {
-		return basename, nil
+		if strings.HasPrefix(basename, crioConmonPrefix) {
+			return "", fmt.Errorf("fail to parse container id: %v", basename)
+		}
+		return strings.TrimPrefix(basename, "crio-"), nil
 	},
 }
 
@@ -108,6 +108,14 @@ func Test_ParseContainerIDSystemd(t *testing.T) {
 			basename:  "cri-containerd-12345.scope",
 			expeceted: "12345",
 		},
+		{
+			basename:  "crio-12345.scope",
+			expeceted: "12345",
+		},
+		{
+			basename:  "crio-conmon-12345.scope",
+			wantError: true,
+		},
 		{
 			basename:  "12345",
 			wantError: true,
@@ -138,6 +146,14 @@ func Test_ParseContainerIDCgroupfs(t *testing.T) {
 			basename:  "docker-12345.scope",
 			expeceted: "docker-12345.scope",
 		},
+		{
+			basename:  "crio-12345",
+			expeceted: "12345",
+		},
+		{
+			basename:  "crio-conmon-12345",
+			wantError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -179,6 +195,13 @@ func Test_SystemdCgroupPathContainerDirFn(t *testing.T) {
 			wantDirName: "pouch-testPouchContainerID.scope",
 			wantError:   false,
 		},
+		{
+			name:        "cri-o",
+			containerID: "cri-o://testCrioContainerID",
+			wantType:    RuntimeTypeCrio,
+			wantDirName: "crio-testCrioContainerID.scope",
+			wantError:   false,
+		},
 		{
 			name:        "bad-format",
 			containerID: "bad-format-id",
@@ -224,6 +247,13 @@ func Test_CgroupfsCgroupPathContainerDirFn(t *testing.T) {
 			wantDirName: "testPouchContainerID",
 			wantError:   false,
 		},
+		{
+			name:        "cri-o",
+			containerID: "cri-o://testCrioContainerID",
+			wantType:    RuntimeTypeCrio,
+			wantDirName: "crio-testCrioContainerID",
+			wantError:   false,
+		},
 		{
 			name:        "bad-format",
 			containerID: "bad-format-id",
@@ -111,6 +111,7 @@ const ( // subsystems
 	CgroupCPUAcctDir string = "cpuacct/"
 	CgroupMemDir     string = "memory/"
 	CgroupBlkioDir   string = "blkio/"
+	CgroupNetClsDir  string = "net_cls/"
 
 	CgroupV2Dir = ""
 )
@@ -122,6 +123,10 @@ const (
 	CPUSharesMaxValue  int64 = 262144
 	CPUWeightMinValue  int64 = 1
 	CPUWeightMaxValue  int64 = 10000
+	IOWeightMinValue   int64 = 1
+	IOWeightMaxValue   int64 = 100
+	IOWeightMinValueV2 int64 = 1
+	IOWeightMaxValueV2 int64 = 10000
 
 	CPUStatName      = "cpu.stat"
 	CPUSharesName    = "cpu.shares"
@@ -169,6 +174,17 @@ const (
 	BlkioTWBpsName    = "blkio.throttle.write_bps_device"
 	BlkioIOWeightName = "blkio.cost.weight"
 	BlkioIOQoSName    = "blkio.cost.qos"
+
+	// the recursive stats include the I/Os of the descendants, e.g. the containers of a pod
+	BlkioIOServiceBytesRecursiveName = "blkio.throttle.io_service_bytes_recursive"
+	BlkioIOServicedRecursiveName     = "blkio.throttle.io_serviced_recursive"
+	IOStatName                       = "io.stat"
+
+	IOMaxName     = "io.max"
+	IOWeightName  = "io.weight"
+	IOCostQoSName = "io.cost.qos"
+
+	NetClsClassIDName = "net_cls.classid"
 )
 
 var (
@@ -190,8 +206,11 @@ var (
 	BlkioTRBpsValidator                     = &BlkIORangeValidator{min: 0, max: math.MaxInt64, resource: BlkioTRBpsName}
 	BlkioTWIopsValidator                    = &BlkIORangeValidator{min: 0, max: math.MaxInt64, resource: BlkioTWIopsName}
 	BlkioTWBpsValidator                     = &BlkIORangeValidator{min: 0, max: math.MaxInt64, resource: BlkioTWBpsName}
-	BlkioIOWeightValidator                  = &BlkIORangeValidator{min: 1, max: 100, resource: BlkioIOWeightName}
+	BlkioIOWeightValidator                  = &BlkIORangeValidator{min: IOWeightMinValue, max: IOWeightMaxValue, resource: BlkioIOWeightName}
+	BlkioIOWeightV2Validator                = &BlkIORangeValidator{min: IOWeightMinValueV2, max: IOWeightMaxValueV2, resource: BlkioIOWeightName}
 	BlkioIOQoSValidator                     = &BlkIORangeValidator{min: 0, max: math.MaxInt64, resource: BlkioIOQoSName}
+	BlkioIOMaxValidator                     = &BlkIORangeValidator{min: 0, max: math.MaxInt64, resource: IOMaxName}
+	NetClsClassIDValidator                  = &RangeValidator{min: 0, max: math.MaxUint32}
 
 	CPUSetCPUSValidator = &CPUSetStrValidator{}
 )
@@ -240,6 +259,11 @@ var (
 	BlkioIOWeight  = DefaultFactory.New(BlkioIOWeightName, CgroupBlkioDir).WithValidator(BlkioIOWeightValidator).WithCheckSupported(SupportedIfFileExistsInKubepods).WithCheckOnce(true)
 	BlkioIOQoS     = DefaultFactory.New(BlkioIOQoSName, CgroupBlkioDir).WithValidator(BlkioIOQoSValidator).WithSupported(SupportedIfFileExistsInRootCgroup(BlkioIOQoSName, CgroupBlkioDir))
 
+	BlkioIOServiceBytesRecursive = DefaultFactory.New(BlkioIOServiceBytesRecursiveName, CgroupBlkioDir)
+	BlkioIOServicedRecursive     = DefaultFactory.New(BlkioIOServicedRecursiveName, CgroupBlkioDir)
+
+	NetClsClassID = DefaultFactory.New(NetClsClassIDName, CgroupNetClsDir).WithValidator(NetClsClassIDValidator).WithCheckSupported(SupportedIfFileExistsInKubepods).WithCheckOnce(true)
+
 	knownCgroupResources = []Resource{
 		CPUStat,
 		CPUShares,
@@ -276,6 +300,9 @@ var (
 		BlkioWriteBps,
 		BlkioIOWeight,
 		BlkioIOQoS,
+		BlkioIOServiceBytesRecursive,
+		BlkioIOServicedRecursive,
+		NetClsClassID,
 	}
 
 	CPUCFSQuotaV2  = DefaultFactory.NewV2(CPUCFSQuotaName, CPUMaxName)
@@ -310,6 +337,11 @@ var (
 	MemoryUsePriorityOomV2   = DefaultFactory.NewV2(MemoryUsePriorityOomName, MemoryUsePriorityOomName).WithValidator(MemoryUsePriorityOomValidator).WithCheckSupported(SupportedIfFileExists)
 	MemoryOomGroupV2         = DefaultFactory.NewV2(MemoryOomGroupName, MemoryOomGroupName).WithValidator(MemoryOomGroupValidator).WithCheckSupported(SupportedIfFileExists)
 
+	BlkioIOMaxV2    = DefaultFactory.NewV2(IOMaxName, IOMaxName).WithValidator(BlkioIOMaxValidator).WithCheckSupported(SupportedIfFileExistsInKubepods).WithCheckOnce(true)
+	BlkioIOWeightV2 = DefaultFactory.NewV2(BlkioIOWeightName, IOWeightName).WithValidator(BlkioIOWeightV2Validator).WithCheckSupported(SupportedIfFileExistsInKubepods).WithCheckOnce(true)
+	BlkioIOQoSV2    = DefaultFactory.NewV2(BlkioIOQoSName, IOCostQoSName).WithValidator(BlkioIOQoSValidator).WithCheckSupported(SupportedIfFileExists) // only exists in the root cgroup
+	IOStatV2        = DefaultFactory.NewV2(IOStatName, IOStatName)
+
 	knownCgroupV2Resources = []Resource{
 		CPUCFSQuotaV2,
 		CPUCFSPeriodV2,
@@ -340,8 +372,10 @@ var (
 		MemoryPriorityV2,
 		MemoryUsePriorityOomV2,
 		MemoryOomGroupV2,
-		BlkioIOWeight,
-		BlkioIOQoS,
+		BlkioIOMaxV2,
+		BlkioIOWeightV2,
+		BlkioIOQoSV2,
+		IOStatV2,
 	}
 )
 
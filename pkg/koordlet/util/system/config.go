@@ -47,6 +47,7 @@ type Config struct {
 	ContainerdEndPoint string
 	PouchEndpoint      string
 	DockerEndPoint     string
+	CrioEndpoint       string
 	DefaultRuntimeType string
 }
 
@@ -119,6 +120,7 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.ContainerdEndPoint, "containerd-endpoint", c.ContainerdEndPoint, "containerd endPoint")
 	fs.StringVar(&c.DockerEndPoint, "docker-endpoint", c.DockerEndPoint, "docker endPoint")
 	fs.StringVar(&c.PouchEndpoint, "pouch-endpoint", c.PouchEndpoint, "pouch endPoint")
+	fs.StringVar(&c.CrioEndpoint, "crio-endpoint", c.CrioEndpoint, "cri-o endPoint")
 
-	fs.StringVar(&c.DefaultRuntimeType, "default-runtime-type", c.DefaultRuntimeType, "default runtime type during runtime hooks handle request, candidates are containerd/docker/pouch.")
+	fs.StringVar(&c.DefaultRuntimeType, "default-runtime-type", c.DefaultRuntimeType, "default runtime type during runtime hooks handle request, candidates are containerd/docker/pouch/cri-o.")
 }
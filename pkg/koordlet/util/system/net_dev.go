@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProcNetDevName = "net/dev"
+
+	SysClassNetSubDir = "class/net"
+
+	LoopbackInterface = "lo"
+)
+
+// NetDevStat is the counters of a network interface in /proc/<pid>/net/dev.
+// https://man7.org/linux/man-pages/man5/proc.5.html
+type NetDevStat struct {
+	Interface string
+	RxBytes   uint64
+	RxPackets uint64
+	RxDrops   uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxDrops   uint64
+}
+
+// GetProcPIDNetDevPath returns the net/dev file in the network namespace of the pid.
+func GetProcPIDNetDevPath(pid uint32) string {
+	return filepath.Join(Conf.ProcRootDir, strconv.FormatUint(uint64(pid), 10), ProcNetDevName)
+}
+
+// GetSysNetDevicePath returns the device link of a network interface in the host network namespace,
+// which only exists for physical NICs.
+func GetSysNetDevicePath(iface string) string {
+	return filepath.Join(Conf.SysRootDir, SysClassNetSubDir, iface, "device")
+}
+
+// ParseNetDev parses the content of /proc/<pid>/net/dev, e.g.
+//
+//	Inter-|   Receive                                                |  Transmit
+//	 face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+//	    lo:  123456     789    0    0    0     0          0         0   123456     789    0    0    0     0       0          0
+//	  eth0: 9876543   12345    0    2    0     0          0         0  8765432   11111    0    1    0     0       0          0
+func ParseNetDev(content string) ([]NetDevStat, error) {
+	var stats []NetDevStat
+	for _, line := range strings.Split(content, "\n") {
+		// skip the headers
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		iface := strings.TrimSpace(parts[0])
+		fields := strings.Fields(parts[1])
+		if len(fields) < 16 {
+			return nil, fmt.Errorf("failed to parse net dev, err: fields not enough for interface %s", iface)
+		}
+		values := make([]uint64, 16)
+		for i := range values {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse net dev, err: invalid field %s for interface %s", fields[i], iface)
+			}
+			values[i] = v
+		}
+		stats = append(stats, NetDevStat{
+			Interface: iface,
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxDrops:   values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxDrops:   values[11],
+		})
+	}
+	return stats, nil
+}
+
+// GetNetDevStats reads the interface counters in the network namespace of the pid.
+func GetNetDevStats(pid uint32) ([]NetDevStat, error) {
+	content, err := os.ReadFile(GetProcPIDNetDevPath(pid))
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetDev(string(content))
+}
+
+// IsPhysicalNetDevice checks if the interface of the host network namespace is backed by a device.
+func IsPhysicalNetDevice(iface string) bool {
+	exist, _ := PathExists(GetSysNetDevicePath(iface))
+	return exist
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNetDevContent = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  123456     789    0    0    0     0          0         0   123456     789    0    0    0     0       0          0
+  eth0: 9876543   12345    0    2    0     0          0         0  8765432   11111    0    1    0     0       0          0
+`
+
+func TestParseNetDev(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    []NetDevStat
+		wantErr bool
+	}{
+		{
+			name:    "parse empty content",
+			arg:     "",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "parse failed for fields not enough",
+			arg: `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+  eth0: 9876543   12345    0    2`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse failed for invalid field",
+			arg: `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+  eth0: 9876543   12345    0    x    0     0          0         0  8765432   11111    0    1    0     0       0          0`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse correctly",
+			arg:  testNetDevContent,
+			want: []NetDevStat{
+				{
+					Interface: "lo",
+					RxBytes:   123456,
+					RxPackets: 789,
+					TxBytes:   123456,
+					TxPackets: 789,
+				},
+				{
+					Interface: "eth0",
+					RxBytes:   9876543,
+					RxPackets: 12345,
+					RxDrops:   2,
+					TxBytes:   8765432,
+					TxPackets: 11111,
+					TxDrops:   1,
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := ParseNetDev(tt.arg)
+			assert.Equal(t, tt.wantErr, gotErr != nil, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetNetDevStats(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	_, err := GetNetDevStats(12345)
+	assert.Error(t, err)
+
+	helper.WriteFileContents(GetProcPIDNetDevPath(12345), testNetDevContent)
+	got, err := GetNetDevStats(12345)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "eth0", got[1].Interface)
+	assert.Equal(t, uint64(9876543), got[1].RxBytes)
+
+	assert.False(t, IsPhysicalNetDevice("eth0"))
+	helper.MkDirAll(GetSysNetDevicePath("eth0"))
+	assert.True(t, IsPhysicalNetDevice("eth0"))
+}
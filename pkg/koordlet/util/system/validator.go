@@ -92,6 +92,19 @@ func (r *BlkIORangeValidator) Validate(value string) (bool, string) {
 		if len(rst) == 5 {
 			newValues = append(newValues, []string{rst[3][5:], rst[4][5:]}...)
 		}
+	case IOMaxName:
+		// io.max: 253:16 rbps=2048 wbps=max riops=max wiops=1024
+		rst := strings.Split(value, " ")
+		if len(rst) < 2 {
+			return false, fmt.Sprintf("value %v has no limit", value)
+		}
+		for _, kv := range rst[1:] {
+			pair := strings.Split(kv, "=")
+			if len(pair) != 2 {
+				return false, fmt.Sprintf("value %v is not in the key=value format", kv)
+			}
+			newValues = append(newValues, pair[1])
+		}
 	default:
 		return false, "unknown blkio resource name"
 	}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/apis/quota/v1alpha1"
+)
+
+// calculateChildQuotaMin partitions the min of the parent quota into the child quotas. The child quotas with
+// percentage take their percentages of the parent min first, and the child quotas with weight share the left
+// parent min by weight. The result is bounded by the floor and cap of each child quota, and scaled down if the sum
+// exceeds the parent min, since the webhook rejects the child quotas whose min sum is larger than the parent min.
+// The resources whose floors are broken by the scale-down are returned by the child quota names.
+func calculateChildQuotaMin(profile *v1alpha1.ElasticQuotaProfile, parentMin corev1.ResourceList) ([]corev1.ResourceList, map[string][]corev1.ResourceName) {
+	childQuotas := profile.Spec.ChildQuotas
+	mins := make([]corev1.ResourceList, len(childQuotas))
+	for i := range mins {
+		mins[i] = corev1.ResourceList{}
+	}
+	brokenFloors := map[string][]corev1.ResourceName{}
+
+	var totalWeight int64
+	for _, child := range childQuotas {
+		if child.Percentage == nil && child.Weight != nil && *child.Weight > 0 {
+			totalWeight += int64(*child.Weight)
+		}
+	}
+
+	for resourceName, total := range parentMin {
+		left := total.DeepCopy()
+		for i, child := range childQuotas {
+			if child.Percentage == nil {
+				continue
+			}
+			percentage := *child.Percentage
+			if percentage < 0 {
+				percentage = 0
+			} else if percentage > 100 {
+				percentage = 100
+			}
+			mins[i][resourceName] = MultiplyQuantity(total, resourceName, float64(percentage)/100)
+			left.Sub(mins[i][resourceName])
+		}
+		if left.Sign() < 0 {
+			left = *resource.NewQuantity(0, total.Format)
+		}
+		for i, child := range childQuotas {
+			if child.Percentage != nil {
+				continue
+			}
+			if child.Weight == nil || *child.Weight <= 0 || totalWeight <= 0 {
+				mins[i][resourceName] = MultiplyQuantity(left, resourceName, 0)
+				continue
+			}
+			mins[i][resourceName] = MultiplyQuantity(left, resourceName, float64(*child.Weight)/float64(totalWeight))
+		}
+
+		sum := *resource.NewQuantity(0, total.Format)
+		for i, child := range childQuotas {
+			quantity := mins[i][resourceName]
+			if floor, ok := child.Min[resourceName]; ok && quantity.Cmp(floor) < 0 {
+				quantity = floor.DeepCopy()
+			}
+			if ceiling, ok := child.Max[resourceName]; ok && quantity.Cmp(ceiling) > 0 {
+				quantity = ceiling.DeepCopy()
+			}
+			mins[i][resourceName] = quantity
+			sum.Add(quantity)
+		}
+
+		if sum.Cmp(total) > 0 && sum.Sign() > 0 {
+			klog.Warningf("the sum of child quota min %v exceeds the min %v of quota %v in %v, scale down the child quotas",
+				sum.String(), total.String(), profile.Spec.QuotaName, resourceName)
+			ratio := total.AsApproximateFloat64() / sum.AsApproximateFloat64()
+			for i, child := range childQuotas {
+				quantity := MultiplyQuantity(mins[i][resourceName], resourceName, ratio)
+				mins[i][resourceName] = quantity
+				if floor, ok := child.Min[resourceName]; ok && quantity.Cmp(floor) < 0 {
+					brokenFloors[child.Name] = append(brokenFloors[child.Name], resourceName)
+				}
+			}
+		}
+	}
+	return mins, brokenFloors
+}
+
+// formatBrokenFloors formats the broken floors in the order of the child quota names, e.g. "a(cpu,memory), b(cpu)".
+func formatBrokenFloors(brokenFloors map[string][]corev1.ResourceName) string {
+	names := make([]string, 0, len(brokenFloors))
+	for name := range brokenFloors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]string, 0, len(names))
+	for _, name := range names {
+		resourceNames := make([]string, 0, len(brokenFloors[name]))
+		for _, resourceName := range brokenFloors[name] {
+			resourceNames = append(resourceNames, string(resourceName))
+		}
+		sort.Strings(resourceNames)
+		items = append(items, fmt.Sprintf("%s(%s)", name, strings.Join(resourceNames, ",")))
+	}
+	return strings.Join(items, ", ")
+}
+
+// releaseChildQuota zeros the min of the child quota removed from the profile, so that it no longer holds the min
+// of the parent quota. The max is kept since the child quota may still have pods bound.
+func releaseChildQuota(quota *schedv1alpha1.ElasticQuota) {
+	min := corev1.ResourceList{}
+	for resourceName, quantity := range quota.Spec.Min {
+		min[resourceName] = *resource.NewQuantity(0, quantity.Format)
+	}
+	quota.Spec.Min = min
+}
+
+// buildChildQuota updates the child quota with the spec of the profile, and the max of the child quota keeps the same
+// resource keys as the parent quota.
+func buildChildQuota(profile *v1alpha1.ElasticQuotaProfile, child *v1alpha1.ElasticQuotaProfileChildQuota,
+	quota *schedv1alpha1.ElasticQuota, treeID string, min, parentMax corev1.ResourceList) {
+	max := corev1.ResourceList{}
+	for resourceName, quantity := range parentMax {
+		if ceiling, ok := child.Max[resourceName]; ok {
+			quantity = ceiling
+		}
+		max[resourceName] = quantity.DeepCopy()
+	}
+	quota.Spec.Min = min
+	quota.Spec.Max = max
+
+	if quota.Labels == nil {
+		quota.Labels = make(map[string]string)
+	}
+	for k, v := range child.Labels {
+		quota.Labels[k] = v
+	}
+	quota.Labels[extension.LabelQuotaProfile] = profile.Name
+	quota.Labels[extension.LabelQuotaParent] = profile.Spec.QuotaName
+	quota.Labels[extension.LabelQuotaTreeID] = treeID
+}
+
+func newChildQuota(profile *v1alpha1.ElasticQuotaProfile, child *v1alpha1.ElasticQuotaProfileChildQuota) *schedv1alpha1.ElasticQuota {
+	return &schedv1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: profile.Namespace,
+			Name:      child.Name,
+		},
+	}
+}
+
+// lowerResourceList returns the lower quantity of each resource in a and b, and the resources only in a are kept.
+func lowerResourceList(a, b corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for resourceName, quantity := range a {
+		if other, ok := b[resourceName]; ok && other.Cmp(quantity) < 0 {
+			quantity = other
+		}
+		result[resourceName] = quantity.DeepCopy()
+	}
+	return result
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	quotav1alpha1 "github.com/koordinator-sh/koordinator/apis/quota/v1alpha1"
+)
+
+func createMilliResourceList(milliCPU, mem int64) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(milliCPU, resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(mem, resource.BinarySI),
+	}
+}
+
+func TestCalculateChildQuotaMin(t *testing.T) {
+	tests := []struct {
+		name               string
+		childQuotas        []quotav1alpha1.ElasticQuotaProfileChildQuota
+		parentMin          corev1.ResourceList
+		expectMins         []corev1.ResourceList
+		expectBrokenFloors map[string][]corev1.ResourceName
+	}{
+		{
+			name: "percentage and weight",
+			childQuotas: []quotav1alpha1.ElasticQuotaProfileChildQuota{
+				{Name: "a", Percentage: pointer.Int32(50)},
+				{Name: "b", Weight: pointer.Int32(3)},
+				{Name: "c", Weight: pointer.Int32(1)},
+				{Name: "d"},
+			},
+			parentMin: createResourceList(20, 2000),
+			expectMins: []corev1.ResourceList{
+				createMilliResourceList(10000, 1000),
+				createMilliResourceList(7500, 750),
+				createMilliResourceList(2500, 250),
+				createMilliResourceList(0, 0),
+			},
+		},
+		{
+			name: "percentage takes precedence over weight",
+			childQuotas: []quotav1alpha1.ElasticQuotaProfileChildQuota{
+				{Name: "a", Percentage: pointer.Int32(20), Weight: pointer.Int32(100)},
+				{Name: "b", Weight: pointer.Int32(1)},
+			},
+			parentMin: createResourceList(20, 2000),
+			expectMins: []corev1.ResourceList{
+				createMilliResourceList(4000, 400),
+				createMilliResourceList(16000, 1600),
+			},
+		},
+		{
+			name: "bounded by floor and cap",
+			childQuotas: []quotav1alpha1.ElasticQuotaProfileChildQuota{
+				{Name: "a", Weight: pointer.Int32(1), Max: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5")}},
+				{Name: "b", Weight: pointer.Int32(1), Min: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("12")}},
+			},
+			parentMin: createResourceList(20, 2000),
+			expectMins: []corev1.ResourceList{
+				createMilliResourceList(5000, 1000),
+				createMilliResourceList(12000, 1000),
+			},
+		},
+		{
+			name: "scale down when the floors exceed the parent min",
+			childQuotas: []quotav1alpha1.ElasticQuotaProfileChildQuota{
+				{Name: "a", Percentage: pointer.Int32(50)},
+				{Name: "b", Weight: pointer.Int32(1), Min: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("15")}},
+			},
+			parentMin: createResourceList(20, 2000),
+			expectMins: []corev1.ResourceList{
+				createMilliResourceList(8000, 1000),
+				createMilliResourceList(12000, 1000),
+			},
+			expectBrokenFloors: map[string][]corev1.ResourceName{"b": {corev1.ResourceCPU}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			profile := &quotav1alpha1.ElasticQuotaProfile{
+				Spec: quotav1alpha1.ElasticQuotaProfileSpec{QuotaName: "root", ChildQuotas: tc.childQuotas},
+			}
+			mins, brokenFloors := calculateChildQuotaMin(profile, tc.parentMin)
+			assert.Equal(t, len(tc.expectMins), len(mins))
+			for i := range mins {
+				assert.True(t, quotav1.Equals(tc.expectMins[i], mins[i]), "child %v, expect %v, got %v",
+					tc.childQuotas[i].Name, tc.expectMins[i], mins[i])
+			}
+			if tc.expectBrokenFloors == nil {
+				tc.expectBrokenFloors = map[string][]corev1.ResourceName{}
+			}
+			assert.Equal(t, tc.expectBrokenFloors, brokenFloors)
+		})
+	}
+}
+
+func TestQuotaProfileReconciler_Reconciler_ChildQuota(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	quotav1alpha1.AddToScheme(scheme)
+	schedv1alpha1.AddToScheme(scheme)
+
+	r := &QuotaProfileReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	nodes := []*corev1.Node{
+		defaultCreateNode("node1", map[string]string{"topology.kubernetes.io/zone": "cn-hangzhou-a"}, createResourceList(10, 1000)),
+		defaultCreateNode("node2", map[string]string{"topology.kubernetes.io/zone": "cn-hangzhou-a"}, createResourceList(10, 1000)),
+	}
+	for _, node := range nodes {
+		assert.NoError(t, r.Client.Create(context.TODO(), node))
+	}
+
+	profile := &quotav1alpha1.ElasticQuotaProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "profile1",
+		},
+		Spec: quotav1alpha1.ElasticQuotaProfileSpec{
+			QuotaName: "profile1-root",
+			NodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"topology.kubernetes.io/zone": "cn-hangzhou-a"},
+			},
+			ChildQuotas: []quotav1alpha1.ElasticQuotaProfileChildQuota{
+				{Name: "child-a", Percentage: pointer.Int32(50), Labels: map[string]string{"team": "a"}},
+				{Name: "child-b", Weight: pointer.Int32(3), Max: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("800")}},
+				{Name: "child-c", Weight: pointer.Int32(1)},
+			},
+		},
+	}
+	assert.NoError(t, r.Client.Create(context.TODO(), profile))
+	treeID := hash(fmt.Sprintf("%s/%s", profile.Namespace, profile.Name))
+	profileReq := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: profile.Namespace, Name: profile.Name}}
+
+	checkChildQuotas := func(expectMins []corev1.ResourceList) {
+		parent := &schedv1alpha1.ElasticQuota{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: profile.Namespace, Name: profile.Spec.QuotaName}, parent)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", parent.Labels[extension.LabelQuotaIsParent])
+
+		sum := corev1.ResourceList{}
+		for i, child := range profile.Spec.ChildQuotas {
+			quota := &schedv1alpha1.ElasticQuota{}
+			err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: profile.Namespace, Name: child.Name}, quota)
+			assert.NoError(t, err)
+			assert.True(t, quotav1.Equals(expectMins[i], quota.Spec.Min), "child %v, expect %v, got %v",
+				child.Name, expectMins[i], quota.Spec.Min)
+			assert.ElementsMatch(t, quotav1.ResourceNames(parent.Spec.Max), quotav1.ResourceNames(quota.Spec.Max))
+			if ceiling, ok := child.Max[corev1.ResourceMemory]; ok {
+				assert.True(t, ceiling.Equal(quota.Spec.Max[corev1.ResourceMemory]))
+			}
+			assert.Equal(t, profile.Name, quota.Labels[extension.LabelQuotaProfile])
+			assert.Equal(t, profile.Spec.QuotaName, quota.Labels[extension.LabelQuotaParent])
+			assert.Equal(t, treeID, quota.Labels[extension.LabelQuotaTreeID])
+			for k, v := range child.Labels {
+				assert.Equal(t, v, quota.Labels[k])
+			}
+			sum = quotav1.Add(sum, quota.Spec.Min)
+		}
+		lessThanOrEqual, _ := quotav1.LessThanOrEqual(sum, parent.Spec.Min)
+		assert.True(t, lessThanOrEqual)
+	}
+
+	_, err := r.Reconcile(context.TODO(), profileReq)
+	assert.NoError(t, err)
+	checkChildQuotas([]corev1.ResourceList{
+		createMilliResourceList(10000, 1000),
+		createMilliResourceList(7500, 750),
+		createMilliResourceList(2500, 250),
+	})
+
+	// the child quotas shrink with the nodes
+	assert.NoError(t, r.Client.Delete(context.TODO(), nodes[1]))
+	_, err = r.Reconcile(context.TODO(), profileReq)
+	assert.NoError(t, err)
+	checkChildQuotas([]corev1.ResourceList{
+		createMilliResourceList(5000, 500),
+		createMilliResourceList(3750, 375),
+		createMilliResourceList(1250, 125),
+	})
+
+	// the child quota removed from the profile releases its min
+	assert.NoError(t, r.Client.Get(context.TODO(), profileReq.NamespacedName, profile))
+	profile.Spec.ChildQuotas = profile.Spec.ChildQuotas[:2]
+	assert.NoError(t, r.Client.Update(context.TODO(), profile))
+	_, err = r.Reconcile(context.TODO(), profileReq)
+	assert.NoError(t, err)
+	checkChildQuotas([]corev1.ResourceList{
+		createMilliResourceList(5000, 500),
+		createMilliResourceList(5000, 500),
+	})
+	removed := &schedv1alpha1.ElasticQuota{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: profile.Namespace, Name: "child-c"}, removed))
+	assert.True(t, quotav1.IsZero(removed.Spec.Min), "got %v", removed.Spec.Min)
+	assert.Equal(t, profile.Name, removed.Labels[extension.LabelQuotaProfile])
+
+	// the floor broken by the scale-down is reported
+	profile.Spec.ChildQuotas[1].Min = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")}
+	assert.NoError(t, r.Client.Update(context.TODO(), profile))
+	_, err = r.Reconcile(context.TODO(), profileReq)
+	assert.NoError(t, err)
+	assert.Equal(t, "Warning ChildQuotaFloorBroken the min of quota profile1-root is not enough for the floors of child quota child-b(cpu)",
+		<-r.Recorder.(*record.FakeRecorder).Events)
+}
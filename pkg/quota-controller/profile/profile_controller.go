@@ -32,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -50,8 +51,9 @@ import (
 const Name = "quotaprofile"
 
 const (
-	ReasonCreateQuotaFailed = "CreateQuotaFailed"
-	ReasonUpdateQuotaFailed = "UpdateQuotaFailed"
+	ReasonCreateQuotaFailed     = "CreateQuotaFailed"
+	ReasonUpdateQuotaFailed     = "UpdateQuotaFailed"
+	ReasonChildQuotaFloorBroken = "ChildQuotaFloorBroken"
 )
 
 var resourceDecorators = []func(profile *v1alpha1.ElasticQuotaProfile, total corev1.ResourceList){
@@ -185,6 +187,10 @@ func (r *QuotaProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// update quota root label
 	quota.Labels[extension.LabelQuotaIsRoot] = "true"
+	// the child quotas require the quota to be a parent
+	if len(profile.Spec.ChildQuotas) > 0 {
+		quota.Labels[extension.LabelQuotaIsParent] = "true"
+	}
 
 	// update total resource
 	data, err := json.Marshal(totalResource)
@@ -197,6 +203,21 @@ func (r *QuotaProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 	quota.Annotations[extension.AnnotationTotalResource] = string(data)
 
+	childMins, brokenFloors := calculateChildQuotaMin(profile, min)
+	if len(brokenFloors) > 0 {
+		r.Recorder.Eventf(profile, "Warning", ReasonChildQuotaFloorBroken, "the min of quota %v is not enough for the floors of child quota %s",
+			profile.Spec.QuotaName, formatBrokenFloors(brokenFloors))
+	}
+	if quotaExist {
+		// lower the min of the child quotas before updating the parent quota, so the sum of the child quota min never
+		// exceeds the parent min when the total resource shrinks
+		if err := r.syncChildQuotas(profile, quotaTreeID, childMins, max, true); err != nil {
+			r.Recorder.Eventf(profile, "Warning", ReasonUpdateQuotaFailed, "failed to update child quota, err: %s", err)
+			klog.Errorf("failed update child quota for profile %v, error: %v", req.NamespacedName, err)
+			return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
+		}
+	}
+
 	if !quotaExist {
 		err = r.Client.Create(context.TODO(), quota)
 		if err != nil {
@@ -215,9 +236,90 @@ func (r *QuotaProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
+	if err := r.syncChildQuotas(profile, quotaTreeID, childMins, max, false); err != nil {
+		r.Recorder.Eventf(profile, "Warning", ReasonUpdateQuotaFailed, "failed to sync child quota, err: %s", err)
+		klog.Errorf("failed sync child quota for profile %v, error: %v", req.NamespacedName, err)
+		return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
+	}
+
 	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 }
 
+// syncChildQuotas creates or updates the child quotas of the profile. If lowerOnly is true, only the min of the
+// existing child quotas is lowered. The child quotas removed from the profile are not deleted, since they may still
+// have pods bound, but their min is zeroed.
+func (r *QuotaProfileReconciler) syncChildQuotas(profile *v1alpha1.ElasticQuotaProfile, treeID string,
+	childMins []corev1.ResourceList, parentMax corev1.ResourceList, lowerOnly bool) error {
+	if err := r.releaseRemovedChildQuotas(profile); err != nil {
+		return err
+	}
+	for i := range profile.Spec.ChildQuotas {
+		child := &profile.Spec.ChildQuotas[i]
+		quota := &schedv1alpha1.ElasticQuota{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: profile.Namespace, Name: child.Name}, quota)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			if lowerOnly {
+				continue
+			}
+			quota = newChildQuota(profile, child)
+			buildChildQuota(profile, child, quota, treeID, childMins[i], parentMax)
+			if err := r.Client.Create(context.TODO(), quota); err != nil {
+				return fmt.Errorf("create child quota %v failed, %w", child.Name, err)
+			}
+			continue
+		}
+
+		if owner := quota.Labels[extension.LabelQuotaProfile]; owner != profile.Name {
+			klog.Warningf("child quota %v/%v of profile %v belongs to profile %q, skip it", quota.Namespace, quota.Name, profile.Name, owner)
+			continue
+		}
+		oldQuota := quota.DeepCopy()
+		if lowerOnly {
+			quota.Spec.Min = lowerResourceList(oldQuota.Spec.Min, childMins[i])
+		} else {
+			buildChildQuota(profile, child, quota, treeID, childMins[i], parentMax)
+		}
+		if reflect.DeepEqual(quota.Labels, oldQuota.Labels) && quotav1.Equals(quota.Spec.Min, oldQuota.Spec.Min) &&
+			quotav1.Equals(quota.Spec.Max, oldQuota.Spec.Max) {
+			continue
+		}
+		if err := r.Client.Update(context.TODO(), quota); err != nil {
+			return fmt.Errorf("update child quota %v failed, %w", child.Name, err)
+		}
+	}
+	return nil
+}
+
+// releaseRemovedChildQuotas zeros the min of the child quotas created by the profile but removed from its spec.
+func (r *QuotaProfileReconciler) releaseRemovedChildQuotas(profile *v1alpha1.ElasticQuotaProfile) error {
+	quotaList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.Client.List(context.TODO(), quotaList, client.InNamespace(profile.Namespace), client.MatchingLabels{
+		extension.LabelQuotaProfile: profile.Name,
+		extension.LabelQuotaParent:  profile.Spec.QuotaName,
+	}); err != nil {
+		return err
+	}
+	childNames := sets.NewString()
+	for _, child := range profile.Spec.ChildQuotas {
+		childNames.Insert(child.Name)
+	}
+	for i := range quotaList.Items {
+		quota := &quotaList.Items[i]
+		if quota.Name == profile.Spec.QuotaName || childNames.Has(quota.Name) || quotav1.IsZero(quota.Spec.Min) {
+			continue
+		}
+		releaseChildQuota(quota)
+		if err := r.Client.Update(context.TODO(), quota); err != nil {
+			return fmt.Errorf("release child quota %v failed, %w", quota.Name, err)
+		}
+		klog.Infof("child quota %v/%v is removed from profile %v, zero its min", quota.Namespace, quota.Name, profile.Name)
+	}
+	return nil
+}
+
 func Add(mgr ctrl.Manager) error {
 	reconciler := QuotaProfileReconciler{
 		Client:   mgr.GetClient(),
@@ -64,6 +64,9 @@ type RuntimeHookConfig struct {
 	RemoteEndpoint string            `json:"remote-endpoint,omitempty"`
 	FailurePolicy  FailurePolicyType `json:"failure-policy,omitempty"`
 	RuntimeHooks   []RuntimeHookType `json:"runtime-hooks,omitempty"`
+	// Order decides the calling order when multiple hook servers register the same hook point. The hook servers are
+	// called in ascending order, and those with the same order are called in the order of their config file names.
+	Order int32 `json:"order,omitempty"`
 }
 
 type RuntimeRequestPath string
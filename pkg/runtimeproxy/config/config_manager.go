@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -36,6 +37,7 @@ const (
 )
 
 type ManagerInterface interface {
+	// GetAllHook returns all the loaded hook configs in the calling order.
 	GetAllHook() []*RuntimeHookConfig
 	Run() error
 }
@@ -54,11 +56,25 @@ type RuntimeHookConfigItem struct {
 }
 
 func (m *Manager) GetAllHook() []*RuntimeHookConfig {
-	var runtimeConfigs []*RuntimeHookConfig
 	m.Lock()
 	defer m.Unlock()
+	items := make([]*RuntimeHookConfigItem, 0, len(m.configs))
 	for _, config := range m.configs {
-		runtimeConfigs = append(runtimeConfigs, config.RuntimeHookConfig)
+		// skip the config file registered but not loaded successfully
+		if config.RuntimeHookConfig == nil {
+			continue
+		}
+		items = append(items, config)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Order != items[j].Order {
+			return items[i].Order < items[j].Order
+		}
+		return items[i].filePath < items[j].filePath
+	})
+	var runtimeConfigs []*RuntimeHookConfig
+	for _, item := range items {
+		runtimeConfigs = append(runtimeConfigs, item.RuntimeHookConfig)
 	}
 	return runtimeConfigs
 }
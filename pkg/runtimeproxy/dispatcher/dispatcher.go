@@ -19,14 +19,17 @@ package dispatcher
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/client"
 	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
+	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/utils"
 )
 
 // RuntimeHookDispatcher dispatches hook request to RuntimeHookServer(e.g. koordlet)
@@ -65,32 +68,153 @@ func (rd *RuntimeHookDispatcher) dispatchInternal(ctx context.Context, hookType
 	return nil, status.Errorf(codes.Unimplemented, fmt.Sprintf("method %v not implemented", string(hookType)))
 }
 
+// Dispatch calls all the hook servers registered on the runtimeRequestPath and stage in order. The response of a hook
+// server is merged into the request of the next one, and the merged result is returned as the response. A failed hook
+// server with the PolicyFail aborts the dispatching, while the others are skipped. The returned policy is the strictest
+// one of the called hook servers.
 func (rd *RuntimeHookDispatcher) Dispatch(ctx context.Context, runtimeRequestPath config.RuntimeRequestPath,
 	stage config.RuntimeHookStage, request interface{}) (interface{}, error, config.FailurePolicyType) {
 	hookServers := rd.hookManager.GetAllHook()
+	policy := config.FailurePolicyType(config.PolicyNone)
+	responded := false
 	for _, hookServer := range hookServers {
-		for _, hookType := range hookServer.RuntimeHooks {
-			if !hookType.OccursOn(runtimeRequestPath) {
-				continue
-			}
-			if hookType.HookStage() != stage {
-				continue
-			}
-			client, err := rd.cm.RuntimeHookServerClient(client.HookServerPath{
-				Path: hookServer.RemoteEndpoint,
-			})
-			if err != nil {
-				klog.Errorf("fail to get client %v", err)
-				continue
+		hookType, ok := getHookType(hookServer, runtimeRequestPath, stage)
+		if !ok {
+			continue
+		}
+		client, err := rd.cm.RuntimeHookServerClient(client.HookServerPath{
+			Path: hookServer.RemoteEndpoint,
+		})
+		if err != nil {
+			klog.Errorf("fail to get client %v", err)
+			continue
+		}
+		policy = mergeFailurePolicy(policy, hookServer.FailurePolicy)
+		rsp, err := rd.dispatchInternal(ctx, hookType, client, request)
+		if err != nil {
+			if hookServer.FailurePolicy == config.PolicyFail {
+				return nil, err, hookServer.FailurePolicy
 			}
-			// currently, only one hook be called during one runtime
-			// TODO: multi hook server to merge response
-			rsp, err := rd.dispatchInternal(ctx, hookType, client, request)
-			if err != nil {
+			klog.Warningf("fail to call hook server %v for %v, ignore it, err: %v", hookServer.RemoteEndpoint, hookType, err)
+			continue
+		}
+		if isNilResponse(rsp) {
+			continue
+		}
+		// the next hook server receives the request updated by the previous ones
+		if request, err = mergeResponseToRequest(request, rsp); err != nil {
+			klog.Errorf("fail to merge response of hook server %v for %v, err: %v", hookServer.RemoteEndpoint, hookType, err)
+			if hookServer.FailurePolicy == config.PolicyFail {
 				return nil, err, hookServer.FailurePolicy
 			}
-			return rsp, err, hookServer.FailurePolicy
+			continue
+		}
+		responded = true
+	}
+	if !responded {
+		return nil, nil, policy
+	}
+	return generateResponse(request), nil, policy
+}
+
+func getHookType(hookServer *config.RuntimeHookConfig, runtimeRequestPath config.RuntimeRequestPath,
+	stage config.RuntimeHookStage) (config.RuntimeHookType, bool) {
+	for _, hookType := range hookServer.RuntimeHooks {
+		if hookType.OccursOn(runtimeRequestPath) && hookType.HookStage() == stage {
+			return hookType, true
+		}
+	}
+	return config.NoneRuntimeHookType, false
+}
+
+// mergeFailurePolicy returns the stricter one of the two policies, i.e. Fail > Ignore > None.
+func mergeFailurePolicy(a, b config.FailurePolicyType) config.FailurePolicyType {
+	if a == config.PolicyFail || b == config.PolicyFail {
+		return config.PolicyFail
+	}
+	if a == config.PolicyIgnore || b == config.PolicyIgnore {
+		return config.PolicyIgnore
+	}
+	return config.PolicyNone
+}
+
+func isNilResponse(rsp interface{}) bool {
+	switch response := rsp.(type) {
+	case *v1alpha1.PodSandboxHookResponse:
+		return response == nil
+	case *v1alpha1.ContainerResourceHookResponse:
+		return response == nil
+	}
+	return rsp == nil
+}
+
+// mergeResponseToRequest returns a copy of the request updated by the response, in the same way that the resource
+// executors update the runtime request.
+func mergeResponseToRequest(request, rsp interface{}) (interface{}, error) {
+	switch req := request.(type) {
+	case *v1alpha1.PodSandboxHookRequest:
+		response, ok := rsp.(*v1alpha1.PodSandboxHookResponse)
+		if !ok {
+			return request, fmt.Errorf("response type not compatible. Should be PodSandboxHookResponse, but got %s", reflect.TypeOf(rsp).String())
+		}
+		newReq := proto.Clone(req).(*v1alpha1.PodSandboxHookRequest)
+		if response.Labels != nil {
+			newReq.Labels = utils.MergeMap(newReq.Labels, response.Labels)
+		}
+		if response.Annotations != nil {
+			newReq.Annotations = utils.MergeMap(newReq.Annotations, response.Annotations)
+		}
+		if response.CgroupParent != "" {
+			newReq.CgroupParent = response.CgroupParent
+		}
+		if newReq.Resources == nil {
+			newReq.Resources = response.Resources
+		} else {
+			newReq.Resources = utils.UpdateResource(newReq.Resources, response.Resources)
+		}
+		return newReq, nil
+	case *v1alpha1.ContainerResourceHookRequest:
+		response, ok := rsp.(*v1alpha1.ContainerResourceHookResponse)
+		if !ok {
+			return request, fmt.Errorf("response type not compatible. Should be ContainerResourceHookResponse, but got %s", reflect.TypeOf(rsp).String())
+		}
+		newReq := proto.Clone(req).(*v1alpha1.ContainerResourceHookRequest)
+		if response.ContainerAnnotations != nil {
+			newReq.ContainerAnnotations = utils.MergeMap(newReq.ContainerAnnotations, response.ContainerAnnotations)
+		}
+		if newReq.ContainerResources == nil {
+			newReq.ContainerResources = response.ContainerResources
+		} else {
+			newReq.ContainerResources = utils.UpdateResource(newReq.ContainerResources, response.ContainerResources)
+		}
+		if response.PodCgroupParent != "" {
+			newReq.PodCgroupParent = response.PodCgroupParent
+		}
+		if response.ContainerEnvs != nil {
+			newReq.ContainerEnvs = utils.MergeMap(newReq.ContainerEnvs, response.ContainerEnvs)
+		}
+		return newReq, nil
+	}
+	return request, fmt.Errorf("request type %T not supported", request)
+}
+
+// generateResponse generates the response from the request merged with the responses of all hook servers.
+func generateResponse(request interface{}) interface{} {
+	switch req := request.(type) {
+	case *v1alpha1.PodSandboxHookRequest:
+		return &v1alpha1.PodSandboxHookResponse{
+			Labels:       req.GetLabels(),
+			Annotations:  req.GetAnnotations(),
+			CgroupParent: req.GetCgroupParent(),
+			Resources:    req.GetResources(),
+		}
+	case *v1alpha1.ContainerResourceHookRequest:
+		return &v1alpha1.ContainerResourceHookResponse{
+			ContainerAnnotations: req.GetContainerAnnotations(),
+			ContainerResources:   req.GetContainerResources(),
+			PodCgroupParent:      req.GetPodCgroupParent(),
+			ContainerEnvs:        req.GetContainerEnvs(),
 		}
 	}
-	return nil, nil, config.PolicyNone
+	return nil
 }
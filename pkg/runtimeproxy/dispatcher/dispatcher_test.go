@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/client"
@@ -177,3 +178,216 @@ func (m *mockHookServerClient) PostStopContainerHook(ctx context.Context, in *v1
 func (m *mockHookServerClient) PreUpdateContainerResourcesHook(ctx context.Context, in *v1alpha1.ContainerResourceHookRequest, opts ...grpc.CallOption) (*v1alpha1.ContainerResourceHookResponse, error) {
 	return nil, nil
 }
+
+func TestRuntimeHookDispatcher_DispatchChain(t *testing.T) {
+	podHook := func(name string) func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error) {
+		return func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error) {
+			return &v1alpha1.PodSandboxHookResponse{
+				Labels:       map[string]string{name: "true"},
+				Annotations:  map[string]string{"called-by": req.Annotations["called-by"] + "," + name},
+				CgroupParent: "/kubepods/" + name,
+			}, nil
+		}
+	}
+	failedPodHook := func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error) {
+		return nil, fmt.Errorf("hook server failed")
+	}
+	tests := []struct {
+		name              string
+		allHooks          []*config.RuntimeHookConfig
+		podHooks          map[string]func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error)
+		expectedResponse  *v1alpha1.PodSandboxHookResponse
+		expectedOperation config.FailurePolicyType
+		expectReturnErr   bool
+	}{
+		{
+			name: "responses of all hook servers are merged in order",
+			allHooks: []*config.RuntimeHookConfig{
+				{
+					RemoteEndpoint: "endpoint0",
+					FailurePolicy:  config.PolicyIgnore,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+				{
+					RemoteEndpoint: "endpoint1",
+					FailurePolicy:  config.PolicyFail,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+			},
+			podHooks: map[string]func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error){
+				"endpoint0": podHook("endpoint0"),
+				"endpoint1": podHook("endpoint1"),
+			},
+			expectedResponse: &v1alpha1.PodSandboxHookResponse{
+				Labels:       map[string]string{"origin": "true", "endpoint0": "true", "endpoint1": "true"},
+				Annotations:  map[string]string{"called-by": "kubelet,endpoint0,endpoint1"},
+				CgroupParent: "/kubepods/endpoint1",
+			},
+			expectedOperation: config.PolicyFail,
+		},
+		{
+			name: "failed hook server with ignore policy is skipped",
+			allHooks: []*config.RuntimeHookConfig{
+				{
+					RemoteEndpoint: "endpoint0",
+					FailurePolicy:  config.PolicyIgnore,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+				{
+					RemoteEndpoint: "endpoint1",
+					FailurePolicy:  config.PolicyIgnore,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+			},
+			podHooks: map[string]func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error){
+				"endpoint0": failedPodHook,
+				"endpoint1": podHook("endpoint1"),
+			},
+			expectedResponse: &v1alpha1.PodSandboxHookResponse{
+				Labels:       map[string]string{"origin": "true", "endpoint1": "true"},
+				Annotations:  map[string]string{"called-by": "kubelet,endpoint1"},
+				CgroupParent: "/kubepods/endpoint1",
+			},
+			expectedOperation: config.PolicyIgnore,
+		},
+		{
+			name: "failed hook server with fail policy aborts the chain",
+			allHooks: []*config.RuntimeHookConfig{
+				{
+					RemoteEndpoint: "endpoint0",
+					FailurePolicy:  config.PolicyIgnore,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+				{
+					RemoteEndpoint: "endpoint1",
+					FailurePolicy:  config.PolicyFail,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+			},
+			podHooks: map[string]func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error){
+				"endpoint0": podHook("endpoint0"),
+				"endpoint1": failedPodHook,
+			},
+			expectedOperation: config.PolicyFail,
+			expectReturnErr:   true,
+		},
+		{
+			name: "all hook servers failed with ignore policy",
+			allHooks: []*config.RuntimeHookConfig{
+				{
+					RemoteEndpoint: "endpoint0",
+					FailurePolicy:  config.PolicyIgnore,
+					RuntimeHooks:   []config.RuntimeHookType{config.PreRunPodSandbox},
+				},
+			},
+			podHooks: map[string]func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error){
+				"endpoint0": failedPodHook,
+			},
+			expectedOperation: config.PolicyIgnore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeHookDispatcher := &RuntimeHookDispatcher{
+				hookManager: NewMockManager(tt.allHooks),
+				cm:          &mockChainClientManager{podHooks: tt.podHooks},
+			}
+			request := &v1alpha1.PodSandboxHookRequest{
+				Labels:      map[string]string{"origin": "true"},
+				Annotations: map[string]string{"called-by": "kubelet"},
+			}
+			rsp, err, operation := runtimeHookDispatcher.Dispatch(context.TODO(), config.RunPodSandbox, config.PreHook, request)
+			assert.Equal(t, tt.expectedOperation, operation)
+			assert.Equal(t, tt.expectReturnErr, err != nil)
+			if tt.expectedResponse == nil {
+				assert.Nil(t, rsp)
+			} else {
+				assert.Equal(t, tt.expectedResponse, rsp)
+			}
+			// the original request should not be modified
+			assert.Equal(t, map[string]string{"origin": "true"}, request.Labels)
+		})
+	}
+}
+
+func TestRuntimeHookDispatcher_DispatchContainerChain(t *testing.T) {
+	runtimeHookDispatcher := &RuntimeHookDispatcher{
+		hookManager: NewMockManager([]*config.RuntimeHookConfig{
+			{
+				RemoteEndpoint: "koordlet",
+				FailurePolicy:  config.PolicyIgnore,
+				RuntimeHooks:   []config.RuntimeHookType{config.PreCreateContainer},
+			},
+			{
+				RemoteEndpoint: "device-injector",
+				FailurePolicy:  config.PolicyIgnore,
+				RuntimeHooks:   []config.RuntimeHookType{config.PreCreateContainer},
+			},
+		}),
+		cm: &mockChainClientManager{
+			containerHooks: map[string]func(req *v1alpha1.ContainerResourceHookRequest) (*v1alpha1.ContainerResourceHookResponse, error){
+				"koordlet": func(req *v1alpha1.ContainerResourceHookRequest) (*v1alpha1.ContainerResourceHookResponse, error) {
+					resources := proto.Clone(req.ContainerResources).(*v1alpha1.LinuxContainerResources)
+					resources.CpusetCpus = "0-3"
+					return &v1alpha1.ContainerResourceHookResponse{
+						ContainerResources: resources,
+					}, nil
+				},
+				"device-injector": func(req *v1alpha1.ContainerResourceHookRequest) (*v1alpha1.ContainerResourceHookResponse, error) {
+					// the response of koordlet is visible to the next hook server
+					assert.Equal(t, "0-3", req.ContainerResources.CpusetCpus)
+					// the responses are merged field by field, the fields not set by the response are kept
+					return &v1alpha1.ContainerResourceHookResponse{
+						ContainerResources: &v1alpha1.LinuxContainerResources{
+							CpusetCpus: req.ContainerResources.CpusetCpus,
+							Unified:    map[string]string{"memory.high": "max"},
+						},
+						ContainerEnvs: map[string]string{"VISIBLE_DEVICES": "0"},
+					}, nil
+				},
+			},
+		},
+	}
+	rsp, err, _ := runtimeHookDispatcher.Dispatch(context.TODO(), config.CreateContainer, config.PreHook,
+		&v1alpha1.ContainerResourceHookRequest{
+			ContainerResources: &v1alpha1.LinuxContainerResources{CpuShares: 1024},
+			ContainerEnvs:      map[string]string{"ORIGIN": "true"},
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, &v1alpha1.ContainerResourceHookResponse{
+		ContainerResources: &v1alpha1.LinuxContainerResources{
+			CpuShares:  1024,
+			CpusetCpus: "0-3",
+			Unified:    map[string]string{"memory.high": "max"},
+		},
+		ContainerEnvs: map[string]string{"ORIGIN": "true", "VISIBLE_DEVICES": "0"},
+	}, rsp)
+}
+
+type mockChainClientManager struct {
+	podHooks       map[string]func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error)
+	containerHooks map[string]func(req *v1alpha1.ContainerResourceHookRequest) (*v1alpha1.ContainerResourceHookResponse, error)
+}
+
+func (m *mockChainClientManager) RuntimeHookServerClient(serverPath client.HookServerPath) (*client.RuntimeHookClient, error) {
+	return &client.RuntimeHookClient{
+		RuntimeHookServiceClient: &mockChainHookServerClient{
+			podHook:       m.podHooks[serverPath.Path],
+			containerHook: m.containerHooks[serverPath.Path],
+		},
+	}, nil
+}
+
+type mockChainHookServerClient struct {
+	mockHookServerClient
+	podHook       func(req *v1alpha1.PodSandboxHookRequest) (*v1alpha1.PodSandboxHookResponse, error)
+	containerHook func(req *v1alpha1.ContainerResourceHookRequest) (*v1alpha1.ContainerResourceHookResponse, error)
+}
+
+func (m *mockChainHookServerClient) PreRunPodSandboxHook(ctx context.Context, in *v1alpha1.PodSandboxHookRequest, opts ...grpc.CallOption) (*v1alpha1.PodSandboxHookResponse, error) {
+	return m.podHook(in)
+}
+
+func (m *mockChainHookServerClient) PreCreateContainerHook(ctx context.Context, in *v1alpha1.ContainerResourceHookRequest, opts ...grpc.CallOption) (*v1alpha1.ContainerResourceHookResponse, error) {
+	return m.containerHook(in)
+}
@@ -172,7 +172,7 @@ func (c *ContainerResourceExecutor) UpdateRequest(rsp interface{}, req interface
 	}
 	// update PodResourceExecutor
 	c.ContainerAnnotations = utils.MergeMap(c.ContainerAnnotations, response.ContainerAnnotations)
-	c.ContainerResources = updateResource(c.ContainerResources, response.ContainerResources)
+	c.ContainerResources = utils.UpdateResource(c.ContainerResources, response.ContainerResources)
 	if response.PodCgroupParent != "" {
 		c.PodCgroupParent = response.PodCgroupParent
 	}
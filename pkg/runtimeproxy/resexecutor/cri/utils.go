@@ -67,36 +67,6 @@ func transferToCRIResources(r *v1alpha1.LinuxContainerResources) *runtimeapi.Lin
 	return linuxResources
 }
 
-func updateResource(a, b *v1alpha1.LinuxContainerResources) *v1alpha1.LinuxContainerResources {
-	if a == nil || b == nil {
-		return a
-	}
-	if b.CpuPeriod > 0 {
-		a.CpuPeriod = b.CpuPeriod
-	}
-	if b.CpuQuota != 0 { // -1 is valid
-		a.CpuQuota = b.CpuQuota
-	}
-	if b.CpuShares > 0 {
-		a.CpuShares = b.CpuShares
-	}
-	if b.MemoryLimitInBytes > 0 {
-		a.MemoryLimitInBytes = b.MemoryLimitInBytes
-	}
-	if b.OomScoreAdj >= -1000 && b.OomScoreAdj <= 1000 {
-		a.OomScoreAdj = b.OomScoreAdj
-	}
-
-	a.CpusetCpus = b.CpusetCpus
-	a.CpusetMems = b.CpusetMems
-
-	a.Unified = utils.MergeMap(a.Unified, b.Unified)
-	if b.MemorySwapLimitInBytes > 0 {
-		a.MemorySwapLimitInBytes = b.MemorySwapLimitInBytes
-	}
-	return a
-}
-
 // updateResourceByUpdateContainerResourceRequest updates resources in cache by UpdateContainerResource request.
 // updateResourceByUpdateContainerResourceRequest will omit OomScoreAdj.
 //
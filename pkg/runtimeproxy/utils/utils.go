@@ -16,6 +16,10 @@ limitations under the License.
 
 package utils
 
+import (
+	"github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
+)
+
 type CallHookPluginOperation string
 
 const (
@@ -33,3 +37,34 @@ func MergeMap(a, b map[string]string) map[string]string {
 	}
 	return a
 }
+
+// UpdateResource updates the resources a by the non-empty fields of b, and returns a.
+func UpdateResource(a, b *v1alpha1.LinuxContainerResources) *v1alpha1.LinuxContainerResources {
+	if a == nil || b == nil {
+		return a
+	}
+	if b.CpuPeriod > 0 {
+		a.CpuPeriod = b.CpuPeriod
+	}
+	if b.CpuQuota != 0 { // -1 is valid
+		a.CpuQuota = b.CpuQuota
+	}
+	if b.CpuShares > 0 {
+		a.CpuShares = b.CpuShares
+	}
+	if b.MemoryLimitInBytes > 0 {
+		a.MemoryLimitInBytes = b.MemoryLimitInBytes
+	}
+	if b.OomScoreAdj >= -1000 && b.OomScoreAdj <= 1000 {
+		a.OomScoreAdj = b.OomScoreAdj
+	}
+
+	a.CpusetCpus = b.CpusetCpus
+	a.CpusetMems = b.CpusetMems
+
+	a.Unified = MergeMap(a.Unified, b.Unified)
+	if b.MemorySwapLimitInBytes > 0 {
+		a.MemorySwapLimitInBytes = b.MemorySwapLimitInBytes
+	}
+	return a
+}
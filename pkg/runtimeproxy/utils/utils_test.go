@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
 )
 
 func TestMergeMap(t *testing.T) {
@@ -83,3 +85,73 @@ func TestMergeMap(t *testing.T) {
 		assert.Equal(t, tt.want, result)
 	}
 }
+
+func TestUpdateResource(t *testing.T) {
+	type args struct {
+		a *v1alpha1.LinuxContainerResources
+		b *v1alpha1.LinuxContainerResources
+	}
+	tests := []struct {
+		name string
+		args args
+		want *v1alpha1.LinuxContainerResources
+	}{
+		{
+			name: "a and b are both nil",
+			args: args{
+				a: nil,
+				b: nil,
+			},
+			want: nil,
+		},
+		{
+			name: "normal case",
+			args: args{
+				a: &v1alpha1.LinuxContainerResources{
+					CpuPeriod:              1000,
+					CpuQuota:               2000,
+					CpuShares:              500,
+					OomScoreAdj:            10,
+					MemorySwapLimitInBytes: 100,
+					MemoryLimitInBytes:     300,
+					CpusetCpus:             "0-64",
+					CpusetMems:             "0-2",
+					Unified: map[string]string{
+						"resourceA": "resource A",
+					},
+				},
+				b: &v1alpha1.LinuxContainerResources{
+					CpuPeriod:              2000,
+					CpuQuota:               4000,
+					CpuShares:              1000,
+					OomScoreAdj:            20,
+					MemorySwapLimitInBytes: 200,
+					MemoryLimitInBytes:     600,
+					CpusetCpus:             "0-31",
+					CpusetMems:             "0-4",
+					Unified: map[string]string{
+						"resourceB": "resource B",
+					},
+				},
+			},
+			want: &v1alpha1.LinuxContainerResources{
+				CpuPeriod:              2000,
+				CpuQuota:               4000,
+				CpuShares:              1000,
+				OomScoreAdj:            20,
+				MemorySwapLimitInBytes: 200,
+				MemoryLimitInBytes:     600,
+				CpusetCpus:             "0-31",
+				CpusetMems:             "0-4",
+				Unified: map[string]string{
+					"resourceA": "resource A",
+					"resourceB": "resource B",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		gotResources := UpdateResource(tt.args.a, tt.args.b)
+		assert.Equal(t, tt.want, gotResources)
+	}
+}
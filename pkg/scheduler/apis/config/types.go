@@ -157,6 +157,9 @@ type ReservationArgs struct {
 	metav1.TypeMeta
 
 	// EnablePreemption indicates whether to enable preemption for reservations.
+	// If enabled, a pending pod can preempt the available Reservations of lower priority (i.e. the priority of the
+	// reservation template) together with the lower priority pods, and the preempted Reservations are marked as
+	// Failed to release their unallocated resources. The Reservations are preferred to the pods as the victims.
 	EnablePreemption bool
 }
 
@@ -206,6 +209,10 @@ type CoschedulingArgs struct {
 	// Skip check schedule cycle
 	// default is false
 	SkipCheckScheduleCycle bool
+	// EnablePreemption indicates whether the pending members of a gang group can preempt the lower priority pods
+	// all together when the gang group is unschedulable.
+	// default is false
+	EnablePreemption bool
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -152,6 +152,9 @@ type ReservationArgs struct {
 	metav1.TypeMeta
 
 	// EnablePreemption indicates whether to enable preemption for reservations.
+	// If enabled, a pending pod can preempt the available Reservations of lower priority (i.e. the priority of the
+	// reservation template) together with the lower priority pods, and the preempted Reservations are marked as
+	// Failed to release their unallocated resources. The Reservations are preferred to the pods as the victims.
 	EnablePreemption *bool `json:"enablePreemption,omitempty"`
 }
 
@@ -201,6 +204,10 @@ type CoschedulingArgs struct {
 	// Skip check schedule cycle
 	// default is false
 	SkipCheckScheduleCycle *bool `json:"skipCheckScheduleCycle,omitempty"`
+	// EnablePreemption indicates whether the pending members of a gang group can preempt the lower priority pods
+	// all together when the gang group is unschedulable.
+	// default is false
+	EnablePreemption *bool `json:"enablePreemption,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -60,8 +60,10 @@ type frameworkExtenderImpl struct {
 	reservationPreBindPlugins []ReservationPreBindPlugin
 	reservationRestorePlugins []ReservationRestorePlugin
 
-	resizePodPlugins         []ResizePodPlugin
-	preBindExtensionsPlugins map[string]PreBindExtensions
+	resizePodPlugins          []ResizePodPlugin
+	simulateAllocationPlugins []SimulateAllocationPlugin
+	queueSortExtensions       []QueueSortExtension
+	preBindExtensionsPlugins  map[string]PreBindExtensions
 
 	numaTopologyHintProviders []topologymanager.NUMATopologyHintProvider
 	topologyManager           topologymanager.Interface
@@ -132,6 +134,12 @@ func (ext *frameworkExtenderImpl) updatePlugins(pl framework.Plugin) {
 	if r, ok := pl.(ResizePodPlugin); ok {
 		ext.resizePodPlugins = append(ext.resizePodPlugins, r)
 	}
+	if p, ok := pl.(SimulateAllocationPlugin); ok {
+		ext.simulateAllocationPlugins = append(ext.simulateAllocationPlugins, p)
+	}
+	if p, ok := pl.(QueueSortExtension); ok {
+		ext.queueSortExtensions = append(ext.queueSortExtensions, p)
+	}
 	if p, ok := pl.(PreBindExtensions); ok {
 		ext.preBindExtensionsPlugins[p.Name()] = p
 	}
@@ -219,6 +227,9 @@ func (ext *frameworkExtenderImpl) RunFilterPluginsWithNominatedPods(ctx context.
 		}
 	}
 	status := ext.Framework.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo)
+	if recorder := getSimulationRecorder(cycleState); recorder != nil {
+		recorder.recordFilterStatus(nodeInfo.Node().Name, status)
+	}
 	if !status.IsSuccess() && debugFilterFailure {
 		klog.Infof("Failed to filter for Pod %q on Node %q, failedPlugin: %s, reason: %s", klog.KObj(pod), klog.KObj(nodeInfo.Node()), status.FailedPlugin(), status.Message())
 	}
@@ -251,6 +262,9 @@ func (ext *frameworkExtenderImpl) RunScorePlugins(ctx context.Context, state *fr
 		}
 	}
 	pluginToNodeScores, status := ext.Framework.RunScorePlugins(ctx, state, pod, nodes)
+	if recorder := getSimulationRecorder(state); recorder != nil && status.IsSuccess() {
+		recorder.recordScores(pluginToNodeScores)
+	}
 	if status.IsSuccess() && debugTopNScores > 0 {
 		debugScores(debugTopNScores, pod, pluginToNodeScores, nodes)
 	}
@@ -474,6 +488,32 @@ func (ext *frameworkExtenderImpl) RunResizePod(ctx context.Context, cycleState *
 	return nil
 }
 
+// RunSimulateAllocation collects the would-be allocations of the Pod on the node from the SimulateAllocationPlugins.
+// The allocations are keyed by the plugin name, and the plugins that allocate nothing are omitted.
+func (ext *frameworkExtenderImpl) RunSimulateAllocation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (map[string]interface{}, *framework.Status) {
+	allocations := map[string]interface{}{}
+	for _, pl := range ext.simulateAllocationPlugins {
+		allocation, status := pl.SimulateAllocation(ctx, cycleState, pod, nodeName)
+		if !status.IsSuccess() {
+			return nil, status.WithFailedPlugin(pl.Name())
+		}
+		if allocation != nil {
+			allocations[pl.Name()] = allocation
+		}
+	}
+	return allocations, nil
+}
+
+// RunQueueSortExtensions returns the first non-zero order of the two Pods given by the QueueSortExtensions.
+func (ext *frameworkExtenderImpl) RunQueueSortExtensions(podInfo1, podInfo2 *framework.QueuedPodInfo) int {
+	for _, pl := range ext.queueSortExtensions {
+		if result := pl.CompareQueuedPods(podInfo1, podInfo2); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
 const podAssumedStateKey = "koordinator.sh/assumed"
 
 type assumedState struct{}
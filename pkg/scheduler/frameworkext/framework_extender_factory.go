@@ -151,6 +151,9 @@ func (f *FrameworkExtenderFactory) InitScheduler(sched Scheduler) {
 			}
 		}
 	}
+	if f.servicesEngine != nil {
+		f.servicesEngine.RegisterService(NewSchedulingSimulator(f))
+	}
 }
 
 func (f *FrameworkExtenderFactory) scheduleOne(ctx context.Context, fwk framework.Framework, cycleState *framework.CycleState, pod *corev1.Pod) (scheduler.ScheduleResult, error) {
@@ -823,3 +823,67 @@ func TestReservationScorePlugin(t *testing.T) {
 		})
 	}
 }
+
+type fakeQueueSortExtension struct {
+	name   string
+	result int
+}
+
+func (f *fakeQueueSortExtension) Name() string { return f.name }
+
+func (f *fakeQueueSortExtension) PreFilter(ctx context.Context, state *framework.CycleState, p *corev1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	return nil, nil
+}
+
+func (f *fakeQueueSortExtension) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+func (f *fakeQueueSortExtension) CompareQueuedPods(podInfo1, podInfo2 *framework.QueuedPodInfo) int {
+	return f.result
+}
+
+func TestRunQueueSortExtensions(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []int
+		want    int
+	}{
+		{
+			name: "no extensions",
+			want: 0,
+		},
+		{
+			name:    "first non-zero result wins",
+			results: []int{0, 1, -1},
+			want:    1,
+		},
+		{
+			name:    "all extensions leave the order",
+			results: []int{0, 0},
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extenderFactory, _ := NewFrameworkExtenderFactory()
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			}
+			for i, result := range tt.results {
+				name := fmt.Sprintf("fakeQueueSortExtension-%d", i)
+				result := result
+				registeredPlugins = append(registeredPlugins, schedulertesting.RegisterPreFilterPlugin(name, PluginFactoryProxy(extenderFactory, func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+					return &fakeQueueSortExtension{name: name, result: result}, nil
+				})))
+			}
+			fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler")
+			assert.NoError(t, err)
+			frameworkExtender := extenderFactory.NewFrameworkExtender(fh)
+			podInfo1 := &framework.QueuedPodInfo{PodInfo: framework.NewPodInfo(&corev1.Pod{})}
+			podInfo2 := &framework.QueuedPodInfo{PodInfo: framework.NewPodInfo(&corev1.Pod{})}
+			assert.Equal(t, tt.want, frameworkExtender.RunQueueSortExtensions(podInfo1, podInfo2))
+		})
+	}
+}
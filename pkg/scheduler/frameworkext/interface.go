@@ -67,6 +67,10 @@ type FrameworkExtender interface {
 	RunNUMATopologyManagerAdmit(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string, numaNodes []int, policyType apiext.NUMATopologyPolicy) *framework.Status
 
 	RunResizePod(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status
+
+	RunSimulateAllocation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (map[string]interface{}, *framework.Status)
+
+	RunQueueSortExtensions(podInfo1, podInfo2 *framework.QueuedPodInfo) int
 }
 
 // SchedulingTransformer is the parent type for all the custom transformer plugins.
@@ -179,6 +183,24 @@ type ResizePodPlugin interface {
 	ResizePod(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status
 }
 
+// SimulateAllocationPlugin returns the resources that would be allocated to the Pod on the node in the Reserve phase.
+// It is used by the scheduling simulation and MUST NOT modify any cache of the scheduler. The allocation should be
+// recorded in the state of GetOrCreateSimulationState, so the Pods simulated later see it.
+type SimulateAllocationPlugin interface {
+	framework.Plugin
+	SimulateAllocation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (interface{}, *framework.Status)
+}
+
+// QueueSortExtension orders the pending Pods before the QueueSort plugin applies its own order, since only one
+// QueueSort plugin is allowed in a profile.
+// It returns a negative number if podInfo1 should be scheduled before podInfo2, a positive number if after, and 0 to
+// leave them to the QueueSort plugin. The order MUST NOT change while the Pods are in the queue, or the heap of the
+// scheduling queue is broken.
+type QueueSortExtension interface {
+	framework.Plugin
+	CompareQueuedPods(podInfo1, podInfo2 *framework.QueuedPodInfo) int
+}
+
 // ReservationPreBindPlugin performs special binding logic specifically for Reservation in the PreBind phase.
 // Similar to the built-in VolumeBinding plugin of kube-scheduler, it does not support Reservation,
 // and how Reservation itself uses PVC reserved resources also needs special handling.
@@ -232,6 +232,10 @@ func (ri *ReservationInfo) Match(pod *corev1.Pod) bool {
 	if ri.ParseError != nil {
 		return false
 	}
+	// the reservations of a group are only consumed by the pods of the gang
+	if ri.Reservation != nil && !reservationutil.MatchReservationGroup(pod, reservationutil.GetReservationGroup(ri.Reservation)) {
+		return false
+	}
 	return reservationutil.MatchReservationOwners(pod, ri.OwnerMatchers)
 }
 
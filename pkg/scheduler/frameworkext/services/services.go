@@ -87,6 +87,11 @@ func (e *Engine) RegisterPluginService(plugin framework.Plugin) {
 	}
 }
 
+// RegisterService registers the endpoints of the service provider under the base path of services.
+func (e *Engine) RegisterService(serviceProvider APIServiceProvider) {
+	serviceProvider.RegisterEndpoints(e.Engine.Group(servicesBaseRelativePath))
+}
+
 func listRegisteredServices(e *gin.Engine) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		routes := e.Routes()
@@ -0,0 +1,584 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/informers"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/services"
+)
+
+const schedulingSimulationStateKey = "koordinator.sh/scheduling-simulation"
+
+// SimulationRequest describes the Pods to be simulated. The Pods are simulated in order, and the Pods simulated
+// successfully are temporarily assumed on their suggested nodes when simulating the subsequent Pods, so a list of
+// Pods or the members of a Gang can be checked as a whole.
+type SimulationRequest struct {
+	Pod  *corev1.Pod   `json:"pod,omitempty"`
+	Pods []*corev1.Pod `json:"pods,omitempty"`
+}
+
+type SimulationResponse struct {
+	// Schedulable indicates whether all the Pods can be scheduled.
+	Schedulable bool                   `json:"schedulable"`
+	Pods        []*PodSimulationResult `json:"pods,omitempty"`
+}
+
+type PodSimulationResult struct {
+	Pod string `json:"pod"`
+	// SuggestedNode is the node the Pod would be scheduled to, empty if the Pod is unschedulable.
+	SuggestedNode string `json:"suggestedNode,omitempty"`
+	// Message is the reason why the Pod is unschedulable.
+	Message string                  `json:"message,omitempty"`
+	Nodes   []*NodeSimulationResult `json:"nodes,omitempty"`
+	// Reservation is the Reservation nominated to the Pod on the suggested node.
+	Reservation string `json:"reservation,omitempty"`
+	// Allocations are the would-be allocations of the Pod on the suggested node, keyed by the plugin name.
+	Allocations map[string]interface{} `json:"allocations,omitempty"`
+}
+
+type NodeSimulationResult struct {
+	Name         string   `json:"name"`
+	Feasible     bool     `json:"feasible"`
+	FailedPlugin string   `json:"failedPlugin,omitempty"`
+	Reasons      []string `json:"reasons,omitempty"`
+	// Scores are the weighted scores of the score plugins. They are absent if only one node is feasible,
+	// since the scheduler skips the scoring in that case.
+	Scores     map[string]int64 `json:"scores,omitempty"`
+	TotalScore int64            `json:"totalScore,omitempty"`
+}
+
+// simulationRecorder records the Filter statuses and the scores of the simulated Pod in the CycleState.
+type simulationRecorder struct {
+	lock           sync.Mutex
+	filterStatuses map[string]*framework.Status
+	scores         framework.PluginToNodeScores
+
+	simulation *simulationState
+	snapshot   *simulationSnapshot
+}
+
+func newSimulationRecorder(simulation *simulationState, snapshot *simulationSnapshot) *simulationRecorder {
+	return &simulationRecorder{
+		filterStatuses: map[string]*framework.Status{},
+		simulation:     simulation,
+		snapshot:       snapshot,
+	}
+}
+
+func (r *simulationRecorder) Clone() framework.StateData {
+	return r
+}
+
+func (r *simulationRecorder) recordFilterStatus(nodeName string, status *framework.Status) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.filterStatuses[nodeName] = status
+}
+
+func (r *simulationRecorder) recordScores(scores framework.PluginToNodeScores) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.scores = scores
+}
+
+func getSimulationRecorder(cycleState *framework.CycleState) *simulationRecorder {
+	if cycleState == nil {
+		return nil
+	}
+	value, err := cycleState.Read(schedulingSimulationStateKey)
+	if err != nil {
+		return nil
+	}
+	recorder, _ := value.(*simulationRecorder)
+	return recorder
+}
+
+// IsSchedulingSimulation returns whether the CycleState is created for the scheduling simulation.
+// The plugins which depend on the objects not existing in the cluster (e.g. the other members of a Gang)
+// should skip the checks for these simulated cycles.
+func IsSchedulingSimulation(cycleState *framework.CycleState) bool {
+	return getSimulationRecorder(cycleState) != nil
+}
+
+// simulationState is shared by the cycles of the Pods simulated together with the same scheduler,
+// it keeps the nominated Reservations and the states of the plugins out of the scheduler.
+type simulationState struct {
+	snapshot *simulationSnapshot
+
+	lock                  sync.Mutex
+	nominatedReservations map[types.UID]map[string]*ReservationInfo
+	pluginStates          map[string]interface{}
+}
+
+func newSimulationState(snapshot *simulationSnapshot) *simulationState {
+	return &simulationState{
+		snapshot:              snapshot,
+		nominatedReservations: map[types.UID]map[string]*ReservationInfo{},
+		pluginStates:          map[string]interface{}{},
+	}
+}
+
+func (s *simulationState) nominateReservation(pod *corev1.Pod, nodeName string, rInfo *ReservationInfo) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	nodeToReservation := s.nominatedReservations[pod.UID]
+	if nodeToReservation == nil {
+		nodeToReservation = map[string]*ReservationInfo{}
+		s.nominatedReservations[pod.UID] = nodeToReservation
+	}
+	nodeToReservation[nodeName] = rInfo
+}
+
+func (s *simulationState) getNominatedReservation(pod *corev1.Pod, nodeName string) *ReservationInfo {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.nominatedReservations[pod.UID][nodeName]
+}
+
+// GetSnapshotSharedLister returns the SharedLister the plugins should read the nodes from in the cycle.
+// It is the private snapshot of the scheduling simulation for the simulated cycles, otherwise the snapshot of the scheduler.
+func GetSnapshotSharedLister(handle framework.Handle, cycleState *framework.CycleState) framework.SharedLister {
+	if recorder := getSimulationRecorder(cycleState); recorder != nil {
+		return recorder.snapshot
+	}
+	return handle.SnapshotSharedLister()
+}
+
+// GetNominatedReservation returns the Reservation nominated to the Pod on the node. The Reservations nominated in the
+// scheduling simulation are kept in the simulation and never reach the ReservationNominator of the scheduler.
+func GetNominatedReservation(handle ExtendedHandle, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *ReservationInfo {
+	if recorder := getSimulationRecorder(cycleState); recorder != nil {
+		return recorder.simulation.getNominatedReservation(pod, nodeName)
+	}
+	nominator := handle.GetReservationNominator()
+	if nominator == nil {
+		return nil
+	}
+	return nominator.GetNominatedReservation(pod, nodeName)
+}
+
+// AddNominatedReservation nominates the Reservation to the Pod on the node. The nominations of the scheduling
+// simulation are kept in the simulation instead of the ReservationNominator of the scheduler.
+func AddNominatedReservation(handle ExtendedHandle, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string, rInfo *ReservationInfo) {
+	if recorder := getSimulationRecorder(cycleState); recorder != nil {
+		recorder.simulation.nominateReservation(pod, nodeName, rInfo)
+		return
+	}
+	if nominator := handle.GetReservationNominator(); nominator != nil {
+		nominator.AddNominatedReservation(pod, nodeName, rInfo)
+	}
+}
+
+// GetOrCreateSimulationState returns the state of the plugin shared by the Pods simulated together, which is created
+// by newFn at the first time. It returns nil if the CycleState is not created for the scheduling simulation.
+// The plugins fork their caches into the state, and record the allocations of the simulated Pods on the forks so the
+// subsequent simulated Pods see them, like the Reserve does in the scheduling cycles.
+func GetOrCreateSimulationState(cycleState *framework.CycleState, pluginName string, newFn func() interface{}) interface{} {
+	recorder := getSimulationRecorder(cycleState)
+	if recorder == nil {
+		return nil
+	}
+	simulation := recorder.simulation
+	simulation.lock.Lock()
+	defer simulation.lock.Unlock()
+	state, ok := simulation.pluginStates[pluginName]
+	if !ok {
+		state = newFn()
+		simulation.pluginStates[pluginName] = state
+	}
+	return state
+}
+
+var _ services.APIServiceProvider = &SchedulingSimulator{}
+
+// SchedulingSimulator answers whether Pods would fit in the cluster without submitting them. It runs the scheduling
+// algorithm (PreFilter/Filter/Score) of the profile against a private snapshot built from the informers, and collects
+// the would-be allocations from the SimulateAllocationPlugins, but never runs Reserve, Permit or Bind.
+//
+// The simulations run concurrently with the scheduling cycles and never change the cache, the snapshot or the
+// ReservationNominator of the scheduler. The plugins read the nodes with GetSnapshotSharedLister and the nominated
+// Reservations with GetNominatedReservation, and keep the allocations of the simulated Pods (e.g. cpuset and devices)
+// in GetOrCreateSimulationState, so the Pods simulated together see the allocations of the earlier ones.
+// The Pods assumed by the scheduler but not bound yet are not in the private snapshot, and the in-tree plugins
+// holding the shared lister of the framework still see the snapshot of the scheduler.
+type SchedulingSimulator struct {
+	extenderFactory *FrameworkExtenderFactory
+}
+
+func NewSchedulingSimulator(extenderFactory *FrameworkExtenderFactory) *SchedulingSimulator {
+	return &SchedulingSimulator{
+		extenderFactory: extenderFactory,
+	}
+}
+
+func (s *SchedulingSimulator) RegisterEndpoints(group *gin.RouterGroup) {
+	group.POST("/simulate", s.simulate)
+}
+
+func (s *SchedulingSimulator) simulate(c *gin.Context) {
+	var request SimulationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		services.ResponseErrorMessage(c, http.StatusBadRequest, "invalid simulation request: %v", err)
+		return
+	}
+	var pods []*corev1.Pod
+	if request.Pod != nil {
+		pods = append(pods, request.Pod)
+	}
+	pods = append(pods, request.Pods...)
+	if len(pods) == 0 {
+		services.ResponseErrorMessage(c, http.StatusBadRequest, "no pod to simulate")
+		return
+	}
+	for i, pod := range pods {
+		defaultSimulatedPod(pod, i)
+		if pod.Spec.NodeName != "" {
+			services.ResponseErrorMessage(c, http.StatusBadRequest, "pod %s has been assigned to node %s", klog.KObj(pod), pod.Spec.NodeName)
+			return
+		}
+		if s.extenderFactory.GetExtender(pod.Spec.SchedulerName) == nil {
+			services.ResponseErrorMessage(c, http.StatusBadRequest, "pod %s specifies unknown scheduler %s", klog.KObj(pod), pod.Spec.SchedulerName)
+			return
+		}
+	}
+
+	response, err := s.Simulate(c.Request.Context(), pods)
+	if err != nil {
+		services.ResponseErrorMessage(c, http.StatusInternalServerError, "failed to simulate: %v", err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func defaultSimulatedPod(pod *corev1.Pod, index int) {
+	if pod.Namespace == "" {
+		pod.Namespace = corev1.NamespaceDefault
+	}
+	if pod.Name == "" {
+		pod.Name = fmt.Sprintf("%ssimulated-%d", pod.GenerateName, index)
+	}
+	if pod.UID == "" {
+		pod.UID = uuid.NewUUID()
+	}
+	if pod.Spec.SchedulerName == "" {
+		pod.Spec.SchedulerName = corev1.DefaultSchedulerName
+	}
+}
+
+// Simulate simulates the scheduling of the Pods in order.
+func (s *SchedulingSimulator) Simulate(ctx context.Context, pods []*corev1.Pod) (*SimulationResponse, error) {
+	response := &SimulationResponse{Schedulable: true}
+	simulations := map[string]*simulationState{}
+	for _, pod := range pods {
+		fwk := s.extenderFactory.GetExtender(pod.Spec.SchedulerName)
+		if fwk == nil {
+			return nil, fmt.Errorf("unknown scheduler %s", pod.Spec.SchedulerName)
+		}
+		simulation := simulations[pod.Spec.SchedulerName]
+		if simulation == nil {
+			snapshot, err := newSimulationSnapshot(fwk.SharedInformerFactory())
+			if err != nil {
+				return nil, err
+			}
+			simulation = newSimulationState(snapshot)
+			simulations[pod.Spec.SchedulerName] = simulation
+		}
+		result := s.simulateOne(ctx, fwk, simulation, pod)
+		response.Pods = append(response.Pods, result)
+		if result.SuggestedNode == "" {
+			response.Schedulable = false
+			continue
+		}
+
+		assumedPod := pod.DeepCopy()
+		assumedPod.Spec.NodeName = result.SuggestedNode
+		simulation.snapshot.nodeInfoMap[result.SuggestedNode].AddPod(assumedPod)
+	}
+	return response, nil
+}
+
+func (s *SchedulingSimulator) simulateOne(ctx context.Context, fwk FrameworkExtender, simulation *simulationState, pod *corev1.Pod) *PodSimulationResult {
+	// the plugins may modify the NodeInfos in the cycle, e.g. restoring the resources of the Reservations,
+	// so every cycle runs on its own copy of the snapshot.
+	snapshot := simulation.snapshot.clone()
+	recorder := newSimulationRecorder(simulation, snapshot)
+	cycleState := framework.NewCycleState()
+	cycleState.Write(schedulingSimulationStateKey, recorder)
+
+	result := &PodSimulationResult{
+		Pod: klog.KObj(pod).String(),
+	}
+	suggestedNode, err := runSimulationCycle(ctx, fwk, cycleState, snapshot, pod)
+	if err != nil {
+		result.Message = err.Error()
+	}
+	if fitErr, ok := err.(*framework.FitError); ok {
+		for nodeName, status := range fitErr.Diagnosis.NodeToStatusMap {
+			if _, ok := recorder.filterStatuses[nodeName]; !ok {
+				recorder.filterStatuses[nodeName] = status
+			}
+		}
+	}
+	result.Nodes = buildNodeSimulationResults(recorder)
+	if err != nil {
+		return result
+	}
+
+	result.SuggestedNode = suggestedNode
+	if nominator := fwk.GetReservationNominator(); nominator != nil {
+		rInfo, status := nominator.NominateReservation(ctx, cycleState, pod, result.SuggestedNode)
+		if !status.IsSuccess() {
+			result.Message = status.Message()
+			return result
+		}
+		if rInfo != nil {
+			result.Reservation = rInfo.GetName()
+			simulation.nominateReservation(pod, result.SuggestedNode, rInfo)
+		}
+	}
+	allocations, status := fwk.RunSimulateAllocation(ctx, cycleState, pod, result.SuggestedNode)
+	if !status.IsSuccess() {
+		result.Message = status.Message()
+		return result
+	}
+	if len(allocations) > 0 {
+		result.Allocations = allocations
+	}
+	return result
+}
+
+// runSimulationCycle runs the PreFilter, Filter and Score plugins like the kube-scheduler, but against the nodes
+// of the simulation snapshot, and returns the node with the highest score.
+func runSimulationCycle(ctx context.Context, fwk FrameworkExtender, cycleState *framework.CycleState, snapshot *simulationSnapshot, pod *corev1.Pod) (string, error) {
+	diagnosis := framework.Diagnosis{
+		NodeToStatusMap:      framework.NodeToStatusMap{},
+		UnschedulablePlugins: sets.NewString(),
+	}
+	fitError := func() error {
+		return &framework.FitError{Pod: pod, NumAllNodes: len(snapshot.nodeInfoList), Diagnosis: diagnosis}
+	}
+	preFilterResult, status := fwk.RunPreFilterPlugins(ctx, cycleState, pod)
+	if !status.IsSuccess() {
+		if !status.IsUnschedulable() {
+			return "", status.AsError()
+		}
+		for _, nodeInfo := range snapshot.nodeInfoList {
+			diagnosis.NodeToStatusMap[nodeInfo.Node().Name] = status
+		}
+		diagnosis.UnschedulablePlugins.Insert(status.FailedPlugin())
+		return "", fitError()
+	}
+
+	var feasibleNodes []*corev1.Node
+	for _, nodeInfo := range snapshot.nodeInfoList {
+		if !preFilterResult.AllNodes() && !preFilterResult.NodeNames.Has(nodeInfo.Node().Name) {
+			continue
+		}
+		status := fwk.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo)
+		if status.Code() == framework.Error {
+			return "", status.AsError()
+		}
+		if !status.IsSuccess() {
+			diagnosis.NodeToStatusMap[nodeInfo.Node().Name] = status
+			diagnosis.UnschedulablePlugins.Insert(status.FailedPlugin())
+			continue
+		}
+		feasibleNodes = append(feasibleNodes, nodeInfo.Node())
+	}
+	if len(feasibleNodes) == 0 {
+		return "", fitError()
+	}
+	if len(feasibleNodes) == 1 {
+		return feasibleNodes[0].Name, nil
+	}
+
+	if status := fwk.RunPreScorePlugins(ctx, cycleState, pod, feasibleNodes); !status.IsSuccess() {
+		return "", status.AsError()
+	}
+	scores, status := fwk.RunScorePlugins(ctx, cycleState, pod, feasibleNodes)
+	if !status.IsSuccess() {
+		return "", status.AsError()
+	}
+	totalScores := map[string]int64{}
+	for _, nodeScores := range scores {
+		for _, nodeScore := range nodeScores {
+			totalScores[nodeScore.Name] += nodeScore.Score
+		}
+	}
+	host := feasibleNodes[0].Name
+	for _, node := range feasibleNodes[1:] {
+		if totalScores[node.Name] > totalScores[host] {
+			host = node.Name
+		}
+	}
+	return host, nil
+}
+
+var (
+	_ framework.SharedLister   = &simulationSnapshot{}
+	_ framework.NodeInfoLister = &simulationSnapshot{}
+)
+
+// simulationSnapshot is a private snapshot of the nodes built from the informers for a simulation,
+// so the simulated Pods can be assumed on it without touching the cache and the snapshot of the scheduler.
+type simulationSnapshot struct {
+	nodeInfoList []*framework.NodeInfo
+	nodeInfoMap  map[string]*framework.NodeInfo
+}
+
+func (s *simulationSnapshot) clone() *simulationSnapshot {
+	snapshot := &simulationSnapshot{
+		nodeInfoList: make([]*framework.NodeInfo, 0, len(s.nodeInfoList)),
+		nodeInfoMap:  make(map[string]*framework.NodeInfo, len(s.nodeInfoMap)),
+	}
+	for _, nodeInfo := range s.nodeInfoList {
+		nodeInfo = nodeInfo.Clone()
+		snapshot.nodeInfoList = append(snapshot.nodeInfoList, nodeInfo)
+		snapshot.nodeInfoMap[nodeInfo.Node().Name] = nodeInfo
+	}
+	return snapshot
+}
+
+func (s *simulationSnapshot) NodeInfos() framework.NodeInfoLister {
+	return s
+}
+
+func (s *simulationSnapshot) List() ([]*framework.NodeInfo, error) {
+	return s.nodeInfoList, nil
+}
+
+func (s *simulationSnapshot) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	var nodeInfos []*framework.NodeInfo
+	for _, nodeInfo := range s.nodeInfoList {
+		if len(nodeInfo.PodsWithAffinity) > 0 {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+	return nodeInfos, nil
+}
+
+func (s *simulationSnapshot) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	var nodeInfos []*framework.NodeInfo
+	for _, nodeInfo := range s.nodeInfoList {
+		if len(nodeInfo.PodsWithRequiredAntiAffinity) > 0 {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+	return nodeInfos, nil
+}
+
+func (s *simulationSnapshot) Get(nodeName string) (*framework.NodeInfo, error) {
+	nodeInfo := s.nodeInfoMap[nodeName]
+	if nodeInfo == nil || nodeInfo.Node() == nil {
+		return nil, fmt.Errorf("nodeinfo not found for node name %q", nodeName)
+	}
+	return nodeInfo, nil
+}
+
+func newSimulationSnapshot(informerFactory informers.SharedInformerFactory) (*simulationSnapshot, error) {
+	nodes, err := informerFactory.Core().V1().Nodes().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	pods, err := informerFactory.Core().V1().Pods().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &simulationSnapshot{
+		nodeInfoMap: map[string]*framework.NodeInfo{},
+	}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		snapshot.nodeInfoMap[node.Name] = nodeInfo
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if nodeInfo := snapshot.nodeInfoMap[pod.Spec.NodeName]; nodeInfo != nil {
+			nodeInfo.AddPod(pod)
+		}
+	}
+	snapshot.nodeInfoList = make([]*framework.NodeInfo, 0, len(snapshot.nodeInfoMap))
+	for _, nodeInfo := range snapshot.nodeInfoMap {
+		snapshot.nodeInfoList = append(snapshot.nodeInfoList, nodeInfo)
+	}
+	sort.Slice(snapshot.nodeInfoList, func(i, j int) bool {
+		return snapshot.nodeInfoList[i].Node().Name < snapshot.nodeInfoList[j].Node().Name
+	})
+	return snapshot, nil
+}
+
+func buildNodeSimulationResults(recorder *simulationRecorder) []*NodeSimulationResult {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	nodes := make([]*NodeSimulationResult, 0, len(recorder.filterStatuses))
+	nodeResults := map[string]*NodeSimulationResult{}
+	for nodeName, status := range recorder.filterStatuses {
+		nodeResult := &NodeSimulationResult{
+			Name:     nodeName,
+			Feasible: status.IsSuccess(),
+		}
+		if !status.IsSuccess() {
+			nodeResult.FailedPlugin = status.FailedPlugin()
+			nodeResult.Reasons = status.Reasons()
+		}
+		nodes = append(nodes, nodeResult)
+		nodeResults[nodeName] = nodeResult
+	}
+	for pluginName, nodeScores := range recorder.scores {
+		for _, nodeScore := range nodeScores {
+			nodeResult := nodeResults[nodeScore.Name]
+			if nodeResult == nil {
+				continue
+			}
+			if nodeResult.Scores == nil {
+				nodeResult.Scores = map[string]int64{}
+			}
+			nodeResult.Scores[pluginName] = nodeScore.Score
+			nodeResult.TotalScore += nodeScore.Score
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Feasible != nodes[j].Feasible {
+			return nodes[i].Feasible
+		}
+		if nodes[i].TotalScore != nodes[j].TotalScore {
+			return nodes[i].TotalScore > nodes[j].TotalScore
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
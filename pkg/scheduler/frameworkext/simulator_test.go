@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/services"
+)
+
+var (
+	_ framework.FilterPlugin   = &fakeSimulationPlugin{}
+	_ framework.ScorePlugin    = &fakeSimulationPlugin{}
+	_ SimulateAllocationPlugin = &fakeSimulationPlugin{}
+)
+
+// fakeSimulationPlugin allows one Pod per node at most, and rejects the unschedulable nodes.
+type fakeSimulationPlugin struct {
+	unschedulableNodes map[string]bool
+	scores             map[string]int64
+}
+
+func (f *fakeSimulationPlugin) Name() string { return "fakeSimulation" }
+
+func (f *fakeSimulationPlugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if f.unschedulableNodes[nodeInfo.Node().Name] {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "node(s) unschedulable")
+	}
+	if len(nodeInfo.Pods) > 0 {
+		return framework.NewStatus(framework.Unschedulable, "node(s) full")
+	}
+	return nil
+}
+
+func (f *fakeSimulationPlugin) Score(ctx context.Context, state *framework.CycleState, p *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	return f.scores[nodeName], nil
+}
+
+func (f *fakeSimulationPlugin) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+func (f *fakeSimulationPlugin) SimulateAllocation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (interface{}, *framework.Status) {
+	return "allocated-on-" + nodeName, nil
+}
+
+type fakePodNominator struct{}
+
+func (n *fakePodNominator) AddNominatedPod(pi *framework.PodInfo, nominatingInfo *framework.NominatingInfo) {
+}
+
+func (n *fakePodNominator) DeleteNominatedPodIfExists(pod *corev1.Pod) {}
+
+func (n *fakePodNominator) UpdateNominatedPod(oldPod *corev1.Pod, newPodInfo *framework.PodInfo) {}
+
+func (n *fakePodNominator) NominatedPodsForNode(nodeName string) []*framework.PodInfo { return nil }
+
+func TestSchedulingSimulator(t *testing.T) {
+	fakeScheduler := NewFakeScheduler()
+	pl := &fakeSimulationPlugin{
+		unschedulableNodes: map[string]bool{"node-3": true},
+		scores:             map[string]int64{"node-1": 10, "node-2": 20},
+	}
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	for _, name := range []string{"node-1", "node-2", "node-3"} {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		assert.NoError(t, informerFactory.Core().V1().Nodes().Informer().GetStore().Add(node))
+	}
+	completedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "completed-pod"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	assert.NoError(t, informerFactory.Core().V1().Pods().Informer().GetStore().Add(completedPod))
+	extenderFactory, _ := NewFrameworkExtenderFactory()
+	proxy := PluginFactoryProxy(extenderFactory, func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+		return pl, nil
+	})
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		schedulertesting.RegisterFilterPlugin(pl.Name(), proxy),
+		schedulertesting.RegisterScorePlugin(pl.Name(), proxy, 1),
+	}
+	fh, err := schedulertesting.NewFramework(registeredPlugins, corev1.DefaultSchedulerName,
+		frameworkruntime.WithPodNominator(&fakePodNominator{}),
+		frameworkruntime.WithInformerFactory(informerFactory))
+	assert.NoError(t, err)
+	frameworkExtender := extenderFactory.NewFrameworkExtender(fh)
+	frameworkExtender.SetConfiguredPlugins(fh.ListPlugins())
+
+	extenderFactory.InitScheduler(fakeScheduler)
+
+	engine := services.NewEngine(gin.New())
+	engine.RegisterService(NewSchedulingSimulator(extenderFactory))
+
+	request := SimulationRequest{
+		Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}},
+		Pods: []*corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "pod-2"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "pod-3"}},
+		},
+	}
+	body, err := json.Marshal(request)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/v1/simulate", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := &SimulationResponse{}
+	assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(response))
+	expectedResponse := &SimulationResponse{
+		Schedulable: false,
+		Pods: []*PodSimulationResult{
+			{
+				Pod:           "default/pod-1",
+				SuggestedNode: "node-2",
+				Nodes: []*NodeSimulationResult{
+					{Name: "node-2", Feasible: true, Scores: map[string]int64{"fakeSimulation": 20}, TotalScore: 20},
+					{Name: "node-1", Feasible: true, Scores: map[string]int64{"fakeSimulation": 10}, TotalScore: 10},
+					{Name: "node-3", FailedPlugin: "fakeSimulation", Reasons: []string{"node(s) unschedulable"}},
+				},
+				Allocations: map[string]interface{}{"fakeSimulation": "allocated-on-node-2"},
+			},
+			{
+				Pod:           "default/pod-2",
+				SuggestedNode: "node-1",
+				Nodes: []*NodeSimulationResult{
+					{Name: "node-1", Feasible: true},
+					{Name: "node-2", FailedPlugin: "fakeSimulation", Reasons: []string{"node(s) full"}},
+					{Name: "node-3", FailedPlugin: "fakeSimulation", Reasons: []string{"node(s) unschedulable"}},
+				},
+				Allocations: map[string]interface{}{"fakeSimulation": "allocated-on-node-1"},
+			},
+			{
+				Pod:     "default/pod-3",
+				Message: "0/3 nodes are available: 1 node(s) unschedulable, 2 node(s) full.",
+				Nodes: []*NodeSimulationResult{
+					{Name: "node-1", FailedPlugin: "fakeSimulation", Reasons: []string{"node(s) full"}},
+					{Name: "node-2", FailedPlugin: "fakeSimulation", Reasons: []string{"node(s) full"}},
+					{Name: "node-3", FailedPlugin: "fakeSimulation", Reasons: []string{"node(s) unschedulable"}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expectedResponse, response)
+	assert.Empty(t, fakeScheduler.AssumedPod, "simulated pods should not be assumed in the scheduler cache")
+
+	w = httptest.NewRecorder()
+	body = []byte(`{"pod": {"metadata": {"name": "pod-1"}, "spec": {"schedulerName": "unknown-scheduler"}}}`)
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/v1/simulate", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+var _ ReservationNominator = &fakeSimulationNominator{}
+
+type fakeSimulationNominator struct {
+	nominatedReservations map[string]*ReservationInfo
+}
+
+func (n *fakeSimulationNominator) Name() string { return "fakeSimulationNominator" }
+
+func (n *fakeSimulationNominator) NominateReservation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (*ReservationInfo, *framework.Status) {
+	return nil, nil
+}
+
+func (n *fakeSimulationNominator) AddNominatedReservation(pod *corev1.Pod, nodeName string, rInfo *ReservationInfo) {
+	n.nominatedReservations[nodeName] = rInfo
+}
+
+func (n *fakeSimulationNominator) RemoveNominatedReservations(pod *corev1.Pod) {}
+
+func (n *fakeSimulationNominator) GetNominatedReservation(pod *corev1.Pod, nodeName string) *ReservationInfo {
+	return n.nominatedReservations[nodeName]
+}
+
+func TestSimulationScopedStates(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	assert.NoError(t, informerFactory.Core().V1().Nodes().Informer().GetStore().Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}))
+	nominator := &fakeSimulationNominator{nominatedReservations: map[string]*ReservationInfo{}}
+	extenderFactory, _ := NewFrameworkExtenderFactory(WithReservationNominator(nominator))
+	fh, err := schedulertesting.NewFramework([]schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}, corev1.DefaultSchedulerName,
+		frameworkruntime.WithPodNominator(&fakePodNominator{}),
+		frameworkruntime.WithInformerFactory(informerFactory))
+	assert.NoError(t, err)
+	frameworkExtender := extenderFactory.NewFrameworkExtender(fh)
+
+	snapshot, err := newSimulationSnapshot(informerFactory)
+	assert.NoError(t, err)
+	simulation := newSimulationState(snapshot)
+	newCycleState := func() *framework.CycleState {
+		cycleState := framework.NewCycleState()
+		cycleState.Write(schedulingSimulationStateKey, newSimulationRecorder(simulation, simulation.snapshot.clone()))
+		return cycleState
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "pod-1"}}
+	rInfo := &ReservationInfo{}
+	cycleState := newCycleState()
+	nodeInfo, err := GetSnapshotSharedLister(frameworkExtender, cycleState).NodeInfos().Get("node-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "node-1", nodeInfo.Node().Name)
+	AddNominatedReservation(frameworkExtender, cycleState, pod, "node-1", rInfo)
+	assert.Equal(t, rInfo, GetNominatedReservation(frameworkExtender, newCycleState(), pod, "node-1"))
+	assert.Empty(t, nominator.nominatedReservations, "simulated nominations should not reach the nominator of the scheduler")
+	assert.Nil(t, GetNominatedReservation(frameworkExtender, framework.NewCycleState(), pod, "node-1"))
+
+	created := 0
+	newFn := func() interface{} {
+		created++
+		return &created
+	}
+	assert.Nil(t, GetOrCreateSimulationState(framework.NewCycleState(), "fake", newFn))
+	first := GetOrCreateSimulationState(cycleState, "fake", newFn)
+	second := GetOrCreateSimulationState(newCycleState(), "fake", newFn)
+	assert.Same(t, first, second, "the pods simulated together should share the state")
+	assert.Equal(t, 1, created)
+}
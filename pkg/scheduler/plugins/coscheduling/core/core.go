@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	listerv1 "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -62,7 +63,7 @@ type Manager interface {
 	PreFilter(context.Context, *corev1.Pod) error
 	Permit(context.Context, *corev1.Pod) (time.Duration, Status)
 	PostBind(context.Context, *corev1.Pod, string)
-	PostFilter(context.Context, *corev1.Pod, framework.Handle, string, framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status)
+	PostFilter(context.Context, *framework.CycleState, *corev1.Pod, framework.Handle, string, framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status)
 	GetCreatTime(*framework.QueuedPodInfo) time.Time
 	GetGroupId(*corev1.Pod) (string, error)
 	GetAllPodsFromGang(string) []*corev1.Pod
@@ -84,6 +85,10 @@ type PodGroupManager struct {
 	pgLister pglister.PodGroupLister
 	// podLister is pod lister
 	podLister listerv1.PodLister
+	// pdbLister is pdb lister, only set when the gang preemption is enabled
+	pdbLister policylisters.PodDisruptionBudgetLister
+	// quotaLister is elastic quota lister, only set when the gang preemption is enabled
+	quotaLister pglister.ElasticQuotaLister
 	// reserveResourcePercentage is the reserved resource for the max finished group, range (0,100]
 	reserveResourcePercentage int32
 	// cache stores gang info
@@ -109,6 +114,10 @@ func NewPodGroupManager(
 		podLister: podInformer.Lister(),
 		cache:     gangCache,
 	}
+	if args != nil && args.EnablePreemption {
+		pgMgr.pdbLister = sharedInformerFactory.Policy().V1().PodDisruptionBudgets().Lister()
+		pgMgr.quotaLister = pgSharedInformerFactory.Scheduling().V1alpha1().ElasticQuotas().Lister()
+	}
 
 	podGroupEventHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc:    gangCache.onPodGroupAdd,
@@ -273,7 +282,9 @@ func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) er
 // PostFilter
 // i. If strict-mode, we will set scheduleCycleValid to false and release all assumed pods.
 // ii. If non-strict mode, we will do nothing.
-func (pgMgr *PodGroupManager) PostFilter(ctx context.Context, pod *corev1.Pod, handle framework.Handle, pluginName string, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+// If preemption is enabled, we will try to preempt lower priority pods for all pending members of the gang group
+// at once before rejecting the gang group.
+func (pgMgr *PodGroupManager) PostFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, handle framework.Handle, pluginName string, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
 	if !util.IsPodNeedGang(pod) {
 		return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable)
 	}
@@ -287,6 +298,15 @@ func (pgMgr *PodGroupManager) PostFilter(ctx context.Context, pod *corev1.Pod, h
 		return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable)
 	}
 
+	if pgMgr.args != nil && pgMgr.args.EnablePreemption {
+		result, status := pgMgr.preemptGangGroup(ctx, state, pod, gang, handle, pluginName, filteredNodeStatusMap)
+		if status.IsSuccess() {
+			pgMgr.ActivateSiblings(pod, state)
+			return result, status
+		}
+		klog.V(4).InfoS("Gang group failed to preempt", "gang", gang.Name, "pod", klog.KObj(pod), "reason", status.Message())
+	}
+
 	if gang.getGangMode() == extension.GangModeStrict {
 		nodeInfos, _ := handle.SnapshotSharedLister().NodeInfos().List()
 		fitErr := &framework.FitError{
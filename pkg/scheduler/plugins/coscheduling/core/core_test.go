@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/util/retry"
@@ -36,10 +37,12 @@ import (
 	pginformer "sigs.k8s.io/scheduler-plugins/pkg/generated/informers/externalversions/scheduling/v1alpha1"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
 	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
 type Mgr struct {
@@ -601,3 +604,54 @@ func TestPostBind(t *testing.T) {
 	}
 
 }
+
+func TestReservationGroup(t *testing.T) {
+	koordClient := koordfake.NewSimpleClientset()
+	newReservation := func(name string) *schedulingv1alpha1.Reservation {
+		return &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				UID:  types.UID(name),
+				Annotations: map[string]string{
+					extension.AnnotationGangName:   "gang-r",
+					extension.AnnotationGangMinNum: "3",
+				},
+			},
+			Spec: schedulingv1alpha1.ReservationSpec{
+				Template: &corev1.PodTemplateSpec{},
+			},
+		}
+	}
+	reservations := []*schedulingv1alpha1.Reservation{newReservation("r-1"), newReservation("r-2")}
+	for _, r := range reservations {
+		_, err := koordClient.SchedulingV1alpha1().Reservations().Create(context.TODO(), r, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	pgClient := fakepgclientset.NewSimpleClientset()
+	pgInformerFactory := pgformers.NewSharedInformerFactory(pgClient, 0)
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	koordInformerFactory := koordinformers.NewSharedInformerFactory(koordClient, 0)
+	args := &config.CoschedulingArgs{DefaultTimeout: metav1.Duration{Duration: 300 * time.Second}}
+	pgMgr := NewPodGroupManager(args, pgClient, pgInformerFactory, informerFactory, koordInformerFactory)
+
+	// the reserve pods of the reservation group make up a gang
+	gang := pgMgr.cache.getGangFromCacheByGangId("default/gang-r", false)
+	assert.NotNil(t, gang)
+	assert.Equal(t, 3, gang.getGangMinNum())
+	assert.Equal(t, 2, gang.getChildrenNum())
+
+	// the reservations are scheduled all-or-nothing
+	reservePod := reservationutil.NewReservePod(reservations[0])
+	err := pgMgr.PreFilter(context.TODO(), reservePod)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "gang child pod not collect enough")
+
+	r3 := newReservation("r-3")
+	_, err = koordClient.SchedulingV1alpha1().Reservations().Create(context.TODO(), r3, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return gang.getChildrenNum() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, pgMgr.PreFilter(context.TODO(), reservePod))
+}
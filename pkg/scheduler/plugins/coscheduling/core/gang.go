@@ -446,6 +446,48 @@ func (gang *Gang) getChildrenFromGang() (children []*v1.Pod) {
 	return
 }
 
+// getPendingChildren returns the children which are neither assumed nor bound.
+func (gang *Gang) getPendingChildren() []*v1.Pod {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+	pending := make([]*v1.Pod, 0)
+	for podId, pod := range gang.Children {
+		if _, ok := gang.WaitingForBindChildren[podId]; ok {
+			continue
+		}
+		if _, ok := gang.BoundChildren[podId]; ok {
+			continue
+		}
+		if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		pending = append(pending, pod)
+	}
+	return pending
+}
+
+// getPendingChildrenNumToSatisfy returns the number of pending children which should be assumed additionally to let
+// the gang pass the Permit stage.
+func (gang *Gang) getPendingChildrenNumToSatisfy() int {
+	gang.lock.Lock()
+	defer gang.lock.Unlock()
+
+	satisfiedNum := len(gang.WaitingForBindChildren)
+	switch gang.GangMatchPolicy {
+	case extension.GangMatchPolicyOnlyWaiting:
+	case extension.GangMatchPolicyWaitingAndRunning:
+		satisfiedNum += len(gang.BoundChildren)
+	default:
+		if gang.OnceResourceSatisfied {
+			return 0
+		}
+	}
+	if satisfiedNum >= gang.MinRequiredNumber {
+		return 0
+	}
+	return gang.MinRequiredNumber - satisfiedNum
+}
+
 func (gang *Gang) isGangFromAnnotation() bool {
 	gang.lock.Lock()
 	defer gang.lock.Unlock()
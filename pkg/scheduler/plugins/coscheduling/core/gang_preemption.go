@@ -0,0 +1,557 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8sfeature "k8s.io/apiserver/pkg/util/feature"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+	pglister "sigs.k8s.io/scheduler-plugins/pkg/generated/listers/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+)
+
+// gangPreemptionSimulationKey marks the CycleState of the gang members in the preemption simulation.
+const gangPreemptionSimulationKey = "koordinator.sh/gang-preemption-simulation"
+
+type gangPreemptionSimulationState struct{}
+
+func (s *gangPreemptionSimulationState) Clone() framework.StateData {
+	return s
+}
+
+// IsGangPreemptionSimulation returns whether the CycleState is created for the gang preemption simulation. The
+// PreFilter of the Coscheduling should skip the schedule cycle checks for these simulated cycles.
+func IsGangPreemptionSimulation(state *framework.CycleState) bool {
+	if state == nil {
+		return false
+	}
+	_, err := state.Read(gangPreemptionSimulationKey)
+	return err == nil
+}
+
+type preFilterPluginsRunner interface {
+	RunPreFilterPlugins(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) (*framework.PreFilterResult, *framework.Status)
+}
+
+// gangPreemptionSimulator simulates the scheduling of all the pending members of a gang group on the cloned nodes.
+// The members try to fit without preemption first, otherwise the lower priority pods on the node are selected as
+// the victims. The simulation succeeds only if all the members can be placed.
+type gangPreemptionSimulator struct {
+	handle      framework.Handle
+	pdbs        []*policy.PodDisruptionBudget
+	quotaLister pglister.ElasticQuotaLister
+	gangGroup   sets.String
+	nodeInfos   []*framework.NodeInfo
+	// namespaceQuotas caches the elastic quota which the namespace is bound to
+	namespaceQuotas map[string]string
+
+	// changes are the victims removed and the members added in order, which should be replayed on the CycleState
+	// of the following members.
+	changes     []*simulatedChange
+	nominations []*gangNomination
+	victims     []*gangNomination
+}
+
+type simulatedChange struct {
+	podInfo  *framework.PodInfo
+	nodeInfo *framework.NodeInfo
+	removed  bool
+}
+
+// gangNomination is a pod with the node where it is nominated to or preempted from.
+type gangNomination struct {
+	pod      *corev1.Pod
+	nodeName string
+}
+
+// preemptGangGroup tries to make all the pending members of the gang group schedulable by preempting the lower
+// priority pods. The victims are evicted only if all the members can be placed in the simulation, and then the
+// members are nominated to the nodes.
+func (pgMgr *PodGroupManager) preemptGangGroup(ctx context.Context, state *framework.CycleState, pod *corev1.Pod,
+	gang *Gang, handle framework.Handle, pluginName string, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	if eligible, message := podEligibleToPreemptForGang(pod, handle); !eligible {
+		return nil, framework.NewStatus(framework.Unschedulable, message)
+	}
+	members, err := pgMgr.getPendingMembersToPreempt(gang, pod)
+	if err != nil {
+		return nil, framework.NewStatus(framework.Unschedulable, err.Error())
+	}
+
+	simulator, err := pgMgr.newGangPreemptionSimulator(gang, handle)
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	if status := simulator.simulate(ctx, state, members, filteredNodeStatusMap); !status.IsSuccess() {
+		return nil, status
+	}
+	if status := simulator.execute(handle, pluginName, gang, pod); !status.IsSuccess() {
+		return nil, status
+	}
+
+	klog.V(1).InfoS("Gang group preempted lower priority pods", "gang", gang.Name, "pod", klog.KObj(pod),
+		"members", len(simulator.nominations), "victims", len(simulator.victims))
+	return framework.NewPostFilterResultWithNominatedNode(simulator.nominations[0].nodeName), framework.NewStatus(framework.Success)
+}
+
+// podEligibleToPreemptForGang returns false if the pod should not preempt, e.g. the victims preempted by the gang
+// previously are still terminating on the nominated node.
+func podEligibleToPreemptForGang(pod *corev1.Pod, handle framework.Handle) (bool, string) {
+	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == corev1.PreemptNever {
+		return false, "not eligible due to preemptionPolicy=Never."
+	}
+	nominatedNodeName := pod.Status.NominatedNodeName
+	if len(nominatedNodeName) <= 0 {
+		return true, ""
+	}
+	nodeInfo, err := handle.SnapshotSharedLister().NodeInfos().Get(nominatedNodeName)
+	if err != nil || nodeInfo == nil {
+		return true, ""
+	}
+	podPriority := corev1helpers.PodPriority(pod)
+	for _, p := range nodeInfo.Pods {
+		if p.Pod.DeletionTimestamp != nil && corev1helpers.PodPriority(p.Pod) < podPriority {
+			return false, "not eligible due to a terminating pod on the nominated node."
+		}
+	}
+	return true, ""
+}
+
+// getPendingMembersToPreempt returns the pending members which are required to satisfy each gang of the gang group,
+// the preemptor pod is always the first one.
+func (pgMgr *PodGroupManager) getPendingMembersToPreempt(gang *Gang, pod *corev1.Pod) ([]*corev1.Pod, error) {
+	members := []*corev1.Pod{pod}
+	for _, gangId := range gang.getGangGroup() {
+		groupGang := pgMgr.cache.getGangFromCacheByGangId(gangId, false)
+		if groupGang == nil {
+			return nil, fmt.Errorf("gang %v of the gang group is not found", gangId)
+		}
+		requiredNum := groupGang.getPendingChildrenNumToSatisfy()
+		if requiredNum <= 0 {
+			continue
+		}
+		pending := groupGang.getPendingChildren()
+		if len(pending) < requiredNum {
+			return nil, fmt.Errorf("gang %v has %d pending children, less than required %d", gangId, len(pending), requiredNum)
+		}
+		sort.Slice(pending, func(i, j int) bool {
+			if pending[i].UID == pod.UID || pending[j].UID == pod.UID {
+				return pending[i].UID == pod.UID
+			}
+			return util.GetId(pending[i].Namespace, pending[i].Name) < util.GetId(pending[j].Namespace, pending[j].Name)
+		})
+		for _, p := range pending[:requiredNum] {
+			if p.UID != pod.UID {
+				members = append(members, p)
+			}
+		}
+	}
+	return members, nil
+}
+
+func (pgMgr *PodGroupManager) newGangPreemptionSimulator(gang *Gang, handle framework.Handle) (*gangPreemptionSimulator, error) {
+	allNodes, err := handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	nodeInfos := make([]*framework.NodeInfo, 0, len(allNodes))
+	for _, nodeInfo := range allNodes {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		nodeInfos = append(nodeInfos, nodeInfo.Clone())
+	}
+	sort.Slice(nodeInfos, func(i, j int) bool {
+		return nodeInfos[i].Node().Name < nodeInfos[j].Node().Name
+	})
+
+	var pdbs []*policy.PodDisruptionBudget
+	if pgMgr.pdbLister != nil {
+		pdbs, err = pgMgr.pdbLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &gangPreemptionSimulator{
+		handle:          handle,
+		pdbs:            pdbs,
+		quotaLister:     pgMgr.quotaLister,
+		gangGroup:       sets.NewString(gang.getGangGroup()...),
+		nodeInfos:       nodeInfos,
+		namespaceQuotas: map[string]string{},
+	}, nil
+}
+
+func (s *gangPreemptionSimulator) simulate(ctx context.Context, preemptorState *framework.CycleState, members []*corev1.Pod,
+	filteredNodeStatusMap framework.NodeToStatusMap) *framework.Status {
+	for i, member := range members {
+		var memberState *framework.CycleState
+		var allowedNodes sets.String
+		if i == 0 {
+			memberState = preemptorState.Clone()
+		} else {
+			var status *framework.Status
+			memberState, allowedNodes, status = s.prepareMemberCycleState(ctx, member)
+			if !status.IsSuccess() {
+				return framework.NewStatus(framework.Unschedulable,
+					fmt.Sprintf("gang member %s fails to run PreFilter, %s", klog.KObj(member), status.Message()))
+			}
+		}
+
+		if nodeInfo := s.findFitNode(ctx, memberState, member, allowedNodes); nodeInfo != nil {
+			s.placeMember(member, nodeInfo)
+			continue
+		}
+
+		var unresolvableNodes framework.NodeToStatusMap
+		if i == 0 {
+			unresolvableNodes = filteredNodeStatusMap
+		}
+		nodeInfo, victims := s.selectNodeAndVictims(ctx, memberState, member, allowedNodes, unresolvableNodes)
+		if nodeInfo == nil {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("gang member %s is unschedulable even if preempting lower priority pods", klog.KObj(member)))
+		}
+		for _, victim := range victims {
+			podInfo := framework.NewPodInfo(victim)
+			if err := nodeInfo.RemovePod(victim); err != nil {
+				return framework.AsStatus(err)
+			}
+			s.changes = append(s.changes, &simulatedChange{podInfo: podInfo, nodeInfo: nodeInfo, removed: true})
+			s.victims = append(s.victims, &gangNomination{pod: victim, nodeName: nodeInfo.Node().Name})
+		}
+		s.placeMember(member, nodeInfo)
+	}
+	return nil
+}
+
+// prepareMemberCycleState runs the PreFilter for the member and replays the simulated changes on its CycleState.
+func (s *gangPreemptionSimulator) prepareMemberCycleState(ctx context.Context, member *corev1.Pod) (*framework.CycleState, sets.String, *framework.Status) {
+	runner, ok := s.handle.(preFilterPluginsRunner)
+	if !ok {
+		return nil, nil, framework.NewStatus(framework.Error, "framework handle cannot run PreFilter plugins")
+	}
+	state := framework.NewCycleState()
+	state.Write(gangPreemptionSimulationKey, &gangPreemptionSimulationState{})
+	result, status := runner.RunPreFilterPlugins(ctx, state, member)
+	if !status.IsSuccess() {
+		return nil, nil, status
+	}
+	var allowedNodes sets.String
+	if !result.AllNodes() {
+		allowedNodes = result.NodeNames
+	}
+	for _, change := range s.changes {
+		if change.removed {
+			status = s.handle.RunPreFilterExtensionRemovePod(ctx, state, member, change.podInfo, change.nodeInfo)
+		} else {
+			status = s.handle.RunPreFilterExtensionAddPod(ctx, state, member, change.podInfo, change.nodeInfo)
+		}
+		if !status.IsSuccess() {
+			return nil, nil, status
+		}
+	}
+	return state, allowedNodes, nil
+}
+
+func (s *gangPreemptionSimulator) findFitNode(ctx context.Context, state *framework.CycleState, member *corev1.Pod, allowedNodes sets.String) *framework.NodeInfo {
+	for _, nodeInfo := range s.nodeInfos {
+		if allowedNodes != nil && !allowedNodes.Has(nodeInfo.Node().Name) {
+			continue
+		}
+		if status := s.handle.RunFilterPluginsWithNominatedPods(ctx, state, member, nodeInfo); status.IsSuccess() {
+			return nodeInfo
+		}
+	}
+	return nil
+}
+
+// selectNodeAndVictims picks the node with the fewest PDB violations, then the lowest highest-priority victim, then
+// the fewest victims.
+func (s *gangPreemptionSimulator) selectNodeAndVictims(ctx context.Context, state *framework.CycleState, member *corev1.Pod,
+	allowedNodes sets.String, unresolvableNodes framework.NodeToStatusMap) (*framework.NodeInfo, []*corev1.Pod) {
+	var bestNode *framework.NodeInfo
+	var bestVictims []*corev1.Pod
+	bestNumViolating := 0
+	for _, nodeInfo := range s.nodeInfos {
+		nodeName := nodeInfo.Node().Name
+		if allowedNodes != nil && !allowedNodes.Has(nodeName) {
+			continue
+		}
+		if unresolvableNodes[nodeName].Code() == framework.UnschedulableAndUnresolvable {
+			continue
+		}
+		victims, numViolating, status := s.selectVictimsOnNode(ctx, state.Clone(), member, nodeInfo.Clone())
+		if !status.IsSuccess() {
+			klog.V(5).InfoS("Gang member cannot preempt on node", "pod", klog.KObj(member), "node", nodeName, "reason", status.Message())
+			continue
+		}
+		if bestNode == nil || isBetterVictims(victims, numViolating, bestVictims, bestNumViolating) {
+			bestNode, bestVictims, bestNumViolating = nodeInfo, victims, numViolating
+		}
+	}
+	return bestNode, bestVictims
+}
+
+func isBetterVictims(victims []*corev1.Pod, numViolating int, otherVictims []*corev1.Pod, otherNumViolating int) bool {
+	if numViolating != otherNumViolating {
+		return numViolating < otherNumViolating
+	}
+	// the victims are sorted by the importance, so the first one has the highest priority
+	highestPriority, otherHighestPriority := corev1helpers.PodPriority(victims[0]), corev1helpers.PodPriority(otherVictims[0])
+	if highestPriority != otherHighestPriority {
+		return highestPriority < otherHighestPriority
+	}
+	return len(victims) < len(otherVictims)
+}
+
+// selectVictimsOnNode finds the minimal set of the lower priority pods on the node to preempt like the default
+// preemption, it tries to reprieve the PDB violating victims first, then the non-violating ones.
+func (s *gangPreemptionSimulator) selectVictimsOnNode(ctx context.Context, state *framework.CycleState, member *corev1.Pod,
+	nodeInfo *framework.NodeInfo) ([]*corev1.Pod, int, *framework.Status) {
+	removePod := func(rpi *framework.PodInfo) error {
+		if err := nodeInfo.RemovePod(rpi.Pod); err != nil {
+			return err
+		}
+		return s.handle.RunPreFilterExtensionRemovePod(ctx, state, member, rpi, nodeInfo).AsError()
+	}
+	addPod := func(api *framework.PodInfo) error {
+		nodeInfo.AddPodInfo(api)
+		return s.handle.RunPreFilterExtensionAddPod(ctx, state, member, api, nodeInfo).AsError()
+	}
+
+	var potentialVictims []*framework.PodInfo
+	for _, pi := range nodeInfo.Pods {
+		if s.canPreempt(member, pi.Pod) {
+			potentialVictims = append(potentialVictims, pi)
+		}
+	}
+	if len(potentialVictims) == 0 {
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, "no victims found")
+	}
+	for _, pi := range potentialVictims {
+		if err := removePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+	if status := s.handle.RunFilterPluginsWithNominatedPods(ctx, state, member, nodeInfo); !status.IsSuccess() {
+		return nil, 0, status
+	}
+
+	var victims []*corev1.Pod
+	numViolatingVictim := 0
+	sort.Slice(potentialVictims, func(i, j int) bool {
+		return schedutil.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod)
+	})
+	violatingVictims, nonViolatingVictims := filterPodsWithPDBViolation(potentialVictims, s.pdbs)
+	reprievePod := func(pi *framework.PodInfo) (bool, error) {
+		if err := addPod(pi); err != nil {
+			return false, err
+		}
+		fits := s.handle.RunFilterPluginsWithNominatedPods(ctx, state, member, nodeInfo).IsSuccess()
+		if !fits {
+			if err := removePod(pi); err != nil {
+				return false, err
+			}
+			victims = append(victims, pi.Pod)
+		}
+		return fits, nil
+	}
+	for _, pi := range violatingVictims {
+		if fits, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		} else if !fits {
+			numViolatingVictim++
+		}
+	}
+	for _, pi := range nonViolatingVictims {
+		if _, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+	if len(victims) == 0 {
+		// should not happen since the member does not fit without preemption
+		return nil, 0, framework.NewStatus(framework.Unschedulable, "no victims selected")
+	}
+	return victims, numViolatingVictim, nil
+}
+
+// canPreempt returns whether the victim can be preempted by the gang member. The victim should have a lower
+// priority, not belong to the same gang group, and be in the same elastic quota with the member so that the
+// gang does not take the resources guaranteed by other quotas.
+func (s *gangPreemptionSimulator) canPreempt(member, victim *corev1.Pod) bool {
+	if extension.IsPodNonPreemptible(victim) || victim.DeletionTimestamp != nil {
+		return false
+	}
+	if corev1helpers.PodPriority(victim) >= corev1helpers.PodPriority(member) {
+		return false
+	}
+	if victimGangName := util.GetGangNameByPod(victim); victimGangName != "" &&
+		s.gangGroup.Has(util.GetId(victim.Namespace, victimGangName)) {
+		return false
+	}
+	return s.getPodQuotaBoundary(member) == s.getPodQuotaBoundary(victim)
+}
+
+// getPodQuotaBoundary returns the elastic quota of the pod in the same way as the ElasticQuota plugin. The pods
+// without the quota label belong to the quota bound to their namespace, otherwise the default quota.
+func (s *gangPreemptionSimulator) getPodQuotaBoundary(pod *corev1.Pod) string {
+	if quotaName := extension.GetQuotaName(pod); quotaName != "" {
+		return quotaName
+	}
+	if k8sfeature.DefaultFeatureGate.Enabled(features.DisableDefaultQuota) {
+		return ""
+	}
+	if quotaName, ok := s.namespaceQuotas[pod.Namespace]; ok {
+		return quotaName
+	}
+	quotaName := s.getNamespaceQuota(pod.Namespace)
+	s.namespaceQuotas[pod.Namespace] = quotaName
+	return quotaName
+}
+
+// getNamespaceQuota returns the quota named as the namespace, or the quota which declares the namespace in the
+// annotation, otherwise the default quota.
+func (s *gangPreemptionSimulator) getNamespaceQuota(namespace string) string {
+	if s.quotaLister == nil {
+		return extension.DefaultQuotaName
+	}
+	if eq, err := s.quotaLister.ElasticQuotas(namespace).Get(namespace); err == nil && eq != nil {
+		return eq.Name
+	} else if err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to get ElasticQuota", "namespace", namespace)
+	}
+	quotas, err := s.quotaLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list ElasticQuotas")
+		return extension.DefaultQuotaName
+	}
+	// keep the result stable since there may be multiple quotas declaring the namespace
+	sort.Slice(quotas, func(i, j int) bool {
+		if quotas[i].Namespace != quotas[j].Namespace {
+			return quotas[i].Namespace < quotas[j].Namespace
+		}
+		return quotas[i].Name < quotas[j].Name
+	})
+	for _, quota := range quotas {
+		for _, ns := range extension.GetAnnotationQuotaNamespaces(quota) {
+			if ns == namespace {
+				return quota.Name
+			}
+		}
+	}
+	return extension.DefaultQuotaName
+}
+
+func (s *gangPreemptionSimulator) placeMember(member *corev1.Pod, nodeInfo *framework.NodeInfo) {
+	podInfo := framework.NewPodInfo(member)
+	nodeInfo.AddPodInfo(podInfo)
+	s.changes = append(s.changes, &simulatedChange{podInfo: podInfo, nodeInfo: nodeInfo})
+	s.nominations = append(s.nominations, &gangNomination{pod: member, nodeName: nodeInfo.Node().Name})
+}
+
+// execute evicts the victims and nominates the members except the preemptor, the preemptor is nominated by the
+// scheduler with the PostFilterResult.
+func (s *gangPreemptionSimulator) execute(handle framework.Handle, pluginName string, gang *Gang, preemptor *corev1.Pod) *framework.Status {
+	cs := handle.ClientSet()
+	for _, victim := range s.victims {
+		if waitingPod := handle.GetWaitingPod(victim.pod.UID); waitingPod != nil {
+			waitingPod.Reject(pluginName, "preempted")
+		} else if err := schedutil.DeletePod(cs, victim.pod); err != nil {
+			klog.ErrorS(err, "Failed to preempt victim for gang", "gang", gang.Name, "victim", klog.KObj(victim.pod))
+			return framework.AsStatus(err)
+		}
+		klog.V(2).InfoS("Gang preempted victim", "gang", gang.Name, "preemptor", klog.KObj(preemptor),
+			"victim", klog.KObj(victim.pod), "node", victim.nodeName)
+		handle.EventRecorder().Eventf(victim.pod, preemptor, corev1.EventTypeNormal, "Preempted", "Preempting",
+			"Preempted by gang %v on node %v", gang.Name, victim.nodeName)
+	}
+
+	for _, nomination := range s.nominations {
+		if nomination.pod.UID == preemptor.UID {
+			continue
+		}
+		newStatus := nomination.pod.Status.DeepCopy()
+		newStatus.NominatedNodeName = nomination.nodeName
+		if err := schedutil.PatchPodStatus(cs, nomination.pod, newStatus); err != nil {
+			// the member would be nominated again by its own preemption, so do not break the others
+			klog.ErrorS(err, "Failed to nominate gang member", "gang", gang.Name, "pod", klog.KObj(nomination.pod),
+				"node", nomination.nodeName)
+			continue
+		}
+		handle.AddNominatedPod(framework.NewPodInfo(nomination.pod), &framework.NominatingInfo{
+			NominatedNodeName: nomination.nodeName,
+			NominatingMode:    framework.ModeOverride,
+		})
+	}
+	return nil
+}
+
+// filterPodsWithPDBViolation groups the given pods into the PDB violating ones and the non-violating ones, it is
+// stable and does not change the order of the pods.
+func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.PodDisruptionBudget) (violatingPodInfos, nonViolatingPodInfos []*framework.PodInfo) {
+	pdbsAllowed := make([]int32, len(pdbs))
+	for i, pdb := range pdbs {
+		pdbsAllowed[i] = pdb.Status.DisruptionsAllowed
+	}
+
+	for _, podInfo := range podInfos {
+		pod := podInfo.Pod
+		pdbForPodIsViolated := false
+		if len(pod.Labels) != 0 {
+			for i, pdb := range pdbs {
+				if pdb.Namespace != pod.Namespace {
+					continue
+				}
+				selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+				if err != nil {
+					continue
+				}
+				if selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+					continue
+				}
+				if _, exist := pdb.Status.DisruptedPods[pod.Name]; exist {
+					continue
+				}
+				pdbsAllowed[i]--
+				if pdbsAllowed[i] < 0 {
+					pdbForPodIsViolated = true
+				}
+			}
+		}
+		if pdbForPodIsViolated {
+			violatingPodInfos = append(violatingPodInfos, podInfo)
+		} else {
+			nonViolatingPodInfos = append(nonViolatingPodInfos, podInfo)
+		}
+	}
+	return violatingPodInfos, nonViolatingPodInfos
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+	fakepgclientset "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned/fake"
+	pgformers "sigs.k8s.io/scheduler-plugins/pkg/generated/informers/externalversions"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+type fakePodNominator struct {
+	nominatedPods map[string][]*framework.PodInfo
+}
+
+func (n *fakePodNominator) AddNominatedPod(pi *framework.PodInfo, nominatingInfo *framework.NominatingInfo) {
+	n.nominatedPods[nominatingInfo.NominatedNodeName] = append(n.nominatedPods[nominatingInfo.NominatedNodeName], pi)
+}
+
+func (n *fakePodNominator) DeleteNominatedPodIfExists(pod *corev1.Pod) {}
+
+func (n *fakePodNominator) UpdateNominatedPod(oldPod *corev1.Pod, newPodInfo *framework.PodInfo) {}
+
+func (n *fakePodNominator) NominatedPodsForNode(nodeName string) []*framework.PodInfo {
+	return n.nominatedPods[nodeName]
+}
+
+type testNodeInfoLister struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newTestNodeInfoLister(nodes []*corev1.Node, pods []*corev1.Pod) *testNodeInfoLister {
+	lister := &testNodeInfoLister{nodeInfoMap: map[string]*framework.NodeInfo{}}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		lister.nodeInfos = append(lister.nodeInfos, nodeInfo)
+		lister.nodeInfoMap[node.Name] = nodeInfo
+	}
+	for _, pod := range pods {
+		if nodeInfo := lister.nodeInfoMap[pod.Spec.NodeName]; nodeInfo != nil {
+			nodeInfo.AddPod(pod)
+		}
+	}
+	return lister
+}
+
+func (l *testNodeInfoLister) NodeInfos() framework.NodeInfoLister {
+	return l
+}
+
+func (l *testNodeInfoLister) List() ([]*framework.NodeInfo, error) {
+	return l.nodeInfos, nil
+}
+
+func (l *testNodeInfoLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (l *testNodeInfoLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (l *testNodeInfoLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	if nodeInfo, ok := l.nodeInfoMap[nodeName]; ok {
+		return nodeInfo, nil
+	}
+	return nil, fmt.Errorf("node %s not found", nodeName)
+}
+
+func makePreemptionTestNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func makePreemptionTestPod(name, nodeName string, priority int32, gangName string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+			Labels:    map[string]string{"app": name},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Priority: &priority,
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
+						},
+					},
+				},
+			},
+		},
+	}
+	if gangName != "" {
+		pod.Annotations = map[string]string{
+			extension.AnnotationGangName:   gangName,
+			extension.AnnotationGangMinNum: "2",
+		}
+	}
+	return pod
+}
+
+func newGangPreemptionTestFramework(t *testing.T, cs *clientsetfake.Clientset, nodes []*corev1.Node, pods []*corev1.Pod) framework.Framework {
+	fitArgs := &schedconfig.NodeResourcesFitArgs{
+		ScoringStrategy: &schedconfig.ScoringStrategy{
+			Type: schedconfig.LeastAllocated,
+			Resources: []schedconfig.ResourceSpec{
+				{Name: string(corev1.ResourceCPU), Weight: 1},
+			},
+		},
+	}
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		schedulertesting.RegisterPluginAsExtensions(noderesources.Name, func(_ apiruntime.Object, fh framework.Handle) (framework.Plugin, error) {
+			return noderesources.NewFit(fitArgs, fh, feature.Features{})
+		}, "PreFilter", "Filter"),
+	}
+	fh, err := schedulertesting.NewFramework(
+		registeredPlugins,
+		"koord-scheduler",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithInformerFactory(informers.NewSharedInformerFactory(cs, 0)),
+		frameworkruntime.WithSnapshotSharedLister(newTestNodeInfoLister(nodes, pods)),
+		frameworkruntime.WithPodNominator(&fakePodNominator{nominatedPods: map[string][]*framework.PodInfo{}}),
+		frameworkruntime.WithEventRecorder(events.NewFakeRecorder(100)),
+	)
+	assert.NoError(t, err)
+	return fh
+}
+
+func TestPostFilterWithGangPreemption(t *testing.T) {
+	nodes := []*corev1.Node{makePreemptionTestNode("node1"), makePreemptionTestNode("node2")}
+	neverPreempt := corev1.PreemptNever
+	tests := []struct {
+		name              string
+		victims           []*corev1.Pod
+		pdbs              []*policy.PodDisruptionBudget
+		preemptionPolicy  *corev1.PreemptionPolicy
+		wantSuccess       bool
+		wantDeleted       []string
+		wantNominatedNode bool
+	}{
+		{
+			name: "preempt lower priority pods for all members",
+			victims: []*corev1.Pod{
+				makePreemptionTestPod("low-1", "node1", 10, ""),
+				makePreemptionTestPod("low-2", "node2", 10, ""),
+			},
+			wantSuccess:       true,
+			wantDeleted:       []string{"low-1", "low-2"},
+			wantNominatedNode: true,
+		},
+		{
+			name: "preempt lower priority pods even if violating pdb",
+			victims: []*corev1.Pod{
+				makePreemptionTestPod("low-1", "node1", 10, ""),
+				makePreemptionTestPod("low-2", "node2", 10, ""),
+			},
+			pdbs: []*policy.PodDisruptionBudget{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb"},
+					Spec: policy.PodDisruptionBudgetSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "low-1"}},
+					},
+				},
+			},
+			wantSuccess:       true,
+			wantDeleted:       []string{"low-1", "low-2"},
+			wantNominatedNode: true,
+		},
+		{
+			name: "do not preempt when only part of members can be placed",
+			victims: []*corev1.Pod{
+				makePreemptionTestPod("low-1", "node1", 10, ""),
+				makePreemptionTestPod("high-1", "node2", 1000, ""),
+			},
+		},
+		{
+			name: "do not preempt pods of other quotas",
+			victims: []*corev1.Pod{
+				makePreemptionTestPod("low-1", "node1", 10, ""),
+				func() *corev1.Pod {
+					pod := makePreemptionTestPod("low-2", "node2", 10, "")
+					pod.Labels[extension.LabelQuotaName] = "other-quota"
+					return pod
+				}(),
+			},
+		},
+		{
+			name: "do not preempt non-preemptible pods",
+			victims: []*corev1.Pod{
+				makePreemptionTestPod("low-1", "node1", 10, ""),
+				func() *corev1.Pod {
+					pod := makePreemptionTestPod("low-2", "node2", 10, "")
+					pod.Labels[extension.LabelPreemptible] = "false"
+					return pod
+				}(),
+			},
+		},
+		{
+			name: "preemptor with never preemption policy",
+			victims: []*corev1.Pod{
+				makePreemptionTestPod("low-1", "node1", 10, ""),
+				makePreemptionTestPod("low-2", "node2", 10, ""),
+			},
+			preemptionPolicy: &neverPreempt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := []*corev1.Pod{
+				makePreemptionTestPod("gang-a-0", "", 100, "gang-a"),
+				makePreemptionTestPod("gang-a-1", "", 100, "gang-a"),
+			}
+			members[0].Spec.PreemptionPolicy = tt.preemptionPolicy
+			cs := clientsetfake.NewSimpleClientset()
+			for _, pod := range append(members, tt.victims...) {
+				_, err := cs.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			for _, pdb := range tt.pdbs {
+				assert.NoError(t, informerFactory.Policy().V1().PodDisruptionBudgets().Informer().GetStore().Add(pdb))
+			}
+			fh := newGangPreemptionTestFramework(t, cs, nodes, tt.victims)
+
+			pgClient := fakepgclientset.NewSimpleClientset()
+			koordClient := koordfake.NewSimpleClientset()
+			args := &config.CoschedulingArgs{DefaultTimeout: metav1.Duration{Duration: 300 * time.Second}, EnablePreemption: true}
+			pgMgr := NewPodGroupManager(args, pgClient, pgformers.NewSharedInformerFactory(pgClient, 0), informerFactory,
+				koordinformers.NewSharedInformerFactory(koordClient, 0))
+			for _, pod := range members {
+				pgMgr.cache.onPodAdd(pod)
+			}
+
+			preemptor := members[0]
+			state := framework.NewCycleState()
+			_, status := fh.RunPreFilterPlugins(context.TODO(), state, preemptor)
+			assert.True(t, status.IsSuccess())
+			result, status := pgMgr.PostFilter(context.TODO(), state, preemptor, fh, "Coscheduling", framework.NodeToStatusMap{})
+			assert.Equal(t, tt.wantSuccess, status.IsSuccess(), status.Message())
+
+			for _, victim := range tt.victims {
+				_, err := cs.CoreV1().Pods(victim.Namespace).Get(context.TODO(), victim.Name, metav1.GetOptions{})
+				deleted := errors.IsNotFound(err)
+				assert.Equal(t, containsString(tt.wantDeleted, victim.Name), deleted, victim.Name)
+			}
+			if !tt.wantNominatedNode {
+				return
+			}
+			preemptorNode := result.NominatedNodeName
+			assert.NotEmpty(t, preemptorNode)
+			member, err := cs.CoreV1().Pods("default").Get(context.TODO(), "gang-a-1", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, member.Status.NominatedNodeName)
+			assert.NotEqual(t, preemptorNode, member.Status.NominatedNodeName)
+			assert.Len(t, fh.NominatedPodsForNode(member.Status.NominatedNodeName), 1)
+		})
+	}
+}
+
+func TestGetPodQuotaBoundary(t *testing.T) {
+	quotaInformer := pgformers.NewSharedInformerFactory(fakepgclientset.NewSimpleClientset(), 0).Scheduling().V1alpha1().ElasticQuotas()
+	quotas := []*schedv1alpha1.ElasticQuota{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-bound", Name: "ns-bound"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "team",
+				Name:      "team-quota",
+				Annotations: map[string]string{
+					extension.AnnotationQuotaNamespaces: `["ns-annotated"]`,
+				},
+			},
+		},
+	}
+	for _, quota := range quotas {
+		assert.NoError(t, quotaInformer.Informer().GetStore().Add(quota))
+	}
+	simulator := &gangPreemptionSimulator{
+		quotaLister:     quotaInformer.Lister(),
+		namespaceQuotas: map[string]string{},
+	}
+	tests := []struct {
+		name      string
+		namespace string
+		quotaName string
+		want      string
+	}{
+		{
+			name:      "quota label",
+			namespace: "ns-bound",
+			quotaName: "labeled-quota",
+			want:      "labeled-quota",
+		},
+		{
+			name:      "quota named as the namespace",
+			namespace: "ns-bound",
+			want:      "ns-bound",
+		},
+		{
+			name:      "quota declaring the namespace in the annotation",
+			namespace: "ns-annotated",
+			want:      "team-quota",
+		},
+		{
+			name:      "default quota",
+			namespace: "other",
+			want:      extension.DefaultQuotaName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := makePreemptionTestPod("test-pod", "", 10, "")
+			pod.Namespace = tt.namespace
+			if tt.quotaName != "" {
+				pod.Labels[extension.LabelQuotaName] = tt.quotaName
+			}
+			assert.Equal(t, tt.want, simulator.getPodQuotaBoundary(pod))
+		})
+	}
+}
+
+func TestIsGangPreemptionSimulation(t *testing.T) {
+	state := framework.NewCycleState()
+	assert.False(t, IsGangPreemptionSimulation(state))
+	state.Write(gangPreemptionSimulationKey, &gangPreemptionSimulationState{})
+	assert.True(t, IsGangPreemptionSimulation(state))
+	assert.True(t, IsGangPreemptionSimulation(state.Clone()))
+}
+
+func containsString(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
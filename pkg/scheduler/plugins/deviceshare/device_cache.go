@@ -66,6 +66,40 @@ func newNodeDevice() *nodeDevice {
 	}
 }
 
+func (n *nodeDevice) clone() *nodeDevice {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	nd := newNodeDevice()
+	for deviceType, resources := range n.deviceTotal {
+		nd.deviceTotal[deviceType] = resources.DeepCopy()
+	}
+	for deviceType, resources := range n.deviceFree {
+		nd.deviceFree[deviceType] = resources.DeepCopy()
+	}
+	for deviceType, resources := range n.deviceUsed {
+		nd.deviceUsed[deviceType] = resources.DeepCopy()
+	}
+	for deviceType, vfAllocation := range n.vfAllocations {
+		allocatedVFs := make(map[int]sets.String, len(vfAllocation.allocatedVFs))
+		for minor, vfs := range vfAllocation.allocatedVFs {
+			allocatedVFs[minor] = sets.NewString(vfs.UnsortedList()...)
+		}
+		nd.vfAllocations[deviceType] = &VFAllocation{allocatedVFs: allocatedVFs}
+	}
+	for deviceType, podAllocated := range n.allocateSet {
+		allocateSet := make(map[types.NamespacedName]deviceResources, len(podAllocated))
+		for podNamespacedName, resources := range podAllocated {
+			allocateSet[podNamespacedName] = resources.DeepCopy()
+		}
+		nd.allocateSet[deviceType] = allocateSet
+	}
+	// the topology and the device infos are replaced instead of updated in place
+	nd.numaTopology = n.numaTopology
+	nd.deviceInfos = n.deviceInfos
+	return nd
+}
+
 func (n *nodeDevice) getNodeDeviceSummary() *NodeDeviceSummary {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
@@ -432,6 +466,8 @@ type nodeDeviceCache struct {
 	lock sync.Mutex
 	// nodeDeviceInfos stores nodeDevice for each node.
 	nodeDeviceInfos map[string]*nodeDevice
+	// base is the nodeDeviceCache forked from, whose nodeDevices are copied at the first access.
+	base *nodeDeviceCache
 }
 
 func newNodeDeviceCache() *nodeDeviceCache {
@@ -444,6 +480,11 @@ func (n *nodeDeviceCache) getNodeDevice(nodeName string, needInit bool) *nodeDev
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
+	if n.nodeDeviceInfos[nodeName] == nil && n.base != nil {
+		if nd := n.base.getNodeDevice(nodeName, false); nd != nil {
+			n.nodeDeviceInfos[nodeName] = nd.clone()
+		}
+	}
 	// getNodeDevice will create new `nodeDevice` if needInit is true and nodeDeviceInfos[nodeName] is nil
 	if n.nodeDeviceInfos[nodeName] == nil && needInit {
 		klog.V(5).Infof("node device cache not found, nodeName: %v, createNodeDevice", nodeName)
@@ -453,6 +494,14 @@ func (n *nodeDeviceCache) getNodeDevice(nodeName string, needInit bool) *nodeDev
 	return n.nodeDeviceInfos[nodeName]
 }
 
+// fork returns a nodeDeviceCache starting with the devices of n, the devices allocated on it never reach n.
+func (n *nodeDeviceCache) fork() *nodeDeviceCache {
+	return &nodeDeviceCache{
+		nodeDeviceInfos: make(map[string]*nodeDevice),
+		base:            n,
+	}
+}
+
 func (n *nodeDeviceCache) removeNodeDevice(nodeName string) {
 	if nodeName == "" {
 		return
@@ -503,6 +552,17 @@ func (n *nodeDeviceCache) updateNodeDevice(nodeName string, device *schedulingv1
 }
 
 func buildDeviceResources(device *schedulingv1alpha1.Device) map[schedulingv1alpha1.DeviceType]deviceResources {
+	// the devices allocated out of the scheduler (e.g. by the kubelet device plugins) are not schedulable
+	allocatedMinors := map[schedulingv1alpha1.DeviceType]sets.Int32{}
+	for _, allocation := range device.Status.Allocations {
+		if allocatedMinors[allocation.Type] == nil {
+			allocatedMinors[allocation.Type] = sets.NewInt32()
+		}
+		for _, entry := range allocation.Entries {
+			allocatedMinors[allocation.Type].Insert(entry.Minors...)
+		}
+	}
+
 	nodeDeviceResource := map[schedulingv1alpha1.DeviceType]deviceResources{}
 	for _, deviceInfo := range device.Spec.Devices {
 		if nodeDeviceResource[deviceInfo.Type] == nil {
@@ -513,6 +573,9 @@ func buildDeviceResources(device *schedulingv1alpha1.Device) map[schedulingv1alp
 		if !deviceInfo.Health {
 			resources = make(corev1.ResourceList)
 			klog.Errorf("Find device unhealthy, nodeName:%v, deviceType:%v, minor:%v", device.Name, deviceInfo.Type, deviceInfo.Minor)
+		} else if allocatedMinors[deviceInfo.Type].Has(*deviceInfo.Minor) {
+			resources = make(corev1.ResourceList)
+			klog.V(5).Infof("Find device allocated out of the scheduler, nodeName:%v, deviceType:%v, minor:%v", device.Name, deviceInfo.Type, *deviceInfo.Minor)
 		} else {
 			resources = deviceInfo.Resources
 			klog.V(5).Infof("Find device resource update, nodeName:%v, deviceType:%v, minor:%v, res:%v", device.Name, deviceInfo.Type, deviceInfo.Minor, resources)
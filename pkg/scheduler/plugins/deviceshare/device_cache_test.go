@@ -82,6 +82,73 @@ func Test_nodeDevice_getUsed(t *testing.T) {
 	assert.Equal(t, expectUsed, used)
 }
 
+func Test_nodeDeviceCache_fork(t *testing.T) {
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 1,
+				Resources: corev1.ResourceList{
+					apiext.ResourceGPUCore:        resource.MustParse("100"),
+					apiext.ResourceGPUMemory:      resource.MustParse("8Gi"),
+					apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+				},
+			},
+		},
+	}
+	cache := newNodeDeviceCache()
+	cache.getNodeDevice("test-node-1", true).updateCacheUsed(allocations, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-1"},
+	}, true)
+
+	forked := cache.fork()
+	assert.Nil(t, forked.getNodeDevice("test-node-2", false))
+	nd := forked.getNodeDevice("test-node-1", false)
+	assert.NotNil(t, nd)
+	assert.NotEmpty(t, nd.getUsed("default", "test-pod-1"), "the forked cache should start with the allocations of the base")
+	nd.updateCacheUsed(allocations, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-2"},
+	}, true)
+	assert.NotEmpty(t, forked.getNodeDevice("test-node-1", false).getUsed("default", "test-pod-2"))
+	assert.Empty(t, cache.getNodeDevice("test-node-1", false).getUsed("default", "test-pod-2"), "the allocations on the forked cache should not reach the base")
+}
+
+func Test_buildDeviceResources(t *testing.T) {
+	gpuResources := corev1.ResourceList{
+		apiext.ResourceGPUCore:        resource.MustParse("100"),
+		apiext.ResourceGPUMemory:      resource.MustParse("8Gi"),
+		apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+	}
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{Type: schedulingv1alpha1.GPU, Minor: pointer.Int32(0), Health: true, Resources: gpuResources},
+				{Type: schedulingv1alpha1.GPU, Minor: pointer.Int32(1), Health: false, Resources: gpuResources},
+				{Type: schedulingv1alpha1.GPU, Minor: pointer.Int32(2), Health: true, Resources: gpuResources},
+			},
+		},
+		Status: schedulingv1alpha1.DeviceStatus{
+			Allocations: []schedulingv1alpha1.DeviceAllocation{
+				{
+					Type: schedulingv1alpha1.GPU,
+					Entries: []schedulingv1alpha1.DeviceAllocationItem{
+						{Name: "test-pod", Namespace: "default", Minors: []int32{2}},
+					},
+				},
+			},
+		},
+	}
+	// the unhealthy devices and the devices allocated out of the scheduler are not schedulable
+	expected := map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: {
+			0: gpuResources,
+			1: corev1.ResourceList{},
+			2: corev1.ResourceList{},
+		},
+	}
+	assert.Equal(t, expected, buildDeviceResources(device))
+}
+
 func Test_gcNodeDevices(t *testing.T) {
 	cache := newNodeDeviceCache()
 	fakeClient := kubefake.NewSimpleClientset()
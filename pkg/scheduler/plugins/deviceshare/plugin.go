@@ -64,6 +64,7 @@ var (
 	_ frameworkext.ReservationScorePlugin     = &Plugin{}
 	_ frameworkext.ReservationScoreExtensions = &Plugin{}
 	_ frameworkext.ReservationPreBindPlugin   = &Plugin{}
+	_ frameworkext.SimulateAllocationPlugin   = &Plugin{}
 )
 
 type Plugin struct {
@@ -125,6 +126,17 @@ func (s *preFilterState) Clone() framework.StateData {
 	return ns
 }
 
+// getNodeDeviceCache returns the fork of the nodeDeviceCache in the scheduling simulation, otherwise the nodeDeviceCache.
+func (p *Plugin) getNodeDeviceCache(cycleState *framework.CycleState) *nodeDeviceCache {
+	forked := frameworkext.GetOrCreateSimulationState(cycleState, Name, func() interface{} {
+		return p.nodeDeviceCache.fork()
+	})
+	if forked == nil {
+		return p.nodeDeviceCache
+	}
+	return forked.(*nodeDeviceCache)
+}
+
 func (p *Plugin) Name() string {
 	return Name
 }
@@ -174,7 +186,7 @@ func (p *Plugin) AddPod(ctx context.Context, cycleState *framework.CycleState, p
 	}
 
 	node := nodeInfo.Node()
-	nd := p.nodeDeviceCache.getNodeDevice(node.Name, false)
+	nd := p.getNodeDeviceCache(cycleState).getNodeDevice(node.Name, false)
 	if nd == nil {
 		return nil
 	}
@@ -189,10 +201,7 @@ func (p *Plugin) AddPod(ctx context.Context, cycleState *framework.CycleState, p
 
 	rInfo := reservation.GetReservationCache().GetReservationInfoByPod(podInfoToAdd.Pod, node.Name)
 	if rInfo == nil {
-		nominator := p.handle.GetReservationNominator()
-		if nominator != nil {
-			rInfo = nominator.GetNominatedReservation(podInfoToAdd.Pod, node.Name)
-		}
+		rInfo = frameworkext.GetNominatedReservation(p.handle, cycleState, podInfoToAdd.Pod, node.Name)
 	}
 	if rInfo == nil {
 		preemptibleDevices := state.preemptibleDevices[node.Name]
@@ -233,7 +242,7 @@ func (p *Plugin) RemovePod(ctx context.Context, cycleState *framework.CycleState
 	}
 
 	node := nodeInfo.Node()
-	nd := p.nodeDeviceCache.getNodeDevice(node.Name, false)
+	nd := p.getNodeDeviceCache(cycleState).getNodeDevice(node.Name, false)
 	if nd == nil {
 		return nil
 	}
@@ -248,10 +257,7 @@ func (p *Plugin) RemovePod(ctx context.Context, cycleState *framework.CycleState
 
 	rInfo := reservation.GetReservationCache().GetReservationInfoByPod(podInfoToRemove.Pod, node.Name)
 	if rInfo == nil {
-		nominator := p.handle.GetReservationNominator()
-		if nominator != nil {
-			rInfo = nominator.GetNominatedReservation(podInfoToRemove.Pod, node.Name)
-		}
+		rInfo = frameworkext.GetNominatedReservation(p.handle, cycleState, podInfoToRemove.Pod, node.Name)
 	}
 	if rInfo == nil {
 		preemptibleDevices := state.preemptibleDevices[node.Name]
@@ -291,7 +297,7 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 		return framework.NewStatus(framework.Error, "node not found")
 	}
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(node.Name, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(node.Name, false)
 	if nodeDeviceInfo == nil {
 		return nil
 	}
@@ -338,7 +344,7 @@ func (p *Plugin) FilterReservation(ctx context.Context, cycleState *framework.Cy
 		return nil
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return framework.AsStatus(err)
 	}
@@ -357,7 +363,7 @@ func (p *Plugin) FilterReservation(ctx context.Context, cycleState *framework.Cy
 		return framework.AsStatus(fmt.Errorf("impossible, there is no relevant Reservation information in deviceShare"))
 	}
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
 	if nodeDeviceInfo == nil {
 		return nil
 	}
@@ -391,14 +397,57 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		return nil
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
-	if err != nil {
-		return framework.AsStatus(err)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
+	if nodeDeviceInfo == nil {
+		return nil
+	}
+
+	nodeDeviceInfo.lock.Lock()
+	defer nodeDeviceInfo.lock.Unlock()
+
+	result, status := p.allocate(cycleState, state, nodeDeviceInfo, pod, nodeName)
+	if !status.IsSuccess() {
+		return status
+	}
+	nodeDeviceInfo.updateCacheUsed(result, pod, true)
+	state.allocationResult = result
+	return nil
+}
+
+// SimulateAllocation returns the devices would be allocated to the Pod, and records the allocation on the fork of
+// the nodeDeviceCache for the simulation instead of the nodeDeviceCache of the scheduler.
+func (p *Plugin) SimulateAllocation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (interface{}, *framework.Status) {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return nil, status
+	}
+	if state.skip {
+		return nil, nil
 	}
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
 	if nodeDeviceInfo == nil {
-		return nil
+		return nil, nil
+	}
+
+	nodeDeviceInfo.lock.Lock()
+	defer nodeDeviceInfo.lock.Unlock()
+
+	result, status := p.allocate(cycleState, state, nodeDeviceInfo, pod, nodeName)
+	if !status.IsSuccess() || len(result) == 0 {
+		return nil, status
+	}
+	if frameworkext.IsSchedulingSimulation(cycleState) {
+		nodeDeviceInfo.updateCacheUsed(result, pod, true)
+	}
+	return result, nil
+}
+
+// allocate allocates devices for the Pod, the caller should hold the lock of nodeDeviceInfo.
+func (p *Plugin) allocate(cycleState *framework.CycleState, state *preFilterState, nodeDeviceInfo *nodeDevice, pod *corev1.Pod, nodeName string) (apiext.DeviceAllocations, *framework.Status) {
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
+	if err != nil {
+		return nil, framework.AsStatus(err)
 	}
 
 	store := topologymanager.GetStore(cycleState)
@@ -417,24 +466,19 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	restoreState := reservationRestoreState.getNodeState(nodeName)
 	preemptible := appendAllocated(nil, restoreState.mergedUnmatchedUsed, state.preemptibleDevices[nodeName])
 
-	nodeDeviceInfo.lock.Lock()
-	defer nodeDeviceInfo.lock.Unlock()
-
 	result, status := p.allocateWithNominatedReservation(
 		allocator, cycleState, state, restoreState, nodeInfo.Node(), pod, preemptible)
 	if !status.IsSuccess() {
-		return status
+		return nil, status
 	}
 	if len(result) == 0 {
 		preemptible = appendAllocated(preemptible, restoreState.mergedMatchedAllocatable)
 		result, status = allocator.Allocate(nil, nil, nil, preemptible)
 		if !status.IsSuccess() {
-			return status
+			return nil, status
 		}
 	}
-	nodeDeviceInfo.updateCacheUsed(result, pod, true)
-	state.allocationResult = result
-	return nil
+	return result, nil
 }
 
 func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) {
@@ -446,7 +490,7 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 		return
 	}
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
 	if nodeDeviceInfo == nil {
 		return
 	}
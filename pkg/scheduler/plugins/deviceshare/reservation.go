@@ -122,7 +122,7 @@ func (p *Plugin) RestoreReservation(ctx context.Context, cycleState *framework.C
 	}
 
 	nodeName := nodeInfo.Node().Name
-	nd := p.nodeDeviceCache.getNodeDevice(nodeName, false)
+	nd := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
 	if nd == nil {
 		return nil, nil
 	}
@@ -302,7 +302,7 @@ func (p *Plugin) allocateWithNominatedReservation(
 		return nil, nil
 	}
 
-	reservation := p.handle.GetReservationNominator().GetNominatedReservation(pod, node.Name)
+	reservation := frameworkext.GetNominatedReservation(p.handle, cycleState, pod, node.Name)
 	if reservation == nil {
 		return nil, nil
 	}
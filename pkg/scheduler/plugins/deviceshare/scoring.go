@@ -40,12 +40,12 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 		return 0, nil
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return 0, framework.AsStatus(err)
 	}
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
 	if nodeDeviceInfo == nil {
 		return 0, nil
 	}
@@ -69,7 +69,7 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 	nodeDeviceInfo.lock.RLock()
 	defer nodeDeviceInfo.lock.RUnlock()
 
-	reservationInfo := p.handle.GetReservationNominator().GetNominatedReservation(pod, nodeName)
+	reservationInfo := frameworkext.GetNominatedReservation(p.handle, cycleState, pod, nodeName)
 	if reservationInfo != nil {
 		score, status := p.scoreWithNominatedReservation(allocator, state, restoreState, nodeName, pod, preemptible, reservationInfo)
 		if status.IsSuccess() {
@@ -105,7 +105,7 @@ func (p *Plugin) ScoreReservation(ctx context.Context, cycleState *framework.Cyc
 		return 0, nil
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return 0, framework.AsStatus(err)
 	}
@@ -113,7 +113,7 @@ func (p *Plugin) ScoreReservation(ctx context.Context, cycleState *framework.Cyc
 	reservationRestoreState := getReservationRestoreState(cycleState)
 	restoreState := reservationRestoreState.getNodeState(nodeName)
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(nodeName, false)
 	if nodeDeviceInfo == nil {
 		return 0, nil
 	}
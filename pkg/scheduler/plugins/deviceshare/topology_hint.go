@@ -26,6 +26,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/topologymanager"
 	"github.com/koordinator-sh/koordinator/pkg/util/bitmask"
 )
@@ -40,13 +41,13 @@ func (p *Plugin) GetPodTopologyHints(ctx context.Context, cycleState *framework.
 		return nil, nil
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return nil, framework.AsStatus(err)
 	}
 	node := nodeInfo.Node()
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(node.Name, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(node.Name, false)
 	if nodeDeviceInfo == nil {
 		return nil, nil
 	}
@@ -64,13 +65,13 @@ func (p *Plugin) Allocate(ctx context.Context, cycleState *framework.CycleState,
 		return nil
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return framework.AsStatus(err)
 	}
 	node := nodeInfo.Node()
 
-	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(node.Name, false)
+	nodeDeviceInfo := p.getNodeDeviceCache(cycleState).getNodeDevice(node.Name, false)
 	if nodeDeviceInfo == nil {
 		return nil
 	}
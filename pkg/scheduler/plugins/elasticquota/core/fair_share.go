@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// QuotaShare is the fair share of a quota group, which is used to order the pending pods across the quota groups
+// by the dominant resource fairness.
+type QuotaShare struct {
+	Name string
+	// MinShare is the dominant share of the used relative to the min, the quota group is below its min if the share
+	// is less than 1. It is +Inf if the quota group has no min.
+	MinShare float64
+	// DominantShare is the dominant share of the used relative to the runtime, or the max if the runtime quota is
+	// disabled.
+	DominantShare float64
+}
+
+// IsBelowMin returns whether the used of the quota group does not reach the min in any dimension.
+func (s *QuotaShare) IsBelowMin() bool {
+	return s.MinShare < 1
+}
+
+// CompareQuotaShare returns -1 if the quota group s should be served before the other, 1 if after, and 0 if they
+// are equal. The quota group below its min is always served first, then the one with the lower dominant share.
+func CompareQuotaShare(s, other *QuotaShare) int {
+	belowMin, otherBelowMin := s.IsBelowMin(), other.IsBelowMin()
+	if belowMin != otherBelowMin {
+		if belowMin {
+			return -1
+		}
+		return 1
+	}
+	if belowMin && s.MinShare != other.MinShare {
+		if s.MinShare < other.MinShare {
+			return -1
+		}
+		return 1
+	}
+	if s.DominantShare != other.DominantShare {
+		if s.DominantShare < other.DominantShare {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// GetQuotaSharePath returns the shares of the quota group and all its parents, ordered from the root to the quota
+// group. The runtime is not refreshed here, so the shares are calculated with the runtime of the latest refresh, and
+// the max is used instead if the runtime has never been refreshed.
+func (gqm *GroupQuotaManager) GetQuotaSharePath(quotaName string, useRuntime bool) []*QuotaShare {
+	gqm.hierarchyUpdateLock.RLock()
+	defer gqm.hierarchyUpdateLock.RUnlock()
+
+	curToAllParInfos := gqm.getCurToAllParentGroupQuotaInfoNoLock(quotaName)
+	shares := make([]*QuotaShare, len(curToAllParInfos))
+	for i, quotaInfo := range curToAllParInfos {
+		quotaInfo.lock.Lock()
+		limit := quotaInfo.CalculateInfo.Max
+		if useRuntime && len(quotaInfo.CalculateInfo.Runtime) > 0 {
+			limit = quotaInfo.CalculateInfo.Runtime
+		}
+		shares[len(curToAllParInfos)-1-i] = &QuotaShare{
+			Name:          quotaInfo.Name,
+			MinShare:      getMinShare(quotaInfo.CalculateInfo.Used, quotaInfo.CalculateInfo.AutoScaleMin),
+			DominantShare: getDominantShare(quotaInfo.CalculateInfo.Used, limit),
+		}
+		quotaInfo.lock.Unlock()
+	}
+	return shares
+}
+
+func getMinShare(used, min v1.ResourceList) float64 {
+	share := math.Inf(1)
+	for resourceName, minQuantity := range min {
+		if minQuantity.Sign() <= 0 {
+			continue
+		}
+		usedQuantity := used[resourceName]
+		resourceShare := float64(usedQuantity.MilliValue()) / float64(minQuantity.MilliValue())
+		if math.IsInf(share, 1) || resourceShare > share {
+			share = resourceShare
+		}
+	}
+	return share
+}
+
+func getDominantShare(used, limit v1.ResourceList) float64 {
+	var share float64
+	for resourceName, usedQuantity := range used {
+		if usedQuantity.Sign() <= 0 {
+			continue
+		}
+		// the quota group can not use more of the resource without the limit, so the resource is saturated
+		resourceShare := 1.0
+		if limitQuantity, ok := limit[resourceName]; ok && limitQuantity.Sign() > 0 {
+			resourceShare = float64(usedQuantity.MilliValue()) / float64(limitQuantity.MilliValue())
+		}
+		if resourceShare > share {
+			share = resourceShare
+		}
+	}
+	return share
+}
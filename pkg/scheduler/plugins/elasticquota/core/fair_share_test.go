@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestCompareQuotaShare(t *testing.T) {
+	tests := []struct {
+		name  string
+		share *QuotaShare
+		other *QuotaShare
+		want  int
+	}{
+		{
+			name:  "below min first",
+			share: &QuotaShare{Name: "a", MinShare: 0.9, DominantShare: 0.9},
+			other: &QuotaShare{Name: "b", MinShare: 1, DominantShare: 0.1},
+			want:  -1,
+		},
+		{
+			name:  "not below min after",
+			share: &QuotaShare{Name: "a", MinShare: math.Inf(1), DominantShare: 0.1},
+			other: &QuotaShare{Name: "b", MinShare: 0, DominantShare: 0.5},
+			want:  1,
+		},
+		{
+			name:  "both below min, further below min first",
+			share: &QuotaShare{Name: "a", MinShare: 0.5, DominantShare: 0.1},
+			other: &QuotaShare{Name: "b", MinShare: 0.2, DominantShare: 0.4},
+			want:  1,
+		},
+		{
+			name:  "both above min, lower dominant share first",
+			share: &QuotaShare{Name: "a", MinShare: 2, DominantShare: 0.3},
+			other: &QuotaShare{Name: "b", MinShare: 1.5, DominantShare: 0.6},
+			want:  -1,
+		},
+		{
+			name:  "equal",
+			share: &QuotaShare{Name: "a", MinShare: 2, DominantShare: 0.3},
+			other: &QuotaShare{Name: "b", MinShare: 1.5, DominantShare: 0.3},
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompareQuotaShare(tt.share, tt.other))
+		})
+	}
+}
+
+func TestGroupQuotaManager_GetQuotaSharePath(t *testing.T) {
+	gqm := NewGroupQuotaManagerForTest()
+	gqm.UpdateClusterTotalResource(createResourceList(100, 1000*GigaByte))
+	AddQuotaToManager(t, gqm, "parent", extension.RootQuotaName, 100, 1000*GigaByte, 50, 500*GigaByte, true, true)
+	AddQuotaToManager(t, gqm, "child", "parent", 40, 400*GigaByte, 20, 100*GigaByte, true, false)
+	gqm.updateGroupDeltaUsedNoLock("child", createResourceList(10, 200*GigaByte), nil)
+	gqm.RefreshRuntime("child")
+
+	path := gqm.GetQuotaSharePath("child", false)
+	assert.Len(t, path, 3)
+	assert.Equal(t, extension.RootQuotaName, path[0].Name)
+	assert.Equal(t, "parent", path[1].Name)
+	assert.Equal(t, 0.4, path[1].MinShare)
+	assert.Equal(t, 0.2, path[1].DominantShare)
+	assert.True(t, path[1].IsBelowMin())
+	assert.Equal(t, "child", path[2].Name)
+	// memory is over the min
+	assert.Equal(t, 2.0, path[2].MinShare)
+	assert.Equal(t, 0.5, path[2].DominantShare)
+	assert.False(t, path[2].IsBelowMin())
+
+	// the runtime of memory is limited by the request
+	runtimePath := gqm.GetQuotaSharePath("child", true)
+	assert.Len(t, runtimePath, 3)
+	assert.Equal(t, 1.0, runtimePath[2].DominantShare)
+
+	assert.Empty(t, gqm.GetQuotaSharePath("not-exist", false))
+}
+
+func TestGetDominantShare(t *testing.T) {
+	tests := []struct {
+		name  string
+		used  v1.ResourceList
+		limit v1.ResourceList
+		want  float64
+	}{
+		{
+			name:  "nothing used",
+			used:  v1.ResourceList{},
+			limit: createResourceList(10, 100),
+			want:  0,
+		},
+		{
+			name:  "dominant resource",
+			used:  createResourceList(5, 20),
+			limit: createResourceList(10, 100),
+			want:  0.5,
+		},
+		{
+			name:  "used without limit is saturated",
+			used:  createResourceList(5, 20),
+			limit: v1.ResourceList{},
+			want:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getDominantShare(tt.used, tt.limit))
+		})
+	}
+}
@@ -43,6 +43,15 @@ var (
 		[]string{"name", "resource", "tree", "is_parent", "parent", "field"},
 	)
 
+	ElasticQuotaUsageAccountingMetric = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem: schedulermetrics.SchedulerSubsystem,
+			Name:      "elastic_quota_usage_accounting",
+			Help:      "ElasticQuota cumulative used and borrowed resources in resource-hours",
+		},
+		[]string{"name", "resource", "tree", "is_parent", "parent", "field"},
+	)
+
 	UpdateElasticQuotaStatusLatency = metrics.NewHistogram(
 		&metrics.HistogramOpts{
 			Subsystem: schedulermetrics.SchedulerSubsystem,
@@ -57,6 +66,7 @@ func init() {
 	koordschedulermetrics.RegisterMetrics(
 		ElasticQuotaSpecMetric,
 		ElasticQuotaStatusMetric,
+		ElasticQuotaUsageAccountingMetric,
 		UpdateElasticQuotaStatusLatency,
 	)
 }
@@ -78,3 +88,11 @@ func recordResourceMetric(gaugeVec *metrics.GaugeVec, value int64, resourceName,
 
 	gaugeVec.With(labels).Set(float64(value))
 }
+
+func RecordElasticQuotaUsageAccountingMetric(amounts map[corev1.ResourceName]float64, field string, labels map[string]string) {
+	for resourceName, amount := range amounts {
+		labels["resource"] = string(resourceName)
+		labels["field"] = field
+		ElasticQuotaUsageAccountingMetric.With(labels).Set(amount)
+	}
+}
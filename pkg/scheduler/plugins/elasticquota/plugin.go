@@ -91,6 +91,8 @@ type Plugin struct {
 	// quotaToTreeMap store the relationship of quota and quota tree
 	// the key is the quota name, the value is the tree id
 	quotaToTreeMap map[string]string
+
+	queuedShares *queuedQuotaShares
 }
 
 var (
@@ -147,6 +149,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		nodeLister:                     handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
 		groupQuotaManagersForQuotaTree: make(map[string]*core.GroupQuotaManager),
 		quotaToTreeMap:                 make(map[string]string),
+		queuedShares:                   newQueuedQuotaShares(),
 	}
 	elasticQuota.groupQuotaManager = core.NewGroupQuotaManager("", pluginArgs.SystemQuotaGroupMax, pluginArgs.DefaultQuotaGroupMax)
 
@@ -191,7 +194,8 @@ func (g *Plugin) Start() {
 func (g *Plugin) NewControllers() ([]frameworkext.Controller, error) {
 	quotaOverUsedRevokeController := NewQuotaOverUsedRevokeController(g)
 	elasticQuotaController := NewElasticQuotaController(g)
-	return []frameworkext.Controller{g, quotaOverUsedRevokeController, elasticQuotaController}, nil
+	usageAccountingController := NewQuotaUsageAccountingController(g)
+	return []frameworkext.Controller{g, quotaOverUsedRevokeController, elasticQuotaController, usageAccountingController}, nil
 }
 
 func (g *Plugin) Name() string {
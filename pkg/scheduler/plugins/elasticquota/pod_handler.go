@@ -51,6 +51,9 @@ func (g *Plugin) OnPodUpdate(oldObj, newObj interface{}) {
 	if oldPod.ResourceVersion == newPod.ResourceVersion {
 		return
 	}
+	if newPod.Spec.NodeName != "" {
+		g.queuedShares.delete(newPod.UID)
+	}
 
 	oldQuotaName, oldTree := g.getPodAssociateQuotaNameAndTreeID(oldPod)
 	newQuotaName, newTree := g.getPodAssociateQuotaNameAndTreeID(newPod)
@@ -110,6 +113,7 @@ func (g *Plugin) OnPodDelete(obj interface{}) {
 		klog.V(4).InfoS("OnPodDeleteFunc, failed to parse object, obj: %T", obj)
 		return
 	}
+	g.queuedShares.delete(pod.UID)
 
 	quotaName, treeID := g.getPodAssociateQuotaNameAndTreeID(pod)
 	if quotaName == "" {
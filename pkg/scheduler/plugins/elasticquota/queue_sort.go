@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+var _ frameworkext.QueueSortExtension = &Plugin{}
+
+// queuedQuotaShares freezes the quota shares of the pending pods when they are added into the queue, because the
+// shares change as the pods are scheduled while the heap of the scheduling queue requires a stable order.
+type queuedQuotaShares struct {
+	lock   sync.Mutex
+	shares map[types.UID]*queuedQuotaShare
+}
+
+type queuedQuotaShare struct {
+	// timestamp is the time the pod is added into the queue, the share is recalculated when the pod is re-queued.
+	timestamp time.Time
+	quotaName string
+	treeID    string
+	path      []*core.QuotaShare
+}
+
+func newQueuedQuotaShares() *queuedQuotaShares {
+	return &queuedQuotaShares{
+		shares: map[types.UID]*queuedQuotaShare{},
+	}
+}
+
+func (q *queuedQuotaShares) delete(uid types.UID) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	delete(q.shares, uid)
+}
+
+// CompareQueuedPods orders the pending pods by the hierarchical dominant resource fairness across the quota tree.
+// If the pods belong to different quota groups of the same tree, compare the shares of the two sibling quota groups
+// where their paths from the root diverge, the one below its min is at the front of the queue, then the one with the
+// lower dominant share relative to its runtime. Otherwise, the pods are left to the QueueSort plugin.
+func (g *Plugin) CompareQueuedPods(podInfo1, podInfo2 *framework.QueuedPodInfo) int {
+	share1 := g.getQueuedQuotaShare(podInfo1)
+	share2 := g.getQueuedQuotaShare(podInfo2)
+	if share1 == nil || share2 == nil || share1.quotaName == share2.quotaName || share1.treeID != share2.treeID {
+		return 0
+	}
+	return compareQuotaSharePath(share1.path, share2.path)
+}
+
+func (g *Plugin) getQueuedQuotaShare(podInfo *framework.QueuedPodInfo) *queuedQuotaShare {
+	pod := podInfo.Pod
+	g.queuedShares.lock.Lock()
+	defer g.queuedShares.lock.Unlock()
+	if share := g.queuedShares.shares[pod.UID]; share != nil && share.timestamp.Equal(podInfo.Timestamp) {
+		return share
+	}
+
+	quotaName, treeID := g.getPodAssociateQuotaNameAndTreeID(pod)
+	if quotaName == "" {
+		return nil
+	}
+	mgr := g.GetGroupQuotaManagerForTree(treeID)
+	if mgr == nil {
+		return nil
+	}
+	share := &queuedQuotaShare{
+		timestamp: podInfo.Timestamp,
+		quotaName: quotaName,
+		treeID:    treeID,
+		path:      mgr.GetQuotaSharePath(quotaName, g.pluginArgs.EnableRuntimeQuota),
+	}
+	g.queuedShares.shares[pod.UID] = share
+	return share
+}
+
+// compareQuotaSharePath compares the shares of the first different quota groups in the paths from the root.
+func compareQuotaSharePath(path1, path2 []*core.QuotaShare) int {
+	for i := 0; i < len(path1) && i < len(path2); i++ {
+		if path1[i].Name == path2[i].Name {
+			continue
+		}
+		return core.CompareQuotaShare(path1[i], path2[i])
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestPlugin_CompareQueuedPods(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	p, err := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
+	assert.Nil(t, err)
+	plugin := p.(*Plugin)
+	gqm := plugin.groupQuotaManager
+	gqm.UpdateClusterTotalResource(createResourceList(100, 100))
+	plugin.addQuota("parent-a", extension.RootQuotaName, 100, 100, 50, 50, 100, 100, true, "", "")
+	plugin.addQuota("parent-b", extension.RootQuotaName, 100, 100, 50, 50, 100, 100, true, "", "")
+	plugin.addQuota("a1", "parent-a", 100, 100, 20, 20, 100, 100, false, "", "")
+	plugin.addQuota("a2", "parent-a", 100, 100, 20, 20, 100, 100, false, "", "")
+	plugin.addQuota("b1", "parent-b", 100, 100, 20, 20, 100, 100, false, "", "")
+	plugin.addQuota("b2", "parent-b", 100, 100, 0, 0, 100, 100, false, "", "")
+
+	// parent-a is over its min, parent-b is below its min.
+	// a1 is below its min, a2 is over its min.
+	// b1 and b2 are both over their min, and b2 has more pending requests so its dominant share is lower.
+	b2Pending := defaultCreatePodWithQuotaName("b2-pending", "b2", 0, 20, 20)
+	b2Pending.Spec.NodeName = ""
+	for _, pod := range []*corev1.Pod{
+		defaultCreatePodWithQuotaName("a1-running", "a1", 0, 10, 10),
+		defaultCreatePodWithQuotaName("a2-running", "a2", 0, 50, 50),
+		defaultCreatePodWithQuotaName("b1-running", "b1", 0, 30, 30),
+		defaultCreatePodWithQuotaName("b2-running", "b2", 0, 5, 5),
+		b2Pending,
+	} {
+		plugin.OnPodAdd(pod)
+	}
+
+	// the runtime is refreshed in PreFilter
+	for _, quotaName := range []string{"a1", "a2", "b1", "b2"} {
+		gqm.RefreshRuntime(quotaName)
+	}
+
+	now := time.Now()
+	newQueuedPodInfo := func(name, quotaName string, priority int32, timestamp time.Time) *framework.QueuedPodInfo {
+		pod := defaultCreatePodWithQuotaName(name, quotaName, priority, 1, 1)
+		pod.Spec.NodeName = ""
+		return &framework.QueuedPodInfo{
+			PodInfo:   framework.NewPodInfo(pod),
+			Timestamp: timestamp,
+		}
+	}
+	tests := []struct {
+		name     string
+		podInfo1 *framework.QueuedPodInfo
+		podInfo2 *framework.QueuedPodInfo
+		want     int
+	}{
+		{
+			name:     "quota tree below min first",
+			podInfo1: newQueuedPodInfo("pod1", "a1", 100, now),
+			podInfo2: newQueuedPodInfo("pod2", "b1", 0, now.Add(time.Second)),
+			want:     1,
+		},
+		{
+			name:     "sibling below min first",
+			podInfo1: newQueuedPodInfo("pod1", "a1", 0, now.Add(time.Second)),
+			podInfo2: newQueuedPodInfo("pod2", "a2", 100, now),
+			want:     -1,
+		},
+		{
+			name:     "sibling with lower dominant share first",
+			podInfo1: newQueuedPodInfo("pod1", "b1", 100, now),
+			podInfo2: newQueuedPodInfo("pod2", "b2", 0, now.Add(time.Second)),
+			want:     1,
+		},
+		{
+			name:     "same quota is left to the queue sort plugin",
+			podInfo1: newQueuedPodInfo("pod1", "a1", 100, now.Add(time.Second)),
+			podInfo2: newQueuedPodInfo("pod2", "a1", 0, now),
+			want:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, plugin.CompareQueuedPods(tt.podInfo1, tt.podInfo2))
+		})
+	}
+}
+
+func TestPlugin_CompareQueuedPodsWithFrozenShares(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	p, err := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
+	assert.Nil(t, err)
+	plugin := p.(*Plugin)
+	gqm := plugin.groupQuotaManager
+	gqm.UpdateClusterTotalResource(createResourceList(100, 100))
+	plugin.addQuota("a1", extension.RootQuotaName, 100, 100, 20, 20, 100, 100, false, "", "")
+	plugin.addQuota("a2", extension.RootQuotaName, 100, 100, 20, 20, 100, 100, false, "", "")
+	plugin.OnPodAdd(defaultCreatePodWithQuotaName("a2-running", "a2", 0, 50, 50))
+
+	now := time.Now()
+	newQueuedPodInfo := func(name, quotaName string, timestamp time.Time) *framework.QueuedPodInfo {
+		pod := defaultCreatePodWithQuotaName(name, quotaName, 0, 1, 1)
+		pod.Spec.NodeName = ""
+		return &framework.QueuedPodInfo{
+			PodInfo:   framework.NewPodInfo(pod),
+			Timestamp: timestamp,
+		}
+	}
+	podInfo1 := newQueuedPodInfo("pod1", "a1", now)
+	podInfo2 := newQueuedPodInfo("pod2", "a2", now)
+	assert.Equal(t, -1, plugin.CompareQueuedPods(podInfo1, podInfo2))
+
+	// a1 goes over its min while the pods are queued, the order is kept
+	plugin.OnPodAdd(defaultCreatePodWithQuotaName("a1-running", "a1", 0, 90, 90))
+	assert.Equal(t, -1, plugin.CompareQueuedPods(podInfo1, podInfo2))
+
+	// the shares are refreshed once the pods are re-queued
+	podInfo1.Timestamp = now.Add(time.Second)
+	podInfo2.Timestamp = now.Add(time.Second)
+	assert.Equal(t, 1, plugin.CompareQueuedPods(podInfo1, podInfo2))
+
+	plugin.OnPodDelete(podInfo1.Pod)
+	assert.Nil(t, plugin.queuedShares.shares[podInfo1.Pod.UID])
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8sfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	koordutil "github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	QuotaUsageAccountingControllerName = "QuotaUsageAccountingController"
+
+	defaultUsageAccountingSampleInterval  = 15 * time.Second
+	defaultUsageAccountingPersistInterval = 5 * time.Minute
+
+	bytesPerGiB = float64(1 << 30)
+)
+
+// QuotaUsageAccountingController integrates the used and borrowed (used above min) resources of each quota over
+// time, so that the tenants can be charged by resource-hours. The cumulative amounts are persisted in the
+// annotation extension.AnnotationUsageAccounting of the ElasticQuota and exported as metrics.
+type QuotaUsageAccountingController struct {
+	plugin          *Plugin
+	sampleInterval  time.Duration
+	persistInterval time.Duration
+
+	lock     sync.Mutex
+	accounts map[string]*quotaUsageAccount
+}
+
+type quotaUsageAccount struct {
+	uid            types.UID
+	accounting     *extension.QuotaUsageAccounting
+	lastSampleTime time.Time
+	lastPersisted  time.Time
+	// used and borrowed are the last sampled resources, which are assumed to be held until the next sample.
+	used     corev1.ResourceList
+	borrowed corev1.ResourceList
+}
+
+func NewQuotaUsageAccountingController(plugin *Plugin) *QuotaUsageAccountingController {
+	return &QuotaUsageAccountingController{
+		plugin:          plugin,
+		sampleInterval:  defaultUsageAccountingSampleInterval,
+		persistInterval: defaultUsageAccountingPersistInterval,
+		accounts:        map[string]*quotaUsageAccount{},
+	}
+}
+
+func (ctrl *QuotaUsageAccountingController) Name() string {
+	return QuotaUsageAccountingControllerName
+}
+
+func (ctrl *QuotaUsageAccountingController) Start() {
+	if !k8sfeature.DefaultFeatureGate.Enabled(features.ElasticQuotaUsageAccounting) {
+		klog.Infof("feature %s is disabled, will not start elasticQuota %s", features.ElasticQuotaUsageAccounting, QuotaUsageAccountingControllerName)
+		return
+	}
+	go wait.Until(ctrl.syncUsageAccountingWorker, ctrl.sampleInterval, context.TODO().Done())
+	klog.Infof("start elasticQuota %s", QuotaUsageAccountingControllerName)
+}
+
+func (ctrl *QuotaUsageAccountingController) syncUsageAccountingWorker() {
+	elasticQuotas, err := ctrl.plugin.quotaLister.List(labels.Everything())
+	if err != nil {
+		klog.V(3).ErrorS(err, "Unable to list elastic quota in syncUsageAccountingWorker")
+		return
+	}
+	now := time.Now()
+	existing := make(map[string]struct{}, len(elasticQuotas))
+	for _, eq := range elasticQuotas {
+		existing[eq.Name] = struct{}{}
+		summary, _ := ctrl.plugin.GetQuotaSummary(eq.Name, false)
+		if summary == nil {
+			continue
+		}
+		accounting, needPersist := ctrl.sample(eq, summary, now)
+		syncElasticQuotaUsageAccountingMetrics(summary, accounting)
+		if needPersist {
+			ctrl.persist(eq, accounting, now)
+		}
+	}
+
+	ctrl.lock.Lock()
+	defer ctrl.lock.Unlock()
+	for name := range ctrl.accounts {
+		if _, ok := existing[name]; !ok {
+			delete(ctrl.accounts, name)
+		}
+	}
+}
+
+// sample accumulates the resources held by the quota since the last sample, and returns a copy of the accounting
+// and whether it should be persisted.
+func (ctrl *QuotaUsageAccountingController) sample(eq *v1alpha1.ElasticQuota, summary *core.QuotaInfoSummary, now time.Time) (*extension.QuotaUsageAccounting, bool) {
+	ctrl.lock.Lock()
+	defer ctrl.lock.Unlock()
+
+	account := ctrl.accounts[eq.Name]
+	if account == nil || account.uid != eq.UID {
+		// restore the persisted accounting, the usage between the last persistence and now is unknown and not counted
+		accounting, err := extension.GetUsageAccounting(eq)
+		if err != nil {
+			klog.ErrorS(err, "Failed to parse usage accounting of elasticQuota, reset it", "elasticQuota", eq.Name)
+		}
+		if accounting == nil {
+			accounting = &extension.QuotaUsageAccounting{StartTime: metav1.NewTime(now)}
+		}
+		account = &quotaUsageAccount{
+			uid:            eq.UID,
+			accounting:     accounting,
+			lastSampleTime: now,
+			lastPersisted:  now,
+		}
+		ctrl.accounts[eq.Name] = account
+	} else if now.After(account.lastSampleTime) {
+		hours := now.Sub(account.lastSampleTime).Hours()
+		account.accounting.Used = accumulateResourceHours(account.accounting.Used, account.used, hours)
+		account.accounting.Borrowed = accumulateResourceHours(account.accounting.Borrowed, account.borrowed, hours)
+		account.lastSampleTime = now
+	}
+	account.used = summary.Used.DeepCopy()
+	account.borrowed = borrowedResources(summary.Used, summary.Min)
+	account.accounting.LastUpdateTime = metav1.NewTime(now)
+
+	needPersist := now.Sub(account.lastPersisted) >= ctrl.persistInterval
+	if needPersist {
+		account.lastPersisted = now
+	}
+	return copyUsageAccounting(account.accounting), needPersist
+}
+
+func (ctrl *QuotaUsageAccountingController) persist(eq *v1alpha1.ElasticQuota, accounting *extension.QuotaUsageAccounting, now time.Time) {
+	data, err := json.Marshal(accounting)
+	if err != nil {
+		klog.ErrorS(err, "Failed to marshal usage accounting", "elasticQuota", eq.Name)
+		return
+	}
+	newEQ := eq.DeepCopy()
+	if newEQ.Annotations == nil {
+		newEQ.Annotations = map[string]string{}
+	}
+	newEQ.Annotations[extension.AnnotationUsageAccounting] = string(data)
+	patch, err := util.CreateMergePatch(eq, newEQ)
+	if err != nil {
+		klog.ErrorS(err, "Failed to create mergePatch", "elasticQuota", eq.Name)
+		return
+	}
+	err = koordutil.RetryOnConflictOrTooManyRequests(func() error {
+		_, patchErr := ctrl.plugin.client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).
+			Patch(context.TODO(), eq.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return patchErr
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to patch usage accounting of elasticQuota", "elasticQuota", eq.Name)
+		// retry in the next sample
+		ctrl.lock.Lock()
+		if account := ctrl.accounts[eq.Name]; account != nil {
+			account.lastPersisted = now.Add(-ctrl.persistInterval)
+		}
+		ctrl.lock.Unlock()
+		return
+	}
+	klog.V(5).InfoS("Successfully persist usage accounting of elasticQuota", "elasticQuota", eq.Name)
+}
+
+func syncElasticQuotaUsageAccountingMetrics(summary *core.QuotaInfoSummary, accounting *extension.QuotaUsageAccounting) {
+	quotaLabels := map[string]string{
+		"name":      summary.Name,
+		"tree":      summary.Tree,
+		"is_parent": strconv.FormatBool(summary.IsParent),
+		"parent":    summary.ParentName,
+	}
+	RecordElasticQuotaUsageAccountingMetric(accounting.Used, "used", quotaLabels)
+	RecordElasticQuotaUsageAccountingMetric(accounting.Borrowed, "borrowed", quotaLabels)
+}
+
+func borrowedResources(used, min corev1.ResourceList) corev1.ResourceList {
+	borrowed := corev1.ResourceList{}
+	for resourceName, quantity := range used {
+		q := quantity.DeepCopy()
+		if m, ok := min[resourceName]; ok {
+			q.Sub(m)
+		}
+		if q.Sign() > 0 {
+			borrowed[resourceName] = q
+		}
+	}
+	return borrowed
+}
+
+func accumulateResourceHours(amounts map[corev1.ResourceName]float64, resources corev1.ResourceList, hours float64) map[corev1.ResourceName]float64 {
+	for resourceName, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		if amounts == nil {
+			amounts = map[corev1.ResourceName]float64{}
+		}
+		amounts[resourceName] += resourceAmount(resourceName, quantity) * hours
+	}
+	return amounts
+}
+
+// resourceAmount converts the quantity into cores for cpu and batch-cpu, GiB for the memory and storage resources,
+// and the plain value for the others.
+func resourceAmount(resourceName corev1.ResourceName, quantity resource.Quantity) float64 {
+	name := string(resourceName)
+	switch {
+	case resourceName == corev1.ResourceCPU:
+		return float64(quantity.MilliValue()) / 1000
+	case resourceName == extension.BatchCPU:
+		// batch-cpu is in milli-cores
+		return float64(quantity.Value()) / 1000
+	case strings.HasSuffix(name, "memory"), strings.HasSuffix(name, "storage"), strings.HasPrefix(name, corev1.ResourceHugePagesPrefix):
+		return float64(quantity.Value()) / bytesPerGiB
+	default:
+		return float64(quantity.Value())
+	}
+}
+
+func copyUsageAccounting(accounting *extension.QuotaUsageAccounting) *extension.QuotaUsageAccounting {
+	c := &extension.QuotaUsageAccounting{
+		StartTime:      accounting.StartTime,
+		LastUpdateTime: accounting.LastUpdateTime,
+	}
+	if accounting.Used != nil {
+		c.Used = make(map[corev1.ResourceName]float64, len(accounting.Used))
+		for k, v := range accounting.Used {
+			c.Used[k] = v
+		}
+	}
+	if accounting.Borrowed != nil {
+		c.Borrowed = make(map[corev1.ResourceName]float64, len(accounting.Borrowed))
+		for k, v := range accounting.Borrowed {
+			c.Borrowed[k] = v
+		}
+	}
+	return c
+}
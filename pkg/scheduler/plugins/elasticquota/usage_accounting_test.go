@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+func Test_resourceAmount(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName corev1.ResourceName
+		quantity     resource.Quantity
+		want         float64
+	}{
+		{
+			name:         "cpu in cores",
+			resourceName: corev1.ResourceCPU,
+			quantity:     resource.MustParse("1500m"),
+			want:         1.5,
+		},
+		{
+			name:         "batch cpu in cores",
+			resourceName: extension.BatchCPU,
+			quantity:     resource.MustParse("2500"),
+			want:         2.5,
+		},
+		{
+			name:         "memory in GiB",
+			resourceName: corev1.ResourceMemory,
+			quantity:     resource.MustParse("512Mi"),
+			want:         0.5,
+		},
+		{
+			name:         "batch memory in GiB",
+			resourceName: extension.BatchMemory,
+			quantity:     resource.MustParse("2Gi"),
+			want:         2,
+		},
+		{
+			name:         "gpu in units",
+			resourceName: extension.ResourceNvidiaGPU,
+			quantity:     resource.MustParse("2"),
+			want:         2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resourceAmount(tt.resourceName, tt.quantity))
+		})
+	}
+}
+
+func TestQuotaUsageAccountingController_sample(t *testing.T) {
+	ctrl := NewQuotaUsageAccountingController(nil)
+	ctrl.persistInterval = time.Hour
+
+	eq := MakeEQ("test-ns", "test-eq").Obj()
+	eq.UID = "test-uid"
+	summary := &core.QuotaInfoSummary{
+		Name: "test-eq",
+		Min:  MakeResourceList().CPU(2).Mem(4 << 30).Obj(),
+		Used: MakeResourceList().CPU(3).Mem(2 << 30).Obj(),
+	}
+
+	now := time.Now()
+	accounting, needPersist := ctrl.sample(eq, summary, now)
+	assert.False(t, needPersist)
+	assert.Equal(t, metav1.NewTime(now), accounting.StartTime)
+	assert.Nil(t, accounting.Used)
+	assert.Nil(t, accounting.Borrowed)
+
+	// 3 cores and 2GiB are held for half an hour, 1 core is borrowed above min
+	summary.Used = MakeResourceList().CPU(1).Mem(2 << 30).Obj()
+	accounting, needPersist = ctrl.sample(eq, summary, now.Add(30*time.Minute))
+	assert.False(t, needPersist)
+	assert.Equal(t, map[corev1.ResourceName]float64{corev1.ResourceCPU: 1.5, corev1.ResourceMemory: 1}, accounting.Used)
+	assert.Equal(t, map[corev1.ResourceName]float64{corev1.ResourceCPU: 0.5}, accounting.Borrowed)
+
+	// 1 core and 2GiB are held for an hour
+	accounting, needPersist = ctrl.sample(eq, summary, now.Add(90*time.Minute))
+	assert.True(t, needPersist)
+	assert.Equal(t, map[corev1.ResourceName]float64{corev1.ResourceCPU: 2.5, corev1.ResourceMemory: 3}, accounting.Used)
+	assert.Equal(t, map[corev1.ResourceName]float64{corev1.ResourceCPU: 0.5}, accounting.Borrowed)
+	assert.Equal(t, metav1.NewTime(now.Add(90*time.Minute)), accounting.LastUpdateTime)
+
+	// the recreated quota starts a new accounting
+	eq.UID = "new-uid"
+	accounting, _ = ctrl.sample(eq, summary, now.Add(2*time.Hour))
+	assert.Nil(t, accounting.Used)
+	assert.Equal(t, metav1.NewTime(now.Add(2*time.Hour)), accounting.StartTime)
+}
+
+func TestQuotaUsageAccountingController_persist(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	eq := MakeEQ("test-ns", "test-eq").
+		Min(MakeResourceList().CPU(2).Mem(4 << 30).Obj()).
+		Max(MakeResourceList().CPU(10).Mem(20 << 30).Obj()).Obj()
+	_, err := suit.client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).Create(context.TODO(), eq, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	plugin, err := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
+	assert.NoError(t, err)
+
+	ctrl := NewQuotaUsageAccountingController(plugin.(*Plugin))
+	now := time.Now().Truncate(time.Second)
+	accounting := &extension.QuotaUsageAccounting{
+		StartTime:      metav1.NewTime(now.Add(-time.Hour)),
+		LastUpdateTime: metav1.NewTime(now),
+		Used:           map[corev1.ResourceName]float64{corev1.ResourceCPU: 4, corev1.ResourceMemory: 8},
+		Borrowed:       map[corev1.ResourceName]float64{corev1.ResourceCPU: 1},
+	}
+	ctrl.persist(eq, accounting, now)
+
+	got, err := suit.client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).Get(context.TODO(), eq.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	persisted, err := extension.GetUsageAccounting(got)
+	assert.NoError(t, err)
+	assert.Equal(t, accounting, persisted)
+
+	// the persisted accounting is restored after restarting
+	ctrl = NewQuotaUsageAccountingController(plugin.(*Plugin))
+	summary := &core.QuotaInfoSummary{
+		Name: "test-eq",
+		Min:  got.Spec.Min,
+		Used: MakeResourceList().CPU(1).Obj(),
+	}
+	restored, _ := ctrl.sample(got, summary, now.Add(time.Minute))
+	assert.Equal(t, accounting.StartTime, restored.StartTime)
+	assert.Equal(t, accounting.Used, restored.Used)
+	assert.Equal(t, accounting.Borrowed, restored.Borrowed)
+}
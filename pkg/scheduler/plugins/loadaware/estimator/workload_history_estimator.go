@@ -0,0 +1,284 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package estimator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+)
+
+const (
+	workloadHistoryEstimatorName = "workloadHistoryEstimator"
+
+	// workloadUsagePercentile is the percentile of the usages of the sibling Pods used as the estimation.
+	workloadUsagePercentile = 0.95
+	// minWorkloadUsageSamples is the minimum number of the sibling Pods to estimate by the workload history.
+	minWorkloadUsageSamples = 3
+)
+
+var _ cache.ResourceEventHandler = &WorkloadHistoryEstimator{}
+
+// WorkloadHistoryEstimator estimates the Pod by the recent usages of the Pods with the same controller owner,
+// which are reported in the NodeMetrics. It falls back to the DefaultEstimator if the workload is unknown or
+// has too few running Pods.
+type WorkloadHistoryEstimator struct {
+	defaultEstimator            Estimator
+	resourceWeights             map[corev1.ResourceName]int64
+	nodeMetricExpirationSeconds int64
+	podLister                   corelisters.PodLister
+	usageCache                  *workloadUsageCache
+}
+
+func NewWorkloadHistoryEstimator(args *config.LoadAwareSchedulingArgs, handle framework.Handle) (Estimator, error) {
+	extendedHandle, ok := handle.(frameworkext.ExtendedHandle)
+	if !ok {
+		return nil, fmt.Errorf("want handle to be of type frameworkext.ExtendedHandle, got %T", handle)
+	}
+	defaultEstimator, err := NewDefaultEstimator(args, handle)
+	if err != nil {
+		return nil, err
+	}
+	var nodeMetricExpirationSeconds int64
+	if args.NodeMetricExpirationSeconds != nil {
+		nodeMetricExpirationSeconds = *args.NodeMetricExpirationSeconds
+	}
+
+	estimator := &WorkloadHistoryEstimator{
+		defaultEstimator:            defaultEstimator,
+		resourceWeights:             args.ResourceWeights,
+		nodeMetricExpirationSeconds: nodeMetricExpirationSeconds,
+		podLister:                   handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		usageCache:                  newWorkloadUsageCache(),
+	}
+	koordSharedInformerFactory := extendedHandle.KoordinatorSharedInformerFactory()
+	nodeMetricInformer := koordSharedInformerFactory.Slo().V1alpha1().NodeMetrics().Informer()
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, nodeMetricInformer, estimator)
+	return estimator, nil
+}
+
+func (e *WorkloadHistoryEstimator) Name() string {
+	return workloadHistoryEstimatorName
+}
+
+func (e *WorkloadHistoryEstimator) EstimatePod(pod *corev1.Pod) (map[corev1.ResourceName]int64, error) {
+	estimatedUsed, err := e.defaultEstimator.EstimatePod(pod)
+	if err != nil {
+		return nil, err
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return estimatedUsed, nil
+	}
+	usages := e.usageCache.getUsages(owner.UID, e.nodeMetricExpirationSeconds)
+	if len(usages) < minWorkloadUsageSamples {
+		return estimatedUsed, nil
+	}
+
+	_, limits := resourceapi.PodRequestsAndLimits(pod)
+	priorityClass := extension.GetPodPriorityClassWithDefault(pod)
+	for resourceName := range e.resourceWeights {
+		values := make([]int64, 0, len(usages))
+		for _, usage := range usages {
+			quantity, ok := usage[resourceName]
+			if !ok {
+				continue
+			}
+			if resourceName == corev1.ResourceCPU {
+				values = append(values, quantity.MilliValue())
+			} else {
+				values = append(values, quantity.Value())
+			}
+		}
+		if len(values) < minWorkloadUsageSamples {
+			continue
+		}
+		estimated := percentile(values, workloadUsagePercentile)
+		realResourceName := extension.TranslateResourceNameByPriorityClass(priorityClass, resourceName)
+		if limitQuantity, ok := limits[realResourceName]; ok {
+			limit := limitQuantity.Value()
+			if realResourceName == corev1.ResourceCPU {
+				limit = limitQuantity.MilliValue()
+			}
+			if limit > 0 && estimated > limit {
+				estimated = limit
+			}
+		}
+		estimatedUsed[resourceName] = estimated
+	}
+	return estimatedUsed, nil
+}
+
+func (e *WorkloadHistoryEstimator) EstimateNode(node *corev1.Node) (corev1.ResourceList, error) {
+	return e.defaultEstimator.EstimateNode(node)
+}
+
+func (e *WorkloadHistoryEstimator) OnAdd(obj interface{}) {
+	nodeMetric, ok := obj.(*slov1alpha1.NodeMetric)
+	if !ok {
+		return
+	}
+	e.updateNodeMetric(nodeMetric)
+}
+
+func (e *WorkloadHistoryEstimator) OnUpdate(oldObj, newObj interface{}) {
+	nodeMetric, ok := newObj.(*slov1alpha1.NodeMetric)
+	if !ok {
+		return
+	}
+	e.updateNodeMetric(nodeMetric)
+}
+
+func (e *WorkloadHistoryEstimator) OnDelete(obj interface{}) {
+	var nodeMetric *slov1alpha1.NodeMetric
+	switch t := obj.(type) {
+	case *slov1alpha1.NodeMetric:
+		nodeMetric = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		nodeMetric, ok = t.Obj.(*slov1alpha1.NodeMetric)
+		if !ok {
+			return
+		}
+	default:
+		return
+	}
+	e.usageCache.deleteNode(nodeMetric.Name)
+}
+
+func (e *WorkloadHistoryEstimator) updateNodeMetric(nodeMetric *slov1alpha1.NodeMetric) {
+	if nodeMetric.Status.UpdateTime == nil {
+		return
+	}
+	workloadUsages := map[types.UID][]corev1.ResourceList{}
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		if podMetric == nil || len(podMetric.PodUsage.ResourceList) == 0 {
+			continue
+		}
+		pod, err := e.podLister.Pods(podMetric.Namespace).Get(podMetric.Name)
+		if err != nil {
+			continue
+		}
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil {
+			continue
+		}
+		workloadUsages[owner.UID] = append(workloadUsages[owner.UID], podMetric.PodUsage.ResourceList)
+	}
+	e.usageCache.updateNode(nodeMetric.Name, nodeMetric.Status.UpdateTime.Time, workloadUsages)
+}
+
+// percentile returns the value at the percentile of the values, the values will be sorted.
+func percentile(values []int64, p float64) int64 {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	index := int(math.Ceil(p*float64(len(values)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return values[index]
+}
+
+// workloadUsageCache stores the usages of the Pods reported in the NodeMetrics, indexed by the controller UID.
+type workloadUsageCache struct {
+	lock sync.RWMutex
+	// workloadUsages stores the usages of the workload on each node.
+	workloadUsages map[types.UID]map[string]*nodeWorkloadUsages
+	// nodeWorkloads stores the workloads on each node to clean up the stale usages.
+	nodeWorkloads map[string][]types.UID
+}
+
+type nodeWorkloadUsages struct {
+	updateTime time.Time
+	usages     []corev1.ResourceList
+}
+
+func newWorkloadUsageCache() *workloadUsageCache {
+	return &workloadUsageCache{
+		workloadUsages: map[types.UID]map[string]*nodeWorkloadUsages{},
+		nodeWorkloads:  map[string][]types.UID{},
+	}
+}
+
+func (c *workloadUsageCache) updateNode(nodeName string, updateTime time.Time, workloadUsages map[types.UID][]corev1.ResourceList) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.deleteNodeLocked(nodeName)
+	workloads := make([]types.UID, 0, len(workloadUsages))
+	for workload, usages := range workloadUsages {
+		m := c.workloadUsages[workload]
+		if m == nil {
+			m = map[string]*nodeWorkloadUsages{}
+			c.workloadUsages[workload] = m
+		}
+		m[nodeName] = &nodeWorkloadUsages{
+			updateTime: updateTime,
+			usages:     usages,
+		}
+		workloads = append(workloads, workload)
+	}
+	if len(workloads) > 0 {
+		c.nodeWorkloads[nodeName] = workloads
+	}
+}
+
+func (c *workloadUsageCache) deleteNode(nodeName string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.deleteNodeLocked(nodeName)
+}
+
+func (c *workloadUsageCache) deleteNodeLocked(nodeName string) {
+	for _, workload := range c.nodeWorkloads[nodeName] {
+		delete(c.workloadUsages[workload], nodeName)
+		if len(c.workloadUsages[workload]) == 0 {
+			delete(c.workloadUsages, workload)
+		}
+	}
+	delete(c.nodeWorkloads, nodeName)
+}
+
+func (c *workloadUsageCache) getUsages(workload types.UID, expirationSeconds int64) []corev1.ResourceList {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var usages []corev1.ResourceList
+	for _, nodeUsages := range c.workloadUsages[workload] {
+		if expirationSeconds > 0 && time.Since(nodeUsages.updateTime) >= time.Duration(expirationSeconds)*time.Second {
+			continue
+		}
+		usages = append(usages, nodeUsages.usages...)
+	}
+	return usages
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package estimator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+)
+
+func TestWorkloadHistoryEstimatorEstimatePod(t *testing.T) {
+	var v1beta2args v1beta2.LoadAwareSchedulingArgs
+	v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+	var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+	err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+	defaultEstimator, err := NewDefaultEstimator(&loadAwareSchedulingArgs, nil)
+	assert.NoError(t, err)
+
+	newOwnerRef := func(uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: string(uid), UID: uid, Controller: pointer.Bool(true)},
+		}
+	}
+	newPod := func(name string, owner types.UID) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "main",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("4"),
+								corev1.ResourceMemory: resource.MustParse("8Gi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("4"),
+								corev1.ResourceMemory: resource.MustParse("8Gi"),
+							},
+						},
+					},
+				},
+			},
+		}
+		if owner != "" {
+			pod.OwnerReferences = newOwnerRef(owner)
+		}
+		return pod
+	}
+	newPodMetric := func(name, cpu, memory string) *slov1alpha1.PodMetricInfo {
+		return &slov1alpha1.PodMetricInfo{
+			Namespace: "default",
+			Name:      name,
+			PodUsage: slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, podIndexer.Add(newPod(fmt.Sprintf("web-%d", i), "web")))
+	}
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, podIndexer.Add(newPod(fmt.Sprintf("small-%d", i), "small")))
+	}
+	assert.NoError(t, podIndexer.Add(newPod("stale-0", "stale")))
+	assert.NoError(t, podIndexer.Add(newPod("huge-0", "huge")))
+
+	estimator := &WorkloadHistoryEstimator{
+		defaultEstimator:            defaultEstimator,
+		resourceWeights:             loadAwareSchedulingArgs.ResourceWeights,
+		nodeMetricExpirationSeconds: *loadAwareSchedulingArgs.NodeMetricExpirationSeconds,
+		podLister:                   corelisters.NewPodLister(podIndexer),
+		usageCache:                  newWorkloadUsageCache(),
+	}
+	now := metav1.Now()
+	expired := metav1.NewTime(now.Add(-time.Hour))
+	estimator.OnAdd(&slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &now,
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				newPodMetric("web-0", "1", "2Gi"),
+				newPodMetric("web-1", "2", "3Gi"),
+				newPodMetric("small-0", "1", "1Gi"),
+				newPodMetric("unknown-0", "1", "1Gi"),
+			},
+		},
+	})
+	estimator.OnAdd(&slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &now,
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				newPodMetric("web-2", "1500m", "4Gi"),
+				newPodMetric("web-3", "500m", "1Gi"),
+				newPodMetric("small-1", "1", "1Gi"),
+			},
+		},
+	})
+	estimator.OnAdd(&slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-3"},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &expired,
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				newPodMetric("stale-0", "1", "1Gi"),
+				newPodMetric("stale-0", "1", "1Gi"),
+				newPodMetric("stale-0", "1", "1Gi"),
+			},
+		},
+	})
+	estimator.OnAdd(&slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-4"},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &now,
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				newPodMetric("huge-0", "8", "16Gi"),
+				newPodMetric("huge-0", "8", "16Gi"),
+				newPodMetric("huge-0", "8", "16Gi"),
+			},
+		},
+	})
+
+	defaultEstimated := map[corev1.ResourceName]int64{
+		corev1.ResourceCPU:    3400,
+		corev1.ResourceMemory: 6012954214, // 5.6Gi
+	}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want map[corev1.ResourceName]int64
+	}{
+		{
+			name: "pod without controller",
+			pod:  newPod("standalone", ""),
+			want: defaultEstimated,
+		},
+		{
+			name: "pod of unknown workload",
+			pod:  newPod("unknown", "unknown"),
+			want: defaultEstimated,
+		},
+		{
+			name: "workload with too few pods",
+			pod:  newPod("small-2", "small"),
+			want: defaultEstimated,
+		},
+		{
+			name: "workload with expired node metrics",
+			pod:  newPod("stale-1", "stale"),
+			want: defaultEstimated,
+		},
+		{
+			name: "estimate by the percentile of the workload",
+			pod:  newPod("web-4", "web"),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    2000,
+				corev1.ResourceMemory: 4 * 1024 * 1024 * 1024,
+			},
+		},
+		{
+			name: "estimated is limited by the pod limits",
+			pod:  newPod("huge-1", "huge"),
+			want: map[corev1.ResourceName]int64{
+				corev1.ResourceCPU:    4000,
+				corev1.ResourceMemory: 8 * 1024 * 1024 * 1024,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := estimator.EstimatePod(tt.pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	estimator.OnDelete(&slov1alpha1.NodeMetric{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}})
+	got, err := estimator.EstimatePod(newPod("web-4", "web"))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultEstimated, got)
+	assert.NotContains(t, estimator.usageCache.nodeWorkloads, "node-2")
+	assert.NotContains(t, estimator.usageCache.workloadUsages[types.UID("small")], "node-2")
+}
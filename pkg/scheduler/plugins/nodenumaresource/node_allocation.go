@@ -55,6 +55,27 @@ func NewNodeAllocation(nodeName string) *NodeAllocation {
 	}
 }
 
+func (n *NodeAllocation) clone() *NodeAllocation {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	allocation := &NodeAllocation{
+		nodeName:           n.nodeName,
+		allocatedPods:      make(map[types.UID]PodAllocation, len(n.allocatedPods)),
+		allocatedCPUs:      n.allocatedCPUs.Clone(),
+		allocatedResources: make(map[int]*NUMANodeResource, len(n.allocatedResources)),
+	}
+	for uid, podAllocation := range n.allocatedPods {
+		allocation.allocatedPods[uid] = podAllocation
+	}
+	for numaNode, res := range n.allocatedResources {
+		allocation.allocatedResources[numaNode] = &NUMANodeResource{
+			Node:      res.Node,
+			Resources: res.Resources.DeepCopy(),
+		}
+	}
+	return allocation
+}
+
 func (n *NodeAllocation) update(allocation *PodAllocation, cpuTopology *CPUTopology) {
 	n.release(allocation.UID)
 	n.addPodAllocation(allocation, cpuTopology)
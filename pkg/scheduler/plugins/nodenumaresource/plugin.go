@@ -66,6 +66,7 @@ var (
 
 	_ frameworkext.ReservationRestorePlugin    = &Plugin{}
 	_ frameworkext.ReservationPreBindPlugin    = &Plugin{}
+	_ frameworkext.SimulateAllocationPlugin    = &Plugin{}
 	_ topologymanager.NUMATopologyHintProvider = &Plugin{}
 )
 
@@ -174,6 +175,28 @@ func (p *Plugin) GetTopologyOptionsManager() TopologyOptionsManager {
 	return p.topologyOptionsManager
 }
 
+// getResourceManager returns the fork of the resourceManager in the scheduling simulation, otherwise the resourceManager.
+func (p *Plugin) getResourceManager(cycleState *framework.CycleState) ResourceManager {
+	if manager := p.getSimulationResourceManager(cycleState); manager != nil {
+		return manager
+	}
+	return p.resourceManager
+}
+
+func (p *Plugin) getSimulationResourceManager(cycleState *framework.CycleState) *resourceManager {
+	manager, ok := p.resourceManager.(*resourceManager)
+	if !ok {
+		return nil
+	}
+	forked := frameworkext.GetOrCreateSimulationState(cycleState, Name, func() interface{} {
+		return manager.fork()
+	})
+	if forked == nil {
+		return nil
+	}
+	return forked.(*resourceManager)
+}
+
 type preFilterState struct {
 	skip                        bool
 	requestCPUBind              bool
@@ -279,7 +302,7 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 		return status
 	}
 
-	if status := p.filterAmplifiedCPUs(state, nodeInfo); !status.IsSuccess() {
+	if status := p.filterAmplifiedCPUs(cycleState, state, nodeInfo); !status.IsSuccess() {
 		return status
 	}
 
@@ -322,7 +345,7 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 			if err != nil {
 				return framework.AsStatus(err)
 			}
-			_, err = p.resourceManager.Allocate(node, pod, resourceOptions)
+			_, err = p.getResourceManager(cycleState).Allocate(node, pod, resourceOptions)
 			if err != nil {
 				return framework.NewStatus(framework.Unschedulable, err.Error())
 			}
@@ -336,7 +359,7 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	return nil
 }
 
-func (p *Plugin) filterAmplifiedCPUs(state *preFilterState, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) filterAmplifiedCPUs(cycleState *framework.CycleState, state *preFilterState, nodeInfo *framework.NodeInfo) *framework.Status {
 	quantity := state.requests[corev1.ResourceCPU]
 	podRequestMilliCPU := quantity.MilliValue()
 	if podRequestMilliCPU == 0 {
@@ -357,7 +380,7 @@ func (p *Plugin) filterAmplifiedCPUs(state *preFilterState, nodeInfo *framework.
 	}
 
 	// TODO(joseph): Reservations and preemption should be considered here.
-	_, allocated, _ := p.resourceManager.GetAvailableCPUs(node.Name, cpuset.CPUSet{})
+	_, allocated, _ := p.getResourceManager(cycleState).GetAvailableCPUs(node.Name, cpuset.CPUSet{})
 	if err != nil {
 		if err.Error() != ErrNotFoundCPUTopology {
 			return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
@@ -380,25 +403,51 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	if !status.IsSuccess() {
 		return status
 	}
+	result, status := p.allocate(cycleState, state, pod, nodeName)
+	if !status.IsSuccess() || result == nil {
+		return status
+	}
+	p.resourceManager.Update(nodeName, result)
+	state.allocation = result
+	return nil
+}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+// SimulateAllocation returns the CPUs and NUMA resources would be allocated to the Pod, and records the allocation
+// on the fork of the resourceManager for the simulation instead of the resourceManager of the scheduler.
+func (p *Plugin) SimulateAllocation(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (interface{}, *framework.Status) {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return nil, status
+	}
+	result, status := p.allocate(cycleState, state, pod, nodeName)
+	if !status.IsSuccess() || result == nil {
+		return nil, status
+	}
+	if manager := p.getSimulationResourceManager(cycleState); manager != nil {
+		manager.Update(nodeName, result)
+	}
+	return result, nil
+}
+
+func (p *Plugin) allocate(cycleState *framework.CycleState, state *preFilterState, pod *corev1.Pod, nodeName string) (*PodAllocation, *framework.Status) {
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
-		return framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
+		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 	node := nodeInfo.Node()
 	topologyOptions := p.topologyOptionsManager.GetTopologyOptions(node.Name)
 	numaTopologyPolicy := getNUMATopologyPolicy(node.Labels, topologyOptions.NUMATopologyPolicy)
 
 	if skipTheNode(state, numaTopologyPolicy) {
-		return nil
+		return nil, nil
 	}
 
 	if state.requestCPUBind {
 		if topologyOptions.CPUTopology == nil {
-			return framework.NewStatus(framework.Error, ErrNotFoundCPUTopology)
+			return nil, framework.NewStatus(framework.Error, ErrNotFoundCPUTopology)
 		}
 		if !topologyOptions.CPUTopology.IsValid() {
-			return framework.NewStatus(framework.Error, ErrInvalidCPUTopology)
+			return nil, framework.NewStatus(framework.Error, ErrInvalidCPUTopology)
 		}
 	}
 
@@ -406,15 +455,13 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	affinity := store.GetAffinity(nodeName)
 	resourceOptions, err := p.getResourceOptions(cycleState, state, node, pod, affinity, topologyOptions)
 	if err != nil {
-		return framework.AsStatus(err)
+		return nil, framework.AsStatus(err)
 	}
-	result, err := p.resourceManager.Allocate(node, pod, resourceOptions)
+	result, err := p.getResourceManager(cycleState).Allocate(node, pod, resourceOptions)
 	if err != nil {
-		return framework.AsStatus(err)
+		return nil, framework.AsStatus(err)
 	}
-	p.resourceManager.Update(nodeName, result)
-	state.allocation = result
-	return nil
+	return result, nil
 }
 
 func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) {
@@ -518,12 +565,12 @@ func (p *Plugin) getReservationReservedCPUs(cycleState *framework.CycleState, po
 	if reservationutil.IsReservePod(pod) {
 		return result, nil
 	}
-	nominatedReservation := p.handle.GetReservationNominator().GetNominatedReservation(pod, nodeName)
+	nominatedReservation := frameworkext.GetNominatedReservation(p.handle, cycleState, pod, nodeName)
 	if nominatedReservation == nil {
 		return result, nil
 	}
 
-	allocatedCPUs, _ := p.resourceManager.GetAllocatedCPUSet(nodeName, nominatedReservation.UID())
+	allocatedCPUs, _ := p.getResourceManager(cycleState).GetAllocatedCPUSet(nodeName, nominatedReservation.UID())
 	if allocatedCPUs.IsEmpty() {
 		return result, nil
 	}
@@ -1173,6 +1173,50 @@ func TestPlugin_Reserve(t *testing.T) {
 	}
 }
 
+func TestPlugin_SimulateAllocation(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("96"),
+					corev1.ResourceMemory: resource.MustParse("512Gi"),
+				},
+			},
+		},
+	}
+	suit := newPluginTestSuit(t, nil, nodes)
+	p, err := suit.proxyNew(suit.nodeNUMAResourceArgs, suit.Handle)
+	assert.NoError(t, err)
+	plg := p.(*Plugin)
+	cpuTopology := buildCPUTopologyForTest(2, 1, 4, 2)
+	plg.topologyOptionsManager.UpdateTopologyOptions("test-node-1", func(options *TopologyOptions) {
+		options.CPUTopology = cpuTopology
+	})
+	suit.start()
+
+	cycleState := framework.NewCycleState()
+	cycleState.Write(stateKey, &preFilterState{
+		requestCPUBind:         true,
+		numCPUsNeeded:          4,
+		preferredCPUBindPolicy: schedulingconfig.CPUBindPolicyFullPCPUs,
+	})
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: uuid.NewUUID()}}
+	for i := 0; i < 2; i++ {
+		allocation, status := plg.SimulateAllocation(context.TODO(), cycleState, pod, "test-node-1")
+		assert.True(t, status.IsSuccess())
+		assert.True(t, cpuset.NewCPUSet(0, 1, 2, 3).Equals(allocation.(*PodAllocation).CPUSet))
+	}
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.Nil(t, state.allocation)
+	availableCPUs, _, err := plg.resourceManager.GetAvailableCPUs("test-node-1", cpuset.CPUSet{})
+	assert.NoError(t, err)
+	assert.Equal(t, cpuTopology.CPUDetails.CPUs().Size(), availableCPUs.Size())
+}
+
 func TestPlugin_Unreserve(t *testing.T) {
 	state := &preFilterState{
 		requestCPUBind: true,
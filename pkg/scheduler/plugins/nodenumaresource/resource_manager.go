@@ -69,6 +69,8 @@ type resourceManager struct {
 	topologyOptionsManager TopologyOptionsManager
 	lock                   sync.Mutex
 	nodeAllocations        map[string]*NodeAllocation
+	// base is the resourceManager forked from, whose NodeAllocations are copied at the first access.
+	base *resourceManager
 }
 
 func NewResourceManager(
@@ -113,12 +115,26 @@ func (c *resourceManager) getOrCreateNodeAllocation(nodeName string) *NodeAlloca
 	defer c.lock.Unlock()
 	v := c.nodeAllocations[nodeName]
 	if v == nil {
-		v = NewNodeAllocation(nodeName)
+		if c.base != nil {
+			v = c.base.getOrCreateNodeAllocation(nodeName).clone()
+		} else {
+			v = NewNodeAllocation(nodeName)
+		}
 		c.nodeAllocations[nodeName] = v
 	}
 	return v
 }
 
+// fork returns a resourceManager starting with the allocations of c, the allocations updated on it never reach c.
+func (c *resourceManager) fork() *resourceManager {
+	return &resourceManager{
+		numaAllocateStrategy:   c.numaAllocateStrategy,
+		topologyOptionsManager: c.topologyOptionsManager,
+		nodeAllocations:        map[string]*NodeAllocation{},
+		base:                   c,
+	}
+}
+
 func (c *resourceManager) GetTopologyHints(node *corev1.Node, pod *corev1.Pod, options *ResourceOptions) (map[string][]topologymanager.NUMATopologyHint, error) {
 	topologyOptions := options.topologyOptions
 	if len(topologyOptions.NUMANodeResources) == 0 {
@@ -1025,3 +1025,34 @@ func TestResourceManagerGetTopologyHint(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceManagerFork(t *testing.T) {
+	cpuTopology := buildCPUTopologyForTest(2, 1, 4, 2)
+	topologyOptionsManager := NewTopologyOptionsManager()
+	topologyOptionsManager.UpdateTopologyOptions("test-node-1", func(options *TopologyOptions) {
+		options.CPUTopology = cpuTopology
+	})
+	manager := &resourceManager{
+		topologyOptionsManager: topologyOptionsManager,
+		nodeAllocations:        map[string]*NodeAllocation{},
+	}
+	manager.Update("test-node-1", &PodAllocation{
+		UID:                "pod-1",
+		CPUSet:             cpuset.NewCPUSet(0, 1),
+		CPUExclusivePolicy: schedulingconfig.CPUExclusivePolicyNone,
+	})
+
+	forked := manager.fork()
+	forked.Update("test-node-1", &PodAllocation{
+		UID:                "pod-2",
+		CPUSet:             cpuset.NewCPUSet(2, 3),
+		CPUExclusivePolicy: schedulingconfig.CPUExclusivePolicyNone,
+	})
+	availableCPUs, _, err := forked.GetAvailableCPUs("test-node-1", cpuset.CPUSet{})
+	assert.NoError(t, err)
+	assert.Equal(t, cpuTopology.CPUDetails.CPUs().Difference(cpuset.NewCPUSet(0, 1, 2, 3)).ToSlice(), availableCPUs.ToSlice())
+
+	availableCPUs, _, err = manager.GetAvailableCPUs("test-node-1", cpuset.CPUSet{})
+	assert.NoError(t, err)
+	assert.Equal(t, cpuTopology.CPUDetails.CPUs().Difference(cpuset.NewCPUSet(0, 1)).ToSlice(), availableCPUs.ToSlice(), "the allocations on the fork should not reach the base")
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/topologymanager"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
@@ -58,7 +59,7 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 		return 0, status
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
@@ -83,12 +84,12 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 	if err != nil {
 		return 0, nil
 	}
-	podAllocation, err := p.resourceManager.Allocate(node, pod, resourceOptions)
+	podAllocation, err := p.getResourceManager(cycleState).Allocate(node, pod, resourceOptions)
 	if err != nil {
 		return 0, nil
 	}
 
-	allocatable, requested := p.calculateAllocatableAndRequested(node.Name, nodeInfo, podAllocation, resourceOptions)
+	allocatable, requested := p.calculateAllocatableAndRequested(cycleState, node.Name, nodeInfo, podAllocation, resourceOptions)
 	return p.scorer.score(requested, allocatable, framework.NewResource(resourceOptions.requests))
 }
 
@@ -105,7 +106,7 @@ func (p *Plugin) scoreWithAmplifiedCPUs(cycleState *framework.CycleState, state
 		return p.scorer.score(nodeInfo.Requested, nodeInfo.Allocatable, framework.NewResource(resourceOptions.requests))
 	}
 
-	_, allocated, err := p.resourceManager.GetAvailableCPUs(node.Name, resourceOptions.preferredCPUs)
+	_, allocated, err := p.getResourceManager(cycleState).GetAvailableCPUs(node.Name, resourceOptions.preferredCPUs)
 	if err != nil {
 		if err.Error() != ErrNotFoundCPUTopology {
 			return 0, nil
@@ -120,12 +121,13 @@ func (p *Plugin) scoreWithAmplifiedCPUs(cycleState *framework.CycleState, state
 }
 
 func (p *Plugin) calculateAllocatableAndRequested(
+	cycleState *framework.CycleState,
 	nodeName string,
 	nodeInfo *framework.NodeInfo,
 	podAllocation *PodAllocation,
 	resourceOptions *ResourceOptions,
 ) (allocatable, requested *framework.Resource) {
-	nodeAllocation := p.resourceManager.GetNodeAllocation(nodeName)
+	nodeAllocation := p.getResourceManager(cycleState).GetNodeAllocation(nodeName)
 	nodeAllocation.lock.RLock()
 	defer nodeAllocation.lock.RUnlock()
 
@@ -43,7 +43,7 @@ func (p *Plugin) GetPodTopologyHints(ctx context.Context, cycleState *framework.
 	if !status.IsSuccess() {
 		return nil, status
 	}
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return nil, framework.AsStatus(err)
 	}
@@ -55,7 +55,7 @@ func (p *Plugin) GetPodTopologyHints(ctx context.Context, cycleState *framework.
 		return nil, framework.AsStatus(err)
 	}
 	resourceOptions.numaScorer = p.numaScorer
-	hints, err := p.resourceManager.GetTopologyHints(node, pod, resourceOptions)
+	hints, err := p.getResourceManager(cycleState).GetTopologyHints(node, pod, resourceOptions)
 	if err != nil {
 		return nil, framework.NewStatus(framework.Unschedulable, "node(s) Insufficient NUMA Node resources")
 	}
@@ -69,7 +69,7 @@ func (p *Plugin) Allocate(ctx context.Context, cycleState *framework.CycleState,
 		return status
 	}
 
-	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(p.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return framework.AsStatus(err)
 	}
@@ -79,7 +79,7 @@ func (p *Plugin) Allocate(ctx context.Context, cycleState *framework.CycleState,
 	if err != nil {
 		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
 	}
-	_, err = p.resourceManager.Allocate(node, pod, resourceOptions)
+	_, err = p.getResourceManager(cycleState).Allocate(node, pod, resourceOptions)
 	if err != nil {
 		return framework.NewStatus(framework.Unschedulable, err.Error())
 	}
@@ -184,7 +184,7 @@ func (c *Controller) sync(reservationName string) (result, error) {
 
 	reservation = reservation.DeepCopy()
 
-	if isReservationNeedExpiration(reservation) {
+	if isReservationNeedExpiration(reservation) || c.isReservationGroupExpired(reservation) {
 		return result{}, c.expireReservation(reservation)
 	}
 
@@ -202,6 +202,9 @@ func (c *Controller) sync(reservationName string) (result, error) {
 func (c *Controller) expireReservation(reservation *schedulingv1alpha1.Reservation) error {
 	reservationutil.SetReservationExpired(reservation)
 	_, err := c.koordClientSet.SchedulingV1alpha1().Reservations().UpdateStatus(context.TODO(), reservation, metav1.UpdateOptions{})
+	if err == nil {
+		c.enqueueReservationGroup(reservation)
+	}
 	return err
 }
 
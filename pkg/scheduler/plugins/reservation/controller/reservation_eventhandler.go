@@ -18,6 +18,7 @@ package controller
 
 import (
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
 func (c *Controller) onReservationAdd(obj interface{}) {
@@ -34,6 +35,11 @@ func (c *Controller) onReservationUpdate(oldObj, newObj interface{}) {
 		if oldReservation.Generation != newReservation.Generation {
 			c.queue.Add(newReservation.Name)
 		}
+		// the member of a reservation group may fail without expiration, e.g. preempted, and the status update does
+		// not change the generation
+		if !reservationutil.IsReservationFailed(oldReservation) && reservationutil.IsReservationFailed(newReservation) {
+			c.enqueueReservationGroup(newReservation)
+		}
 	}
 }
 
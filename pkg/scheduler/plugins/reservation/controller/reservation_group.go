@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+// isReservationGroupExpired checks if any other member of the reservation group has failed since the reservation
+// was created, so that all the members of a group expire together.
+func (c *Controller) isReservationGroupExpired(r *schedulingv1alpha1.Reservation) bool {
+	group := reservationutil.GetReservationGroup(r)
+	if group == "" {
+		return false
+	}
+	for _, member := range c.listReservationGroupMembers(group) {
+		if member.UID == r.UID || !reservationutil.IsReservationFailed(member) {
+			continue
+		}
+		if failedTime := getReservationFailedTime(member); !failedTime.Before(&r.CreationTimestamp) {
+			klog.V(4).InfoS("Reservation group member has failed, expire the reservation",
+				"reservation", klog.KObj(r), "group", group, "member", klog.KObj(member))
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueReservationGroup enqueues the other active members of the reservation group to sync their expiration.
+func (c *Controller) enqueueReservationGroup(r *schedulingv1alpha1.Reservation) {
+	group := reservationutil.GetReservationGroup(r)
+	if group == "" {
+		return
+	}
+	for _, member := range c.listReservationGroupMembers(group) {
+		if member.UID == r.UID || reservationutil.IsReservationFailed(member) || reservationutil.IsReservationSucceeded(member) {
+			continue
+		}
+		c.queue.Add(member.Name)
+	}
+}
+
+func (c *Controller) listReservationGroupMembers(group string) []*schedulingv1alpha1.Reservation {
+	reservations, err := c.reservationLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list reservations", "group", group)
+		return nil
+	}
+	var members []*schedulingv1alpha1.Reservation
+	for _, r := range reservations {
+		if reservationutil.GetReservationGroup(r) == group {
+			members = append(members, r)
+		}
+	}
+	return members
+}
+
+func getReservationFailedTime(r *schedulingv1alpha1.Reservation) *metav1.Time {
+	for i := range r.Status.Conditions {
+		if r.Status.Conditions[i].Type == schedulingv1alpha1.ReservationConditionReady {
+			return &r.Status.Conditions[i].LastProbeTime
+		}
+	}
+	return &r.CreationTimestamp
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func newTestGroupReservation(name, gangName string, creationTime time.Time, ttl time.Duration) *schedulingv1alpha1.Reservation {
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:               uuid.NewUUID(),
+			Name:              name,
+			CreationTimestamp: metav1.Time{Time: creationTime},
+			Annotations: map[string]string{
+				apiext.AnnotationGangName:   gangName,
+				apiext.AnnotationGangMinNum: "2",
+			},
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			TTL: &metav1.Duration{Duration: ttl},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase: schedulingv1alpha1.ReservationPending,
+		},
+	}
+}
+
+func TestExpireReservationGroup(t *testing.T) {
+	fakeClientSet := kubefake.NewSimpleClientset()
+	fakeKoordClientSet := koordfake.NewSimpleClientset()
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClientSet, 0)
+	koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(fakeKoordClientSet, 0)
+
+	now := time.Now()
+	// memberA expires by its TTL and memberB should expire together
+	memberA := newTestGroupReservation("member-a", "gang-a", now.Add(-5*time.Minute), time.Minute)
+	memberB := newTestGroupReservation("member-b", "gang-a", now.Add(-5*time.Minute), time.Hour)
+	// the failed member of an older group should not expire the new members
+	staleMember := newTestGroupReservation("stale-member", "gang-b", now.Add(-2*time.Hour), time.Minute)
+	reservationutil.SetReservationExpired(staleMember)
+	staleMember.Status.Conditions[0].LastProbeTime = metav1.Time{Time: now.Add(-time.Hour)}
+	newMember := newTestGroupReservation("new-member", "gang-b", now.Add(-time.Minute), time.Hour)
+	otherGroupMember := newTestGroupReservation("other-group-member", "gang-c", now.Add(-5*time.Minute), time.Hour)
+
+	reservations := []*schedulingv1alpha1.Reservation{memberA, memberB, staleMember, newMember, otherGroupMember}
+	for _, v := range reservations {
+		_, err := fakeKoordClientSet.SchedulingV1alpha1().Reservations().Create(context.TODO(), v, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	controller := New(sharedInformerFactory, koordSharedInformerFactory, fakeKoordClientSet, 0)
+	sharedInformerFactory.Start(nil)
+	koordSharedInformerFactory.Start(nil)
+	sharedInformerFactory.WaitForCacheSync(nil)
+	koordSharedInformerFactory.WaitForCacheSync(nil)
+
+	_, err := controller.sync(memberA.Name)
+	assert.NoError(t, err)
+	got, err := fakeKoordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), memberA.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, reservationutil.IsReservationExpired(got))
+	// the other members of the group are enqueued
+	assert.Equal(t, 1, controller.queue.Len())
+	item, _ := controller.queue.Get()
+	assert.Equal(t, memberB.Name, item)
+	controller.queue.Done(item)
+
+	// wait for the lister to observe the expired member
+	assert.Eventually(t, func() bool {
+		r, err := controller.reservationLister.Get(memberA.Name)
+		return err == nil && reservationutil.IsReservationFailed(r)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, v := range []*schedulingv1alpha1.Reservation{memberB, newMember, otherGroupMember} {
+		_, err = controller.sync(v.Name)
+		assert.NoError(t, err)
+	}
+	got, err = fakeKoordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), memberB.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, reservationutil.IsReservationExpired(got))
+	got, err = fakeKoordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), newMember.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, reservationutil.IsReservationFailed(got))
+	got, err = fakeKoordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), otherGroupMember.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, reservationutil.IsReservationFailed(got))
+}
+
+func TestEnqueueReservationGroupOnMemberFailed(t *testing.T) {
+	fakeClientSet := kubefake.NewSimpleClientset()
+	fakeKoordClientSet := koordfake.NewSimpleClientset()
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClientSet, 0)
+	koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(fakeKoordClientSet, 0)
+
+	now := time.Now()
+	memberA := newTestGroupReservation("member-a", "gang-a", now, time.Hour)
+	memberB := newTestGroupReservation("member-b", "gang-a", now, time.Hour)
+	otherGroupMember := newTestGroupReservation("other-group-member", "gang-b", now, time.Hour)
+	for _, v := range []*schedulingv1alpha1.Reservation{memberA, memberB, otherGroupMember} {
+		_, err := fakeKoordClientSet.SchedulingV1alpha1().Reservations().Create(context.TODO(), v, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	controller := New(sharedInformerFactory, koordSharedInformerFactory, fakeKoordClientSet, 0)
+	sharedInformerFactory.Start(nil)
+	koordSharedInformerFactory.Start(nil)
+	sharedInformerFactory.WaitForCacheSync(nil)
+	koordSharedInformerFactory.WaitForCacheSync(nil)
+	for controller.queue.Len() > 0 {
+		item, _ := controller.queue.Get()
+		controller.queue.Done(item)
+		controller.queue.Forget(item)
+	}
+
+	// the status update without a generation change does not enqueue the group
+	updated := memberA.DeepCopy()
+	updated.Status.NodeName = "test-node"
+	controller.onReservationUpdate(memberA, updated)
+	assert.Equal(t, 0, controller.queue.Len())
+
+	// memberA fails without expiration, e.g. preempted
+	failed := updated.DeepCopy()
+	failed.Status.Phase = schedulingv1alpha1.ReservationFailed
+	controller.onReservationUpdate(updated, failed)
+	assert.Equal(t, 1, controller.queue.Len())
+	item, _ := controller.queue.Get()
+	assert.Equal(t, memberB.Name, item)
+	controller.queue.Done(item)
+}
@@ -84,7 +84,7 @@ func (pl *Plugin) NominateReservation(ctx context.Context, cycleState *framework
 		return nil, nil
 	}
 
-	rInfo := pl.GetNominatedReservation(pod, nodeName)
+	rInfo := frameworkext.GetNominatedReservation(pl.handle, cycleState, pod, nodeName)
 	if rInfo != nil {
 		return rInfo, nil
 	}
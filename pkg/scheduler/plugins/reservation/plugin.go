@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/klog/v2"
 	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -83,6 +84,7 @@ type Plugin struct {
 	args             *config.ReservationArgs
 	rLister          listerschedulingv1alpha1.ReservationLister
 	client           clientschedulingv1alpha1.SchedulingV1alpha1Interface
+	pdbLister        policylisters.PodDisruptionBudgetLister
 	reservationCache *reservationCache
 
 	nominator *nominator
@@ -119,6 +121,9 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		reservationCache: cache,
 		nominator:        nominator,
 	}
+	if pluginArgs.EnablePreemption {
+		p.pdbLister = sharedInformerFactory.Policy().V1().PodDisruptionBudgets().Lister()
+	}
 
 	return p, nil
 }
@@ -481,11 +486,19 @@ func fitsNode(podRequest *framework.Resource, nodeInfo *framework.NodeInfo, node
 	return true
 }
 
-func (pl *Plugin) PostFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, _ framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+func (pl *Plugin) PostFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
 	if reservationutil.IsReservePod(pod) {
 		// return err to stop default preemption
 		return nil, framework.NewStatus(framework.Error)
 	}
+	if pl.args != nil && pl.args.EnablePreemption {
+		result, status := pl.preempt(ctx, cycleState, pod, filteredNodeStatusMap)
+		if status.IsSuccess() {
+			return result, status
+		}
+		// fallback to the default preemption of the pods
+		klog.V(4).InfoS("Failed to preempt reservations", "pod", klog.KObj(pod), "reason", status.Message())
+	}
 	return nil, framework.NewStatus(framework.Unschedulable)
 }
 
@@ -510,7 +523,7 @@ func (pl *Plugin) FilterReservation(ctx context.Context, cycleState *framework.C
 		return framework.NewStatus(framework.Unschedulable, "reservation has allocateOnce enabled and has already been allocated")
 	}
 
-	nodeInfo, err := pl.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	nodeInfo, err := frameworkext.GetSnapshotSharedLister(pl.handle, cycleState).NodeInfos().Get(nodeName)
 	if err != nil {
 		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "missing node")
 	}
@@ -531,7 +544,7 @@ func (pl *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		return nil
 	}
 
-	nominatedReservation := pl.handle.GetReservationNominator().GetNominatedReservation(pod, nodeName)
+	nominatedReservation := frameworkext.GetNominatedReservation(pl.handle, cycleState, pod, nodeName)
 	if nominatedReservation == nil {
 		// The scheduleOne skip scores and reservation nomination if there is only one node available.
 		var status *framework.Status
@@ -543,7 +556,7 @@ func (pl *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 			klog.V(5).Infof("Skip reserve with reservation since there are no matched reservations, pod %v, node: %v", klog.KObj(pod), nodeName)
 			return nil
 		}
-		pl.handle.GetReservationNominator().AddNominatedReservation(pod, nodeName, nominatedReservation)
+		frameworkext.AddNominatedReservation(pl.handle, cycleState, pod, nodeName, nominatedReservation)
 	}
 
 	err := pl.reservationCache.assumePod(nominatedReservation.UID(), pod)
@@ -0,0 +1,427 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+const (
+	// ErrReasonNoVictimsToPreempt is the reason for no lower priority reservations or pods can be preempted to make
+	// the pod schedulable.
+	ErrReasonNoVictimsToPreempt = "no lower priority reservations or pods can be preempted"
+)
+
+var _ preemption.Interface = &Plugin{}
+
+// preempt tries to make the pod schedulable by failing the available Reservations of lower priority, together with
+// the lower priority pods if necessary. The plugin implements the preemption.Interface, so the dry run and the
+// candidate selection of the preemption Evaluator take the reservations as victims as well as the pods, and a node
+// which needs both of them preempted can be found. The reservations are presented to the Evaluator by their reserve
+// pods. The candidates without any reservation victim are left to the default preemption.
+func (pl *Plugin) preempt(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	if ok, msg := pl.PodEligibleToPreemptOthers(pod, filteredNodeStatusMap[pod.Status.NominatedNodeName]); !ok {
+		return nil, framework.NewStatus(framework.Unschedulable, msg)
+	}
+	nodeInfos, err := pl.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	potentialNodes := make([]*framework.NodeInfo, 0, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil || filteredNodeStatusMap[nodeInfo.Node().Name].Code() == framework.UnschedulableAndUnresolvable {
+			continue
+		}
+		potentialNodes = append(potentialNodes, nodeInfo)
+	}
+	if len(potentialNodes) == 0 {
+		return nil, framework.NewStatus(framework.Unschedulable, ErrReasonNoVictimsToPreempt)
+	}
+	sort.Slice(potentialNodes, func(i, j int) bool {
+		return potentialNodes[i].Node().Name < potentialNodes[j].Node().Name
+	})
+
+	var pdbs []*policy.PodDisruptionBudget
+	if pl.pdbLister != nil {
+		pdbs, err = pl.pdbLister.List(labels.Everything())
+		if err != nil {
+			return nil, framework.AsStatus(err)
+		}
+	}
+	evaluator := &preemption.Evaluator{
+		PluginName: Name,
+		Handler:    pl.handle,
+		PdbLister:  pl.pdbLister,
+		State:      cycleState,
+		Interface:  pl,
+	}
+	offset, numCandidates := pl.GetOffsetAndNumCandidates(int32(len(potentialNodes)))
+	candidates, _, err := evaluator.DryRunPreemption(ctx, pod, potentialNodes, pdbs, offset, numCandidates)
+	if err != nil && len(candidates) == 0 {
+		return nil, framework.AsStatus(err)
+	}
+	bestCandidate := evaluator.SelectCandidate(candidates)
+	if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
+		return nil, framework.NewStatus(framework.Unschedulable, ErrReasonNoVictimsToPreempt)
+	}
+	if !hasReservationVictims(bestCandidate.Victims().Pods) {
+		return nil, framework.NewStatus(framework.Unschedulable, "no reservations need to be preempted on the best candidate node")
+	}
+
+	if status := pl.prepareCandidate(ctx, pod, bestCandidate); !status.IsSuccess() {
+		return nil, status
+	}
+	klog.V(1).InfoS("Pod preempted lower priority reservations", "pod", klog.KObj(pod), "node", bestCandidate.Name(), "victims", len(bestCandidate.Victims().Pods))
+	return framework.NewPostFilterResultWithNominatedNode(bestCandidate.Name()), framework.NewStatus(framework.Success)
+}
+
+func (pl *Plugin) GetOffsetAndNumCandidates(nodes int32) (int32, int32) {
+	return 0, nodes
+}
+
+func (pl *Plugin) CandidatesToVictimsMap(candidates []preemption.Candidate) map[string]*extenderv1.Victims {
+	m := make(map[string]*extenderv1.Victims)
+	for _, c := range candidates {
+		m[c.Name()] = c.Victims()
+	}
+	return m
+}
+
+// PodEligibleToPreemptOthers returns false if the pod has preemptionPolicy=Never, or there are terminating lower
+// priority pods on the nominated node of the pod, which means the pod has preempted others and should wait for them.
+func (pl *Plugin) PodEligibleToPreemptOthers(pod *corev1.Pod, nominatedNodeStatus *framework.Status) (bool, string) {
+	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == corev1.PreemptNever {
+		return false, "not eligible due to preemptionPolicy=Never."
+	}
+	nomNodeName := pod.Status.NominatedNodeName
+	if len(nomNodeName) == 0 || nominatedNodeStatus.Code() == framework.UnschedulableAndUnresolvable {
+		return true, ""
+	}
+	nodeInfo, _ := pl.handle.SnapshotSharedLister().NodeInfos().Get(nomNodeName)
+	if nodeInfo == nil {
+		return true, ""
+	}
+	podPriority := corev1helpers.PodPriority(pod)
+	for _, p := range nodeInfo.Pods {
+		if p.Pod.DeletionTimestamp != nil && corev1helpers.PodPriority(p.Pod) < podPriority {
+			return false, "not eligible due to a terminating pod on the nominated node."
+		}
+	}
+	return true, ""
+}
+
+// SelectVictimsOnNode finds the minimal set of the lower priority reservations and pods on the node to preempt.
+// Like the default preemption, it removes all the preemptible reservations and pods on the cloned node and checks
+// if the pod fits, then reprieves the pods from the PDB violating ones and the higher priority ones as long as the
+// pod still fits, and the reservations at last. The reservations are preferred to be preempted since they have not
+// been allocated by the running pods. The reservation victims are returned as their reserve pods.
+func (pl *Plugin) SelectVictimsOnNode(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod,
+	nodeInfo *framework.NodeInfo, pdbs []*policy.PodDisruptionBudget) ([]*corev1.Pod, int, *framework.Status) {
+	state := getStateData(cycleState)
+	nodeName := nodeInfo.Node().Name
+	podPriority := corev1helpers.PodPriority(pod)
+	removePod := func(rpi *framework.PodInfo) error {
+		if err := nodeInfo.RemovePod(rpi.Pod); err != nil {
+			return err
+		}
+		return pl.handle.RunPreFilterExtensionRemovePod(ctx, cycleState, pod, rpi, nodeInfo).AsError()
+	}
+	addPod := func(api *framework.PodInfo) error {
+		nodeInfo.AddPodInfo(api)
+		return pl.handle.RunPreFilterExtensionAddPod(ctx, cycleState, pod, api, nodeInfo).AsError()
+	}
+
+	var potentialVictims []*framework.PodInfo
+	for _, pi := range nodeInfo.Pods {
+		if reservationutil.IsReservePod(pi.Pod) || apiext.IsPodNonPreemptible(pi.Pod) || pi.Pod.DeletionTimestamp != nil {
+			continue
+		}
+		if corev1helpers.PodPriority(pi.Pod) < podPriority {
+			potentialVictims = append(potentialVictims, pi)
+		}
+	}
+	reservations := pl.getPreemptibleReservations(state, podPriority, nodeName)
+	if len(potentialVictims) == 0 && len(reservations) == 0 {
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("No victims found on node %v for preemptor pod %v", nodeName, pod.Name))
+	}
+	for _, pi := range potentialVictims {
+		if err := removePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+	for _, rInfo := range reservations {
+		simulateReservationPreempted(state, nodeInfo, rInfo, true)
+	}
+	if status := pl.handle.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo); !status.IsSuccess() {
+		return nil, 0, status
+	}
+
+	var victims []*corev1.Pod
+	numViolatingVictim := 0
+	sort.Slice(potentialVictims, func(i, j int) bool {
+		return schedutil.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod)
+	})
+	violatingVictims, nonViolatingVictims := filterPodsWithPDBViolation(potentialVictims, pdbs)
+	reprievePod := func(pi *framework.PodInfo) (bool, error) {
+		if err := addPod(pi); err != nil {
+			return false, err
+		}
+		fits := pl.handle.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo).IsSuccess()
+		if !fits {
+			if err := removePod(pi); err != nil {
+				return false, err
+			}
+			victims = append(victims, pi.Pod)
+			klog.V(5).InfoS("Pod is a potential preemption victim on node", "pod", klog.KObj(pi.Pod), "node", nodeName)
+		}
+		return fits, nil
+	}
+	for _, pi := range violatingVictims {
+		if fits, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		} else if !fits {
+			numViolatingVictim++
+		}
+	}
+	for _, pi := range nonViolatingVictims {
+		if _, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+
+	// Try to reprieve as many reservations as possible from the highest priority. For the same priority, the
+	// reservations partially allocated are reprieved first.
+	sort.Slice(reservations, func(i, j int) bool {
+		if reservations[i].GetPriority() != reservations[j].GetPriority() {
+			return reservations[i].GetPriority() > reservations[j].GetPriority()
+		}
+		if len(reservations[i].AssignedPods) != len(reservations[j].AssignedPods) {
+			return len(reservations[i].AssignedPods) > len(reservations[j].AssignedPods)
+		}
+		return reservations[i].GetName() < reservations[j].GetName()
+	})
+	for _, rInfo := range reservations {
+		simulateReservationPreempted(state, nodeInfo, rInfo, false)
+		if status := pl.handle.RunFilterPluginsWithNominatedPods(ctx, cycleState, pod, nodeInfo); !status.IsSuccess() {
+			simulateReservationPreempted(state, nodeInfo, rInfo, true)
+			victims = append(victims, rInfo.Pod)
+			klog.V(5).InfoS("Reservation is a potential preemption victim on node", "reservation", klog.KObj(rInfo), "node", nodeName)
+		}
+	}
+	if len(victims) == 0 {
+		// should not happen since the pod does not fit without preemption
+		return nil, 0, framework.NewStatus(framework.Unschedulable, "no victims selected")
+	}
+	// the Evaluator takes the first victim as the one with the highest priority
+	sort.SliceStable(victims, func(i, j int) bool {
+		return corev1helpers.PodPriority(victims[i]) > corev1helpers.PodPriority(victims[j])
+	})
+	return victims, numViolatingVictim, nil
+}
+
+// getPreemptibleReservations returns the available Reservations on the node whose priority is lower than the pod.
+// The reservations matched by the pod are excluded since their resources have been returned to the pod.
+func (pl *Plugin) getPreemptibleReservations(state *stateData, podPriority int32, nodeName string) []*frameworkext.ReservationInfo {
+	matched := map[string]bool{}
+	for _, rInfo := range state.nodeReservationStates[nodeName].matched {
+		matched[string(rInfo.UID())] = true
+	}
+	var candidates []*frameworkext.ReservationInfo
+	for _, rInfo := range pl.reservationCache.listAvailableReservationInfosOnNode(nodeName) {
+		// only the Reservation objects can be preempted, rather than the pods in the reservation operating mode
+		if rInfo.Reservation == nil || rInfo.ParseError != nil || matched[string(rInfo.UID())] {
+			continue
+		}
+		// The reservations allocated once are going to succeed, and their resources are not restored in the NodeInfo.
+		if rInfo.IsAllocateOnce() && len(rInfo.AssignedPods) > 0 {
+			continue
+		}
+		if rInfo.GetPriority() >= podPriority || quotav1.IsZero(getReservationRemained(rInfo)) {
+			continue
+		}
+		candidates = append(candidates, rInfo)
+	}
+	return candidates
+}
+
+// simulateReservationPreempted releases or holds again the unallocated resources of the reservation on the cloned
+// NodeInfo. The resources are also recorded as preemptible so that the Filter of the plugin can see them.
+func simulateReservationPreempted(state *stateData, nodeInfo *framework.NodeInfo, rInfo *frameworkext.ReservationInfo, preempted bool) {
+	remained := getReservationRemained(rInfo)
+	remainedPod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{Requests: remained},
+				},
+			},
+		},
+	}
+	nodeName := nodeInfo.Node().Name
+	if state.preemptible == nil {
+		state.preemptible = map[string]corev1.ResourceList{}
+	}
+	if preempted {
+		updateNodeInfoRequested(nodeInfo, remainedPod, -1)
+		state.preemptible[nodeName] = quotav1.Add(state.preemptible[nodeName], remained)
+	} else {
+		updateNodeInfoRequested(nodeInfo, remainedPod, 1)
+		state.preemptible[nodeName] = quotav1.Subtract(state.preemptible[nodeName], remained)
+	}
+}
+
+func getReservationRemained(rInfo *frameworkext.ReservationInfo) corev1.ResourceList {
+	return quotav1.SubtractWithNonNegativeResult(rInfo.Allocatable, rInfo.Allocated)
+}
+
+func hasReservationVictims(victims []*corev1.Pod) bool {
+	for _, victim := range victims {
+		if reservationutil.IsReservePod(victim) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareCandidate evicts the victim pods like the default preemption, and marks the victim reservations as Failed
+// with the Preempted reason, so that the reservation controller and the scheduler cache release their unallocated
+// resources. The pods already allocated to the reservations keep running.
+func (pl *Plugin) prepareCandidate(ctx context.Context, pod *corev1.Pod, c preemption.Candidate) *framework.Status {
+	nodeName := c.Name()
+	cs := pl.handle.ClientSet()
+	for _, victim := range c.Victims().Pods {
+		if reservationutil.IsReservePod(victim) {
+			if status := pl.failPreemptedReservation(ctx, pod, nodeName, victim); !status.IsSuccess() {
+				return status
+			}
+			continue
+		}
+		if waitingPod := pl.handle.GetWaitingPod(victim.UID); waitingPod != nil {
+			waitingPod.Reject(Name, "preempted")
+		} else if err := schedutil.DeletePod(cs, victim); err != nil {
+			klog.ErrorS(err, "Failed to preempt pod", "pod", klog.KObj(victim), "preemptor", klog.KObj(pod))
+			return framework.AsStatus(err)
+		}
+		klog.V(2).InfoS("Preemptor pod preempted victim pod", "preemptor", klog.KObj(pod), "victim", klog.KObj(victim), "node", nodeName)
+		pl.handle.EventRecorder().Eventf(victim, pod, corev1.EventTypeNormal, "Preempted", "Preempting", "Preempted by a pod on node %v", nodeName)
+	}
+
+	// Lower priority pods nominated to run on this node may no longer fit, so their nominations are removed.
+	podPriority := corev1helpers.PodPriority(pod)
+	var nominatedPods []*corev1.Pod
+	for _, pi := range pl.handle.NominatedPodsForNode(nodeName) {
+		if corev1helpers.PodPriority(pi.Pod) < podPriority {
+			nominatedPods = append(nominatedPods, pi.Pod)
+		}
+	}
+	if err := schedutil.ClearNominatedNodeName(cs, nominatedPods...); err != nil {
+		klog.ErrorS(err, "Cannot clear 'NominatedNodeName' field")
+	}
+	return nil
+}
+
+func (pl *Plugin) failPreemptedReservation(ctx context.Context, pod *corev1.Pod, nodeName string, reservePod *corev1.Pod) *framework.Status {
+	message := fmt.Sprintf("Preempted by pod %s on node %s", klog.KObj(pod), nodeName)
+	var reservation *schedulingv1alpha1.Reservation
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
+		r, err := pl.rLister.Get(reservationutil.GetReservationNameFromReservePod(reservePod))
+		if err != nil {
+			return err
+		}
+		if !reservationutil.IsReservationAvailable(r) {
+			return nil
+		}
+		reservation = r.DeepCopy()
+		reservationutil.SetReservationPreempted(reservation, message)
+		_, err = pl.client.Reservations().UpdateStatus(ctx, reservation, metav1.UpdateOptions{})
+		return err
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to preempt reservation", "reservation", reservationutil.GetReservationNameFromReservePod(reservePod), "pod", klog.KObj(pod))
+		return framework.AsStatus(err)
+	}
+	if reservation != nil {
+		klog.V(2).InfoS("Preemptor pod preempted victim reservation", "preemptor", klog.KObj(pod), "reservation", klog.KObj(reservation), "node", nodeName)
+		pl.handle.EventRecorder().Eventf(reservation, pod, corev1.EventTypeWarning, schedulingv1alpha1.ReasonReservationPreempted, "Preempting", message)
+	}
+	return nil
+}
+
+// filterPodsWithPDBViolation groups the given pods into the PDB violating ones and the non-violating ones, it is
+// stable and does not change the order of the pods.
+func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.PodDisruptionBudget) (violatingPodInfos, nonViolatingPodInfos []*framework.PodInfo) {
+	pdbsAllowed := make([]int32, len(pdbs))
+	for i, pdb := range pdbs {
+		pdbsAllowed[i] = pdb.Status.DisruptionsAllowed
+	}
+
+	for _, podInfo := range podInfos {
+		pod := podInfo.Pod
+		pdbForPodIsViolated := false
+		if len(pod.Labels) != 0 {
+			for i, pdb := range pdbs {
+				if pdb.Namespace != pod.Namespace {
+					continue
+				}
+				selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+				if err != nil {
+					continue
+				}
+				if selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+					continue
+				}
+				if _, exist := pdb.Status.DisruptedPods[pod.Name]; exist {
+					continue
+				}
+				pdbsAllowed[i]--
+				if pdbsAllowed[i] < 0 {
+					pdbForPodIsViolated = true
+				}
+			}
+		}
+		if pdbForPodIsViolated {
+			violatingPodInfos = append(violatingPodInfos, podInfo)
+		} else {
+			nonViolatingPodInfos = append(nonViolatingPodInfos, podInfo)
+		}
+	}
+	return violatingPodInfos, nonViolatingPodInfos
+}
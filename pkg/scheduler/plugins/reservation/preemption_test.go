@@ -0,0 +1,336 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+type fakePodNominator struct{}
+
+func (n *fakePodNominator) AddNominatedPod(pi *framework.PodInfo, nominatingInfo *framework.NominatingInfo) {
+}
+
+func (n *fakePodNominator) DeleteNominatedPodIfExists(pod *corev1.Pod) {}
+
+func (n *fakePodNominator) UpdateNominatedPod(oldPod *corev1.Pod, newPodInfo *framework.PodInfo) {}
+
+func (n *fakePodNominator) NominatedPodsForNode(nodeName string) []*framework.PodInfo {
+	return nil
+}
+
+func makePreemptionTestReservation(name string, priority int32, cpu string) *schedulingv1alpha1.Reservation {
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:  types.UID(name),
+			Name: name,
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Priority: pointer.Int32(priority),
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse(cpu),
+								},
+							},
+						},
+					},
+				},
+			},
+			Owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					Object: &corev1.ObjectReference{
+						Name: "owner-" + name,
+					},
+				},
+			},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "node1",
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(cpu),
+			},
+		},
+	}
+}
+
+func makePreemptionTestPod(name string, priority int32, cpu string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(name),
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Priority: pointer.Int32(priority),
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse(cpu),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newPreemptionTestPlugin(t *testing.T, reservations []*schedulingv1alpha1.Reservation, runningPods []*corev1.Pod) (framework.Framework, *Plugin, *koordfake.Clientset) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("8"),
+				corev1.ResourcePods: resource.MustParse("100"),
+			},
+		},
+	}
+	var pods []*corev1.Pod
+	cs := kubefake.NewSimpleClientset()
+	for _, pod := range runningPods {
+		_, err := cs.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		pods = append(pods, pod)
+	}
+	koordClientSet := koordfake.NewSimpleClientset()
+	for _, r := range reservations {
+		_, err := koordClientSet.SchedulingV1alpha1().Reservations().Create(context.TODO(), r, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		reservePod := reservationutil.NewReservePod(r)
+		reservePod.Spec.NodeName = r.Status.NodeName
+		pods = append(pods, reservePod)
+	}
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+	extenderFactory, _ := frameworkext.NewFrameworkExtenderFactory(
+		frameworkext.WithKoordinatorClientSet(koordClientSet),
+		frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+	)
+	extenderFactory.InitScheduler(frameworkext.NewFakeScheduler())
+	proxyNew := frameworkext.PluginFactoryProxy(extenderFactory, New)
+
+	fitArgs := &schedconfig.NodeResourcesFitArgs{
+		ScoringStrategy: &schedconfig.ScoringStrategy{
+			Type: schedconfig.LeastAllocated,
+			Resources: []schedconfig.ResourceSpec{
+				{Name: string(corev1.ResourceCPU), Weight: 1},
+			},
+		},
+	}
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		schedulertesting.RegisterPluginAsExtensions(noderesources.Name, func(_ apiruntime.Object, fh framework.Handle) (framework.Plugin, error) {
+			return noderesources.NewFit(fitArgs, fh, feature.Features{})
+		}, "PreFilter", "Filter"),
+	}
+	fw, err := schedulertesting.NewFramework(
+		registeredPlugins,
+		"koord-scheduler",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithInformerFactory(informers.NewSharedInformerFactory(cs, 0)),
+		frameworkruntime.WithSnapshotSharedLister(newFakeSharedLister(pods, []*corev1.Node{node}, false)),
+		frameworkruntime.WithPodNominator(&fakePodNominator{}),
+		frameworkruntime.WithEventRecorder(record.NewEventRecorderAdapter(record.NewFakeRecorder(1024))),
+	)
+	assert.NoError(t, err)
+
+	p, err := proxyNew(&config.ReservationArgs{EnablePreemption: true}, fw)
+	assert.NoError(t, err)
+	pl := p.(*Plugin)
+	extenderFactory.NewFrameworkExtender(fw).SetConfiguredPlugins(fw.ListPlugins())
+	koordSharedInformerFactory.Start(nil)
+	koordSharedInformerFactory.WaitForCacheSync(nil)
+	for _, r := range reservations {
+		pl.reservationCache.updateReservation(r)
+	}
+	return fw, pl, koordClientSet
+}
+
+func TestPostFilterWithReservationPreemption(t *testing.T) {
+	neverPreempt := corev1.PreemptNever
+	tests := []struct {
+		name             string
+		reservations     []*schedulingv1alpha1.Reservation
+		pods             []*corev1.Pod
+		podCPU           string
+		preemptionPolicy *corev1.PreemptionPolicy
+		wantStatus       *framework.Status
+		wantPreempted    []string
+		wantDeletedPods  []string
+	}{
+		{
+			name: "preempt the lowest priority reservation",
+			reservations: []*schedulingv1alpha1.Reservation{
+				makePreemptionTestReservation("r-low", 100, "4"),
+				makePreemptionTestReservation("r-mid", 200, "2"),
+			},
+			podCPU:        "4",
+			wantStatus:    framework.NewStatus(framework.Success),
+			wantPreempted: []string{"r-low"},
+		},
+		{
+			name: "preempt multiple reservations",
+			reservations: []*schedulingv1alpha1.Reservation{
+				makePreemptionTestReservation("r-low", 100, "4"),
+				makePreemptionTestReservation("r-mid", 200, "4"),
+			},
+			podCPU:        "6",
+			wantStatus:    framework.NewStatus(framework.Success),
+			wantPreempted: []string{"r-low", "r-mid"},
+		},
+		{
+			name: "preempt the reservation together with the pod",
+			reservations: []*schedulingv1alpha1.Reservation{
+				makePreemptionTestReservation("r-low", 100, "4"),
+			},
+			pods: []*corev1.Pod{
+				makePreemptionTestPod("pod-low", 100, "3"),
+			},
+			podCPU:          "6",
+			wantStatus:      framework.NewStatus(framework.Success),
+			wantPreempted:   []string{"r-low"},
+			wantDeletedPods: []string{"pod-low"},
+		},
+		{
+			name: "prefer to preempt the reservation rather than the pod",
+			reservations: []*schedulingv1alpha1.Reservation{
+				makePreemptionTestReservation("r-low", 200, "2"),
+			},
+			pods: []*corev1.Pod{
+				makePreemptionTestPod("pod-low", 100, "2"),
+			},
+			podCPU:        "6",
+			wantStatus:    framework.NewStatus(framework.Success),
+			wantPreempted: []string{"r-low"},
+		},
+		{
+			name: "leave the preemption of the pods only to the default preemption",
+			pods: []*corev1.Pod{
+				makePreemptionTestPod("pod-low", 100, "4"),
+			},
+			podCPU:     "6",
+			wantStatus: framework.NewStatus(framework.Unschedulable),
+		},
+		{
+			name: "higher priority reservations cannot be preempted",
+			reservations: []*schedulingv1alpha1.Reservation{
+				makePreemptionTestReservation("r-high", 1000, "4"),
+				makePreemptionTestReservation("r-low", 100, "2"),
+			},
+			podCPU:     "6",
+			wantStatus: framework.NewStatus(framework.Unschedulable),
+		},
+		{
+			name: "pod with preemptionPolicy=Never",
+			reservations: []*schedulingv1alpha1.Reservation{
+				makePreemptionTestReservation("r-low", 100, "4"),
+			},
+			podCPU:           "6",
+			preemptionPolicy: &neverPreempt,
+			wantStatus:       framework.NewStatus(framework.Unschedulable),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw, pl, koordClientSet := newPreemptionTestPlugin(t, tt.reservations, tt.pods)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"},
+				Spec: corev1.PodSpec{
+					Priority:         pointer.Int32(500),
+					PreemptionPolicy: tt.preemptionPolicy,
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse(tt.podCPU),
+								},
+							},
+						},
+					},
+				},
+			}
+			cycleState := framework.NewCycleState()
+			_, _, status := pl.BeforePreFilter(context.TODO(), cycleState, pod)
+			assert.True(t, status.IsSuccess())
+			_, status = fw.RunPreFilterPlugins(context.TODO(), cycleState, pod)
+			assert.True(t, status.IsSuccess())
+
+			result, status := pl.PostFilter(context.TODO(), cycleState, pod, framework.NodeToStatusMap{})
+			assert.Equal(t, tt.wantStatus, status)
+			if status.IsSuccess() {
+				assert.Equal(t, "node1", result.NominatedNodeName)
+			} else {
+				assert.Nil(t, result)
+			}
+
+			var gotPreempted []string
+			for _, r := range tt.reservations {
+				got, err := koordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), r.Name, metav1.GetOptions{})
+				assert.NoError(t, err)
+				if got.Status.Phase == schedulingv1alpha1.ReservationFailed {
+					gotPreempted = append(gotPreempted, got.Name)
+					cond := got.Status.Conditions[len(got.Status.Conditions)-1]
+					assert.Equal(t, schedulingv1alpha1.ReasonReservationPreempted, cond.Reason)
+				}
+			}
+			assert.Equal(t, tt.wantPreempted, gotPreempted)
+
+			var gotDeletedPods []string
+			for _, p := range tt.pods {
+				_, err := fw.ClientSet().CoreV1().Pods(p.Namespace).Get(context.TODO(), p.Name, metav1.GetOptions{})
+				if errors.IsNotFound(err) {
+					gotDeletedPods = append(gotDeletedPods, p.Name)
+				}
+			}
+			assert.Equal(t, tt.wantDeletedPods, gotDeletedPods)
+		})
+	}
+}
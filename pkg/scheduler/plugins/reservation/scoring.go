@@ -83,7 +83,7 @@ func (pl *Plugin) PreScore(ctx context.Context, cycleState *framework.CycleState
 
 	nominatedReservations = nominatedReservations[:nominatedNodeIndex]
 	for _, v := range nominatedReservations {
-		pl.handle.GetReservationNominator().AddNominatedReservation(pod, v.GetNodeName(), v)
+		frameworkext.AddNominatedReservation(pl.handle, cycleState, pod, v.GetNodeName(), v)
 	}
 
 	var selectOrder int64 = math.MaxInt64
@@ -111,7 +111,7 @@ func (pl *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, p
 		return mostPreferredScore, nil
 	}
 
-	reservationInfo := pl.handle.GetReservationNominator().GetNominatedReservation(pod, nodeName)
+	reservationInfo := frameworkext.GetNominatedReservation(pl.handle, cycleState, pod, nodeName)
 	if reservationInfo == nil {
 		return framework.MinNodeScore, nil
 	}
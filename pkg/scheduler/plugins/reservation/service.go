@@ -18,15 +18,19 @@ package reservation
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/services"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
 var _ services.APIServiceProvider = &Plugin{}
@@ -52,6 +56,30 @@ type NodeReservations struct {
 	Items []ReservationItem `json:"items,omitempty"`
 }
 
+// ReservationGroupStatus aggregates the status of the member reservations of a reservation group.
+type ReservationGroupStatus struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Phase is Failed if any member failed, Pending if any member is not scheduled,
+	// Succeeded if all the members are succeeded, otherwise Available.
+	Phase       schedulingv1alpha1.ReservationPhase `json:"phase,omitempty"`
+	Total       int                                 `json:"total,omitempty"`
+	Pending     int                                 `json:"pending,omitempty"`
+	Available   int                                 `json:"available,omitempty"`
+	Succeeded   int                                 `json:"succeeded,omitempty"`
+	Failed      int                                 `json:"failed,omitempty"`
+	Allocatable corev1.ResourceList                 `json:"allocatable,omitempty"`
+	Allocated   corev1.ResourceList                 `json:"allocated,omitempty"`
+	Members     []ReservationGroupMember            `json:"members,omitempty"`
+}
+
+type ReservationGroupMember struct {
+	Name     string                              `json:"name,omitempty"`
+	UID      types.UID                           `json:"uid,omitempty"`
+	Phase    schedulingv1alpha1.ReservationPhase `json:"phase,omitempty"`
+	NodeName string                              `json:"nodeName,omitempty"`
+}
+
 func (pl *Plugin) RegisterEndpoints(group *gin.RouterGroup) {
 	group.GET("/nodeReservations/:nodeName", func(c *gin.Context) {
 		nodeName := c.Param("nodeName")
@@ -94,4 +122,74 @@ func (pl *Plugin) RegisterEndpoints(group *gin.RouterGroup) {
 		}
 		c.JSON(http.StatusOK, resp)
 	})
+	group.GET("/reservationGroups/:namespace/:name", func(c *gin.Context) {
+		namespace, name := c.Param("namespace"), c.Param("name")
+		status, err := pl.getReservationGroupStatus(namespace, name)
+		if err != nil {
+			services.ResponseErrorMessage(c, http.StatusInternalServerError, "failed to list reservations: %v", err)
+			return
+		}
+		if status == nil {
+			services.ResponseErrorMessage(c, http.StatusNotFound, "cannot find reservation group %s/%s", namespace, name)
+			return
+		}
+		c.JSON(http.StatusOK, status)
+	})
+}
+
+func (pl *Plugin) getReservationGroupStatus(namespace, name string) (*ReservationGroupStatus, error) {
+	reservations, err := pl.rLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	groupKey := namespace + "/" + name
+	status := &ReservationGroupStatus{
+		Name:      name,
+		Namespace: namespace,
+	}
+	for _, r := range reservations {
+		if reservationutil.GetReservationGroup(r) != groupKey {
+			continue
+		}
+		phase := r.Status.Phase
+		switch phase {
+		case schedulingv1alpha1.ReservationAvailable, schedulingv1alpha1.ReservationWaiting:
+			status.Available++
+		case schedulingv1alpha1.ReservationSucceeded:
+			status.Succeeded++
+		case schedulingv1alpha1.ReservationFailed:
+			status.Failed++
+		default:
+			phase = schedulingv1alpha1.ReservationPending
+			status.Pending++
+		}
+		if phase != schedulingv1alpha1.ReservationFailed {
+			status.Allocatable = quotav1.Add(status.Allocatable, reservationutil.ReservationRequests(r))
+			status.Allocated = quotav1.Add(status.Allocated, r.Status.Allocated)
+		}
+		status.Members = append(status.Members, ReservationGroupMember{
+			Name:     r.Name,
+			UID:      r.UID,
+			Phase:    phase,
+			NodeName: r.Status.NodeName,
+		})
+	}
+	status.Total = len(status.Members)
+	if status.Total == 0 {
+		return nil, nil
+	}
+	sort.Slice(status.Members, func(i, j int) bool {
+		return status.Members[i].Name < status.Members[j].Name
+	})
+	switch {
+	case status.Failed > 0:
+		status.Phase = schedulingv1alpha1.ReservationFailed
+	case status.Pending > 0:
+		status.Phase = schedulingv1alpha1.ReservationPending
+	case status.Succeeded == status.Total:
+		status.Phase = schedulingv1alpha1.ReservationSucceeded
+	default:
+		status.Phase = schedulingv1alpha1.ReservationAvailable
+	}
+	return status, nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package reservation
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -26,6 +27,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -33,6 +35,8 @@ import (
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 )
 
@@ -249,3 +253,108 @@ func TestQueryNodeReservations(t *testing.T) {
 	})
 	assert.Equal(t, expectedReservations, reservations)
 }
+
+func TestQueryReservationGroup(t *testing.T) {
+	koordClientSet := koordfake.NewSimpleClientset()
+	koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(koordClientSet, 0)
+	rLister := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Lister()
+
+	newMember := func(name, gangName string, phase schedulingv1alpha1.ReservationPhase, nodeName string) *schedulingv1alpha1.Reservation {
+		return &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				UID:  uuid.NewUUID(),
+				Annotations: map[string]string{
+					extension.AnnotationGangName: gangName,
+				},
+			},
+			Spec: schedulingv1alpha1.ReservationSpec{
+				Template: &corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "test-ns",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "main",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU: resource.MustParse("4"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase:    phase,
+				NodeName: nodeName,
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("4"),
+				},
+			},
+		}
+	}
+	reservations := []*schedulingv1alpha1.Reservation{
+		newMember("member-b", "gang-a", schedulingv1alpha1.ReservationPending, ""),
+		newMember("member-a", "gang-a", schedulingv1alpha1.ReservationAvailable, "test-node"),
+		newMember("other-member", "gang-b", schedulingv1alpha1.ReservationAvailable, "test-node"),
+	}
+	reservations[1].Status.Allocated = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("2"),
+	}
+	for _, r := range reservations {
+		_, err := koordClientSet.SchedulingV1alpha1().Reservations().Create(context.TODO(), r, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	koordSharedInformerFactory.Start(nil)
+	koordSharedInformerFactory.WaitForCacheSync(nil)
+
+	pl := &Plugin{
+		rLister:          rLister,
+		reservationCache: newReservationCache(nil),
+	}
+	engine := gin.Default()
+	pl.RegisterEndpoints(engine.Group("/"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/reservationGroups/test-ns/gang-a", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	status := &ReservationGroupStatus{}
+	assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(status))
+	expectedStatus := &ReservationGroupStatus{
+		Name:      "gang-a",
+		Namespace: "test-ns",
+		Phase:     schedulingv1alpha1.ReservationPending,
+		Total:     2,
+		Pending:   1,
+		Available: 1,
+		Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("8"),
+		},
+		Allocated: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("2"),
+		},
+		Members: []ReservationGroupMember{
+			{
+				Name:     "member-a",
+				UID:      reservations[1].UID,
+				Phase:    schedulingv1alpha1.ReservationAvailable,
+				NodeName: "test-node",
+			},
+			{
+				Name:  "member-b",
+				UID:   reservations[0].UID,
+				Phase: schedulingv1alpha1.ReservationPending,
+			},
+		},
+	}
+	assert.True(t, equality.Semantic.DeepEqual(expectedStatus, status), "expected %+v, got %+v", expectedStatus, status)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/reservationGroups/test-ns/not-found", nil)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
@@ -78,7 +78,7 @@ func (pl *Plugin) prepareMatchReservationState(ctx context.Context, cycleState *
 	}
 
 	processNode := func(i int) {
-		nodeInfo, err := pl.handle.SnapshotSharedLister().NodeInfos().Get(allNodes[i])
+		nodeInfo, err := frameworkext.GetSnapshotSharedLister(pl.handle, cycleState).NodeInfos().Get(allNodes[i])
 		if err != nil {
 			klog.Warningf("Failed to get NodeInfo of %s during reservation's BeforePreFilter for pod: %v, err: %v", allNodes[i], klog.KObj(pod), err)
 			return
@@ -135,10 +135,14 @@ func (pl *Plugin) prepareMatchReservationState(ctx context.Context, cycleState *
 			return
 		}
 
-		if err := extender.Scheduler().GetCache().InvalidNodeInfo(node.Name); err != nil {
-			klog.ErrorS(err, "Failed to InvalidNodeInfo", "pod", klog.KObj(pod), "node", node.Name)
-			errCh.SendErrorWithCancel(err, cancel)
-			return
+		// The simulated cycles restore the Reservations on the copies of the NodeInfos owned by the simulation,
+		// the NodeInfos in the cache of the scheduler are untouched.
+		if !frameworkext.IsSchedulingSimulation(cycleState) {
+			if err := extender.Scheduler().GetCache().InvalidNodeInfo(node.Name); err != nil {
+				klog.ErrorS(err, "Failed to InvalidNodeInfo", "pod", klog.KObj(pod), "node", node.Name)
+				errCh.SendErrorWithCancel(err, cancel)
+				return
+			}
 		}
 
 		for _, rInfo := range unmatched {
@@ -31,6 +31,7 @@ import (
 	"k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/utils/pointer"
+	pgv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
@@ -169,6 +170,43 @@ func PodPriority(r *schedulingv1alpha1.Reservation) int32 {
 	return 0
 }
 
+// GetReservationGroup returns the namespaced name of the gang which the reservation belongs to, or empty if the
+// reservation is not in a group. The gang is specified by the PodGroup label or the gang annotations of the
+// reservation (or its template) in the namespace of the reserve pod. All the member reservations of a group are
+// scheduled all-or-nothing by the Coscheduling plugin as the reserve pods of a gang, and they expire together.
+func GetReservationGroup(r *schedulingv1alpha1.Reservation) string {
+	if r == nil {
+		return ""
+	}
+	gangName := r.Labels[pgv1alpha1.PodGroupLabel]
+	if gangName == "" && r.Spec.Template != nil {
+		gangName = r.Spec.Template.Labels[pgv1alpha1.PodGroupLabel]
+	}
+	if gangName == "" {
+		gangName = r.Annotations[extension.AnnotationGangName]
+	}
+	if gangName == "" && r.Spec.Template != nil {
+		gangName = r.Spec.Template.Annotations[extension.AnnotationGangName]
+	}
+	if gangName == "" {
+		return ""
+	}
+	return GetReservePodNamespacedName(r).Namespace + "/" + gangName
+}
+
+// MatchReservationGroup checks if the pod can consume the reservations of the group, i.e. the pod belongs to the gang
+// of the same namespaced name. The reservations not in a group can be consumed by any pod.
+func MatchReservationGroup(pod *corev1.Pod, group string) bool {
+	if group == "" {
+		return true
+	}
+	gangName := pod.Labels[pgv1alpha1.PodGroupLabel]
+	if gangName == "" {
+		gangName = extension.GetGangName(pod)
+	}
+	return gangName != "" && pod.Namespace+"/"+gangName == group
+}
+
 func IsReservePod(pod *corev1.Pod) bool {
 	return pod != nil && pod.Annotations != nil && pod.Annotations[AnnotationReservePod] == "true"
 }
@@ -274,6 +312,29 @@ func SetReservationExpired(r *schedulingv1alpha1.Reservation) {
 	}
 }
 
+// SetReservationPreempted marks the reservation as Failed since it is preempted by a higher priority pod.
+func SetReservationPreempted(r *schedulingv1alpha1.Reservation, message string) {
+	r.Status.Phase = schedulingv1alpha1.ReservationFailed
+	condition := schedulingv1alpha1.ReservationCondition{
+		Type:               schedulingv1alpha1.ReservationConditionReady,
+		Status:             schedulingv1alpha1.ConditionStatusFalse,
+		Reason:             schedulingv1alpha1.ReasonReservationPreempted,
+		Message:            message,
+		LastProbeTime:      metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+	}
+	for i := range r.Status.Conditions {
+		if r.Status.Conditions[i].Type == schedulingv1alpha1.ReservationConditionReady {
+			if r.Status.Conditions[i].Status == schedulingv1alpha1.ConditionStatusFalse {
+				condition.LastTransitionTime = r.Status.Conditions[i].LastTransitionTime
+			}
+			r.Status.Conditions[i] = condition
+			return
+		}
+	}
+	r.Status.Conditions = append(r.Status.Conditions, condition)
+}
+
 func SetReservationSucceeded(r *schedulingv1alpha1.Reservation) {
 	r.Status.Phase = schedulingv1alpha1.ReservationSucceeded
 	idx := -1
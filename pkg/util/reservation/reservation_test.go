@@ -27,7 +27,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/utils/pointer"
+	pgv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 )
 
@@ -253,6 +255,145 @@ func TestGetReservationSchedulerName(t *testing.T) {
 	}
 }
 
+func TestGetReservationGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  *schedulingv1alpha1.Reservation
+		want string
+	}{
+		{
+			name: "nil reservation",
+			arg:  nil,
+			want: "",
+		},
+		{
+			name: "not in a group",
+			arg: &schedulingv1alpha1.Reservation{
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template: &corev1.PodTemplateSpec{},
+				},
+			},
+			want: "",
+		},
+		{
+			name: "gang annotation on reservation",
+			arg: &schedulingv1alpha1.Reservation{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						extension.AnnotationGangName: "gang-a",
+					},
+				},
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template: &corev1.PodTemplateSpec{},
+				},
+			},
+			want: "default/gang-a",
+		},
+		{
+			name: "gang annotation on template",
+			arg: &schedulingv1alpha1.Reservation{
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template: &corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "test-ns",
+							Annotations: map[string]string{
+								extension.AnnotationGangName: "gang-b",
+							},
+						},
+					},
+				},
+			},
+			want: "test-ns/gang-b",
+		},
+		{
+			name: "podGroup label takes precedence over gang annotation",
+			arg: &schedulingv1alpha1.Reservation{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						extension.AnnotationGangName: "gang-a",
+					},
+				},
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template: &corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								pgv1alpha1.PodGroupLabel: "pg-a",
+							},
+						},
+					},
+				},
+			},
+			want: "default/pg-a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetReservationGroup(tt.arg)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchReservationGroup(t *testing.T) {
+	tests := []struct {
+		name  string
+		pod   *corev1.Pod
+		group string
+		want  bool
+	}{
+		{
+			name:  "reservation not in a group",
+			pod:   &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			group: "",
+			want:  true,
+		},
+		{
+			name:  "pod not in a gang",
+			pod:   &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			group: "default/gang-a",
+			want:  false,
+		},
+		{
+			name: "pod in the gang by annotation",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Annotations: map[string]string{extension.AnnotationGangName: "gang-a"},
+				},
+			},
+			group: "default/gang-a",
+			want:  true,
+		},
+		{
+			name: "pod in the gang by podGroup label",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Labels:    map[string]string{pgv1alpha1.PodGroupLabel: "gang-a"},
+				},
+			},
+			group: "default/gang-a",
+			want:  true,
+		},
+		{
+			name: "pod in the gang of another namespace",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "test-ns",
+					Annotations: map[string]string{extension.AnnotationGangName: "gang-a"},
+				},
+			},
+			group: "default/gang-a",
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchReservationGroup(tt.pod, tt.group))
+		})
+	}
+}
+
 func TestMatchReservationOwners(t *testing.T) {
 	type args struct {
 		pod *corev1.Pod
@@ -523,6 +664,42 @@ func TestSetReservationAvailable(t *testing.T) {
 	}
 }
 
+func TestSetReservationPreempted(t *testing.T) {
+	readyTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	reservation := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reserve-pod-0",
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "test-node",
+			Conditions: []schedulingv1alpha1.ReservationCondition{
+				{
+					Type:               schedulingv1alpha1.ReservationConditionScheduled,
+					Status:             schedulingv1alpha1.ConditionStatusTrue,
+					Reason:             schedulingv1alpha1.ReasonReservationScheduled,
+					LastTransitionTime: readyTime,
+				},
+				{
+					Type:               schedulingv1alpha1.ReservationConditionReady,
+					Status:             schedulingv1alpha1.ConditionStatusTrue,
+					Reason:             schedulingv1alpha1.ReasonReservationAvailable,
+					LastTransitionTime: readyTime,
+				},
+			},
+		},
+	}
+	SetReservationPreempted(reservation, "preempted by test-pod")
+	assert.True(t, IsReservationFailed(reservation))
+	assert.Len(t, reservation.Status.Conditions, 2)
+	cond := reservation.Status.Conditions[1]
+	assert.Equal(t, schedulingv1alpha1.ReservationConditionReady, cond.Type)
+	assert.Equal(t, schedulingv1alpha1.ConditionStatusFalse, cond.Status)
+	assert.Equal(t, schedulingv1alpha1.ReasonReservationPreempted, cond.Reason)
+	assert.Equal(t, "preempted by test-pod", cond.Message)
+	assert.NotEqual(t, readyTime, cond.LastTransitionTime)
+}
+
 func TestReservePod(t *testing.T) {
 	reservation := &schedulingv1alpha1.Reservation{
 		ObjectMeta: metav1.ObjectMeta{
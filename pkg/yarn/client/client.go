@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ResourceManagerClient talks to the REST API of the YARN ResourceManager.
+type ResourceManagerClient interface {
+	// ListNodes lists the NodeManagers registered in the ResourceManager.
+	ListNodes(ctx context.Context) ([]NodeInfo, error)
+	// UpdateNodeResource updates the total resource of the NodeManager which can be allocated to containers.
+	UpdateNodeResource(ctx context.Context, nodeID string, resource ResourceInfo) error
+}
+
+// NodeManagerClient talks to the REST API of the YARN NodeManager.
+type NodeManagerClient interface {
+	// ListContainers lists the containers launched by the NodeManager.
+	ListContainers(ctx context.Context) ([]ContainerInfo, error)
+}
+
+type restClient struct {
+	address    string
+	httpClient *http.Client
+}
+
+// NewResourceManagerClient returns a client of the ResourceManager, the address is in the format of `http://host:port`.
+func NewResourceManagerClient(address string, timeout time.Duration) ResourceManagerClient {
+	return &restClient{
+		address:    strings.TrimSuffix(address, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// NewNodeManagerClient returns a client of the NodeManager, the address is in the format of `http://host:port`.
+func NewNodeManagerClient(address string, timeout time.Duration) NodeManagerClient {
+	return &restClient{
+		address:    strings.TrimSuffix(address, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *restClient) ListNodes(ctx context.Context) ([]NodeInfo, error) {
+	nodesInfo := &NodesInfo{}
+	if err := c.do(ctx, http.MethodGet, "/ws/v1/cluster/nodes", nil, nodesInfo); err != nil {
+		return nil, err
+	}
+	if nodesInfo.Nodes == nil {
+		return nil, nil
+	}
+	return nodesInfo.Nodes.Node, nil
+}
+
+func (c *restClient) UpdateNodeResource(ctx context.Context, nodeID string, resource ResourceInfo) error {
+	option := &ResourceOptionInfo{
+		Resource:          resource,
+		OverCommitTimeout: -1,
+	}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/ws/v1/cluster/nodes/%s/resource", url.PathEscape(nodeID)), option, nil)
+}
+
+func (c *restClient) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	containersInfo := &ContainersInfo{}
+	if err := c.do(ctx, http.MethodGet, "/ws/v1/node/containers", nil, containersInfo); err != nil {
+		return nil, err
+	}
+	if containersInfo.Containers == nil {
+		return nil, nil
+	}
+	return containersInfo.Containers.Container, nil
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	requestURL := c.address + path
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rsp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request %s %s failed, code %d", method, requestURL, rsp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parse response of %s failed, err: %v", requestURL, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceManagerClient(t *testing.T) {
+	server := NewFakeServer([]NodeInfo{
+		{
+			ID:               "node1:8041",
+			State:            NodeStateRunning,
+			NodeHostName:     "node1",
+			UsedMemoryMB:     2048,
+			UsedVirtualCores: 2,
+			TotalResource:    &ResourceInfo{MemoryMB: 8192, VCores: 8},
+		},
+	}, nil)
+	defer server.Close()
+
+	c := NewResourceManagerClient(server.URL+"/", time.Second)
+	nodes, err := c.ListNodes(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, server.Nodes(), nodes)
+
+	err = c.UpdateNodeResource(context.TODO(), "node1:8041", ResourceInfo{MemoryMB: 4096, VCores: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, &ResourceInfo{MemoryMB: 4096, VCores: 4}, server.Nodes()[0].TotalResource)
+
+	err = c.UpdateNodeResource(context.TODO(), "node2:8041", ResourceInfo{MemoryMB: 4096, VCores: 4})
+	assert.Error(t, err)
+}
+
+func TestResourceManagerClientWithoutNodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"nodes":null}`))
+	}))
+	defer server.Close()
+
+	nodes, err := NewResourceManagerClient(server.URL, time.Second).ListNodes(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, nodes)
+}
+
+func TestNodeManagerClient(t *testing.T) {
+	containers := []ContainerInfo{
+		{
+			ID:                  "container_1680000000000_0001_01_000001",
+			State:               ContainerStateRunning,
+			TotalMemoryNeededMB: 1024,
+			TotalVCoresNeeded:   1,
+		},
+	}
+	server := NewFakeServer(nil, containers)
+	defer server.Close()
+
+	got, err := NewNodeManagerClient(server.URL, time.Second).ListContainers(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, containers, got)
+
+	server.SetContainers(nil)
+	got, err = NewNodeManagerClient(server.URL, time.Second).ListContainers(context.TODO())
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// FakeServer is a local fake of the YARN ResourceManager and NodeManager REST API for testing.
+type FakeServer struct {
+	*httptest.Server
+
+	lock       sync.Mutex
+	nodes      []NodeInfo
+	containers []ContainerInfo
+}
+
+// NewFakeServer starts a fake YARN server with the NodeManagers and the containers, call Close after using it.
+func NewFakeServer(nodes []NodeInfo, containers []ContainerInfo) *FakeServer {
+	s := &FakeServer{
+		nodes:      nodes,
+		containers: containers,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Nodes returns the NodeManagers registered in the fake ResourceManager.
+func (s *FakeServer) Nodes() []NodeInfo {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	nodes := make([]NodeInfo, len(s.nodes))
+	copy(nodes, s.nodes)
+	return nodes
+}
+
+// SetContainers replaces the containers of the fake NodeManager.
+func (s *FakeServer) SetContainers(containers []ContainerInfo) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.containers = containers
+}
+
+func (s *FakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	const nodesPath = "/ws/v1/cluster/nodes"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == nodesPath:
+		writeJSON(w, &NodesInfo{Nodes: &NodeList{Node: s.nodes}})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, nodesPath+"/") && strings.HasSuffix(r.URL.Path, "/resource"):
+		nodeID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, nodesPath+"/"), "/resource")
+		option := &ResourceOptionInfo{}
+		if err := json.NewDecoder(r.Body).Decode(option); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i := range s.nodes {
+			if s.nodes[i].ID == nodeID {
+				resource := option.Resource
+				s.nodes[i].TotalResource = &resource
+				writeJSON(w, option)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodGet && r.URL.Path == "/ws/v1/node/containers":
+		writeJSON(w, &ContainersInfo{Containers: &ContainerList{Container: s.containers}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(obj)
+}
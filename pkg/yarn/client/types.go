@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+const (
+	// NodeStateRunning is the state of the NodeManager which is running and accepting containers.
+	NodeStateRunning = "RUNNING"
+
+	// ContainerStateRunning is the state of the container whose processes are launched.
+	ContainerStateRunning = "RUNNING"
+)
+
+// NodesInfo is the response of the ResourceManager Cluster Nodes API, `/ws/v1/cluster/nodes`.
+type NodesInfo struct {
+	Nodes *NodeList `json:"nodes"`
+}
+
+type NodeList struct {
+	Node []NodeInfo `json:"node,omitempty"`
+}
+
+// NodeInfo describes a NodeManager registered in the ResourceManager.
+type NodeInfo struct {
+	// ID is the node id of the NodeManager, in the format of `host:port`
+	ID                string `json:"id"`
+	State             string `json:"state"`
+	NodeHostName      string `json:"nodeHostName"`
+	NodeHTTPAddress   string `json:"nodeHTTPAddress,omitempty"`
+	NumContainers     int64  `json:"numContainers"`
+	UsedMemoryMB      int64  `json:"usedMemoryMB"`
+	AvailMemoryMB     int64  `json:"availMemoryMB"`
+	UsedVirtualCores  int64  `json:"usedVirtualCores"`
+	AvailVirtualCores int64  `json:"availableVirtualCores"`
+	// TotalResource is the resource of the node which can be allocated by the ResourceManager
+	TotalResource *ResourceInfo `json:"totalResource,omitempty"`
+}
+
+// ResourceInfo is the memory in MB and the virtual cores of a YARN resource.
+type ResourceInfo struct {
+	MemoryMB int64 `json:"memory"`
+	VCores   int64 `json:"vCores"`
+}
+
+// ResourceOptionInfo is the request of the ResourceManager Cluster Node Update Resource API,
+// `/ws/v1/cluster/nodes/{nodeid}/resource`.
+type ResourceOptionInfo struct {
+	Resource ResourceInfo `json:"resource"`
+	// OverCommitTimeout is the timeout in milliseconds to preempt the containers over the new resource,
+	// -1 means the containers are never preempted.
+	OverCommitTimeout int64 `json:"overCommitTimeout"`
+}
+
+// ContainersInfo is the response of the NodeManager Containers API, `/ws/v1/node/containers`.
+type ContainersInfo struct {
+	Containers *ContainerList `json:"containers"`
+}
+
+type ContainerList struct {
+	Container []ContainerInfo `json:"container,omitempty"`
+}
+
+// ContainerInfo describes a container launched by the NodeManager.
+type ContainerInfo struct {
+	ID                  string `json:"id"`
+	State               string `json:"state"`
+	User                string `json:"user,omitempty"`
+	NodeID              string `json:"nodeId,omitempty"`
+	TotalMemoryNeededMB int64  `json:"totalMemoryNeededMB"`
+	TotalVCoresNeeded   int64  `json:"totalVCoresNeeded"`
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/fields"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	yarnclient "github.com/koordinator-sh/koordinator/pkg/yarn/client"
+)
+
+const Name = "yarnresource"
+
+const (
+	// ThirdPartyAllocationName is the name of the YARN allocations in the third-party allocations of the node.
+	ThirdPartyAllocationName = "hadoop-yarn"
+
+	ReasonUpdateYarnNodeResourceFailed = "UpdateYarnNodeResourceFailed"
+)
+
+var (
+	ResourceManagerAddress string
+	SyncPeriod             = 30 * time.Second
+	RequestTimeout         = 10 * time.Second
+)
+
+func InitFlags(fs *flag.FlagSet) {
+	pflag.StringVar(&ResourceManagerAddress, "yarn-rm-address", ResourceManagerAddress, "The address of the YARN ResourceManager REST API, e.g. http://resourcemanager:8088. "+
+		"The yarnresource controller is not started if it is empty.")
+	pflag.DurationVar(&SyncPeriod, "yarn-sync-period", SyncPeriod, "The period to sync the YARN allocations and the node resources with the YARN ResourceManager.")
+	pflag.DurationVar(&RequestTimeout, "yarn-request-timeout", RequestTimeout, "The timeout of the requests to the YARN ResourceManager.")
+}
+
+// YarnResourceReconciler synchronizes the batch resources between Koordinator and the YARN NodeManagers co-located on
+// the nodes. For each node, it records the resources allocated by YARN containers as the third-party allocations of the
+// node, which are subtracted from the batch allocatable of the node. In turn, the batch allocatable not requested by
+// the batch pods is exposed to the YARN ResourceManager as the resource of the NodeManager.
+type YarnResourceReconciler struct {
+	client.Client
+	Recorder   record.EventRecorder
+	RMClient   yarnclient.ResourceManagerClient
+	SyncPeriod time.Duration
+
+	lock           sync.Mutex
+	yarnNodes      map[string]*yarnclient.NodeInfo
+	lastListedTime time.Time
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+
+func (r *YarnResourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	node := &corev1.Node{}
+	if err := r.Client.Get(ctx, req.NamespacedName, node); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("failed to get node %v, error: %v", req.Name, err)
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	yarnNode, err := r.getYarnNode(ctx, node)
+	if err != nil {
+		klog.Errorf("failed to list yarn nodes, error: %v", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	if yarnNode == nil || yarnNode.State != yarnclient.NodeStateRunning {
+		// the allocations are released if the NodeManager is not running
+		if err := r.updateThirdPartyAllocation(ctx, node, nil); err != nil {
+			klog.Errorf("failed to reset yarn allocations of node %v, error: %v", node.Name, err)
+			return ctrl.Result{Requeue: true}, err
+		}
+		return ctrl.Result{RequeueAfter: r.SyncPeriod}, nil
+	}
+
+	if err := r.updateThirdPartyAllocation(ctx, node, getYarnAllocated(yarnNode)); err != nil {
+		klog.Errorf("failed to update yarn allocations of node %v, error: %v", node.Name, err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	if err := r.updateYarnNodeResource(ctx, node, yarnNode); err != nil {
+		r.Recorder.Eventf(node, corev1.EventTypeWarning, ReasonUpdateYarnNodeResourceFailed, "failed to update yarn node resource, err: %s", err)
+		klog.Errorf("failed to update yarn node resource of node %v, error: %v", node.Name, err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	return ctrl.Result{RequeueAfter: r.SyncPeriod}, nil
+}
+
+// getYarnNode returns the NodeManager whose host name is the node name or the hostname label of the node.
+// The NodeManagers are listed from the ResourceManager at most once in the sync period.
+func (r *YarnResourceReconciler) getYarnNode(ctx context.Context, node *corev1.Node) (*yarnclient.NodeInfo, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.yarnNodes == nil || time.Since(r.lastListedTime) >= r.SyncPeriod {
+		nodes, err := r.RMClient.ListNodes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.yarnNodes = make(map[string]*yarnclient.NodeInfo, len(nodes))
+		for i := range nodes {
+			r.yarnNodes[nodes[i].NodeHostName] = &nodes[i]
+		}
+		r.lastListedTime = time.Now()
+	}
+	if yarnNode, ok := r.yarnNodes[node.Name]; ok {
+		return yarnNode, nil
+	}
+	if hostname := node.Labels[corev1.LabelHostname]; hostname != "" {
+		return r.yarnNodes[hostname], nil
+	}
+	return nil, nil
+}
+
+func getYarnAllocated(yarnNode *yarnclient.NodeInfo) corev1.ResourceList {
+	return corev1.ResourceList{
+		extension.BatchCPU:    *resource.NewQuantity(yarnNode.UsedVirtualCores*1000, resource.DecimalSI),
+		extension.BatchMemory: *resource.NewQuantity(yarnNode.UsedMemoryMB*1024*1024, resource.BinarySI),
+	}
+}
+
+// updateThirdPartyAllocation records the YARN allocated resources in the node annotation, a nil allocated means
+// the YARN containers are all released.
+func (r *YarnResourceReconciler) updateThirdPartyAllocation(ctx context.Context, node *corev1.Node, allocated corev1.ResourceList) error {
+	var oldAllocated corev1.ResourceList
+	allocations, err := slov1alpha1.GetThirdPartyAllocations(node.Annotations)
+	if err != nil {
+		klog.V(4).Infof("failed to parse third party allocations of node %v, overwrite it, error: %v", node.Name, err)
+	}
+	exist := false
+	if allocations != nil {
+		for _, alloc := range allocations.Allocations {
+			if alloc.Name == ThirdPartyAllocationName {
+				oldAllocated, exist = alloc.Resources, true
+				break
+			}
+		}
+	}
+	if allocated == nil {
+		if !exist || quotav1.IsZero(oldAllocated) {
+			return nil
+		}
+		allocated = corev1.ResourceList{
+			extension.BatchCPU:    *resource.NewQuantity(0, resource.DecimalSI),
+			extension.BatchMemory: *resource.NewQuantity(0, resource.BinarySI),
+		}
+	} else if exist && quotav1.Equals(oldAllocated, allocated) {
+		return nil
+	}
+
+	newNode := node.DeepCopy()
+	if newNode.Annotations == nil {
+		newNode.Annotations = map[string]string{}
+	}
+	if err := slov1alpha1.SetThirdPartyAllocation(newNode.Annotations, ThirdPartyAllocationName, extension.PriorityBatch, allocated); err != nil {
+		return err
+	}
+	if err := r.Client.Patch(ctx, newNode, client.MergeFrom(node)); err != nil {
+		return err
+	}
+	klog.V(4).Infof("update yarn allocations of node %v to %v", node.Name, util.DumpJSON(allocated))
+	return nil
+}
+
+// updateYarnNodeResource updates the resource of the NodeManager to the batch allocatable which is not requested by
+// the batch pods on the node.
+func (r *YarnResourceReconciler) updateYarnNodeResource(ctx context.Context, node *corev1.Node, yarnNode *yarnclient.NodeInfo) error {
+	originAllocatable, err := slov1alpha1.GetOriginExtendedAllocatable(node.Annotations)
+	if err != nil {
+		return err
+	}
+	if originAllocatable == nil {
+		klog.V(5).Infof("skip updating yarn node resource since the batch allocatable of node %v is not calculated", node.Name)
+		return nil
+	}
+	batchAllocatable := quotav1.Mask(originAllocatable.Resources, []corev1.ResourceName{extension.BatchCPU, extension.BatchMemory})
+
+	podList := &corev1.PodList{}
+	if err := r.Client.List(ctx, podList, &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node.Name),
+	}); err != nil {
+		return err
+	}
+	podsRequested := corev1.ResourceList{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName != node.Name || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsRequested = quotav1.Add(podsRequested, util.GetPodRequest(pod, extension.BatchCPU, extension.BatchMemory))
+	}
+	yarnAllocatable := quotav1.SubtractWithNonNegativeResult(batchAllocatable, podsRequested)
+	batchMilliCPU := yarnAllocatable[extension.BatchCPU]
+	batchMemory := yarnAllocatable[extension.BatchMemory]
+	newResource := yarnclient.ResourceInfo{
+		MemoryMB: batchMemory.Value() / 1024 / 1024,
+		VCores:   batchMilliCPU.Value() / 1000,
+	}
+	if yarnNode.TotalResource != nil && *yarnNode.TotalResource == newResource {
+		return nil
+	}
+	if err := r.RMClient.UpdateNodeResource(ctx, yarnNode.ID, newResource); err != nil {
+		return err
+	}
+	r.lock.Lock()
+	yarnNode.TotalResource = &newResource
+	r.lock.Unlock()
+	klog.V(4).Infof("update yarn node %v resource to %+v", yarnNode.ID, newResource)
+	return nil
+}
+
+func Add(mgr ctrl.Manager) error {
+	if ResourceManagerAddress == "" {
+		klog.Infof("controller %v is not started since the yarn resource manager address is not specified", Name)
+		return nil
+	}
+	reconciler := &YarnResourceReconciler{
+		Client:     mgr.GetClient(),
+		Recorder:   mgr.GetEventRecorderFor("yarnresource-controller"),
+		RMClient:   yarnclient.NewResourceManagerClient(ResourceManagerAddress, RequestTimeout),
+		SyncPeriod: SyncPeriod,
+	}
+	return reconciler.SetupWithManager(mgr)
+}
+
+func (r *YarnResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the node status is updated frequently, while the nodes are synced periodically
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(Name). // avoid conflict with others reconciling `Node`
+		For(&corev1.Node{}, builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	yarnclient "github.com/koordinator-sh/koordinator/pkg/yarn/client"
+)
+
+func newTestNode(name string, originBatch corev1.ResourceList) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{corev1.LabelHostname: name + ".example.com"},
+			Annotations: map[string]string{},
+		},
+	}
+	if originBatch != nil {
+		_ = slov1alpha1.SetOriginExtendedAllocatableRes(node.Annotations, originBatch)
+	}
+	return node
+}
+
+func newTestBatchPod(name, nodeName string, phase corev1.PodPhase, milliCPU, memory int64) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							extension.BatchCPU:    *resource.NewQuantity(milliCPU, resource.DecimalSI),
+							extension.BatchMemory: *resource.NewQuantity(memory, resource.BinarySI),
+						},
+						Limits: corev1.ResourceList{
+							extension.BatchCPU:    *resource.NewQuantity(milliCPU, resource.DecimalSI),
+							extension.BatchMemory: *resource.NewQuantity(memory, resource.BinarySI),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func getYarnAllocation(t *testing.T, node *corev1.Node) *slov1alpha1.ThirdPartyAllocation {
+	allocations, err := slov1alpha1.GetThirdPartyAllocations(node.Annotations)
+	assert.NoError(t, err)
+	if allocations == nil {
+		return nil
+	}
+	for i := range allocations.Allocations {
+		if allocations.Allocations[i].Name == ThirdPartyAllocationName {
+			return &allocations.Allocations[i]
+		}
+	}
+	return nil
+}
+
+func TestYarnResourceReconciler(t *testing.T) {
+	const gi = 1024 * 1024 * 1024
+	originBatch := corev1.ResourceList{
+		extension.BatchCPU:    *resource.NewQuantity(16000, resource.DecimalSI),
+		extension.BatchMemory: *resource.NewQuantity(32*gi, resource.BinarySI),
+	}
+	tests := []struct {
+		name              string
+		node              *corev1.Node
+		pods              []*corev1.Pod
+		yarnNodes         []yarnclient.NodeInfo
+		wantAllocated     corev1.ResourceList
+		wantYarnResources map[string]*yarnclient.ResourceInfo
+	}{
+		{
+			name: "node not found in yarn",
+			node: newTestNode("node0", originBatch),
+			yarnNodes: []yarnclient.NodeInfo{
+				{ID: "node1:8041", State: yarnclient.NodeStateRunning, NodeHostName: "node1"},
+			},
+			wantAllocated: nil,
+			wantYarnResources: map[string]*yarnclient.ResourceInfo{
+				"node1:8041": nil,
+			},
+		},
+		{
+			name: "publish yarn allocations and update yarn node resource",
+			node: newTestNode("node1", originBatch),
+			pods: []*corev1.Pod{
+				newTestBatchPod("pod0", "node1", corev1.PodRunning, 4000, 8*gi),
+				newTestBatchPod("pod1", "node1", corev1.PodSucceeded, 4000, 8*gi),
+				newTestBatchPod("pod2", "node2", corev1.PodRunning, 4000, 8*gi),
+			},
+			yarnNodes: []yarnclient.NodeInfo{
+				{
+					ID:               "node1:8041",
+					State:            yarnclient.NodeStateRunning,
+					NodeHostName:     "node1",
+					UsedMemoryMB:     4096,
+					UsedVirtualCores: 2,
+					TotalResource:    &yarnclient.ResourceInfo{MemoryMB: 8192, VCores: 8},
+				},
+			},
+			wantAllocated: corev1.ResourceList{
+				extension.BatchCPU:    *resource.NewQuantity(2000, resource.DecimalSI),
+				extension.BatchMemory: *resource.NewQuantity(4*gi, resource.BinarySI),
+			},
+			wantYarnResources: map[string]*yarnclient.ResourceInfo{
+				"node1:8041": {MemoryMB: 24 * 1024, VCores: 12},
+			},
+		},
+		{
+			name: "match yarn node by hostname label",
+			node: newTestNode("node1", originBatch),
+			yarnNodes: []yarnclient.NodeInfo{
+				{
+					ID:               "node1.example.com:8041",
+					State:            yarnclient.NodeStateRunning,
+					NodeHostName:     "node1.example.com",
+					UsedMemoryMB:     1024,
+					UsedVirtualCores: 1,
+				},
+			},
+			wantAllocated: corev1.ResourceList{
+				extension.BatchCPU:    *resource.NewQuantity(1000, resource.DecimalSI),
+				extension.BatchMemory: *resource.NewQuantity(1*gi, resource.BinarySI),
+			},
+			wantYarnResources: map[string]*yarnclient.ResourceInfo{
+				"node1.example.com:8041": {MemoryMB: 32 * 1024, VCores: 16},
+			},
+		},
+		{
+			name: "batch pods request more than the origin batch allocatable",
+			node: newTestNode("node1", originBatch),
+			pods: []*corev1.Pod{
+				newTestBatchPod("pod0", "node1", corev1.PodRunning, 20000, 40*gi),
+			},
+			yarnNodes: []yarnclient.NodeInfo{
+				{ID: "node1:8041", State: yarnclient.NodeStateRunning, NodeHostName: "node1"},
+			},
+			wantAllocated: corev1.ResourceList{
+				extension.BatchCPU:    *resource.NewQuantity(0, resource.DecimalSI),
+				extension.BatchMemory: *resource.NewQuantity(0, resource.BinarySI),
+			},
+			wantYarnResources: map[string]*yarnclient.ResourceInfo{
+				"node1:8041": {MemoryMB: 0, VCores: 0},
+			},
+		},
+		{
+			name: "skip updating yarn node resource without origin batch allocatable",
+			node: newTestNode("node1", nil),
+			yarnNodes: []yarnclient.NodeInfo{
+				{ID: "node1:8041", State: yarnclient.NodeStateRunning, NodeHostName: "node1", UsedMemoryMB: 1024, UsedVirtualCores: 1},
+			},
+			wantAllocated: corev1.ResourceList{
+				extension.BatchCPU:    *resource.NewQuantity(1000, resource.DecimalSI),
+				extension.BatchMemory: *resource.NewQuantity(1*gi, resource.BinarySI),
+			},
+			wantYarnResources: map[string]*yarnclient.ResourceInfo{
+				"node1:8041": nil,
+			},
+		},
+		{
+			name: "reset yarn allocations when node manager is lost",
+			node: func() *corev1.Node {
+				node := newTestNode("node1", originBatch)
+				_ = slov1alpha1.SetThirdPartyAllocation(node.Annotations, ThirdPartyAllocationName, extension.PriorityBatch, corev1.ResourceList{
+					extension.BatchCPU:    *resource.NewQuantity(2000, resource.DecimalSI),
+					extension.BatchMemory: *resource.NewQuantity(4*gi, resource.BinarySI),
+				})
+				return node
+			}(),
+			yarnNodes: []yarnclient.NodeInfo{
+				{ID: "node1:8041", State: "LOST", NodeHostName: "node1", UsedMemoryMB: 4096, UsedVirtualCores: 2},
+			},
+			wantAllocated: corev1.ResourceList{
+				extension.BatchCPU:    *resource.NewQuantity(0, resource.DecimalSI),
+				extension.BatchMemory: *resource.NewQuantity(0, resource.BinarySI),
+			},
+			wantYarnResources: map[string]*yarnclient.ResourceInfo{
+				"node1:8041": nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(scheme)
+			objs := []client.Object{tt.node}
+			for _, pod := range tt.pods {
+				objs = append(objs, pod)
+			}
+			server := yarnclient.NewFakeServer(tt.yarnNodes, nil)
+			defer server.Close()
+
+			r := &YarnResourceReconciler{
+				Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+				Recorder:   record.NewFakeRecorder(10),
+				RMClient:   yarnclient.NewResourceManagerClient(server.URL, time.Second),
+				SyncPeriod: time.Minute,
+			}
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: tt.node.Name}})
+			assert.NoError(t, err)
+			assert.Equal(t, time.Minute, result.RequeueAfter)
+
+			gotNode := &corev1.Node{}
+			assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: tt.node.Name}, gotNode))
+			gotAllocation := getYarnAllocation(t, gotNode)
+			if tt.wantAllocated == nil {
+				assert.Nil(t, gotAllocation)
+			} else {
+				assert.NotNil(t, gotAllocation)
+				assert.Equal(t, extension.PriorityBatch, gotAllocation.Priority)
+				assert.Equal(t, tt.wantAllocated.Name(extension.BatchCPU, resource.DecimalSI).Value(), gotAllocation.Resources.Name(extension.BatchCPU, resource.DecimalSI).Value())
+				assert.Equal(t, tt.wantAllocated.Name(extension.BatchMemory, resource.BinarySI).Value(), gotAllocation.Resources.Name(extension.BatchMemory, resource.BinarySI).Value())
+			}
+
+			gotYarnResources := map[string]*yarnclient.ResourceInfo{}
+			for _, yarnNode := range server.Nodes() {
+				gotYarnResources[yarnNode.ID] = yarnNode.TotalResource
+			}
+			assert.Equal(t, tt.wantYarnResources, gotYarnResources)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package copilot
+
+import (
+	"flag"
+	"time"
+)
+
+type Config struct {
+	// NodeManagerAddress is the address of the YARN NodeManager REST API, e.g. http://localhost:8042.
+	NodeManagerAddress string
+	// NodeManagerCgroupDir is the cgroup hierarchy of the NodeManager containers relative to the cgroup root,
+	// which is the `yarn.nodemanager.linux-container-executor.cgroups.hierarchy` of the NodeManager.
+	NodeManagerCgroupDir string
+	// CgroupDir is the cgroup dir of the YARN containers relative to the best-effort cgroup of the kubepods.
+	CgroupDir string
+	// SyncInterval is the interval to sync the cgroups of the YARN containers.
+	SyncInterval time.Duration
+	// RequestTimeout is the timeout of the requests to the NodeManager.
+	RequestTimeout time.Duration
+	// MemoryEvictThresholdPercent is the node memory usage percent to evict the YARN containers, 0 means disabled.
+	MemoryEvictThresholdPercent int64
+	// MemoryEvictLowerPercent is the node memory usage percent the eviction stops at, and defaults to
+	// MemoryEvictThresholdPercent - 2 when it is 0.
+	MemoryEvictLowerPercent int64
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		NodeManagerAddress:          "http://localhost:8042",
+		NodeManagerCgroupDir:        "hadoop-yarn",
+		CgroupDir:                   "hadoop-yarn",
+		SyncInterval:                10 * time.Second,
+		RequestTimeout:              5 * time.Second,
+		MemoryEvictThresholdPercent: 0,
+		MemoryEvictLowerPercent:     0,
+	}
+}
+
+func (c *Config) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.NodeManagerAddress, "yarn-nm-address", c.NodeManagerAddress, "The address of the YARN NodeManager REST API.")
+	fs.StringVar(&c.NodeManagerCgroupDir, "yarn-nm-cgroup-dir", c.NodeManagerCgroupDir, "The cgroup hierarchy of the YARN NodeManager containers relative to the cgroup root.")
+	fs.StringVar(&c.CgroupDir, "yarn-cgroup-dir", c.CgroupDir, "The cgroup dir of the YARN containers relative to the best-effort cgroup of the kubepods.")
+	fs.DurationVar(&c.SyncInterval, "sync-interval", c.SyncInterval, "The interval to sync the cgroups of the YARN containers.")
+	fs.DurationVar(&c.RequestTimeout, "request-timeout", c.RequestTimeout, "The timeout of the requests to the YARN NodeManager.")
+	fs.Int64Var(&c.MemoryEvictThresholdPercent, "memory-evict-threshold-percent", c.MemoryEvictThresholdPercent, "The node memory usage percent to evict the YARN containers, 0 means disabled.")
+	fs.Int64Var(&c.MemoryEvictLowerPercent, "memory-evict-lower-percent", c.MemoryEvictLowerPercent, "The node memory usage percent the eviction stops at, defaults to memory-evict-threshold-percent - 2.")
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package copilot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	yarnclient "github.com/koordinator-sh/koordinator/pkg/yarn/client"
+)
+
+const cpusetMemsName = "cpuset.mems"
+
+// YarnCopilot runs on the node beside the YARN NodeManager. It moves the processes of the YARN containers from the
+// cgroups of the NodeManager into the best-effort cgroup of the kubepods, so the YARN containers are suppressed by
+// the koordlet like the best-effort pods. It also kills the YARN containers when the node memory usage is too high,
+// since the koordlet only evicts the pods.
+type YarnCopilot struct {
+	config       *Config
+	nmClient     yarnclient.NodeManagerClient
+	cgroupReader resourceexecutor.CgroupReader
+	getMemInfo   func() (*koordletutil.MemInfo, error)
+	killProcess  func(pid int) error
+}
+
+func NewYarnCopilot(config *Config) *YarnCopilot {
+	return &YarnCopilot{
+		config:       config,
+		nmClient:     yarnclient.NewNodeManagerClient(config.NodeManagerAddress, config.RequestTimeout),
+		cgroupReader: resourceexecutor.NewCgroupReader(),
+		getMemInfo:   koordletutil.GetMemInfo,
+		killProcess: func(pid int) error {
+			return syscall.Kill(pid, syscall.SIGKILL)
+		},
+	}
+}
+
+func (c *YarnCopilot) Run(stopCh <-chan struct{}) error {
+	klog.Infof("starting yarn copilot, nodemanager %v", c.config.NodeManagerAddress)
+	wait.Until(c.sync, c.config.SyncInterval, stopCh)
+	return nil
+}
+
+func (c *YarnCopilot) sync() {
+	containers, err := c.nmClient.ListContainers(context.TODO())
+	if err != nil {
+		klog.Warningf("failed to list yarn containers, error: %v", err)
+		return
+	}
+	running := map[string]bool{}
+	for _, container := range containers {
+		if container.State != yarnclient.ContainerStateRunning {
+			continue
+		}
+		running[container.ID] = true
+		if err := c.attachContainer(container.ID); err != nil {
+			klog.Warningf("failed to attach yarn container %v to cgroup, error: %v", container.ID, err)
+		}
+	}
+	c.cleanupContainers(running)
+	c.evictByMemory(running)
+}
+
+// parentCgroupDir returns the cgroup dir of the YARN containers relative to the cgroup root,
+// e.g. kubepods.slice/kubepods-besteffort.slice/hadoop-yarn
+func (c *YarnCopilot) parentCgroupDir() string {
+	return filepath.Join(koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort), c.config.CgroupDir)
+}
+
+func (c *YarnCopilot) containerCgroupDir(containerID string) string {
+	return filepath.Join(c.parentCgroupDir(), containerID)
+}
+
+func getSubsystems() []string {
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		return []string{system.CgroupV2Dir}
+	}
+	return []string{system.CgroupCPUDir, system.CgroupCPUAcctDir, system.CgroupCPUSetDir, system.CgroupMemDir}
+}
+
+// attachContainer creates the cgroups of the container under the best-effort cgroup, and moves the processes of the
+// container from the NodeManager cgroups into them.
+func (c *YarnCopilot) attachContainer(containerID string) error {
+	targetDir := c.containerCgroupDir(containerID)
+	sourceDir := filepath.Join(c.config.NodeManagerCgroupDir, containerID)
+	for _, subfs := range getSubsystems() {
+		subfsRoot := system.GetRootCgroupSubfsDir(subfs)
+		beDir := filepath.Join(subfsRoot, koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort))
+		if err := ensureCgroupDir(beDir, filepath.Join(c.config.CgroupDir, containerID), subfs == system.CgroupCPUSetDir); err != nil {
+			return err
+		}
+		if filepath.Clean(sourceDir) == filepath.Clean(targetDir) {
+			continue
+		}
+		sourceProcsPath := filepath.Join(subfsRoot, sourceDir, system.CPUProcsName)
+		if !system.FileExists(sourceProcsPath) {
+			continue
+		}
+		content, err := system.CommonFileRead(sourceProcsPath)
+		if err != nil {
+			return err
+		}
+		pids, err := system.ParseCgroupProcs(content)
+		if err != nil {
+			return err
+		}
+		targetProcsPath := filepath.Join(subfsRoot, targetDir, system.CPUProcsName)
+		for _, pid := range pids {
+			// the process may have exited
+			if err := system.CommonFileWrite(targetProcsPath, strconv.FormatUint(uint64(pid), 10)); err != nil {
+				klog.V(4).Infof("failed to move process %v of yarn container %v to %v, error: %v", pid, containerID, targetProcsPath, err)
+			}
+		}
+		if len(pids) > 0 {
+			klog.V(5).Infof("move %v processes of yarn container %v to %v", len(pids), containerID, targetProcsPath)
+		}
+	}
+	return nil
+}
+
+// ensureCgroupDir creates the cgroup dir under the base dir level by level, and the cpuset of each new cpuset cgroup is
+// initialized with its parent, otherwise no process can be attached.
+func ensureCgroupDir(baseDir, relativeDir string, isCPUSet bool) error {
+	if !system.FileExists(baseDir) {
+		return fmt.Errorf("cgroup dir %s not exist", baseDir)
+	}
+	parent := baseDir
+	for _, name := range splitPath(relativeDir) {
+		dir := filepath.Join(parent, name)
+		if !system.FileExists(dir) {
+			if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+				return err
+			}
+			if isCPUSet {
+				for _, file := range []string{system.CPUSetCPUSName, cpusetMemsName} {
+					value, err := system.CommonFileRead(filepath.Join(parent, file))
+					if err != nil {
+						return err
+					}
+					if err = system.CommonFileWrite(filepath.Join(dir, file), value); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		parent = dir
+	}
+	return nil
+}
+
+func splitPath(path string) []string {
+	var names []string
+	for dir := filepath.Clean(path); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		names = append([]string{filepath.Base(dir)}, names...)
+	}
+	return names
+}
+
+// cleanupContainers removes the cgroups of the containers which are not running.
+func (c *YarnCopilot) cleanupContainers(running map[string]bool) {
+	for _, subfs := range getSubsystems() {
+		parentDir := filepath.Join(system.GetRootCgroupSubfsDir(subfs), c.parentCgroupDir())
+		entries, err := os.ReadDir(parentDir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				klog.Warningf("failed to read yarn cgroup dir %v, error: %v", parentDir, err)
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || running[entry.Name()] {
+				continue
+			}
+			dir := filepath.Join(parentDir, entry.Name())
+			// cgroupfs only allows rmdir, and the cgroup with processes cannot be removed
+			if err := syscall.Rmdir(dir); err != nil {
+				klog.V(4).Infof("failed to remove cgroup %v of stale yarn container, error: %v", dir, err)
+				continue
+			}
+			klog.V(4).Infof("remove cgroup %v of stale yarn container", dir)
+		}
+	}
+}
+
+// evictByMemory kills the YARN containers with the largest memory usage until the node memory usage is lower than
+// MemoryEvictLowerPercent, if it exceeds MemoryEvictThresholdPercent.
+func (c *YarnCopilot) evictByMemory(running map[string]bool) {
+	if c.config.MemoryEvictThresholdPercent <= 0 {
+		return
+	}
+	lowerPercent := c.config.MemoryEvictLowerPercent
+	if lowerPercent <= 0 {
+		lowerPercent = c.config.MemoryEvictThresholdPercent - 2
+	}
+	memInfo, err := c.getMemInfo()
+	if err != nil {
+		klog.Warningf("failed to get node memory info, error: %v", err)
+		return
+	}
+	total := int64(memInfo.MemTotalBytes())
+	usage := int64(memInfo.MemUsageBytes())
+	if total <= 0 || usage*100 < total*c.config.MemoryEvictThresholdPercent {
+		return
+	}
+
+	type containerUsage struct {
+		id    string
+		usage int64
+	}
+	var containers []containerUsage
+	for id := range running {
+		memStat, err := c.cgroupReader.ReadMemoryStat(c.containerCgroupDir(id))
+		if err != nil {
+			klog.V(4).Infof("failed to read memory stat of yarn container %v, error: %v", id, err)
+			continue
+		}
+		containers = append(containers, containerUsage{id: id, usage: memStat.Usage()})
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		if containers[i].usage != containers[j].usage {
+			return containers[i].usage > containers[j].usage
+		}
+		return containers[i].id < containers[j].id
+	})
+
+	for _, container := range containers {
+		if usage*100 < total*lowerPercent {
+			break
+		}
+		if err := c.killContainer(container.id); err != nil {
+			klog.Warningf("failed to kill yarn container %v, error: %v", container.id, err)
+			continue
+		}
+		usage -= container.usage
+		klog.Infof("kill yarn container %v to release memory %v bytes, node memory usage %v/%v exceeds threshold %v%%",
+			container.id, container.usage, usage+container.usage, total, c.config.MemoryEvictThresholdPercent)
+	}
+}
+
+func (c *YarnCopilot) killContainer(containerID string) error {
+	pids, err := c.cgroupReader.ReadCPUProcs(c.containerCgroupDir(containerID))
+	if err != nil {
+		return err
+	}
+	if len(pids) == 0 {
+		return fmt.Errorf("no process found")
+	}
+	for _, pid := range pids {
+		if err := c.killProcess(int(pid)); err != nil && err != syscall.ESRCH {
+			return err
+		}
+	}
+	return nil
+}
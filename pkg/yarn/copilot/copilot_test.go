@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package copilot
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	yarnclient "github.com/koordinator-sh/koordinator/pkg/yarn/client"
+)
+
+func newTestMemoryStat(usage int64) string {
+	return fmt.Sprintf("total_cache 0\ntotal_rss %d\ntotal_inactive_file 0\ntotal_active_file 0\n"+
+		"total_inactive_anon 0\ntotal_active_anon %d\ntotal_unevictable 0\n", usage, usage)
+}
+
+func newTestCopilot(t *testing.T, server *yarnclient.FakeServer, memInfo *koordletutil.MemInfo, killed *[]int) *YarnCopilot {
+	config := NewDefaultConfig()
+	config.NodeManagerAddress = server.URL
+	config.RequestTimeout = time.Second
+	config.MemoryEvictThresholdPercent = 80
+	return &YarnCopilot{
+		config:       config,
+		nmClient:     yarnclient.NewNodeManagerClient(server.URL, time.Second),
+		cgroupReader: resourceexecutor.NewCgroupReader(),
+		getMemInfo: func() (*koordletutil.MemInfo, error) {
+			return memInfo, nil
+		},
+		killProcess: func(pid int) error {
+			*killed = append(*killed, pid)
+			return nil
+		},
+	}
+}
+
+func TestYarnCopilotAttachContainers(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.SetCgroupsV2(false)
+
+	beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	helper.WriteFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, system.CPUSetCPUSName), "0-7")
+	helper.WriteFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, cpusetMemsName), "0")
+	helper.WriteFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, "hadoop-yarn", system.CPUSetCPUSName), "0-7")
+	helper.WriteFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, "hadoop-yarn", cpusetMemsName), "0")
+	for _, subfs := range getSubsystems() {
+		helper.WriteFileContents(filepath.Join(subfs, "hadoop-yarn", "container_01", system.CPUProcsName), "100\n101\n")
+		helper.MkDirAll(filepath.Join(subfs, beDir, "hadoop-yarn", "container_stale"))
+	}
+
+	server := yarnclient.NewFakeServer(nil, []yarnclient.ContainerInfo{
+		{ID: "container_01", State: yarnclient.ContainerStateRunning},
+		{ID: "container_02", State: "NEW"},
+	})
+	defer server.Close()
+	var killed []int
+	c := newTestCopilot(t, server, &koordletutil.MemInfo{MemTotal: 100, MemAvailable: 50}, &killed)
+	c.sync()
+
+	for _, subfs := range getSubsystems() {
+		containerDir := filepath.Join(subfs, beDir, "hadoop-yarn", "container_01")
+		assert.True(t, system.FileExists(filepath.Join(helper.TempDir, containerDir)), subfs)
+		// the file is overwritten by each process in test
+		assert.Equal(t, "101", helper.ReadFileContents(filepath.Join(containerDir, system.CPUProcsName)), subfs)
+		assert.False(t, system.FileExists(filepath.Join(helper.TempDir, subfs, beDir, "hadoop-yarn", "container_02")), subfs)
+		assert.False(t, system.FileExists(filepath.Join(helper.TempDir, subfs, beDir, "hadoop-yarn", "container_stale")), subfs)
+	}
+	assert.Equal(t, "0-7", helper.ReadFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, "hadoop-yarn", "container_01", system.CPUSetCPUSName)))
+	assert.Equal(t, "0", helper.ReadFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, "hadoop-yarn", "container_01", cpusetMemsName)))
+	assert.Empty(t, killed)
+}
+
+func TestYarnCopilotEvictByMemory(t *testing.T) {
+	const gi = 1024 * 1024 * 1024
+	tests := []struct {
+		name       string
+		memInfo    *koordletutil.MemInfo
+		wantKilled []int
+	}{
+		{
+			name:       "memory usage below threshold",
+			memInfo:    &koordletutil.MemInfo{MemTotal: 10 * gi / 1024, MemAvailable: 3 * gi / 1024},
+			wantKilled: nil,
+		},
+		{
+			name:       "kill the container with the largest memory usage",
+			memInfo:    &koordletutil.MemInfo{MemTotal: 10 * gi / 1024, MemAvailable: 1 * gi / 1024},
+			wantKilled: []int{300},
+		},
+		{
+			name:       "kill containers until memory usage below lower percent",
+			memInfo:    &koordletutil.MemInfo{MemTotal: 10 * gi / 1024, MemAvailable: 0},
+			wantKilled: []int{100, 101, 300},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(false)
+
+			beDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+			helper.WriteFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, system.CPUSetCPUSName), "0-7")
+			helper.WriteFileContents(filepath.Join(system.CgroupCPUSetDir, beDir, cpusetMemsName), "0")
+			helper.MkDirAll(filepath.Join(system.CgroupCPUAcctDir, beDir))
+			containers := map[string]struct {
+				procs string
+				usage int64
+			}{
+				"container_01": {procs: "100\n101\n", usage: 1 * gi},
+				"container_02": {procs: "200\n", usage: gi / 2},
+				"container_03": {procs: "300\n", usage: 2 * gi},
+			}
+			var containerInfos []yarnclient.ContainerInfo
+			for id, container := range containers {
+				containerDir := filepath.Join(beDir, "hadoop-yarn", id)
+				helper.WriteFileContents(filepath.Join(system.CgroupCPUDir, containerDir, system.CPUProcsName), container.procs)
+				helper.WriteFileContents(filepath.Join(system.CgroupMemDir, containerDir, system.MemoryStatName), newTestMemoryStat(container.usage))
+				containerInfos = append(containerInfos, yarnclient.ContainerInfo{ID: id, State: yarnclient.ContainerStateRunning})
+			}
+
+			server := yarnclient.NewFakeServer(nil, containerInfos)
+			defer server.Close()
+			var killed []int
+			c := newTestCopilot(t, server, tt.memInfo, &killed)
+			c.sync()
+			sort.Ints(killed)
+			assert.Equal(t, tt.wantKilled, killed)
+		})
+	}
+}